@@ -19,6 +19,9 @@ const (
 	ErrClosingDatabaseInstanceCode                = "1012"
 	ErrInitializingRegistryManagerCode            = "1013"
 	ErrInitializingKeysRegistrationCode           = "1569"
+	ErrConfiguringSVGStorageCode                  = "1586"
+	ErrSetupTelemetryCode                         = "1621"
+	ErrShutdownTelemetryCode                      = "1622"
 )
 
 func ErrInitializingRegistryManager(err error) error {
@@ -72,3 +75,15 @@ func ErrClosingDatabaseInstance(err error) error {
 func ErrInitializingKeysRegistration(err error) error {
 	return errors.New(ErrInitializingKeysRegistrationCode, errors.Fatal, []string{"could not initialize keys registry manager"}, []string{err.Error()}, []string{"could not migrate tables into the database"}, []string{"make sure the database instance passed is not nil"})
 }
+
+func ErrConfiguringSVGStorage(err error) error {
+	return errors.New(ErrConfiguringSVGStorageCode, errors.Alert, []string{"could not configure the svg storage, falling back to the file system"}, []string{err.Error()}, []string{"the SVG_STORAGE_* settings are incomplete or invalid"}, []string{"set SVG_STORAGE_BACKEND to \"filesystem\" or \"s3\" and SVG_STORAGE_BUCKET when using \"s3\""})
+}
+
+func ErrSetupTelemetry(err error) error {
+	return errors.New(ErrSetupTelemetryCode, errors.Alert, []string{"could not set up the OpenTelemetry exporters, telemetry is disabled"}, []string{err.Error()}, []string{"the OTEL_EXPORTER_OTLP_* settings are invalid"}, []string{"check the OTEL_EXPORTER_OTLP_ENDPOINT setting or unset OTEL_ENABLED"})
+}
+
+func ErrShutdownTelemetry(err error) error {
+	return errors.New(ErrShutdownTelemetryCode, errors.Alert, []string{"could not flush the pending telemetry on shutdown"}, []string{err.Error()}, []string{"the OTLP collector is unreachable"}, []string{"make sure the collector at OTEL_EXPORTER_OTLP_ENDPOINT is reachable"})
+}
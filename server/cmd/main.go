@@ -22,6 +22,7 @@ import (
 	meshmodelhelper "github.com/layer5io/meshery/server/meshmodel"
 	"github.com/layer5io/meshery/server/models"
 	mesherymeshmodel "github.com/layer5io/meshery/server/models/meshmodel"
+	mcore "github.com/layer5io/meshery/server/models/meshmodel/core"
 	"github.com/layer5io/meshery/server/router"
 	"github.com/layer5io/meshkit/broker/nats"
 	"github.com/layer5io/meshkit/logger"
@@ -112,9 +113,63 @@ func main() {
 	viper.SetDefault("REGISTER_STATIC_K8S", true)
 	viper.SetDefault("SKIP_DOWNLOAD_CONTENT", false)
 	viper.SetDefault("SKIP_COMP_GEN", false)
+	// The paths of the SVGs of the components written on the file system are relative to the root of the repository
+	viper.SetDefault("COMPONENTS_SVG_ROOT", "../..")
+	viper.SetDefault("KUBECONFIG_CONTENT", "")
+	viper.SetDefault("KUBECONFIG_DIRECTORY_MODE", false)
+	viper.SetDefault("KUBECONFIG_SECRET", "")
+	viper.SetDefault("REQUIRE_KUBECONFIG_FLATTEN", false)
+	viper.SetDefault("KUBECONFIG_CURRENT_CONTEXT_POLICY", handlers.K8sCurrentContextPolicyBestEffort)
+	viper.SetDefault("COMPRESS_STORED_KUBECONFIG", false)
+	viper.SetDefault("KUBERNETES_CLOCK_SKEW_THRESHOLD", models.DefaultClockSkewThreshold)
+	viper.SetDefault("EVENT_METADATA_MAX_SIZE", models.DefaultEventMetadataMaxSize)
+	viper.SetDefault("K8S_CONTEXT_SAVE_RETRIES", 5)
+	viper.SetDefault("K8S_CONTEXT_SAVE_BACKOFF", time.Second)
+	viper.SetDefault("KUBERNETES_PROBE_TIMEOUT", 5*time.Second)
+	viper.SetDefault("KUBERNETES_DIAL_TIMEOUT", models.DefaultK8sDialTimeout)
+	viper.SetDefault("KUBERNETES_TLS_HANDSHAKE_TIMEOUT", models.DefaultK8sTLSHandshakeTimeout)
+	viper.SetDefault("KUBERNETES_RESPONSE_HEADER_TIMEOUT", models.DefaultK8sResponseHeaderTimeout)
+	viper.SetDefault("KUBERNETES_CLIENT_QPS", models.DefaultK8sClientQPS)
+	viper.SetDefault("KUBERNETES_CLIENT_BURST", models.DefaultK8sClientBurst)
+	viper.SetDefault("KUBERNETES_STATS_CACHE_TTL", 30*time.Second)
+	viper.SetDefault("KUBERNETES_PING_CACHE_TTL", 30*time.Second)
+	viper.SetDefault("KUBERNETES_OPENAPI_CACHE_TTL", 10*time.Minute)
+	viper.SetDefault("KUBERNETES_CAPABILITIES_CACHE_TTL", 10*time.Minute)
+	viper.SetDefault("OPERATOR_CHART_CACHE_TTL", time.Hour)
+	viper.SetDefault("KUBERNETES_DEV_MODE", false)
+	viper.SetDefault("MESHSYNC_RESYNC_TIMEOUT", 2*time.Minute)
+	viper.SetDefault("KUBERNETES_COMPONENT_EXCLUDED_NAMESPACES", mcore.DefaultExcludedNamespaces)
+	viper.SetDefault("KUBERNETES_PRIMARY_CONTEXT", "")
+	viper.SetDefault("KUBERNETES_FALLBACK_CONTEXT", "")
+	viper.SetDefault("CONNECTION_WEBHOOK_RETRIES", 3)
+	viper.SetDefault("CONNECTION_WEBHOOK_BACKOFF", time.Second)
+	viper.SetDefault("CONNECTION_FAILURE_THRESHOLD", 1)
+	viper.SetDefault("CONNECTION_FAILURE_GRACE_PERIOD", 0)
+	viper.SetDefault("CONNECTION_RECOVERY_ENABLED", false)
+	viper.SetDefault("CONNECTION_RECOVERY_INTERVAL", time.Minute)
+	viper.SetDefault("CONNECTION_EXPIRY_SWEEP_INTERVAL", time.Minute)
+	viper.SetDefault("CONNECTION_DELETION_GRACE_PERIOD", models.DefaultK8sContextDeletionGracePeriod)
+	viper.SetDefault("SVG_STORAGE_BACKEND", utils.FileSystemSVGStorage)
 	viper.SetDefault("PLAYGROUND", false)
+	viper.SetDefault("OTEL_ENABLED", false)
+	viper.SetDefault("OTEL_SERVICE_NAME", "meshery-server")
+	viper.SetDefault("OTEL_METRIC_EXPORT_INTERVAL", time.Minute)
 	store.Initialize()
 
+	shutdownTelemetry, err := setupTelemetry(ctx)
+	if err != nil {
+		log.Error(ErrSetupTelemetry(err))
+		shutdownTelemetry = func(context.Context) error { return nil }
+	}
+
+	utils.SetSVGLogger(log)
+	svgStore, err := utils.NewSVGStoreFromConfig()
+	if err != nil {
+		log.Error(ErrConfiguringSVGStorage(err))
+	} else {
+		utils.SetSVGStore(svgStore)
+	}
+
 	log.Info("Local Provider capabilities are: ", version)
 
 	// Get the channel
@@ -195,6 +250,8 @@ func main() {
 		&models.PerformanceTestConfig{},
 		&models.SmiResultWithID{},
 		models.K8sContext{},
+		models.K8sComponentsRegistrationCheckpoint{},
+		models.K8sComponentsRegistryHost{},
 		models.Organization{},
 		models.Key{},
 		_events.Event{},
@@ -251,6 +308,8 @@ func main() {
 
 		K8scontextChannel: models.NewContextHelper(),
 		OperatorTracker:   models.NewOperatorTracker(viper.GetBool("DISABLE_OPERATOR")),
+
+		K8sContextRecycleBin: models.NewK8sContextRecycleBinFromConfig(log),
 	}
 	krh, err := models.NewKeysRegistrationHelper(dbHandler, log)
 	if err != nil {
@@ -310,6 +369,9 @@ func main() {
 
 	models.InitMeshSyncRegistrationQueue()
 	mhelpers.InitRegistrationHelperSingleton(dbHandler, log, &connToInstanceTracker, hc.EventBroadcaster)
+	go mhelpers.NewConnectionRecoveryControllerFromConfig(&connToInstanceTracker, log, hc.EventBroadcaster).Run(ctx)
+	go mhelpers.NewConnectionExpirySweeperFromConfig(&connToInstanceTracker, hc.K8sContextRecycleBin, log, hc.EventBroadcaster).Run(ctx)
+	go hc.K8sContextRecycleBin.Run(ctx)
 	h := handlers.NewHandlerInstance(hc, meshsyncCh, log, brokerConn, k8sComponentsRegistrationHelper, mctrlHelper, dbHandler, events.NewEventStreamer(), regManager, viper.GetString("PROVIDER"), rego, &connToInstanceTracker)
 
 	b := broadcast.NewBroadcaster(100)
@@ -365,5 +427,11 @@ func main() {
 		log.Error(ErrClosingDatabaseInstance(err))
 	}
 
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), telemetryShutdownTimeout)
+	defer cancel()
+	if err := shutdownTelemetry(shutdownCtx); err != nil {
+		log.Error(ErrShutdownTelemetry(err))
+	}
+
 	log.Info("Shutting down Meshery Server...")
 }
@@ -1,7 +1,14 @@
 package main
 
 import (
+	"context"
 	"testing"
+	"time"
+
+	"github.com/spf13/viper"
+	"go.opentelemetry.io/otel"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
 )
 
 func TestMain(t *testing.T) {
@@ -11,3 +18,33 @@ func TestMain(t *testing.T) {
 
 	t.Log("Need to run main() skipping")
 }
+
+func TestSetupTelemetry(t *testing.T) {
+	defer viper.Reset()
+
+	viper.Set("OTEL_ENABLED", false)
+	if _, err := setupTelemetry(context.Background()); err != nil {
+		t.Fatalf("setupTelemetry() error = %v", err)
+	}
+	if _, ok := otel.GetTracerProvider().(*sdktrace.TracerProvider); ok {
+		t.Fatal("setupTelemetry() registered a tracer provider with OTEL_ENABLED unset")
+	}
+
+	viper.Set("OTEL_ENABLED", true)
+	viper.Set("OTEL_METRIC_EXPORT_INTERVAL", time.Minute)
+	shutdown, err := setupTelemetry(context.Background())
+	if err != nil {
+		t.Fatalf("setupTelemetry() error = %v", err)
+	}
+	if _, ok := otel.GetTracerProvider().(*sdktrace.TracerProvider); !ok {
+		t.Errorf("otel.GetTracerProvider() = %T, want *trace.TracerProvider", otel.GetTracerProvider())
+	}
+	if _, ok := otel.GetMeterProvider().(*sdkmetric.MeterProvider); !ok {
+		t.Errorf("otel.GetMeterProvider() = %T, want *metric.MeterProvider", otel.GetMeterProvider())
+	}
+
+	// Nothing listens on the collector endpoint, only the shutdown of the providers matters here
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	_ = shutdown(ctx)
+}
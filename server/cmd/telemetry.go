@@ -0,0 +1,62 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/spf13/viper"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/exporters/otlp/otlpmetric/otlpmetrichttp"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.21.0"
+)
+
+// setupTelemetry registers the global tracer and meter providers exporting over OTLP/HTTP when "OTEL_ENABLED" is set.
+// The exporters are configured by the standard OTEL_EXPORTER_OTLP_* environment variables,
+// the returned function flushes and shuts the providers down. Without "OTEL_ENABLED" the providers stay no-ops.
+func setupTelemetry(ctx context.Context) (func(context.Context) error, error) {
+	if !viper.GetBool("OTEL_ENABLED") {
+		return func(context.Context) error { return nil }, nil
+	}
+
+	res, err := resource.Merge(resource.Default(), resource.NewWithAttributes(
+		semconv.SchemaURL,
+		semconv.ServiceName(viper.GetString("OTEL_SERVICE_NAME")),
+		semconv.ServiceVersion(viper.GetString("BUILD")),
+	))
+	if err != nil {
+		return nil, err
+	}
+
+	traceExporter, err := otlptracehttp.New(ctx)
+	if err != nil {
+		return nil, err
+	}
+	metricExporter, err := otlpmetrichttp.New(ctx)
+	if err != nil {
+		_ = traceExporter.Shutdown(ctx)
+		return nil, err
+	}
+
+	tracerProvider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(traceExporter),
+		sdktrace.WithResource(res),
+	)
+	meterProvider := sdkmetric.NewMeterProvider(
+		sdkmetric.WithReader(sdkmetric.NewPeriodicReader(metricExporter, sdkmetric.WithInterval(viper.GetDuration("OTEL_METRIC_EXPORT_INTERVAL")))),
+		sdkmetric.WithResource(res),
+	)
+	otel.SetTracerProvider(tracerProvider)
+	otel.SetMeterProvider(meterProvider)
+
+	return func(ctx context.Context) error {
+		return errors.Join(tracerProvider.Shutdown(ctx), meterProvider.Shutdown(ctx))
+	}, nil
+}
+
+// telemetryShutdownTimeout bounds the flush of the pending spans and metrics on shutdown
+const telemetryShutdownTimeout = 5 * time.Second
@@ -70,7 +70,7 @@ func (h *Handler) MeshModelGenerationHandler(rw http.ResponseWriter, r *http.Req
 		}
 		if gpi.Register {
 			for _, comp := range comps {
-				utils.WriteSVGsOnFileSystem(&comp)
+				utils.WriteSVGs(&comp)
 				host := fmt.Sprintf("%s.artifacthub.meshery", gpi.Name)
 				err = h.registryManager.RegisterEntity(meshmodel.Host{
 					IHost:    meshmodel.ArtifactHub{},
@@ -1265,7 +1265,7 @@ func (h *Handler) RegisterMeshmodelComponents(rw http.ResponseWriter, r *http.Re
 			http.Error(rw, err.Error(), http.StatusBadRequest)
 			return
 		}
-		utils.WriteSVGsOnFileSystem(&c)
+		utils.WriteSVGs(&c)
 		err = h.registryManager.RegisterEntity(cc.Host, c)
 	}
 	if err != nil {
@@ -253,6 +253,15 @@ func (h *Handler) GetConnections(w http.ResponseWriter, req *http.Request, prefO
 		http.Error(w, ErrQueryGet(obj).Error(), http.StatusInternalServerError)
 		return
 	}
+	// Providers which do not persist the reason of the status are backfilled from the machines of the connections.
+	for _, connection := range connectionsPage.Connections {
+		if connection.StatusReason != "" {
+			continue
+		}
+		if inst, ok := h.ConnectionToStateMachineInstanceTracker.Get(connection.ID); ok {
+			connection.StatusReason = inst.StatusReason()
+		}
+	}
 
 	if err := json.NewEncoder(w).Encode(connectionsPage); err != nil {
 		h.log.Error(models.ErrEncoding(err, obj))
@@ -432,7 +441,7 @@ func (h *Handler) UpdateConnectionStatus(w http.ResponseWriter, req *http.Reques
 	} else {
 		token, _ := req.Context().Value(models.TokenCtxKey).(string)
 		for id, status := range *connectionStatusPayload {
-			connection, statusCode, err := provider.UpdateConnectionStatusByID(token, id, status)
+			connection, statusCode, err := provider.UpdateConnectionStatusByID(token, id, status, machines.DefaultStatusReason(helpers.StatusToEvent(status), machines.StateType(status), nil))
 
 			if err != nil {
 				event := events.NewEvent().WithCategory("connection").WithAction("update").WithSeverity(events.Error).ActedUpon(id).FromUser(userID).FromSystem(*h.SystemID).WithDescription(fmt.Sprintf("Unable to update connection status to %s", status)).WithMetadata(map[string]interface{}{"error": err}).Build()
@@ -1,9 +1,13 @@
 package handlers
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"sort"
+	"strconv"
+	"strings"
 
 	"github.com/gofrs/uuid"
 	"github.com/gorilla/mux"
@@ -11,6 +15,7 @@ import (
 	mhelpers "github.com/layer5io/meshery/server/machines/helpers"
 	"github.com/layer5io/meshery/server/machines/kubernetes"
 	"github.com/layer5io/meshery/server/models"
+	"github.com/layer5io/meshery/server/models/connections"
 	"github.com/layer5io/meshkit/models/events"
 )
 
@@ -26,6 +31,10 @@ import (
 // ```?pagesize={pagesize}``` Default pagesize is 10
 //
 // ```?search={contextname}``` If search is non empty then a greedy search is performed
+//
+// ```?pinned_first=true``` lists the pinned contexts ahead of the others, each in the passed order
+//
+// ```?environment={name}``` lists the contexts of the connections of the environment only, ```?orgID={orgid}``` being its organization
 // responses:
 //
 //	200: systemK8sContextsResponseWrapper
@@ -37,6 +46,44 @@ func (h *Handler) GetAllContexts(w http.ResponseWriter, req *http.Request, _ *mo
 	}
 
 	q := req.URL.Query()
+	// The providers neither order by pin nor filter by environment, so the contexts are paged here.
+	pinnedFirst, _ := strconv.ParseBool(q.Get("pinned_first"))
+	if environment := q.Get("environment"); pinnedFirst || environment != "" {
+		contexts, err := loadK8sContexts(provider, token, q.Get("search"), q.Get("order"))
+		if err != nil {
+			h.log.Error(err)
+			http.Error(w, "failed to get contexts", http.StatusInternalServerError)
+			return
+		}
+		if environment != "" {
+			connectionIDs, err := k8sEnvironmentConnectionIDs(req, provider, token, environment, q.Get("orgID"))
+			if err != nil {
+				h.log.Error(err)
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+			inEnvironment := make([]*models.K8sContext, 0, len(connectionIDs))
+			for _, ctx := range contexts {
+				if inK8sEnvironment(ctx, connectionIDs) {
+					inEnvironment = append(inEnvironment, ctx)
+				}
+			}
+			contexts = inEnvironment
+		}
+		if pinnedFirst {
+			sortPinnedK8sContextsFirst(contexts)
+		}
+		page, _ := strconv.ParseUint(q.Get("page"), 10, 32)
+		pageSize, err := strconv.ParseUint(q.Get("pagesize"), 10, 32)
+		if err != nil || pageSize == 0 {
+			pageSize = 10
+		}
+		if err := json.NewEncoder(w).Encode(k8sContextsPage(contexts, page, pageSize)); err != nil {
+			http.Error(w, "failed to encode contexts", http.StatusInternalServerError)
+		}
+		return
+	}
+
 	// Don't fetch credentials as UI has no use case.
 	vals, err := provider.GetK8sContexts(token, q.Get("page"), q.Get("pagesize"), q.Get("search"), q.Get("order"), "", false)
 	if err != nil {
@@ -56,6 +103,316 @@ func (h *Handler) GetAllContexts(w http.ResponseWriter, req *http.Request, _ *mo
 	}
 }
 
+// sortPinnedK8sContextsFirst moves the pinned contexts ahead of the others, keeping the order of the contexts otherwise
+func sortPinnedK8sContextsFirst(contexts []*models.K8sContext) {
+	sort.SliceStable(contexts, func(i, j int) bool {
+		return contexts[i].Pinned && !contexts[j].Pinned
+	})
+}
+
+// k8sContextsPage returns the page of the contexts
+func k8sContextsPage(contexts []*models.K8sContext, page, pageSize uint64) models.MesheryK8sContextPage {
+	start := page * pageSize
+	if start > uint64(len(contexts)) {
+		start = uint64(len(contexts))
+	}
+	end := start + pageSize
+	if end > uint64(len(contexts)) {
+		end = uint64(len(contexts))
+	}
+	return models.MesheryK8sContextPage{
+		Page:       page,
+		PageSize:   pageSize,
+		TotalCount: len(contexts),
+		Contexts:   contexts[start:end],
+	}
+}
+
+// redactK8sContext returns the context without the credentials of its cluster and of its proxy, to be sent to the clients
+func redactK8sContext(k8sContext models.K8sContext) models.K8sContext {
+	k8sContext.Auth, k8sContext.Cluster = nil, nil
+	k8sContext.ProxyUsername, k8sContext.ProxyPassword = "", ""
+	return k8sContext
+}
+
+// K8sContextPinRequest is the body of a request to pin or unpin a context, the pin is toggled when pinned is left out
+type K8sContextPinRequest struct {
+	Pinned *bool `json:"pinned,omitempty"`
+}
+
+// swagger:route PATCH /api/system/kubernetes/contexts/{id}/pin SystemAPI idPatchK8sContextPin
+// Handle PATCH request to pin or unpin a Kubernetes context
+//
+// The id is the connection ID of the context with remote providers. Pinned contexts can be listed first
+// through "pinned_first" of GET /api/system/kubernetes/contexts.
+// responses:
+//
+//	200: K8sContext
+//	400:
+//	401:
+//	500:
+func (h *Handler) K8sContextPinHandler(w http.ResponseWriter, req *http.Request, _ *models.Preference, user *models.User, provider models.Provider) {
+	token, ok := h.userToken(w, req)
+	if !ok {
+		return
+	}
+	id := mux.Vars(req)["id"]
+	userID := uuid.FromStringOrNil(user.ID)
+
+	var pinRequest K8sContextPinRequest
+	if req.ContentLength != 0 {
+		if err := json.NewDecoder(req.Body).Decode(&pinRequest); err != nil {
+			h.log.Error(models.ErrUnmarshal(err, "pin request"))
+			http.Error(w, models.ErrUnmarshal(err, "pin request").Error(), http.StatusBadRequest)
+			return
+		}
+	}
+	if pinRequest.Pinned == nil {
+		k8sContext, err := provider.GetK8sContext(token, id)
+		if err != nil {
+			h.log.Error(err)
+			http.Error(w, "failed to get context", http.StatusInternalServerError)
+			return
+		}
+		pinned := !k8sContext.Pinned
+		pinRequest.Pinned = &pinned
+	}
+
+	k8sContext, err := provider.SetK8sContextPinned(token, id, *pinRequest.Pinned)
+	if err != nil {
+		_err := ErrFailToSave(err, "kubernetes context")
+		h.log.Error(_err)
+		http.Error(w, _err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	description := fmt.Sprintf("Kubernetes context %s unpinned.", k8sContext.Name)
+	if k8sContext.Pinned {
+		description = fmt.Sprintf("Kubernetes context %s pinned.", k8sContext.Name)
+	}
+	event := events.NewEvent().ActedUpon(uuid.FromStringOrNil(id)).FromUser(userID).FromSystem(*h.SystemID).WithCategory("connection").WithAction("update").
+		WithSeverity(events.Informational).WithDescription(description).Build()
+	h.persistEvent(provider, event)
+	go h.config.EventBroadcaster.Publish(userID, event)
+
+	k8sContext = redactK8sContext(k8sContext)
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(k8sContext); err != nil {
+		h.log.Error(models.ErrMarshal(err, "kubernetes context"))
+		http.Error(w, models.ErrMarshal(err, "kubernetes context").Error(), http.StatusInternalServerError)
+	}
+}
+
+// K8sContextNotesRequest is the body of a request to replace the notes of a context, empty notes clear them
+type K8sContextNotesRequest struct {
+	Notes string `json:"notes"`
+}
+
+// swagger:route PATCH /api/system/kubernetes/contexts/{id}/notes SystemAPI idPatchK8sContextNotes
+// Handle PATCH request to replace the notes of a Kubernetes context
+//
+// The id is the connection ID of the context with remote providers. The notes are free text for the users,
+// e.g. "shared staging, do not delete", and are returned along with the context.
+// responses:
+//
+//	200: K8sContext
+//	400:
+//	401:
+//	500:
+func (h *Handler) K8sContextNotesHandler(w http.ResponseWriter, req *http.Request, _ *models.Preference, user *models.User, provider models.Provider) {
+	token, ok := h.userToken(w, req)
+	if !ok {
+		return
+	}
+	id := mux.Vars(req)["id"]
+	userID := uuid.FromStringOrNil(user.ID)
+
+	var notesRequest K8sContextNotesRequest
+	if err := json.NewDecoder(req.Body).Decode(&notesRequest); err != nil {
+		h.log.Error(models.ErrUnmarshal(err, "notes request"))
+		http.Error(w, models.ErrUnmarshal(err, "notes request").Error(), http.StatusBadRequest)
+		return
+	}
+
+	k8sContext, err := provider.SetK8sContextNotes(token, id, strings.TrimSpace(notesRequest.Notes))
+	if err != nil {
+		_err := ErrFailToSave(err, "kubernetes context")
+		h.log.Error(_err)
+		http.Error(w, _err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	event := events.NewEvent().ActedUpon(uuid.FromStringOrNil(id)).FromUser(userID).FromSystem(*h.SystemID).WithCategory("connection").WithAction("update").
+		WithSeverity(events.Informational).WithDescription(fmt.Sprintf("Notes of Kubernetes context %s updated.", k8sContext.Name)).Build()
+	h.persistEvent(provider, event)
+	go h.config.EventBroadcaster.Publish(userID, event)
+
+	k8sContext = redactK8sContext(k8sContext)
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(k8sContext); err != nil {
+		h.log.Error(models.ErrMarshal(err, "kubernetes context"))
+		http.Error(w, models.ErrMarshal(err, "kubernetes context").Error(), http.StatusInternalServerError)
+	}
+}
+
+// K8sContextSyncIntervalRequest is the body of a request to replace the sync interval of a context,
+// a Go duration of at least models.MinK8sSyncInterval, an empty interval stops the periodic resync
+type K8sContextSyncIntervalRequest struct {
+	SyncInterval string `json:"sync_interval"`
+}
+
+// swagger:route PATCH /api/system/kubernetes/contexts/{id}/sync-interval SystemAPI idPatchK8sContextSyncInterval
+// Handle PATCH request to replace the sync interval of a Kubernetes context
+//
+// The id is the connection ID of the context with remote providers. MeshSync is asked to resync the resources
+// of the cluster at the interval, e.g. "5m", the change applies right away to a connected context.
+// responses:
+//
+//	200: K8sContext
+//	400:
+//	401:
+//	500:
+func (h *Handler) K8sContextSyncIntervalHandler(w http.ResponseWriter, req *http.Request, _ *models.Preference, user *models.User, provider models.Provider) {
+	token, ok := h.userToken(w, req)
+	if !ok {
+		return
+	}
+	id := mux.Vars(req)["id"]
+	userID := uuid.FromStringOrNil(user.ID)
+
+	var syncIntervalRequest K8sContextSyncIntervalRequest
+	if err := json.NewDecoder(req.Body).Decode(&syncIntervalRequest); err != nil {
+		h.log.Error(models.ErrUnmarshal(err, "sync interval request"))
+		http.Error(w, models.ErrUnmarshal(err, "sync interval request").Error(), http.StatusBadRequest)
+		return
+	}
+	syncInterval := strings.TrimSpace(syncIntervalRequest.SyncInterval)
+	if _, err := models.ParseK8sSyncInterval(syncInterval); err != nil {
+		h.log.Error(err)
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	k8sContext, err := provider.SetK8sContextSyncInterval(token, id, syncInterval)
+	if err != nil {
+		_err := ErrFailToSave(err, "kubernetes context")
+		h.log.Error(_err)
+		http.Error(w, _err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if inst, ok := h.ConnectionToStateMachineInstanceTracker.Get(uuid.FromStringOrNil(k8sContext.ConnectionID)); ok {
+		if machineCtx, ok := inst.Context.(*kubernetes.MachineCtx); ok {
+			machineCtx.SetSyncInterval(syncInterval)
+		}
+	}
+
+	description := fmt.Sprintf("MeshSync of Kubernetes context %s resyncs every %s.", k8sContext.Name, syncInterval)
+	if syncInterval == "" {
+		description = fmt.Sprintf("MeshSync of Kubernetes context %s no longer resyncs periodically.", k8sContext.Name)
+	}
+	event := events.NewEvent().ActedUpon(uuid.FromStringOrNil(id)).FromUser(userID).FromSystem(*h.SystemID).WithCategory("connection").WithAction("update").
+		WithSeverity(events.Informational).WithDescription(description).Build()
+	h.persistEvent(provider, event)
+	go h.config.EventBroadcaster.Publish(userID, event)
+
+	k8sContext = redactK8sContext(k8sContext)
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(k8sContext); err != nil {
+		h.log.Error(models.ErrMarshal(err, "kubernetes context"))
+		http.Error(w, models.ErrMarshal(err, "kubernetes context").Error(), http.StatusInternalServerError)
+	}
+}
+
+// swagger:route GET /api/system/kubernetes/contexts/by-server-id/{uid} SystemAPI idGetK8sContextByServerID
+// Handle GET request for the Kubernetes context of a cluster
+//
+// The uid is the UID of the kube-system namespace of the cluster, reported as kubernetes_server_id.
+// When several contexts point at the cluster, the most recently updated one is returned.
+// responses:
+//
+//	200: K8sContext
+//	400:
+//	401:
+//	404:
+//	500:
+func (h *Handler) GetContextByServerID(w http.ResponseWriter, req *http.Request, _ *models.Preference, _ *models.User, provider models.Provider) {
+	token, ok := h.userToken(w, req)
+	if !ok {
+		return
+	}
+	serverID, err := uuid.FromString(mux.Vars(req)["uid"])
+	if err != nil {
+		h.log.Error(ErrInvalidUUID(err))
+		http.Error(w, ErrInvalidUUID(err).Error(), http.StatusBadRequest)
+		return
+	}
+
+	contexts, err := loadK8sContexts(provider, token, "", "updated_at desc")
+	if err != nil {
+		h.log.Error(err)
+		http.Error(w, "failed to get contexts", http.StatusInternalServerError)
+		return
+	}
+	k8sContext := k8sContextByServerID(contexts, serverID)
+	if k8sContext == nil {
+		http.Error(w, fmt.Sprintf("no kubernetes context found for the cluster %s", serverID), http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(redactK8sContext(*k8sContext)); err != nil {
+		h.log.Error(models.ErrMarshal(err, "kubernetes context"))
+		http.Error(w, models.ErrMarshal(err, "kubernetes context").Error(), http.StatusInternalServerError)
+	}
+}
+
+// swagger:route GET /api/system/kubernetes/contexts/conflicts SystemAPI idGetK8sContextConflicts
+// Handle GET request for the Kubernetes contexts managing the same cluster
+//
+// Groups the contexts by the UID of the kube-system namespace of their cluster and returns the clusters with more than one context,
+// along with how the contexts differ (server, credentials, namespace, impersonation, proxy). The credentials are identified by their
+// fingerprint only. Contexts whose cluster was never reached are left out.
+// responses:
+//
+//	200: []K8sContextConflict
+//	401:
+//	500:
+func (h *Handler) K8sContextConflictsHandler(w http.ResponseWriter, req *http.Request, _ *models.Preference, _ *models.User, provider models.Provider) {
+	token, ok := h.userToken(w, req)
+	if !ok {
+		return
+	}
+
+	contexts, err := models.LoadK8sContextPages(provider, token, "", "", true)
+	if err != nil {
+		h.log.Error(err)
+		http.Error(w, "failed to get contexts", http.StatusInternalServerError)
+		return
+	}
+	conflicts, err := models.FindK8sContextConflicts(contexts)
+	if err != nil {
+		h.log.Error(err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(conflicts); err != nil {
+		h.log.Error(models.ErrMarshal(err, "kubernetes context conflicts"))
+		http.Error(w, models.ErrMarshal(err, "kubernetes context conflicts").Error(), http.StatusInternalServerError)
+	}
+}
+
+// k8sContextByServerID returns the first of the contexts pointing at the cluster with the given server ID
+func k8sContextByServerID(contexts []*models.K8sContext, serverID uuid.UUID) *models.K8sContext {
+	for _, ctx := range contexts {
+		if ctx.KubernetesServerID != nil && *ctx.KubernetesServerID == serverID {
+			return ctx
+		}
+	}
+	return nil
+}
+
 // not being used....
 func (h *Handler) GetContext(w http.ResponseWriter, req *http.Request, _ *models.Preference, _ *models.User, provider models.Provider) {
 	token, ok := req.Context().Value(models.TokenCtxKey).(string)
@@ -77,18 +434,35 @@ func (h *Handler) GetContext(w http.ResponseWriter, req *http.Request, _ *models
 	}
 }
 
+// swagger:route DELETE /api/system/kubernetes/contexts/{id} SystemAPI idDeleteK8sContext
+// Handle DELETE request for a Kubernetes context
+//
+// The connection of the context is deleted and the context is moved to the recycle bin, from which it is restored
+// until "CONNECTION_DELETION_GRACE_PERIOD" is over, see GET /api/system/kubernetes/contexts/deleted.
+// "hard=true" deletes the context permanently right away.
+// responses:
+//
+//	200:
+//	500:
 func (h *Handler) DeleteContext(w http.ResponseWriter, req *http.Request, _ *models.Preference, user *models.User, provider models.Provider) {
 	userID := uuid.FromStringOrNil(user.ID)
 	contextID := mux.Vars(req)["id"]
 
-	eventBuilder := events.NewEvent().ActedUpon(uuid.FromStringOrNil(contextID)).FromUser(userID).FromSystem(*h.SystemID).WithCategory("connection").WithAction("delete")
-
 	token, ok := req.Context().Value(models.TokenCtxKey).(string)
 	if !ok {
 		http.Error(w, "failed to get token", http.StatusInternalServerError)
 		return
 	}
 
+	h.deleteK8sContext(req.Context(), provider, token, userID, contextID)
+	h.recycleK8sContext(provider, token, contextID, req.URL.Query().Get("hard") == "true")
+}
+
+// deleteK8sContext transitions the connection of the context to the deleted state.
+// The transition happens in the background, failures are reported through events.
+func (h *Handler) deleteK8sContext(ctx context.Context, provider models.Provider, token string, userID uuid.UUID, contextID string) {
+	eventBuilder := events.NewEvent().ActedUpon(uuid.FromStringOrNil(contextID)).FromUser(userID).FromSystem(*h.SystemID).WithCategory("connection").WithAction("delete")
+
 	smInstanceTracker := h.ConnectionToStateMachineInstanceTracker
 	k8scontext, err := provider.GetK8sContext(token, contextID)
 	if err != nil {
@@ -100,23 +474,15 @@ func (h *Handler) DeleteContext(w http.ResponseWriter, req *http.Request, _ *mod
 	description := fmt.Sprintf("Delete request received for kubernetes context \"%s\"", k8scontext.Name)
 
 	event := eventBuilder.WithSeverity(events.Informational).WithDescription(description).Build()
-	_ = provider.PersistEvent(event)
+	h.persistEvent(provider, event)
 
-	machineCtx := &kubernetes.MachineCtx{
-		K8sContext:         k8scontext,
-		MesheryCtrlsHelper: h.MesheryCtrlsHelper,
-		K8sCompRegHelper:   h.K8sCompRegHelper,
-		OperatorTracker:    h.config.OperatorTracker,
-		K8scontextChannel:  h.config.K8scontextChannel,
-		EventBroadcaster:   h.config.EventBroadcaster,
-		RegistryManager:    h.registryManager,
-	}
+	machineCtx := h.newK8sMachineCtx(k8scontext)
 
 	connectionUUID := uuid.FromStringOrNil(contextID)
 
 	inst, err := mhelpers.InitializeMachineWithContext(
 		machineCtx,
-		req.Context(),
+		ctx,
 		connectionUUID,
 		userID,
 		smInstanceTracker,
@@ -127,7 +493,7 @@ func (h *Handler) DeleteContext(w http.ResponseWriter, req *http.Request, _ *mod
 		kubernetes.AssignInitialCtx,
 	)
 	go func(inst *machines.StateMachine) {
-		event, err = inst.SendEvent(req.Context(), machines.Delete, nil)
+		event, err = inst.SendEvent(ctx, machines.Delete, nil)
 		if err != nil {
 			h.log.Error(err)
 			h.log.Debug(event)
@@ -143,10 +509,111 @@ func (h *Handler) DeleteContext(w http.ResponseWriter, req *http.Request, _ *mod
 			"error": err,
 		})
 		event := eventBuilder.Build()
-		_ = provider.PersistEvent(event)
+		h.persistEvent(provider, event)
 		go h.config.EventBroadcaster.Publish(userID, event)
 	}
 	// go h.config.EventBroadcaster.Publish(userID, event)
 
 	// h.config.K8scontextChannel.PublishContext()
 }
+
+// newK8sMachineCtx returns the context for the state machine of the connection associated with the given kubernetes context.
+func (h *Handler) newK8sMachineCtx(k8sContext models.K8sContext) *kubernetes.MachineCtx {
+	return &kubernetes.MachineCtx{
+		K8sContext:         k8sContext,
+		MesheryCtrlsHelper: h.MesheryCtrlsHelper,
+		K8sCompRegHelper:   h.K8sCompRegHelper,
+		OperatorTracker:    h.config.OperatorTracker,
+		K8scontextChannel:  h.config.K8scontextChannel,
+		EventBroadcaster:   h.config.EventBroadcaster,
+		RegistryManager:    h.registryManager,
+	}
+}
+
+// K8sReconnectResponse - struct used as (json marshaled) response to connection reconnect requests
+type K8sReconnectResponse struct {
+	ConnectionID string                       `json:"connection_id"`
+	Status       connections.ConnectionStatus `json:"status"`
+	Error        string                       `json:"error,omitempty"`
+}
+
+// swagger:route POST /api/system/kubernetes/contexts/{connection_id}/reconnect SystemAPI idPostK8SContextReconnect
+// Handle POST request to reconnect a Kubernetes connection
+//
+// Re-runs the full connect flow (discover, register, connect) for the connection from its stored context and returns the resulting status
+// responses:
+//
+//	200:
+//	401:
+//	500:
+func (h *Handler) K8sContextReconnectHandler(w http.ResponseWriter, req *http.Request, _ *models.Preference, user *models.User, provider models.Provider) {
+	userID := uuid.FromStringOrNil(user.ID)
+	connectionID := mux.Vars(req)["connection_id"]
+	connectionUUID := uuid.FromStringOrNil(connectionID)
+
+	token, ok := h.userToken(w, req)
+	if !ok {
+		return
+	}
+
+	eventBuilder := events.NewEvent().ActedUpon(connectionUUID).FromUser(userID).FromSystem(*h.SystemID).WithCategory("connection").WithAction("reconnect")
+
+	k8scontext, err := provider.GetK8sContext(token, connectionID)
+	if err != nil {
+		http.Error(w, "failed to get kubernetes context for the given ID", http.StatusInternalServerError)
+		return
+	}
+
+	event := eventBuilder.WithSeverity(events.Informational).WithDescription(fmt.Sprintf("Reconnect requested for kubernetes context \"%s\" at %s", k8scontext.Name, k8scontext.Server)).Build()
+	h.persistEvent(provider, event)
+	go h.config.EventBroadcaster.Publish(userID, event)
+
+	// Discard the existing machine as it may be in a state from which the connection cannot be re-driven,
+	// the new machine starts afresh and re-runs the complete flow.
+	smInstanceTracker := h.ConnectionToStateMachineInstanceTracker
+	smInstanceTracker.Remove(connectionUUID)
+
+	res := K8sReconnectResponse{
+		ConnectionID: connectionID,
+	}
+
+	inst, err := mhelpers.InitializeMachineWithContext(
+		h.newK8sMachineCtx(k8scontext),
+		req.Context(),
+		connectionUUID,
+		userID,
+		smInstanceTracker,
+		h.log,
+		provider,
+		machines.InitialState,
+		"kubernetes",
+		kubernetes.AssignInitialCtx,
+	)
+	if err == nil {
+		_, err = inst.SendEvent(req.Context(), machines.Discovery, nil)
+		res.Status = connections.ConnectionStatus(inst.CurrentState)
+	}
+
+	eventBuilder = events.NewEvent().ActedUpon(connectionUUID).FromUser(userID).FromSystem(*h.SystemID).WithCategory("connection").WithAction("reconnect")
+	if err != nil || res.Status != connections.CONNECTED {
+		if err == nil {
+			err = fmt.Errorf("connection ended in \"%s\" state", res.Status)
+		}
+		err = ErrReconnectK8sContext(err, k8scontext.Name, k8scontext.Server)
+		h.log.Error(err)
+		res.Error = err.Error()
+		eventBuilder.WithSeverity(events.Error).WithDescription(fmt.Sprintf("Unable to reconnect kubernetes context \"%s\" at %s", k8scontext.Name, k8scontext.Server)).WithMetadata(map[string]interface{}{
+			"error": err,
+		})
+	} else {
+		eventBuilder.WithSeverity(events.Success).WithDescription(fmt.Sprintf("Reconnected kubernetes context \"%s\" at %s", k8scontext.Name, k8scontext.Server))
+	}
+	event = eventBuilder.Build()
+	h.persistEvent(provider, event)
+	go h.config.EventBroadcaster.Publish(userID, event)
+
+	if err := json.NewEncoder(w).Encode(res); err != nil {
+		h.log.Error(models.ErrMarshal(err, "reconnect response"))
+		http.Error(w, models.ErrMarshal(err, "reconnect response").Error(), http.StatusInternalServerError)
+	}
+}
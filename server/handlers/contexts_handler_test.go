@@ -0,0 +1,242 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gofrs/uuid"
+	"github.com/gorilla/mux"
+	"github.com/layer5io/meshery/server/internal/sql"
+	"github.com/layer5io/meshery/server/machines"
+	"github.com/layer5io/meshery/server/machines/kubernetes"
+	"github.com/layer5io/meshery/server/models"
+	"github.com/layer5io/meshkit/logger"
+	"github.com/layer5io/meshkit/models/controllers"
+	"github.com/sirupsen/logrus"
+)
+
+func TestSortPinnedK8sContextsFirst(t *testing.T) {
+	contexts := []*models.K8sContext{
+		{Name: "a"}, {Name: "b", Pinned: true}, {Name: "c"}, {Name: "d", Pinned: true}, {Name: "e"},
+	}
+
+	sortPinnedK8sContextsFirst(contexts)
+	page := k8sContextsPage(contexts, 0, 3)
+	names := []string{}
+	for _, ctx := range page.Contexts {
+		names = append(names, ctx.Name)
+	}
+	if strings.Join(names, ",") != "b,d,a" || page.TotalCount != 5 {
+		t.Errorf("first page = %v (total %d), want [b d a] (total 5)", names, page.TotalCount)
+	}
+
+	page = k8sContextsPage(contexts, 1, 3)
+	if len(page.Contexts) != 2 || page.Contexts[0].Name != "c" || page.Contexts[1].Name != "e" {
+		t.Errorf("unexpected second page: %v", page.Contexts)
+	}
+	if page = k8sContextsPage(contexts, 5, 3); len(page.Contexts) != 0 {
+		t.Errorf("expected an empty page past the end, got %v", page.Contexts)
+	}
+}
+
+func TestRedactK8sContext(t *testing.T) {
+	k8sContext := models.K8sContext{
+		Name: "ctx", Auth: sql.Map{"token": "secret"}, Cluster: sql.Map{"server": "https://cluster"}, ProxyUsername: "user", ProxyPassword: "password",
+	}
+
+	redacted := redactK8sContext(k8sContext)
+	if redacted.Auth != nil || redacted.Cluster != nil || redacted.ProxyUsername != "" || redacted.ProxyPassword != "" {
+		t.Errorf("expected the credentials to be left out, got %+v", redacted)
+	}
+	if redacted.Name != "ctx" || k8sContext.Auth == nil || k8sContext.ProxyPassword != "password" {
+		t.Errorf("expected only the returned copy to be redacted, got %+v and %+v", redacted, k8sContext)
+	}
+}
+
+func TestK8sContextByServerID(t *testing.T) {
+	serverID, other := uuid.Must(uuid.NewV4()), uuid.Must(uuid.NewV4())
+	contexts := []*models.K8sContext{
+		{Name: "no-server-id"}, {Name: "other", KubernetesServerID: &other}, {Name: "recent", KubernetesServerID: &serverID}, {Name: "older", KubernetesServerID: &serverID},
+	}
+
+	if ctx := k8sContextByServerID(contexts, serverID); ctx == nil || ctx.Name != "recent" {
+		t.Errorf("k8sContextByServerID() = %+v, want the first context of the cluster", ctx)
+	}
+	if ctx := k8sContextByServerID(contexts, uuid.Must(uuid.NewV4())); ctx != nil {
+		t.Errorf("k8sContextByServerID() = %+v, want no context for an unknown cluster", ctx)
+	}
+}
+
+type notesProvider struct {
+	storedContextProvider
+	id string
+}
+
+func (p *notesProvider) SetK8sContextNotes(_, id, notes string) (models.K8sContext, error) {
+	p.id = id
+	p.k8sContext.Notes = notes
+	return p.k8sContext, nil
+}
+
+func TestK8sContextNotesHandler(t *testing.T) {
+	log, err := logger.New("test", logger.Options{LogLevel: int(logrus.InfoLevel)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	systemID := uuid.Must(uuid.NewV4())
+	h := &Handler{log: log, SystemID: &systemID, config: &models.HandlerConfig{EventBroadcaster: models.NewBroadcaster()}}
+	provider := &notesProvider{storedContextProvider: storedContextProvider{k8sContext: models.K8sContext{
+		Name: "staging",
+		Auth: sql.Map{"token": "abc"},
+	}}}
+	id := uuid.Must(uuid.NewV4()).String()
+
+	req := httptest.NewRequest(http.MethodPatch, "/api/system/kubernetes/contexts/"+id+"/notes", strings.NewReader(`{"notes":" shared staging, do not delete "}`))
+	req = mux.SetURLVars(req, map[string]string{"id": id})
+	req = req.WithContext(context.WithValue(req.Context(), models.TokenCtxKey, "token"))
+	w := httptest.NewRecorder()
+	h.K8sContextNotesHandler(w, req, nil, &models.User{ID: uuid.Must(uuid.NewV4()).String()}, provider)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("K8sContextNotesHandler() status = %d, body = %s", w.Code, w.Body.String())
+	}
+	if provider.id != id {
+		t.Errorf("notes set on %q, want %q", provider.id, id)
+	}
+	var k8sContext models.K8sContext
+	if err := json.Unmarshal(w.Body.Bytes(), &k8sContext); err != nil {
+		t.Fatal(err)
+	}
+	if k8sContext.Notes != "shared staging, do not delete" || k8sContext.Auth != nil {
+		t.Errorf("K8sContextNotesHandler() = %+v, want the trimmed notes and no credentials", k8sContext)
+	}
+	if len(provider.events) != 1 {
+		t.Errorf("%d events emitted, want the update of the context", len(provider.events))
+	}
+}
+
+type syncIntervalProvider struct {
+	storedContextProvider
+}
+
+func (p *syncIntervalProvider) SetK8sContextSyncInterval(_, _, syncInterval string) (models.K8sContext, error) {
+	p.k8sContext.SyncInterval = syncInterval
+	return p.k8sContext, nil
+}
+
+func TestK8sContextSyncIntervalHandler(t *testing.T) {
+	log, err := logger.New("test", logger.Options{LogLevel: int(logrus.InfoLevel)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	systemID := uuid.Must(uuid.NewV4())
+	connectionID := uuid.Must(uuid.NewV4())
+	machineCtx := &kubernetes.MachineCtx{
+		K8sContext:         models.K8sContext{ID: "staging", Name: "staging", ConnectionID: connectionID.String()},
+		MesheryCtrlsHelper: models.NewMesheryControllersHelper(log, controllers.OperatorDeploymentConfig{}, nil),
+	}
+	h := &Handler{
+		log:      log,
+		SystemID: &systemID,
+		config:   &models.HandlerConfig{EventBroadcaster: models.NewBroadcaster()},
+		ConnectionToStateMachineInstanceTracker: &machines.ConnectionToStateMachineInstanceTracker{ConnectToInstanceMap: map[uuid.UUID]*machines.StateMachine{
+			connectionID: {Context: machineCtx},
+		}},
+	}
+	provider := &syncIntervalProvider{storedContextProvider{k8sContext: models.K8sContext{
+		Name:         "staging",
+		ConnectionID: connectionID.String(),
+		Auth:         sql.Map{"token": "abc"},
+	}}}
+
+	patch := func(body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPatch, "/api/system/kubernetes/contexts/"+connectionID.String()+"/sync-interval", strings.NewReader(body))
+		req = mux.SetURLVars(req, map[string]string{"id": connectionID.String()})
+		req = req.WithContext(context.WithValue(req.Context(), models.TokenCtxKey, "token"))
+		w := httptest.NewRecorder()
+		h.K8sContextSyncIntervalHandler(w, req, nil, &models.User{ID: uuid.Must(uuid.NewV4()).String()}, provider)
+		return w
+	}
+
+	if w := patch(`{"sync_interval":"5s"}`); w.Code != http.StatusBadRequest {
+		t.Errorf("K8sContextSyncIntervalHandler() status = %d for an interval below the minimum, want 400", w.Code)
+	}
+	if provider.k8sContext.SyncInterval != "" {
+		t.Errorf("sync interval %q stored, want the invalid interval rejected", provider.k8sContext.SyncInterval)
+	}
+
+	w := patch(`{"sync_interval":"5m"}`)
+	if w.Code != http.StatusOK {
+		t.Fatalf("K8sContextSyncIntervalHandler() status = %d, body = %s", w.Code, w.Body.String())
+	}
+	var k8sContext models.K8sContext
+	if err := json.Unmarshal(w.Body.Bytes(), &k8sContext); err != nil {
+		t.Fatal(err)
+	}
+	if k8sContext.SyncInterval != "5m" || k8sContext.Auth != nil {
+		t.Errorf("K8sContextSyncIntervalHandler() = %+v, want the sync interval and no credentials", k8sContext)
+	}
+	// the interval is applied by the helper of the running machine asynchronously
+	deadline := time.Now().Add(5 * time.Second)
+	for machineCtx.MesheryCtrlsHelper.MeshsyncInterval("staging") != 5*time.Minute && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	if interval := machineCtx.MesheryCtrlsHelper.MeshsyncInterval("staging"); interval != 5*time.Minute {
+		t.Errorf("sync interval of the running machine = %s, want 5m", interval)
+	}
+}
+
+type recycleBinProvider struct {
+	storedContextProvider
+	deleted []*models.K8sContext
+	purged  []string
+}
+
+func (p *recycleBinProvider) GetDeletedK8sContexts(_ string) ([]*models.K8sContext, error) {
+	return p.deleted, nil
+}
+
+func (p *recycleBinProvider) DeleteK8sContext(_, id string) (models.K8sContext, error) {
+	p.purged = append(p.purged, id)
+	return models.K8sContext{}, nil
+}
+
+func TestDeletedK8sContextsHandler(t *testing.T) {
+	log, err := logger.New("test", logger.Options{LogLevel: int(logrus.InfoLevel)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	recycleBin := models.NewK8sContextRecycleBinFromConfig(log)
+	recycleBin.GracePeriod = 24 * time.Hour
+	h := &Handler{log: log, config: &models.HandlerConfig{K8sContextRecycleBin: recycleBin}}
+
+	recent, expired := time.Now().Add(-time.Hour), time.Now().Add(-48*time.Hour)
+	provider := &recycleBinProvider{deleted: []*models.K8sContext{
+		{ID: "ctx-recent", ConnectionID: "conn-recent", Name: "recent", DeletedAt: &recent, Auth: sql.Map{"token": "abc"}},
+		{ID: "ctx-expired", Name: "expired", DeletedAt: &expired},
+	}}
+
+	req := httptest.NewRequest(http.MethodGet, "/api/system/kubernetes/contexts/deleted", nil)
+	req = req.WithContext(context.WithValue(req.Context(), models.TokenCtxKey, "token"))
+	w := httptest.NewRecorder()
+	h.DeletedK8sContextsHandler(w, req, nil, nil, provider)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("DeletedK8sContextsHandler() status = %d, body = %s", w.Code, w.Body.String())
+	}
+	var deleted []DeletedK8sContext
+	if err := json.Unmarshal(w.Body.Bytes(), &deleted); err != nil {
+		t.Fatal(err)
+	}
+	if len(deleted) != 1 || deleted[0].Name != "recent" || deleted[0].Auth != nil || deleted[0].PurgeAt == nil {
+		t.Errorf("DeletedK8sContextsHandler() = %+v, want the recent context without credentials along with its purge time", deleted)
+	}
+	if len(provider.purged) != 1 || provider.purged[0] != "ctx-expired" {
+		t.Errorf("purged %v, want the context past its grace period", provider.purged)
+	}
+}
@@ -193,6 +193,8 @@ func (h *Handler) ResetSystemDatabase(w http.ResponseWriter, r *http.Request, _
 			&models.PerformanceTestConfig{},
 			&models.SmiResultWithID{},
 			&models.K8sContext{},
+			&models.K8sComponentsRegistrationCheckpoint{},
+			&models.K8sComponentsRegistryHost{},
 		)
 
 		if err != nil {
@@ -298,7 +298,7 @@ type adapterParamsWrapper struct {
 // swagger:response k8sConfigRespWrapper
 type k8sConfigRespWrapper struct {
 	// in: body
-	// Body *models.K8SConfig
+	Body *SaveK8sContextResponse
 }
 
 // Returns kubernetes context list
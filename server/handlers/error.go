@@ -135,6 +135,19 @@ const (
 	ErrMarshallingDesignIntoYAMLCode       = "1564"
 	ErrConvertingHelmChartToDesignCode     = "1565"
 	ErrInvalidUUIDCode                     = "1568"
+	ErrDecompressConfigCode                = "1570"
+	ErrFlattenKubeConfigCode               = "1571"
+	ErrDecodeKubeconfigContentCode         = "1581"
+	ErrListK8sCRDsCode                     = "1588"
+	ErrGetMeshSyncHealthCode               = "1590"
+	ErrResolveEnvironmentCode              = "1591"
+	ErrInvalidEventsTimeRangeCode          = "1595"
+	ErrFetchK8sOpenAPICode                 = "1605"
+	ErrRestoreK8sContextCode               = "1610"
+	ErrReconnectK8sContextCode             = "1611"
+	ErrInvalidCurrentContextPolicyCode     = "1612"
+	ErrReadComponentSVGCode                = "1614"
+	ErrK8sDiagnosticsBundleCode            = "1625"
 )
 
 var (
@@ -265,6 +278,18 @@ func ErrFormFile(err error) error {
 	return errors.New(ErrFormFileCode, errors.Alert, []string{"error getting k8s file"}, []string{err.Error()}, []string{"The kubeconfig file does not exist in the location"}, []string{"Make sure to upload the correct kubeconfig file"})
 }
 
+func ErrDecompressConfig(err error) error {
+	return errors.New(ErrDecompressConfigCode, errors.Alert, []string{"error decompressing gzip compressed kubeconfig"}, []string{err.Error()}, []string{"The uploaded kubeconfig is not a valid gzip archive", "The decompressed kubeconfig exceeds the maximum allowed size"}, []string{"Make sure to upload a valid gzip compressed kubeconfig file", "Upload the kubeconfig uncompressed or reduce the number of contexts in it"})
+}
+
+func ErrDecodeKubeconfigContent(err error) error {
+	return errors.New(ErrDecodeKubeconfigContentCode, errors.Alert, []string{"unable to decode the kubeconfig of \"KUBECONFIG_CONTENT\""}, []string{err.Error()}, []string{"\"KUBECONFIG_CONTENT\" is not base64 encoded"}, []string{"Set \"KUBECONFIG_CONTENT\" to the base64 encoded kubeconfig, for example the output of `kubectl config view --flatten --raw | base64 -w0`"})
+}
+
+func ErrFlattenKubeConfig(err error) error {
+	return errors.New(ErrFlattenKubeConfigCode, errors.Alert, []string{"unable to flatten the kubeconfig"}, []string{err.Error()}, []string{"The kubeconfig references files (certificates, keys or tokens) which are not accessible to Meshery Server", "The kubeconfig is not a valid YAML document"}, []string{"Inline the referenced files in the kubeconfig, for example using `kubectl config view --flatten`", "Upload the kubeconfig without setting \"require_flatten\" to continue with the non-flattened kubeconfig"})
+}
+
 func ErrReadConfig(err error) error {
 	return errors.New(ErrReadConfigCode, errors.Alert, []string{"error reading config"}, []string{err.Error()}, []string{"The kubeconfig file is empty or not valid"}, []string{"Make sure to upload the correct kubeconfig file"})
 }
@@ -596,3 +621,43 @@ func ErrConvertingHelmChartToDesign(err error) error {
 func ErrInvalidUUID(err error) error {
 	return errors.New(ErrInvalidUUIDCode, errors.Alert, []string{"invalid or empty uuid"}, []string{err.Error()}, []string{"provided id is not a valid uuid"}, []string{"provide a valid uuid"})
 }
+
+func ErrListK8sCRDs(err error, ctxName string) error {
+	return errors.New(ErrListK8sCRDsCode, errors.Alert, []string{fmt.Sprintf("unable to list the custom resources of kubernetes context %s", ctxName)}, []string{err.Error()}, []string{"The cluster is not reachable.", "The user of the context is not allowed to list customresourcedefinitions."}, []string{"Make sure the cluster is reachable from Meshery Server.", "Grant the user of the context \"list\" on customresourcedefinitions.apiextensions.k8s.io."})
+}
+
+func ErrGetMeshSyncHealth(err error, ctxName string) error {
+	return errors.New(ErrGetMeshSyncHealthCode, errors.Alert, []string{fmt.Sprintf("unable to get the health of MeshSync in kubernetes context %s", ctxName)}, []string{err.Error()}, []string{"The cluster is not reachable.", "The user of the context is not allowed to list the pods of the \"meshery\" namespace."}, []string{"Make sure the cluster is reachable from Meshery Server.", "Grant the user of the context \"list\" on pods in the \"meshery\" namespace."})
+}
+
+func ErrResolveEnvironment(err error, name string) error {
+	return errors.New(ErrResolveEnvironmentCode, errors.Alert, []string{fmt.Sprintf("unable to resolve the connections of environment %s", name)}, []string{err.Error()}, []string{"There is no environment with the given name.", "The provider does not support environments."}, []string{"Create the environment and assign the connections to it through /api/environments.", "Pass the orgID of the organization the environment belongs to.", "Sign in with a provider supporting environments."})
+}
+
+func ErrInvalidEventsTimeRange(err error) error {
+	return errors.New(ErrInvalidEventsTimeRangeCode, errors.Alert, []string{"invalid time range of the events"}, []string{err.Error()}, []string{"\"from\" or \"to\" is not an RFC 3339 timestamp.", "\"from\" is after \"to\"."}, []string{"Pass \"from\" and \"to\" as RFC 3339 timestamps, e.g. \"2024-01-02T15:04:05Z\", with \"from\" before \"to\"."})
+}
+
+func ErrFetchK8sOpenAPI(err error, ctxName string) error {
+	return errors.New(ErrFetchK8sOpenAPICode, errors.Alert, []string{fmt.Sprintf("unable to fetch the OpenAPI schema of kubernetes context %s", ctxName)}, []string{err.Error()}, []string{"The cluster is not reachable.", "The user of the context is not allowed to get the non-resource URLs under /openapi/v3.", "The API server is older than Kubernetes 1.27 and does not serve the OpenAPI v3 schema by default."}, []string{"Make sure the cluster is reachable from Meshery Server.", "Grant the user of the context \"get\" on the non-resource URLs \"/openapi/v3\" and \"/openapi/v3/*\"."})
+}
+
+func ErrRestoreK8sContext(err error, name string) error {
+	return errors.New(ErrRestoreK8sContextCode, errors.Alert, []string{fmt.Sprintf("Kubernetes context \"%s\" restored but not connected", name)}, []string{err.Error()}, []string{"The cluster of the context is not reachable.", "The credentials of the context are no longer valid."}, []string{"Make sure the cluster is reachable from Meshery Server and reconnect the context."})
+}
+
+func ErrReconnectK8sContext(err error, name, server string) error {
+	return errors.New(ErrReconnectK8sContextCode, errors.Alert, []string{fmt.Sprintf("unable to reconnect Kubernetes context \"%s\" at %s", name, server)}, []string{err.Error()}, []string{"The cluster of the context is not reachable.", "The credentials of the context are no longer valid."}, []string{"Make sure the cluster is reachable from Meshery Server, or upload a kubeconfig with valid credentials for the context."})
+}
+
+func ErrInvalidCurrentContextPolicy(policy string) error {
+	return errors.New(ErrInvalidCurrentContextPolicyCode, errors.Alert, []string{"invalid current-context policy"}, []string{fmt.Sprintf("invalid current_context_policy %q, expected %q or %q", policy, K8sCurrentContextPolicyBestEffort, K8sCurrentContextPolicyStrict)}, []string{"The current_context_policy of the upload or KUBECONFIG_CURRENT_CONTEXT_POLICY is not a known policy."}, []string{fmt.Sprintf("Set the policy to %q or %q.", K8sCurrentContextPolicyBestEffort, K8sCurrentContextPolicyStrict)})
+}
+
+func ErrReadComponentSVG(err error, svgPath, kind string) error {
+	return errors.New(ErrReadComponentSVGCode, errors.Alert, []string{fmt.Sprintf("failed to read SVG %s of component %s", svgPath, kind)}, []string{err.Error()}, []string{"The SVG was removed after the component was registered.", "COMPONENTS_SVG_ROOT is not the directory the paths of the SVGs are relative to.", "The path of the SVG is not within COMPONENTS_SVG_ROOT."}, []string{"Register the components of the connection again, or set COMPONENTS_SVG_ROOT to the root of the Meshery repository."})
+}
+
+func ErrK8sDiagnosticsBundle(err error, ctxName string) error {
+	return errors.New(ErrK8sDiagnosticsBundleCode, errors.Alert, []string{fmt.Sprintf("failed to bundle the diagnostics of kubernetes context %s", ctxName)}, []string{err.Error()}, []string{"The diagnostics could not be written to the zip archive."}, []string{"Try again, the diagnostics are collected anew on each request."})
+}
@@ -81,6 +81,64 @@ func (h *Handler) GetAllEvents(w http.ResponseWriter, req *http.Request, prefObj
 	}
 }
 
+// swagger:route GET /api/system/kubernetes/contexts/{connection_id}/events EventsAPI idGetK8sContextEvents
+// Handle GET request for the events of a Kubernetes connection.
+// Returns the timeline of the connection, e.g. its onboarding, errors and component registrations, as the events acted upon the connection.
+// ```?severity=[eventseverity] Returns events belonging to provided severities ```
+// ```?action=[eventaction] Returns events belonging to provided actions ```
+// ```?from={timestamp}``` and ```?to={timestamp}``` Return events created in the time range, as RFC 3339 timestamps
+// ```?sort={field} order the records based on passed field, defaults to created_at```
+// ```?order={[asc/desc]}``` Default behavior is desc
+// ```?page={page-number}``` Default page number is 1
+// ```?pagesize={pagesize}``` Default pagesize is 25. To return all results: ```pagesize=all```
+// responses:
+// 	200: eventsResponseWrapper
+// 	400:
+
+func (h *Handler) K8sContextEventsHandler(w http.ResponseWriter, req *http.Request, _ *models.Preference, user *models.User, provider models.Provider) {
+	userID := uuid.FromStringOrNil(user.ID)
+	page, offset, limit,
+		search, order, sortOnCol, status := getPaginationParams(req)
+	if req.URL.Query().Get("sort") == "" {
+		sortOnCol = "created_at"
+	}
+	filter, err := getEventFilter(req)
+	if err != nil {
+		h.log.Error(err)
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	from, to, err := getEventsTimeRange(req)
+	if err != nil {
+		h.log.Error(err)
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	filter.ActedUpon = []string{mux.Vars(req)["connection_id"]}
+	filter.Limit = limit
+	filter.Offset = offset
+	filter.Order = order
+	filter.SortOn = sortOnCol
+	filter.Search = search
+	filter.Status = events.EventStatus(status)
+
+	eventsResult, err := provider.GetAllEventsInTimeRange(filter, from, to, userID)
+	if err != nil {
+		h.log.Error(ErrGetEvents(err))
+		http.Error(w, ErrGetEvents(err).Error(), http.StatusInternalServerError)
+		return
+	}
+	eventsResult.Page = page
+	w.Header().Set("Content-Type", "application/json")
+	err = json.NewEncoder(w).Encode(eventsResult)
+	if err != nil {
+		h.log.Error(models.ErrMarshal(err, "events response"))
+		http.Error(w, models.ErrMarshal(err, "events response").Error(), http.StatusInternalServerError)
+		return
+	}
+}
+
 // swagger:route GET /api/events/types EventsAPI idGetEventStreamer
 // Handle GET request for available event categories and actions.
 // responses:
@@ -227,6 +285,25 @@ func (h *Handler) DeleteEvent(w http.ResponseWriter, req *http.Request, prefObj
 	}
 }
 
+// getEventsTimeRange returns the "from" and "to" timestamps of the request, zero when not passed
+func getEventsTimeRange(req *http.Request) (from, to time.Time, err error) {
+	urlValues := req.URL.Query()
+	if v := urlValues.Get("from"); v != "" {
+		if from, err = time.Parse(time.RFC3339, v); err != nil {
+			return from, to, ErrInvalidEventsTimeRange(err)
+		}
+	}
+	if v := urlValues.Get("to"); v != "" {
+		if to, err = time.Parse(time.RFC3339, v); err != nil {
+			return from, to, ErrInvalidEventsTimeRange(err)
+		}
+	}
+	if !from.IsZero() && !to.IsZero() && from.After(to) {
+		return from, to, ErrInvalidEventsTimeRange(fmt.Errorf("from %s is after to %s", from.Format(time.RFC3339), to.Format(time.RFC3339)))
+	}
+	return from, to, nil
+}
+
 func getEventFilter(req *http.Request) (*events.EventsFilter, error) {
 	urlValues := req.URL.Query()
 	category := urlValues.Get("category")
@@ -0,0 +1,53 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/layer5io/meshery/server/models"
+)
+
+// K8sCachesResponse lists the in-memory caches used for the generation of the Kubernetes components
+type K8sCachesResponse struct {
+	Caches []models.CacheStats `json:"caches"`
+}
+
+func k8sCaches() K8sCachesResponse {
+	return K8sCachesResponse{
+		Caches: []models.CacheStats{models.K8sMeshModelMetadataCacheStats()},
+	}
+}
+
+// swagger:route GET /api/system/kubernetes/cache SystemAPI idGetK8sCache
+// Handle GET request for the in-memory caches used for the generation of the Kubernetes components
+//
+// Lists the number of entries, the keys and the age of each cache.
+// responses:
+//
+//	200: K8sCachesResponse
+func (h *Handler) GetK8sCacheHandler(w http.ResponseWriter, _ *http.Request, _ *models.Preference, _ *models.User, _ models.Provider) {
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(k8sCaches()); err != nil {
+		h.log.Error(models.ErrMarshal(err, "kubernetes caches"))
+		http.Error(w, models.ErrMarshal(err, "kubernetes caches").Error(), http.StatusInternalServerError)
+	}
+}
+
+// swagger:route DELETE /api/system/kubernetes/cache SystemAPI idDeleteK8sCache
+// Handle DELETE request to flush the in-memory caches used for the generation of the Kubernetes components
+//
+// The metadata of the Kubernetes model is read again from the model template, so that stale entries are
+// cleared without restarting Meshery Server. Responds with the caches as they are after the flush.
+// responses:
+//
+//	200: K8sCachesResponse
+func (h *Handler) DeleteK8sCacheHandler(w http.ResponseWriter, _ *http.Request, _ *models.Preference, _ *models.User, _ models.Provider) {
+	models.ReloadK8sMeshModelMetadata()
+	h.log.Info("flushed the kubernetes component caches")
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(k8sCaches()); err != nil {
+		h.log.Error(models.ErrMarshal(err, "kubernetes caches"))
+		http.Error(w, models.ErrMarshal(err, "kubernetes caches").Error(), http.StatusInternalServerError)
+	}
+}
@@ -0,0 +1,77 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/layer5io/meshery/server/models"
+	"github.com/spf13/viper"
+)
+
+// K8sCapabilitiesResponse - struct used as (json marshaled) response to the capabilities requests
+type K8sCapabilitiesResponse struct {
+	ConnectionID string `json:"connection_id"`
+	// Capabilities of the cluster, see models.DetectK8sCapabilities
+	Capabilities map[string]bool `json:"capabilities"`
+	// Cached reports whether the capabilities were served from the cache, detected Age seconds ago
+	Cached bool    `json:"cached"`
+	Age    float64 `json:"age"`
+}
+
+// swagger:route GET /api/system/kubernetes/contexts/{connection_id}/capabilities SystemAPI idGetK8sContextCapabilities
+// Handle GET request for the capabilities of the cluster of a Kubernetes context
+//
+// Detects the notable features of the cluster the deployment of designs depends upon: network policies, a default StorageClass,
+// an Ingress controller and an installed service mesh. The capabilities are detected on demand rather than on each save of the
+// context, and cached for "KUBERNETES_CAPABILITIES_CACHE_TTL" unless "fresh=true" is passed.
+// responses:
+//
+//	200: K8sCapabilitiesResponse
+//	400:
+//	401:
+//	500:
+func (h *Handler) K8sContextCapabilitiesHandler(w http.ResponseWriter, req *http.Request, _ *models.Preference, _ *models.User, provider models.Provider) {
+	token, ok := h.userToken(w, req)
+	if !ok {
+		return
+	}
+	connectionID := mux.Vars(req)["connection_id"]
+	k8sContext, err := provider.GetK8sContext(token, connectionID)
+	if err != nil {
+		http.Error(w, "failed to get kubernetes context for the given ID", http.StatusInternalServerError)
+		return
+	}
+
+	cached, ok := k8sCapabilities.get(connectionID)
+	if !ok || req.URL.Query().Get("fresh") == "true" {
+		kubeclient, err := k8sContext.GenerateKubeHandler()
+		if err != nil {
+			h.log.Error(ErrInvalidKubeHandler(err, "Meshery"))
+			http.Error(w, ErrInvalidKubeHandler(err, "Meshery").Error(), http.StatusBadRequest)
+			return
+		}
+		cached, ok = k8sCapability{capabilities: models.DetectK8sCapabilities(req.Context(), kubeclient), detectedAt: time.Now()}, false
+		k8sCapabilities.set(connectionID, cached, viper.GetDuration("KUBERNETES_CAPABILITIES_CACHE_TTL"))
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(K8sCapabilitiesResponse{
+		ConnectionID: connectionID,
+		Capabilities: cached.capabilities,
+		Cached:       ok,
+		Age:          time.Since(cached.detectedAt).Round(time.Millisecond).Seconds(),
+	}); err != nil {
+		h.log.Error(models.ErrMarshal(err, "kubernetes capabilities"))
+		http.Error(w, models.ErrMarshal(err, "kubernetes capabilities").Error(), http.StatusInternalServerError)
+	}
+}
+
+// k8sCapabilities caches the capabilities of the clusters per connection, detecting them takes several requests to the cluster
+var k8sCapabilities = newTTLCache[k8sCapability]()
+
+type k8sCapability struct {
+	capabilities map[string]bool
+	detectedAt   time.Time
+}
@@ -0,0 +1,469 @@
+package handlers
+
+import (
+	"archive/zip"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/gofrs/uuid"
+	"github.com/gorilla/mux"
+	"github.com/layer5io/meshery/server/helpers/utils"
+	"github.com/layer5io/meshery/server/models"
+	mcore "github.com/layer5io/meshery/server/models/meshmodel/core"
+	"github.com/layer5io/meshkit/models/events"
+	"github.com/layer5io/meshkit/models/meshmodel/core/v1alpha1"
+	"github.com/spf13/viper"
+)
+
+// svgMetadataKeys are the keys of the component/model metadata holding the references to the SVGs in the SVG store
+var svgMetadataKeys = []string{"svgColor", "svgWhite", "svgComplete"}
+
+// readComponentSVG reads the SVG of the slash separated path relative to root, or fetches it from the SVG store when it is a URL,
+// returning the cleaned path it is exported as. Paths escaping root are rejected.
+func readComponentSVG(ctx context.Context, root, svgRef string) (string, []byte, error) {
+	if strings.HasPrefix(svgRef, "https://") || strings.HasPrefix(svgRef, "http://") {
+		store, ok := utils.GetSVGStore().(*utils.S3SVGStore)
+		if !ok {
+			return "", nil, fmt.Errorf("%s is not served by the configured SVG store", svgRef)
+		}
+		svg, key, err := store.Get(ctx, svgRef)
+		if err != nil {
+			return "", nil, err
+		}
+		name, err := cleanComponentSVGPath(key)
+		if err != nil {
+			return "", nil, err
+		}
+		return name, svg, nil
+	}
+
+	name, err := cleanComponentSVGPath(svgRef)
+	if err != nil {
+		return "", nil, err
+	}
+	svg, err := os.ReadFile(filepath.Join(root, filepath.FromSlash(name)))
+	if err != nil {
+		return "", nil, err
+	}
+	return name, svg, nil
+}
+
+func cleanComponentSVGPath(svgPath string) (string, error) {
+	name := path.Clean(filepath.ToSlash(svgPath))
+	if path.IsAbs(name) || name == ".." || strings.HasPrefix(name, "../") {
+		return "", fmt.Errorf("path %q is not within the root of the SVGs", svgPath)
+	}
+	return name, nil
+}
+
+// swagger:route GET /api/system/kubernetes/contexts/{connection_id}/components/export SystemAPI idGetK8sComponentsExport
+// Handle GET request to export the Kubernetes components registered for a connection
+//
+// Returns a zip archive containing the registered ComponentDefinitions as "components.json" along with their SVGs,
+// read relative to "COMPONENTS_SVG_ROOT" or fetched from the S3 compatible SVG storage
+// responses:
+//
+//	200:
+//	401:
+//	404:
+//	500:
+func (h *Handler) K8sComponentsExportHandler(w http.ResponseWriter, req *http.Request, _ *models.Preference, _ *models.User, provider models.Provider) {
+	token, ok := h.userToken(w, req)
+	if !ok {
+		return
+	}
+
+	connectionID := mux.Vars(req)["connection_id"]
+	k8sContext, err := provider.GetK8sContext(token, connectionID)
+	if err != nil {
+		http.Error(w, "failed to get kubernetes context for the given ID", http.StatusInternalServerError)
+		return
+	}
+
+	comps, err := models.GetK8sContextComponents(h.dbHandler, k8sContext.ID)
+	if err != nil {
+		h.log.Error(ErrGetMeshModels(err))
+		http.Error(w, ErrGetMeshModels(err).Error(), http.StatusInternalServerError)
+		return
+	}
+	if len(comps) == 0 {
+		http.Error(w, fmt.Sprintf("no components registered for kubernetes context %s", k8sContext.Name), http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/zip")
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="%s-components.zip"`, k8sContext.Name))
+
+	zw := zip.NewWriter(w)
+	defer func() {
+		_ = zw.Close()
+	}()
+
+	cw, err := zw.Create("components.json")
+	if err != nil {
+		h.log.Error(ErrWriteResponse)
+		return
+	}
+	if err := json.NewEncoder(cw).Encode(comps); err != nil {
+		h.log.Error(models.ErrMarshal(err, "kubernetes components"))
+		return
+	}
+
+	// Multiple components share the same SVG, write each only once
+	written := make(map[string]bool)
+	for _, comp := range comps {
+		for _, metadata := range []map[string]interface{}{comp.Metadata, comp.Model.Metadata} {
+			for _, key := range svgMetadataKeys {
+				svgPath, _ := metadata[key].(string)
+				if !strings.HasSuffix(svgPath, ".svg") || written[svgPath] {
+					continue
+				}
+				name, svg, err := readComponentSVG(req.Context(), viper.GetString("COMPONENTS_SVG_ROOT"), svgPath)
+				if err != nil {
+					h.log.Warn(ErrReadComponentSVG(err, svgPath, comp.Kind))
+					continue
+				}
+				sw, err := zw.Create(name)
+				if err != nil {
+					h.log.Error(ErrWriteResponse)
+					return
+				}
+				if _, err := sw.Write(svg); err != nil {
+					h.log.Error(ErrWriteResponse)
+					return
+				}
+				written[svgPath] = true
+			}
+		}
+	}
+}
+
+// K8sComponentsManifest is the pre-generated manifest of Kubernetes components registered for a context
+//
+// swagger:model K8sComponentsManifest
+type K8sComponentsManifest struct {
+	// ID of the kubernetes context to register the components for, the connection ID of the context with remote providers
+	ContextID string `json:"context_id"`
+	// Pin the version of the Kubernetes model the components are associated with, optional
+	ModelVersion string `json:"model_version,omitempty"`
+	// ComponentDefinitions as generated for a cluster, e.g. "components.json" of an export
+	Components []v1alpha1.ComponentDefinition `json:"components"`
+}
+
+// swagger:route POST /api/system/kubernetes/components/register-manifest SystemAPI idPostK8sComponentsRegisterManifest
+// Handle POST request to register Kubernetes components from a pre-generated manifest
+//
+// Registers the components of the manifest for the context without accessing the cluster, for air-gapped clusters
+// responses:
+//
+//	200:
+//	400:
+//	401:
+func (h *Handler) K8sComponentsRegisterManifestHandler(w http.ResponseWriter, req *http.Request, _ *models.Preference, user *models.User, provider models.Provider) {
+	token, ok := h.userToken(w, req)
+	if !ok {
+		return
+	}
+	defer func() {
+		_ = req.Body.Close()
+	}()
+
+	var manifest K8sComponentsManifest
+	if err := json.NewDecoder(req.Body).Decode(&manifest); err != nil {
+		err = ErrDecoding(err, "kubernetes components manifest")
+		h.log.Error(err)
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if manifest.ContextID == "" {
+		http.Error(w, "context_id of the kubernetes context to register the components for is required", http.StatusBadRequest)
+		return
+	}
+	// the context must be one of the user's, the components are registered under the ID of the context
+	k8sContext, err := provider.GetK8sContext(token, manifest.ContextID)
+	if err != nil {
+		http.Error(w, "failed to get kubernetes context for the given ID", http.StatusBadRequest)
+		return
+	}
+
+	count, failures, err := mcore.RegisterK8sMeshModelComponentsFromManifest(manifest.Components, k8sContext.ID, h.registryManager, manifest.ModelVersion)
+	if err != nil {
+		h.log.Error(err)
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	userID := uuid.FromStringOrNil(user.ID)
+	eventBuilder := events.NewEvent().FromUser(userID).FromSystem(*h.SystemID).WithCategory("kubernetes_components").WithAction("registration").
+		WithSeverity(events.Informational).WithDescription(fmt.Sprintf("%d Kubernetes components registered from manifest for context %s", count, k8sContext.Name))
+	if len(failures) > 0 {
+		eventBuilder.WithSeverity(events.Warning).WithMetadata(map[string]interface{}{
+			"failed_components": failures,
+		}).WithDescription(fmt.Sprintf("%d Kubernetes components registered from manifest for context %s, %d components failed to register", count, k8sContext.Name, len(failures)))
+	}
+	event := eventBuilder.Build()
+	h.persistEvent(provider, event)
+	go h.config.EventBroadcaster.Publish(userID, event)
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(map[string]interface{}{
+		"context_id":        manifest.ContextID,
+		"components_count":  count,
+		"failed_components": failures,
+	}); err != nil {
+		h.log.Error(models.ErrMarshal(err, "registration result"))
+		http.Error(w, models.ErrMarshal(err, "registration result").Error(), http.StatusInternalServerError)
+	}
+}
+
+// K8sComponentsMetadataRefreshResponse - struct used as (json marshaled) response to the component metadata refresh requests
+type K8sComponentsMetadataRefreshResponse struct {
+	ConnectionID string `json:"connection_id"`
+	mcore.K8sComponentsMetadataRefresh
+}
+
+// swagger:route POST /api/system/kubernetes/contexts/{connection_id}/components/refresh-metadata SystemAPI idPostK8sComponentsRefreshMetadata
+// Handle POST request to refresh the metadata of the Kubernetes components registered for a connection
+//
+// Merges the current metadata of the Kubernetes model into the components registered for the connection and persists them,
+// regenerating their SVGs. The components of the same kinds registered for other connections or by other hosts are left as is.
+// responses:
+//
+//	200:
+//	401:
+//	500:
+func (h *Handler) K8sComponentsRefreshMetadataHandler(w http.ResponseWriter, req *http.Request, _ *models.Preference, _ *models.User, provider models.Provider) {
+	token, ok := h.userToken(w, req)
+	if !ok {
+		return
+	}
+
+	connectionID := mux.Vars(req)["connection_id"]
+	k8sContext, err := provider.GetK8sContext(token, connectionID)
+	if err != nil {
+		http.Error(w, "failed to get kubernetes context for the given ID", http.StatusInternalServerError)
+		return
+	}
+
+	result, err := mcore.RefreshK8sMeshModelComponentsMetadata(h.dbHandler, k8sContext.ID)
+	if err != nil {
+		h.log.Error(ErrGetMeshModels(err))
+		http.Error(w, ErrGetMeshModels(err).Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(K8sComponentsMetadataRefreshResponse{
+		ConnectionID:                 connectionID,
+		K8sComponentsMetadataRefresh: result,
+	}); err != nil {
+		h.log.Error(models.ErrMarshal(err, "component metadata refresh result"))
+		http.Error(w, models.ErrMarshal(err, "component metadata refresh result").Error(), http.StatusInternalServerError)
+	}
+}
+
+// K8sComponentsStatusResponse - struct used as (json marshaled) response to the components status requests
+type K8sComponentsStatusResponse struct {
+	ConnectionID    string `json:"connection_id"`
+	ContextID       string `json:"context_id"`
+	ComponentsCount int64  `json:"components_count"`
+	// Checkpoint of the last registration if it did not complete, the next registration resumes from it
+	Checkpoint *models.K8sComponentsRegistrationCheckpoint `json:"checkpoint,omitempty"`
+}
+
+// swagger:route GET /api/system/kubernetes/contexts/{connection_id}/components/status SystemAPI idGetK8sComponentsStatus
+// Handle GET request for the status of the Kubernetes components registered for a connection
+//
+// Returns the number of components registered, along with the checkpoint of the last registration when it failed partway
+// or left components which failed to register.
+// responses:
+//
+//	200:
+//	401:
+//	500:
+func (h *Handler) K8sComponentsStatusHandler(w http.ResponseWriter, req *http.Request, _ *models.Preference, _ *models.User, provider models.Provider) {
+	token, ok := h.userToken(w, req)
+	if !ok {
+		return
+	}
+
+	connectionID := mux.Vars(req)["connection_id"]
+	k8sContext, err := provider.GetK8sContext(token, connectionID)
+	if err != nil {
+		http.Error(w, "failed to get kubernetes context for the given ID", http.StatusInternalServerError)
+		return
+	}
+
+	count, err := models.CountK8sContextComponents(h.dbHandler, k8sContext.ID)
+	if err != nil {
+		h.log.Error(ErrGetMeshModels(err))
+		http.Error(w, ErrGetMeshModels(err).Error(), http.StatusInternalServerError)
+		return
+	}
+	checkpoint, err := models.GetK8sComponentsRegistrationCheckpoint(h.dbHandler, k8sContext.ID)
+	if err != nil {
+		h.log.Error(ErrGetMeshModels(err))
+		http.Error(w, ErrGetMeshModels(err).Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(K8sComponentsStatusResponse{
+		ConnectionID:    connectionID,
+		ContextID:       k8sContext.ID,
+		ComponentsCount: count,
+		Checkpoint:      checkpoint,
+	}); err != nil {
+		h.log.Error(models.ErrMarshal(err, "kubernetes components status"))
+		http.Error(w, models.ErrMarshal(err, "kubernetes components status").Error(), http.StatusInternalServerError)
+	}
+}
+
+// K8sComponentsComparisonResponse - struct used as (json marshaled) response to the components comparison requests
+type K8sComponentsComparisonResponse struct {
+	// A and B are the IDs of the compared connections
+	A string `json:"a"`
+	B string `json:"b"`
+	models.K8sComponentsComparison
+}
+
+// swagger:route GET /api/system/kubernetes/contexts/compare SystemAPI idGetK8sComponentsComparison
+// Handle GET request to compare the Kubernetes components registered for two connections
+//
+// Compares the components registered for the connections "a" and "b" by the group and kind of their API,
+// listing the kinds registered only for "a", only for "b", and for both along with whether their versions differ.
+// Used to validate that a target cluster serves the same custom resources as the source one before migrating workloads.
+// responses:
+//
+//	200:
+//	400:
+//	401:
+//	500:
+func (h *Handler) K8sComponentsCompareHandler(w http.ResponseWriter, req *http.Request, _ *models.Preference, _ *models.User, provider models.Provider) {
+	token, ok := h.userToken(w, req)
+	if !ok {
+		return
+	}
+
+	q := req.URL.Query()
+	connectionIDs := []string{q.Get("a"), q.Get("b")}
+	if connectionIDs[0] == "" || connectionIDs[1] == "" {
+		http.Error(w, "the IDs of the connections to compare are required as \"a\" and \"b\"", http.StatusBadRequest)
+		return
+	}
+
+	comps := make([][]v1alpha1.ComponentDefinition, len(connectionIDs))
+	for i, connectionID := range connectionIDs {
+		k8sContext, err := provider.GetK8sContext(token, connectionID)
+		if err != nil {
+			http.Error(w, fmt.Sprintf("failed to get kubernetes context for the ID %s", connectionID), http.StatusInternalServerError)
+			return
+		}
+		comps[i], err = models.GetK8sContextComponents(h.dbHandler, k8sContext.ID)
+		if err != nil {
+			h.log.Error(ErrGetMeshModels(err))
+			http.Error(w, ErrGetMeshModels(err).Error(), http.StatusInternalServerError)
+			return
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(K8sComponentsComparisonResponse{
+		A:                       connectionIDs[0],
+		B:                       connectionIDs[1],
+		K8sComponentsComparison: models.CompareK8sComponents(comps[0], comps[1]),
+	}); err != nil {
+		h.log.Error(models.ErrMarshal(err, "kubernetes components comparison"))
+		http.Error(w, models.ErrMarshal(err, "kubernetes components comparison").Error(), http.StatusInternalServerError)
+	}
+}
+
+// swagger:route POST /api/system/kubernetes/contexts/{connection_id}/components/watch SystemAPI idPostK8sComponentsWatch
+// Handle POST request to start watching the custom resource definitions of a connection
+//
+// Registers the components of the custom resource definitions as they are created in the cluster, and unregisters them as they are deleted.
+// The definitions existing when the watch starts are left to the registration of the connection.
+// The components are registered with the same options as the ones of the registration, e.g. "kinds" and "component_labels".
+// responses:
+//
+//	200:
+//	201:
+//	400:
+//	401:
+//	500:
+func (h *Handler) K8sComponentsWatchStartHandler(w http.ResponseWriter, req *http.Request, _ *models.Preference, user *models.User, provider models.Provider) {
+	token, ok := h.userToken(w, req)
+	if !ok {
+		return
+	}
+
+	connectionID := mux.Vars(req)["connection_id"]
+	k8sContext, err := provider.GetK8sContext(token, connectionID)
+	if err != nil {
+		http.Error(w, "failed to get kubernetes context for the given ID", http.StatusInternalServerError)
+		return
+	}
+	kubeconfig, err := k8sContext.GenerateKubeConfig()
+	if err != nil {
+		h.log.Error(err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	registrationOptions, err := k8sComponentsRegistrationOptionsFromRequest(req)
+	if err != nil {
+		h.log.Error(err)
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	watch, started, err := mcore.StartK8sComponentsWatch(mcore.K8sComponentsWatchOptions{
+		Provider:          provider,
+		Config:            kubeconfig,
+		ContextID:         k8sContext.ID,
+		ContextName:       k8sContext.Name,
+		ConnectionID:      connectionID,
+		UserID:            uuid.FromStringOrNil(user.ID),
+		MesheryInstanceID: *h.SystemID,
+		Registry:          h.registryManager,
+		DB:                h.dbHandler,
+		EventBroadcast:    h.config.EventBroadcaster,
+		Log:               h.log,
+		Registration:      registrationOptions,
+	})
+	if err != nil {
+		h.log.Error(err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if started {
+		w.WriteHeader(http.StatusCreated)
+	}
+	if err := json.NewEncoder(w).Encode(watch); err != nil {
+		h.log.Error(models.ErrMarshal(err, "kubernetes components watch"))
+	}
+}
+
+// swagger:route DELETE /api/system/kubernetes/contexts/{connection_id}/components/watch SystemAPI idDeleteK8sComponentsWatch
+// Handle DELETE request to stop watching the custom resource definitions of a connection
+//
+// The components registered by the watch are retained.
+// responses:
+//
+//	204:
+//	404:
+func (h *Handler) K8sComponentsWatchStopHandler(w http.ResponseWriter, req *http.Request, _ *models.Preference, _ *models.User, _ models.Provider) {
+	connectionID := mux.Vars(req)["connection_id"]
+	if !mcore.StopK8sComponentsWatch(connectionID) {
+		http.Error(w, fmt.Sprintf("the components of connection %s are not watched", connectionID), http.StatusNotFound)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
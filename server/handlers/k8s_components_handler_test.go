@@ -0,0 +1,68 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/gofrs/uuid"
+	"github.com/layer5io/meshery/server/models"
+	"github.com/layer5io/meshkit/logger"
+	"github.com/sirupsen/logrus"
+)
+
+func TestReadComponentSVG(t *testing.T) {
+	root := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(root, "ui", "svg"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(root, "ui", "svg", "pod.svg"), []byte("<svg/>"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	name, svg, err := readComponentSVG(context.Background(), root, "ui/./svg/pod.svg")
+	if err != nil || name != "ui/svg/pod.svg" || string(svg) != "<svg/>" {
+		t.Errorf("readComponentSVG() = %q, %q, %v, want the SVG exported as ui/svg/pod.svg", name, svg, err)
+	}
+	for _, svgPath := range []string{"../secret.svg", "ui/../../secret.svg", "/etc/secret.svg"} {
+		if _, _, err := readComponentSVG(context.Background(), root, svgPath); err == nil {
+			t.Errorf("readComponentSVG(%q) succeeded, want the path rejected", svgPath)
+		}
+	}
+	// URLs are fetched from the S3 compatible SVG store only, never from the file system or arbitrary hosts
+	if _, _, err := readComponentSVG(context.Background(), root, "https://example.com/pod.svg"); err == nil {
+		t.Error("readComponentSVG() of a URL succeeded with the file system SVG store, want an error")
+	}
+}
+
+// foreignContextProvider fails to get any context, as for a context of another user
+type foreignContextProvider struct {
+	models.Provider
+}
+
+func (foreignContextProvider) GetK8sContext(_, _ string) (models.K8sContext, error) {
+	return models.K8sContext{}, models.ErrResultNotFound(fmt.Errorf("connection not found"))
+}
+
+func TestK8sComponentsRegisterManifestHandlerForeignContext(t *testing.T) {
+	log, err := logger.New("test", logger.Options{LogLevel: int(logrus.InfoLevel)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	systemID := uuid.Must(uuid.NewV4())
+	h := &Handler{log: log, SystemID: &systemID, config: &models.HandlerConfig{EventBroadcaster: models.NewBroadcaster()}}
+
+	req := httptest.NewRequest(http.MethodPost, "/api/system/kubernetes/components/register-manifest", strings.NewReader(`{"context_id": "other", "components": []}`))
+	req = req.WithContext(context.WithValue(req.Context(), models.TokenCtxKey, "token"))
+	w := httptest.NewRecorder()
+	h.K8sComponentsRegisterManifestHandler(w, req, nil, &models.User{ID: uuid.Must(uuid.NewV4()).String()}, foreignContextProvider{})
+
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("status = %d, want %d", w.Code, http.StatusBadRequest)
+	}
+}
@@ -0,0 +1,130 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/gofrs/uuid"
+	"github.com/layer5io/meshery/server/models"
+	"github.com/layer5io/meshkit/models/events"
+)
+
+// K8sContextsLabelsRequest is the body of a request to add and remove labels of several contexts,
+// selected either by their connection IDs or by a Kubernetes label selector (e.g. "env=staging")
+type K8sContextsLabelsRequest struct {
+	ConnectionIDs []string `json:"connection_ids,omitempty"`
+	Selector      string   `json:"selector,omitempty"`
+	models.K8sContextLabelsPatch
+}
+
+// K8sContextLabelsResult is the outcome of updating the labels of one of the contexts
+type K8sContextLabelsResult struct {
+	ConnectionID string            `json:"connection_id"`
+	Name         string            `json:"name,omitempty"`
+	Labels       map[string]string `json:"labels,omitempty"`
+	Error        string            `json:"error,omitempty"`
+}
+
+// K8sContextsLabelsResponse - struct used as (json marshaled) response to the bulk label requests
+type K8sContextsLabelsResponse struct {
+	Updated int                      `json:"updated"`
+	Failed  int                      `json:"failed"`
+	Results []K8sContextLabelsResult `json:"results"`
+}
+
+// swagger:route POST /api/system/kubernetes/contexts/tags SystemAPI idPostK8sContextsLabels
+// Handle POST request to add and remove labels of several Kubernetes contexts at once
+//
+// The contexts are given either by "connection_ids" or by a Kubernetes label "selector" matched against their labels.
+// The labels of "add" are set and those of "remove" are removed. The local provider updates all of the contexts or none of them,
+// the remote provider updates them one by one. The outcome is returned per connection.
+// responses:
+//
+//	200: K8sContextsLabelsResponse
+//	400:
+//	401:
+//	500:
+func (h *Handler) K8sContextsLabelsHandler(w http.ResponseWriter, req *http.Request, _ *models.Preference, user *models.User, provider models.Provider) {
+	token, ok := h.userToken(w, req)
+	if !ok {
+		return
+	}
+	userID := uuid.FromStringOrNil(user.ID)
+
+	var labelsRequest K8sContextsLabelsRequest
+	if err := json.NewDecoder(req.Body).Decode(&labelsRequest); err != nil {
+		h.log.Error(models.ErrUnmarshal(err, "labels request"))
+		http.Error(w, models.ErrUnmarshal(err, "labels request").Error(), http.StatusBadRequest)
+		return
+	}
+	selector := strings.TrimSpace(labelsRequest.Selector)
+	if (len(labelsRequest.ConnectionIDs) == 0) == (selector == "") {
+		http.Error(w, "either connection_ids or selector is required", http.StatusBadRequest)
+		return
+	}
+	if err := labelsRequest.Validate(); err != nil {
+		h.log.Error(err)
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	ids := labelsRequest.ConnectionIDs
+	if selector != "" {
+		parsed, err := models.ParseK8sContextSelector(selector)
+		if err != nil {
+			h.log.Error(err)
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		contexts, err := models.LoadK8sContextPages(provider, token, "", "", false)
+		if err != nil {
+			h.log.Error(err)
+			http.Error(w, "failed to get contexts", http.StatusInternalServerError)
+			return
+		}
+		for _, ctx := range contexts {
+			if !ctx.MatchesSelector(parsed) {
+				continue
+			}
+			id := ctx.ConnectionID
+			if id == "" {
+				id = ctx.ID
+			}
+			ids = append(ids, id)
+		}
+	}
+
+	response := K8sContextsLabelsResponse{Results: make([]K8sContextLabelsResult, 0, len(ids))}
+	if len(ids) != 0 {
+		for _, result := range provider.PatchK8sContextsLabels(token, ids, labelsRequest.K8sContextLabelsPatch) {
+			labelsResult := K8sContextLabelsResult{ConnectionID: result.ID, Name: result.Context.Name}
+			if result.Err != nil {
+				h.log.Error(ErrFailToSave(result.Err, "kubernetes context"))
+				labelsResult.Error = result.Err.Error()
+				response.Failed++
+			} else {
+				labelsResult.Labels = result.Context.Labels
+				response.Updated++
+			}
+			response.Results = append(response.Results, labelsResult)
+		}
+
+		severity := events.Informational
+		if response.Failed != 0 {
+			severity = events.Warning
+		}
+		event := events.NewEvent().FromUser(userID).FromSystem(*h.SystemID).WithCategory("connection").WithAction("update").
+			WithSeverity(severity).WithDescription(fmt.Sprintf("Labels of %d Kubernetes contexts updated, %d failed.", response.Updated, response.Failed)).
+			WithMetadata(map[string]interface{}{"results": response.Results}).Build()
+		h.persistEvent(provider, event)
+		go h.config.EventBroadcaster.Publish(userID, event)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		h.log.Error(models.ErrMarshal(err, "labels response"))
+		http.Error(w, models.ErrMarshal(err, "labels response").Error(), http.StatusInternalServerError)
+	}
+}
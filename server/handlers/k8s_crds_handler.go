@@ -0,0 +1,66 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"github.com/layer5io/meshery/server/models"
+	mcore "github.com/layer5io/meshery/server/models/meshmodel/core"
+)
+
+// K8sCRDInventoryResponse - struct used as (json marshaled) response to the CRD inventory requests
+type K8sCRDInventoryResponse struct {
+	ConnectionID string         `json:"connection_id"`
+	CRDs         []mcore.K8sCRD `json:"crds"`
+	// API groups whose discovery failed, their custom resources are missing from the inventory
+	DiscoveryFailures []mcore.APIGroupDiscoveryFailure `json:"discovery_failures,omitempty"`
+}
+
+// swagger:route POST /api/system/kubernetes/contexts/{connection_id}/crds SystemAPI idPostK8SContextCRDs
+// Handle POST request for the CRD inventory of a Kubernetes context
+//
+// Lists the custom resources installed in the cluster (group, version, kind and scope) through the discovery client, without registering any component
+// responses:
+//
+//	200:
+//	400:
+//	401:
+//	500:
+func (h *Handler) K8sContextCRDsHandler(w http.ResponseWriter, req *http.Request, _ *models.Preference, _ *models.User, provider models.Provider) {
+	token, ok := h.userToken(w, req)
+	if !ok {
+		return
+	}
+
+	connectionID := mux.Vars(req)["connection_id"]
+	k8sContext, err := provider.GetK8sContext(token, connectionID)
+	if err != nil {
+		http.Error(w, "failed to get kubernetes context for the given ID", http.StatusInternalServerError)
+		return
+	}
+
+	kubeclient, err := k8sContext.GenerateKubeHandler()
+	if err != nil {
+		h.log.Error(ErrInvalidKubeHandler(err, "Meshery"))
+		http.Error(w, ErrInvalidKubeHandler(err, "Meshery").Error(), http.StatusBadRequest)
+		return
+	}
+
+	crds, failures, err := mcore.ListK8sCRDs(req.Context(), kubeclient)
+	if err != nil {
+		h.log.Error(ErrListK8sCRDs(err, k8sContext.Name))
+		http.Error(w, ErrListK8sCRDs(err, k8sContext.Name).Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(K8sCRDInventoryResponse{
+		ConnectionID:      connectionID,
+		CRDs:              crds,
+		DiscoveryFailures: failures,
+	}); err != nil {
+		h.log.Error(models.ErrMarshal(err, "kubernetes crd inventory"))
+		http.Error(w, models.ErrMarshal(err, "kubernetes crd inventory").Error(), http.StatusInternalServerError)
+	}
+}
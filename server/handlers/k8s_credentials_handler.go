@@ -0,0 +1,83 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gofrs/uuid"
+	"github.com/gorilla/mux"
+	"github.com/layer5io/meshery/server/models"
+	"github.com/layer5io/meshkit/models/events"
+)
+
+// k8sCredentialsValidationTimeout bounds the call made to the cluster to validate the credentials of a connection
+const k8sCredentialsValidationTimeout = 10 * time.Second
+
+// K8sCredentialsValidationResponse - struct used as (json marshaled) response to the credentials validation requests
+type K8sCredentialsValidationResponse struct {
+	ConnectionID string `json:"connection_id"`
+	models.K8sCredentialsValidation
+}
+
+// swagger:route POST /api/system/kubernetes/contexts/{connection_id}/validate-credentials SystemAPI idPostK8sContextValidateCredentials
+// Handle POST request to validate the stored credentials of a Kubernetes context
+//
+// Makes a SelfSubjectReview with the stored credentials and reports whether they still authenticate with the cluster,
+// telling credentials rejected by the cluster ("invalid") apart from a cluster which could not be reached ("unreachable").
+// A Warning event is emitted when the credentials are invalid, so that they can be refreshed.
+// responses:
+//
+//	200:
+//	400:
+//	401:
+//	500:
+func (h *Handler) K8sContextValidateCredentialsHandler(w http.ResponseWriter, req *http.Request, _ *models.Preference, user *models.User, provider models.Provider) {
+	token, ok := h.userToken(w, req)
+	if !ok {
+		return
+	}
+
+	connectionID := mux.Vars(req)["connection_id"]
+	k8sContext, err := provider.GetK8sContext(token, connectionID)
+	if err != nil {
+		http.Error(w, "failed to get kubernetes context for the given ID", http.StatusInternalServerError)
+		return
+	}
+
+	kubeclient, err := k8sContext.GenerateKubeHandler()
+	if err != nil {
+		h.log.Error(ErrInvalidKubeHandler(err, "Meshery"))
+		http.Error(w, ErrInvalidKubeHandler(err, "Meshery").Error(), http.StatusBadRequest)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(req.Context(), k8sCredentialsValidationTimeout)
+	defer cancel()
+	validation := models.ValidateK8sCredentials(ctx, kubeclient)
+
+	if validation.Status == models.K8sCredentialsInvalid {
+		userID := uuid.FromStringOrNil(user.ID)
+		event := events.NewEvent().ActedUpon(uuid.FromStringOrNil(connectionID)).FromUser(userID).FromSystem(*h.SystemID).WithCategory("connection").WithAction("validate_credentials").
+			WithSeverity(events.Warning).WithDescription(fmt.Sprintf("Credentials of Kubernetes context \"%s\" at %s are no longer valid, refresh them by uploading the kubeconfig again", k8sContext.Name, k8sContext.Server)).
+			WithMetadata(map[string]interface{}{
+				"connection_id": connectionID,
+				"context":       k8sContext.Name,
+				"server":        k8sContext.Server,
+				"error":         validation.Error,
+			}).Build()
+		h.persistEvent(provider, event)
+		go h.config.EventBroadcaster.Publish(userID, event)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(K8sCredentialsValidationResponse{
+		ConnectionID:             connectionID,
+		K8sCredentialsValidation: validation,
+	}); err != nil {
+		h.log.Error(models.ErrMarshal(err, "credentials validation"))
+		http.Error(w, models.ErrMarshal(err, "credentials validation").Error(), http.StatusInternalServerError)
+	}
+}
@@ -0,0 +1,89 @@
+package handlers
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/layer5io/meshery/server/models"
+	"github.com/spf13/viper"
+	"k8s.io/client-go/tools/clientcmd"
+)
+
+// Policies of a kubeconfig upload whose current-context could not be connected to or saved
+const (
+	// The failure of the current-context is reported in "current_context_failure", the other contexts are saved
+	K8sCurrentContextPolicyBestEffort = "best_effort"
+	// The upload fails with 422 and the failure of the current-context, none of the contexts are saved
+	K8sCurrentContextPolicyStrict = "strict"
+)
+
+// K8sCurrentContextFailure reports why the current-context of the uploaded kubeconfig could not be connected to or saved
+type K8sCurrentContextFailure struct {
+	Context     string `json:"context"`
+	Server      string `json:"server,omitempty"`
+	Description string `json:"description,omitempty"`
+	Error       string `json:"error,omitempty"`
+}
+
+// k8sCurrentContextPolicy returns the policy of the upload for a failing current-context, "current_context_policy"
+// of the request overriding the "KUBECONFIG_CURRENT_CONTEXT_POLICY" default
+func k8sCurrentContextPolicy(req *http.Request) (string, error) {
+	policy := strings.TrimSpace(req.FormValue("current_context_policy"))
+	if policy == "" {
+		policy = viper.GetString("KUBECONFIG_CURRENT_CONTEXT_POLICY")
+	}
+	switch policy {
+	case "", K8sCurrentContextPolicyBestEffort:
+		return K8sCurrentContextPolicyBestEffort, nil
+	case K8sCurrentContextPolicyStrict:
+		return policy, nil
+	}
+	return "", ErrInvalidCurrentContextPolicy(policy)
+}
+
+// currentK8sContextDiscoveryFailure returns the failure of the current-context of the kubeconfig when it was left out of
+// the discovered contexts for not being connected to, as recorded in the event metadata of the discovery, nil otherwise
+func currentK8sContextDiscoveryFailure(kubeconfig []byte, contexts []*models.K8sContext, eventMetadata map[string]interface{}) *K8sCurrentContextFailure {
+	cfg, err := clientcmd.Load(kubeconfig)
+	if err != nil || cfg.CurrentContext == "" {
+		return nil
+	}
+	for _, ctx := range contexts {
+		if ctx.IsCurrentContext {
+			return nil
+		}
+	}
+	metadata, ok := eventMetadata[cfg.CurrentContext].(map[string]interface{})
+	if !ok {
+		return nil
+	}
+	// The context of the metadata is redacted, the server is taken from the kubeconfig
+	var server string
+	if kubeContext, ok := cfg.Contexts[cfg.CurrentContext]; ok {
+		if cluster, ok := cfg.Clusters[kubeContext.Cluster]; ok {
+			server = cluster.Server
+		}
+	}
+	return newK8sCurrentContextFailure(cfg.CurrentContext, server, metadata)
+}
+
+// newK8sCurrentContextFailure returns the failure of the current-context at server from the event metadata of its discovery or save
+func newK8sCurrentContextFailure(name, server string, metadata map[string]interface{}) *K8sCurrentContextFailure {
+	failure := &K8sCurrentContextFailure{Context: name, Server: server}
+	failure.Description, _ = metadata["description"].(string)
+	if err, ok := metadata["error"].(error); ok {
+		failure.Error = err.Error()
+	}
+	return failure
+}
+
+// currentK8sContextFirst moves the current-context to the front of the contexts, to be saved before any other
+func currentK8sContextFirst(contexts []*models.K8sContext) {
+	for i, ctx := range contexts {
+		if ctx.IsCurrentContext {
+			copy(contexts[1:i+1], contexts[:i])
+			contexts[0] = ctx
+			return
+		}
+	}
+}
@@ -0,0 +1,178 @@
+package handlers
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gofrs/uuid"
+	"github.com/gorilla/mux"
+	"github.com/layer5io/meshery/server/machines"
+	"github.com/layer5io/meshery/server/models"
+	"github.com/layer5io/meshkit/models/events"
+)
+
+// k8sDiagnosticsEventsLimit is the number of the most recent events of the connection in the diagnostic bundle
+const k8sDiagnosticsEventsLimit = 100
+
+// K8sDiagnosticsManifest describes the files of a diagnostic bundle and the errors encountered while collecting them
+type K8sDiagnosticsManifest struct {
+	ConnectionID string            `json:"connection_id"`
+	Context      string            `json:"context"`
+	Files        []string          `json:"files"`
+	Errors       map[string]string `json:"errors,omitempty"`
+	GeneratedAt  time.Time         `json:"generated_at"`
+}
+
+// K8sDiagnosticsStateMachine is the state machine of the connection in a diagnostic bundle
+type K8sDiagnosticsStateMachine struct {
+	State       string                `json:"state"`
+	LastError   string                `json:"last_error,omitempty"`
+	LastErrorAt *time.Time            `json:"last_error_at,omitempty"`
+	History     []machines.Transition `json:"history"`
+}
+
+// K8sDiagnosticsPing is the last ping of the connection in a diagnostic bundle
+type K8sDiagnosticsPing struct {
+	Pinged    bool    `json:"pinged"`
+	LatencyMs float64 `json:"latency_ms,omitempty"`
+}
+
+// swagger:route GET /api/system/kubernetes/contexts/{connection_id}/diagnostics SystemAPI idGetK8sContextDiagnostics
+// Handle GET request for the diagnostic bundle of a Kubernetes connection
+//
+// Returns a zip archive with the redacted kubeconfig of the connection, its last ping, the history of its state machine,
+// the number of its registered components and its recent events, to be attached to support requests.
+// Credentials are redacted throughout. Whatever could not be collected is reported in manifest.json.
+// responses:
+//
+//	200:
+//	401:
+//	500:
+func (h *Handler) K8sContextDiagnosticsHandler(w http.ResponseWriter, req *http.Request, _ *models.Preference, user *models.User, provider models.Provider) {
+	token, ok := h.userToken(w, req)
+	if !ok {
+		return
+	}
+	connectionID := mux.Vars(req)["connection_id"]
+
+	k8sContext, err := provider.GetK8sContext(token, connectionID)
+	if err != nil {
+		http.Error(w, "failed to get kubernetes context for the given ID", http.StatusInternalServerError)
+		return
+	}
+
+	bundle, err := h.k8sDiagnosticsBundle(provider, uuid.FromStringOrNil(user.ID), &k8sContext)
+	if err != nil {
+		h.log.Error(err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/zip")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=\"meshery-diagnostics-%s-%s.zip\"", k8sContext.Name, time.Now().UTC().Format("20060102T150405Z")))
+	if _, err := w.Write(bundle); err != nil {
+		h.log.Error(ErrWriteResponse)
+	}
+}
+
+// k8sDiagnosticsBundle collects the diagnostics of the context into a zip archive
+func (h *Handler) k8sDiagnosticsBundle(provider models.Provider, userID uuid.UUID, k8sContext *models.K8sContext) ([]byte, error) {
+	manifest := K8sDiagnosticsManifest{
+		ConnectionID: k8sContext.ConnectionID,
+		Context:      k8sContext.Name,
+		Errors:       map[string]string{},
+		GeneratedAt:  time.Now(),
+	}
+	files := map[string][]byte{}
+	addJSON := func(name string, v interface{}) {
+		data, err := json.MarshalIndent(v, "", "  ")
+		if err != nil {
+			manifest.Errors[name] = models.ErrMarshal(err, name).Error()
+			return
+		}
+		files[name] = data
+	}
+
+	kubeconfig, err := k8sContext.GenerateKubeConfig()
+	if err == nil {
+		kubeconfig, err = models.RedactKubeConfig(kubeconfig)
+	}
+	if err != nil {
+		manifest.Errors["kubeconfig.yaml"] = err.Error()
+	} else {
+		files["kubeconfig.yaml"] = kubeconfig
+	}
+
+	addJSON("context.json", redactK8sContext(*k8sContext))
+
+	ping := K8sDiagnosticsPing{}
+	if latency, ok := k8sPingLatencies.get(k8sContext.ConnectionID); ok {
+		ping.Pinged, ping.LatencyMs = true, float64(latency)/float64(time.Millisecond)
+	}
+	addJSON("ping.json", ping)
+
+	stateMachine := K8sDiagnosticsStateMachine{State: k8sConnectionNotTracked, History: []machines.Transition{}}
+	if inst, ok := h.ConnectionToStateMachineInstanceTracker.Get(uuid.FromStringOrNil(k8sContext.ConnectionID)); ok {
+		stateMachine.State = string(inst.State())
+		if lastError, at := inst.LastError(); lastError != "" {
+			stateMachine.LastError, stateMachine.LastErrorAt = lastError, &at
+		}
+		stateMachine.History = inst.History()
+	}
+	addJSON("state_machine.json", stateMachine)
+
+	count, err := models.CountK8sContextComponents(h.dbHandler, k8sContext.ID)
+	if err != nil {
+		manifest.Errors["components.json"] = err.Error()
+	} else {
+		addJSON("components.json", map[string]int64{"registered": count})
+	}
+
+	eventsResult, err := provider.GetAllEvents(&events.EventsFilter{
+		ActedUpon: []string{k8sContext.ConnectionID},
+		Limit:     k8sDiagnosticsEventsLimit,
+		SortOn:    "created_at",
+		Order:     "desc",
+	}, userID)
+	if err != nil {
+		manifest.Errors["events.json"] = err.Error()
+	} else {
+		addJSON("events.json", eventsResult.Events)
+	}
+
+	var buf bytes.Buffer
+	archive := zip.NewWriter(&buf)
+	for _, name := range []string{"kubeconfig.yaml", "context.json", "ping.json", "state_machine.json", "components.json", "events.json"} {
+		data, ok := files[name]
+		if !ok {
+			continue
+		}
+		f, err := archive.Create(name)
+		if err != nil {
+			return nil, ErrK8sDiagnosticsBundle(err, k8sContext.Name)
+		}
+		if _, err := f.Write(data); err != nil {
+			return nil, ErrK8sDiagnosticsBundle(err, k8sContext.Name)
+		}
+		manifest.Files = append(manifest.Files, name)
+	}
+	data, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return nil, models.ErrMarshal(err, "diagnostics manifest")
+	}
+	f, err := archive.Create("manifest.json")
+	if err != nil {
+		return nil, ErrK8sDiagnosticsBundle(err, k8sContext.Name)
+	}
+	if _, err := f.Write(data); err != nil {
+		return nil, ErrK8sDiagnosticsBundle(err, k8sContext.Name)
+	}
+	if err := archive.Close(); err != nil {
+		return nil, ErrK8sDiagnosticsBundle(err, k8sContext.Name)
+	}
+	return buf.Bytes(), nil
+}
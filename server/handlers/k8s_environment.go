@@ -0,0 +1,107 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+
+	"github.com/layer5io/meshery/server/models"
+	"github.com/layer5io/meshery/server/models/connections"
+	"github.com/layer5io/meshery/server/models/environments"
+)
+
+// environmentPageSize is the size of the pages of environments and connections fetched to resolve an environment
+const environmentPageSize = 25
+
+// k8sEnvironmentConnectionIDs returns the IDs of the Kubernetes connections of the environment with the given name,
+// so that a group of connections, e.g. all the "prod" clusters, can be operated on together.
+func k8sEnvironmentConnectionIDs(req *http.Request, provider models.Provider, token, name, orgID string) (map[string]bool, error) {
+	env, err := environmentByName(provider, token, name, orgID)
+	if err != nil {
+		return nil, err
+	}
+
+	ids := make(map[string]bool)
+	for page := 0; ; page++ {
+		res, err := provider.GetConnectionsOfEnvironment(req, env.ID.String(), strconv.Itoa(page), strconv.Itoa(environmentPageSize), "", "", "")
+		if err != nil {
+			return nil, ErrResolveEnvironment(err, name)
+		}
+		var connectionsPage connections.ConnectionPage
+		if err := json.Unmarshal(res, &connectionsPage); err != nil {
+			return nil, models.ErrUnmarshal(err, "environment connections")
+		}
+		for _, conn := range connectionsPage.Connections {
+			if conn != nil && conn.Kind == "kubernetes" {
+				ids[conn.ID.String()] = true
+			}
+		}
+		if len(connectionsPage.Connections) == 0 || (page+1)*environmentPageSize >= connectionsPage.TotalCount {
+			return ids, nil
+		}
+	}
+}
+
+// environmentByName looks the environment up by its exact name, the search of the providers being a greedy one
+func environmentByName(provider models.Provider, token, name, orgID string) (environments.EnvironmentData, error) {
+	for page := 0; ; page++ {
+		res, err := provider.GetEnvironments(token, strconv.Itoa(page), strconv.Itoa(environmentPageSize), name, "", "", orgID)
+		if err != nil {
+			return environments.EnvironmentData{}, ErrResolveEnvironment(err, name)
+		}
+		var environmentsPage environments.EnvironmentPage
+		if err := json.Unmarshal(res, &environmentsPage); err != nil {
+			return environments.EnvironmentData{}, models.ErrUnmarshal(err, "environments")
+		}
+		for _, env := range environmentsPage.Environments {
+			if env.Name == name {
+				return env, nil
+			}
+		}
+		if len(environmentsPage.Environments) == 0 || (page+1)*environmentPageSize >= environmentsPage.TotalCount {
+			return environments.EnvironmentData{}, ErrResolveEnvironment(fmt.Errorf("no environment named %s", name), name)
+		}
+	}
+}
+
+// k8sEnvironmentFailure is a connection of an environment whose context could not be loaded
+type k8sEnvironmentFailure struct {
+	ConnectionID string
+	Err          error
+}
+
+// k8sContextsOfEnvironment returns the contexts of the Kubernetes connections of the environment with the given name.
+// The connections whose context could not be loaded are returned as failures, the others are operated on regardless.
+func k8sContextsOfEnvironment(req *http.Request, provider models.Provider, token, name, orgID string) ([]*models.K8sContext, []k8sEnvironmentFailure, error) {
+	ids, err := k8sEnvironmentConnectionIDs(req, provider, token, name, orgID)
+	if err != nil {
+		return nil, nil, err
+	}
+	contexts := make([]*models.K8sContext, 0, len(ids))
+	failures := []k8sEnvironmentFailure{}
+	for id := range ids {
+		k8sContext, err := provider.GetK8sContext(token, id)
+		if err != nil {
+			failures = append(failures, k8sEnvironmentFailure{ConnectionID: id, Err: err})
+			continue
+		}
+		if k8sContext.ConnectionID == "" {
+			k8sContext.ConnectionID = id
+		}
+		contexts = append(contexts, &k8sContext)
+	}
+	sort.Slice(contexts, func(i, j int) bool {
+		return contexts[i].Name < contexts[j].Name
+	})
+	sort.Slice(failures, func(i, j int) bool {
+		return failures[i].ConnectionID < failures[j].ConnectionID
+	})
+	return contexts, failures, nil
+}
+
+// inK8sEnvironment reports whether the context is the one of a connection of the environment
+func inK8sEnvironment(k8sContext *models.K8sContext, connectionIDs map[string]bool) bool {
+	return connectionIDs[k8sContext.ConnectionID] || connectionIDs[k8sContext.ID]
+}
@@ -0,0 +1,96 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gofrs/uuid"
+	"github.com/layer5io/meshery/server/models"
+	"github.com/layer5io/meshery/server/models/connections"
+	"github.com/layer5io/meshery/server/models/environments"
+)
+
+type environmentsProvider struct {
+	models.Provider
+	environments []environments.EnvironmentData
+	connections  map[string][]*connections.Connection
+	contexts     map[string]models.K8sContext
+}
+
+func (p environmentsProvider) GetEnvironments(_, _, _, _, _, _, _ string) ([]byte, error) {
+	return json.Marshal(environments.EnvironmentPage{Environments: p.environments, TotalCount: len(p.environments)})
+}
+
+func (p environmentsProvider) GetConnectionsOfEnvironment(_ *http.Request, environmentID, _, _, _, _, _ string) ([]byte, error) {
+	conns := p.connections[environmentID]
+	return json.Marshal(connections.ConnectionPage{Connections: conns, TotalCount: len(conns)})
+}
+
+func (p environmentsProvider) GetK8sContext(_, connectionID string) (models.K8sContext, error) {
+	k8sContext, ok := p.contexts[connectionID]
+	if !ok {
+		return models.K8sContext{}, models.ErrResultNotFound(fmt.Errorf("connection %s not found", connectionID))
+	}
+	return k8sContext, nil
+}
+
+func TestK8sContextsOfEnvironment(t *testing.T) {
+	prod := uuid.Must(uuid.NewV4())
+	cluster, missing := uuid.Must(uuid.NewV4()), uuid.Must(uuid.NewV4())
+	provider := environmentsProvider{
+		environments: []environments.EnvironmentData{{ID: prod, Name: "prod"}},
+		connections: map[string][]*connections.Connection{
+			prod.String(): {{ID: cluster, Kind: "kubernetes"}, {ID: missing, Kind: "kubernetes"}},
+		},
+		contexts: map[string]models.K8sContext{cluster.String(): {Name: "prod-cluster"}},
+	}
+	req := httptest.NewRequest(http.MethodGet, "/api/system/kubernetes/ping?environment=prod", nil)
+
+	contexts, failures, err := k8sContextsOfEnvironment(req, provider, "token", "prod", "")
+	if err != nil {
+		t.Fatalf("k8sContextsOfEnvironment() failed for a single connection: %v", err)
+	}
+	if len(contexts) != 1 || contexts[0].Name != "prod-cluster" || contexts[0].ConnectionID != cluster.String() {
+		t.Errorf("contexts = %+v, want the context of the connection which could be loaded", contexts)
+	}
+	if len(failures) != 1 || failures[0].ConnectionID != missing.String() || failures[0].Err == nil {
+		t.Errorf("failures = %+v, want the connection whose context could not be loaded", failures)
+	}
+
+	results := erroredK8sEnvironmentRegistrationResults(failures)
+	if len(results) != 1 || results[0].ConnectionID != missing.String() || results[0].Status != models.K8sRegistrationFailed {
+		t.Errorf("erroredK8sEnvironmentRegistrationResults() = %+v, want the connection reported as failed", results)
+	}
+}
+
+func TestK8sEnvironmentConnectionIDs(t *testing.T) {
+	prod, production := uuid.Must(uuid.NewV4()), uuid.Must(uuid.NewV4())
+	cluster, grafana := uuid.Must(uuid.NewV4()), uuid.Must(uuid.NewV4())
+	provider := environmentsProvider{
+		// The search of the providers is greedy, "prod" matches "production" too.
+		environments: []environments.EnvironmentData{{ID: production, Name: "production"}, {ID: prod, Name: "prod"}},
+		connections: map[string][]*connections.Connection{
+			prod.String():       {{ID: cluster, Kind: "kubernetes"}, {ID: grafana, Kind: "grafana"}},
+			production.String(): {{ID: uuid.Must(uuid.NewV4()), Kind: "kubernetes"}},
+		},
+	}
+	req := httptest.NewRequest(http.MethodGet, "/api/system/kubernetes/contexts?environment=prod", nil)
+
+	ids, err := k8sEnvironmentConnectionIDs(req, provider, "token", "prod", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ids) != 1 || !ids[cluster.String()] {
+		t.Errorf("k8sEnvironmentConnectionIDs() = %v, want the kubernetes connection of prod only", ids)
+	}
+	if !inK8sEnvironment(&models.K8sContext{ConnectionID: cluster.String()}, ids) || inK8sEnvironment(&models.K8sContext{ID: grafana.String()}, ids) {
+		t.Error("inK8sEnvironment() does not match the contexts by their connection")
+	}
+
+	if _, err := k8sEnvironmentConnectionIDs(req, provider, "token", "staging", ""); err == nil {
+		t.Error("k8sEnvironmentConnectionIDs() expected an error for an unknown environment")
+	}
+}
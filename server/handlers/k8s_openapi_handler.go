@@ -0,0 +1,89 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/layer5io/meshery/server/models"
+	mcore "github.com/layer5io/meshery/server/models/meshmodel/core"
+	"github.com/spf13/viper"
+)
+
+// K8sOpenAPIResponse - struct used as (json marshaled) response to the OpenAPI schema requests
+type K8sOpenAPIResponse struct {
+	ConnectionID string `json:"connection_id"`
+	// Group the schema is filtered by, all of the API groups when empty
+	Group string `json:"group,omitempty"`
+	mcore.K8sOpenAPI
+	// Cached reports whether the schema was served from the cache, fetched Age seconds ago
+	Cached bool    `json:"cached"`
+	Age    float64 `json:"age"`
+}
+
+// swagger:route GET /api/system/kubernetes/contexts/{connection_id}/openapi SystemAPI idGetK8sContextOpenAPI
+// Handle GET request for the OpenAPI schema of a Kubernetes context
+//
+// Returns the OpenAPI v3 documents of the group versions served by the cluster, the raw input the components of the context
+// are generated from, for external tools to do their own generation. "group" filters the documents by API group,
+// "core" for the core group. The schema is cached for "KUBERNETES_OPENAPI_CACHE_TTL" unless "fresh=true" is passed.
+// responses:
+//
+//	200: K8sOpenAPIResponse
+//	400:
+//	401:
+//	500:
+func (h *Handler) K8sContextOpenAPIHandler(w http.ResponseWriter, req *http.Request, _ *models.Preference, _ *models.User, provider models.Provider) {
+	token, ok := h.userToken(w, req)
+	if !ok {
+		return
+	}
+	connectionID := mux.Vars(req)["connection_id"]
+	group := strings.TrimSpace(req.URL.Query().Get("group"))
+	k8sContext, err := provider.GetK8sContext(token, connectionID)
+	if err != nil {
+		http.Error(w, "failed to get kubernetes context for the given ID", http.StatusInternalServerError)
+		return
+	}
+
+	cacheKey := connectionID + "/" + group
+	cached, ok := k8sOpenAPIs.get(cacheKey)
+	if !ok || req.URL.Query().Get("fresh") == "true" {
+		kubeclient, err := k8sContext.GenerateKubeHandler()
+		if err != nil {
+			h.log.Error(ErrInvalidKubeHandler(err, "Meshery"))
+			http.Error(w, ErrInvalidKubeHandler(err, "Meshery").Error(), http.StatusBadRequest)
+			return
+		}
+		openAPI, err := mcore.FetchK8sOpenAPI(req.Context(), kubeclient, group)
+		if err != nil {
+			h.log.Error(ErrFetchK8sOpenAPI(err, k8sContext.Name))
+			http.Error(w, ErrFetchK8sOpenAPI(err, k8sContext.Name).Error(), http.StatusInternalServerError)
+			return
+		}
+		cached, ok = k8sOpenAPI{openAPI: openAPI, fetchedAt: time.Now()}, false
+		k8sOpenAPIs.set(cacheKey, cached, viper.GetDuration("KUBERNETES_OPENAPI_CACHE_TTL"))
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(K8sOpenAPIResponse{
+		ConnectionID: connectionID,
+		Group:        group,
+		K8sOpenAPI:   *cached.openAPI,
+		Cached:       ok,
+		Age:          time.Since(cached.fetchedAt).Round(time.Millisecond).Seconds(),
+	}); err != nil {
+		h.log.Error(models.ErrMarshal(err, "kubernetes openapi"))
+		http.Error(w, models.ErrMarshal(err, "kubernetes openapi").Error(), http.StatusInternalServerError)
+	}
+}
+
+// k8sOpenAPIs caches the OpenAPI schema of the clusters per connection and group, as it is large and rarely changes
+var k8sOpenAPIs = newTTLCache[k8sOpenAPI]()
+
+type k8sOpenAPI struct {
+	openAPI   *mcore.K8sOpenAPI
+	fetchedAt time.Time
+}
@@ -0,0 +1,130 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/gofrs/uuid"
+	"github.com/gorilla/mux"
+	"github.com/layer5io/meshery/server/models"
+	"github.com/layer5io/meshkit/models/events"
+)
+
+// swagger:route GET /api/system/kubernetes/contexts/{connection_id}/portable SystemAPI idGetPortableK8sContext
+// Handle GET request to export a Kubernetes connection in a portable format
+//
+// Returns the connection as a self-contained JSON document, its configuration along with the kubeconfig of the context,
+// to be imported through POST /api/system/kubernetes/contexts/import on another Meshery instance.
+// The credentials are left out unless both "include_credentials=true" and "confirm=true" are passed,
+// an event records every export of the credentials.
+// responses:
+//
+//	200: PortableK8sContext
+//	400:
+//	401:
+//	500:
+func (h *Handler) PortableK8sContextExportHandler(w http.ResponseWriter, req *http.Request, _ *models.Preference, user *models.User, provider models.Provider) {
+	token, ok := h.userToken(w, req)
+	if !ok {
+		return
+	}
+	withCredentials := req.URL.Query().Get("include_credentials") == "true"
+	if withCredentials && req.URL.Query().Get("confirm") != "true" {
+		http.Error(w, "the exported connection would hold the credentials of the cluster, pass \"confirm=true\" to export them", http.StatusBadRequest)
+		return
+	}
+
+	connectionID := mux.Vars(req)["connection_id"]
+	k8sContext, err := provider.GetK8sContext(token, connectionID)
+	if err != nil {
+		http.Error(w, "failed to get kubernetes context for the given ID", http.StatusInternalServerError)
+		return
+	}
+	portable, err := models.NewPortableK8sContext(k8sContext, withCredentials)
+	if err != nil {
+		h.log.Error(err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	if withCredentials {
+		userID := uuid.FromStringOrNil(user.ID)
+		event := events.NewEvent().ActedUpon(uuid.FromStringOrNil(connectionID)).FromUser(userID).FromSystem(*h.SystemID).WithCategory("connection").WithAction("export").
+			WithSeverity(events.Informational).WithDescription(fmt.Sprintf("Kubernetes context %s exported along with its credentials.", k8sContext.Name)).Build()
+		h.persistEvent(provider, event)
+		go h.config.EventBroadcaster.Publish(userID, event)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="%s-connection.json"`, k8sContext.Name))
+	if err := json.NewEncoder(w).Encode(portable); err != nil {
+		h.log.Error(models.ErrMarshal(err, "portable kubernetes context"))
+		http.Error(w, models.ErrMarshal(err, "portable kubernetes context").Error(), http.StatusInternalServerError)
+	}
+}
+
+// swagger:route POST /api/system/kubernetes/contexts/import SystemAPI idPostPortableK8sContextImport
+// Handle POST request to import a Kubernetes connection exported in the portable format
+//
+// Recreates the connection of the portable context, as exported along with its credentials by
+// GET /api/system/kubernetes/contexts/{connection_id}/portable, with its configuration restored.
+// Importing stores the credentials of the cluster, hence "confirm=true" must be passed.
+// responses:
+//
+//	200: SaveK8sContextResponse
+//	400:
+//	401:
+//	500:
+func (h *Handler) PortableK8sContextImportHandler(w http.ResponseWriter, req *http.Request, prefObj *models.Preference, user *models.User, provider models.Provider) {
+	token, ok := h.userToken(w, req)
+	if !ok {
+		return
+	}
+	userID := uuid.FromStringOrNil(user.ID)
+
+	var portable models.PortableK8sContext
+	if err := json.NewDecoder(req.Body).Decode(&portable); err != nil {
+		h.log.Error(models.ErrUnmarshal(err, "portable kubernetes context"))
+		http.Error(w, models.ErrUnmarshal(err, "portable kubernetes context").Error(), http.StatusBadRequest)
+		return
+	}
+	if err := portable.Validate(); err != nil {
+		h.log.Error(err)
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if req.URL.Query().Get("confirm") != "true" {
+		http.Error(w, "importing the connection stores the credentials of the cluster, pass \"confirm=true\" to import it", http.StatusBadRequest)
+		return
+	}
+
+	response := SaveK8sContextResponse{
+		RegisteredContexts: make([]models.K8sContext, 0),
+		ConnectedContexts:  make([]models.K8sContext, 0),
+		IgnoredContexts:    make([]models.K8sContext, 0),
+		ErroredContexts:    make([]models.K8sContext, 0),
+	}
+	eventBuilder := events.NewEvent().FromUser(userID).FromSystem(*h.SystemID).WithCategory("connection").WithAction("create").
+		WithDescription(fmt.Sprintf("Kubernetes context %s imported.", portable.Name)).WithSeverity(events.Informational)
+	eventMetadata := map[string]interface{}{}
+
+	contexts := models.K8sContextsFromKubeconfig(provider, user.ID, h.config.EventBroadcaster, []byte(portable.Kubeconfig), h.SystemID, eventMetadata, portable.Configure)
+	for _, ctx := range contexts {
+		eventMetadata[ctx.Name], _, _ = h.saveK8sContext(req, provider, token, userID, ctx, eventBuilder, &response, prefObj.K8sConnectionDefaultState())
+	}
+	if len(contexts) > 0 {
+		h.config.K8scontextChannel.PublishContext()
+	}
+
+	event := eventBuilder.WithMetadata(eventMetadata).Build()
+	h.persistEvent(provider, event)
+	go h.config.EventBroadcaster.Publish(userID, event)
+
+	response.sort()
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		h.log.Error(models.ErrMarshal(err, "kubernetes context import"))
+		http.Error(w, models.ErrMarshal(err, "kubernetes context import").Error(), http.StatusInternalServerError)
+	}
+}
@@ -0,0 +1,56 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/layer5io/meshery/server/models"
+	"github.com/spf13/viper"
+)
+
+// swagger:route GET /api/system/kubernetes/primary SystemAPI idGetPrimaryK8sContext
+// Handle GET request for the Kubernetes connection Meshery uses to operate on its own cluster
+//
+// The in-cluster connection is preferred when reachable, falling back to the out-of-cluster connection
+// designated by "KUBERNETES_FALLBACK_CONTEXT" and then to the first reachable one.
+// "KUBERNETES_PRIMARY_CONTEXT" overrides the choice. The reason of the choice is part of the response.
+// responses:
+//
+//	200: PrimaryK8sContext
+//	401:
+//	404:
+//	500:
+func (h *Handler) PrimaryK8sContextHandler(w http.ResponseWriter, req *http.Request, _ *models.Preference, _ *models.User, provider models.Provider) {
+	token, ok := h.userToken(w, req)
+	if !ok {
+		return
+	}
+
+	// the contexts are probed, hence loaded along with their credentials
+	contexts, err := models.LoadK8sContextPages(provider, token, "", "", true)
+	if err != nil {
+		h.log.Error(err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	probeTimeout := viper.GetDuration("KUBERNETES_PROBE_TIMEOUT")
+	primary, err := models.ResolvePrimaryK8sContext(contexts, viper.GetString("KUBERNETES_PRIMARY_CONTEXT"), viper.GetString("KUBERNETES_FALLBACK_CONTEXT"), func(ctx *models.K8sContext) error {
+		_, err := ctx.ServerVersionWithTimeout(probeTimeout)
+		return err
+	})
+	if err != nil {
+		h.log.Warn(err)
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	// the identity of the context is the point of the response, only its credentials are left out
+	primaryContext := redactK8sContext(*primary.Context)
+	primary.Context = &primaryContext
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(primary); err != nil {
+		h.log.Error(models.ErrMarshal(err, "primary kubernetes context"))
+		http.Error(w, models.ErrMarshal(err, "primary kubernetes context").Error(), http.StatusInternalServerError)
+	}
+}
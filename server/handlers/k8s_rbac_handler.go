@@ -0,0 +1,195 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"slices"
+
+	"github.com/gorilla/mux"
+	"github.com/layer5io/meshery/server/models"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	authorizationv1client "k8s.io/client-go/kubernetes/typed/authorization/v1"
+)
+
+// mesheryRBACCapability is an operation Meshery performs against the cluster
+// along with the access required to perform it.
+type mesheryRBACCapability struct {
+	Name        string
+	Description string
+	Rules       []rbacv1.PolicyRule
+}
+
+// mesheryRBACCapabilities are the operations Meshery requires to manage a cluster.
+var mesheryRBACCapabilities = []mesheryRBACCapability{
+	{
+		Name:        "operator_crds",
+		Description: "Create the CustomResourceDefinitions used by Meshery Operator",
+		Rules: []rbacv1.PolicyRule{
+			{APIGroups: []string{"apiextensions.k8s.io"}, Resources: []string{"customresourcedefinitions"}, Verbs: []string{"get", "create", "update"}},
+		},
+	},
+	{
+		Name:        "operator_deploy",
+		Description: "Deploy Meshery Operator and its controllers in the meshery namespace",
+		Rules: []rbacv1.PolicyRule{
+			{APIGroups: []string{""}, Resources: []string{"namespaces"}, Verbs: []string{"create"}},
+			{APIGroups: []string{"apps"}, Resources: []string{"deployments"}, Verbs: []string{"get", "create", "delete"}},
+		},
+	},
+	{
+		Name:        "discovery",
+		Description: "Read the cluster identity, version and the API discovery documents used for component generation",
+		Rules: []rbacv1.PolicyRule{
+			{APIGroups: []string{""}, Resources: []string{"namespaces"}, Verbs: []string{"get", "list"}},
+			{APIGroups: []string{"apiextensions.k8s.io"}, Resources: []string{"customresourcedefinitions"}, Verbs: []string{"list"}},
+			{NonResourceURLs: []string{"/version", "/openapi/v3", "/openapi/v3/*"}, Verbs: []string{"get"}},
+		},
+	},
+	{
+		Name:        "meshsync",
+		Description: "List and watch cluster resources for MeshSync",
+		Rules: []rbacv1.PolicyRule{
+			{APIGroups: []string{"*"}, Resources: []string{"*"}, Verbs: []string{"list", "watch"}},
+		},
+	},
+}
+
+// MesheryRBACCapabilityResult is the outcome of the access review for a single capability.
+type MesheryRBACCapabilityResult struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	Allowed     bool   `json:"allowed"`
+	// Rules that need to be granted to the user of the context to perform the operation.
+	MissingRules []rbacv1.PolicyRule `json:"missing_rules,omitempty"`
+	// Rules whose access could not be reviewed, the first of the errors of their reviews is reported in Error.
+	UncheckedRules []rbacv1.PolicyRule `json:"unchecked_rules,omitempty"`
+	Error          string              `json:"error,omitempty"`
+}
+
+// MesheryRBACResponse - struct used as (json marshaled) response to Meshery RBAC check requests
+type MesheryRBACResponse struct {
+	ConnectionID string                        `json:"connection_id"`
+	Allowed      bool                          `json:"allowed"`
+	Capabilities []MesheryRBACCapabilityResult `json:"capabilities"`
+}
+
+// swagger:route POST /api/system/kubernetes/contexts/{connection_id}/meshery-rbac SystemAPI idPostMesheryRBACCheck
+// Handle POST request to check RBAC of a Kubernetes context
+//
+// Runs SelfSubjectAccessReviews for the operations Meshery requires and reports pass/fail per capability along with the rules needed to fix each gap,
+// and the rules whose reviews failed as unchecked
+// responses:
+//
+//	200:
+//	400:
+//	401:
+//	500:
+func (h *Handler) MesheryRBACCheckHandler(w http.ResponseWriter, req *http.Request, _ *models.Preference, _ *models.User, provider models.Provider) {
+	token, ok := h.userToken(w, req)
+	if !ok {
+		return
+	}
+
+	connectionID := mux.Vars(req)["connection_id"]
+	k8sContext, err := provider.GetK8sContext(token, connectionID)
+	if err != nil {
+		http.Error(w, "failed to get kubernetes context for the given ID", http.StatusInternalServerError)
+		return
+	}
+
+	kubeclient, err := k8sContext.GenerateKubeHandler()
+	if err != nil {
+		h.log.Error(ErrInvalidKubeHandler(err, "Meshery"))
+		http.Error(w, ErrInvalidKubeHandler(err, "Meshery").Error(), http.StatusBadRequest)
+		return
+	}
+
+	res := MesheryRBACResponse{ConnectionID: connectionID}
+	res.Allowed, res.Capabilities = reviewMesheryRBAC(req.Context(), kubeclient.KubeClient.AuthorizationV1().SelfSubjectAccessReviews())
+
+	if err := json.NewEncoder(w).Encode(res); err != nil {
+		h.log.Error(models.ErrMarshal(err, "meshery rbac check"))
+		http.Error(w, models.ErrMarshal(err, "meshery rbac check").Error(), http.StatusInternalServerError)
+	}
+}
+
+// reviewMesheryRBAC reviews the access to each of mesheryRBACCapabilities, returns whether all of them are allowed and the results per capability.
+// A capability whose access could not be reviewed entirely is not allowed, the verbs not reviewed are reported as unchecked.
+func reviewMesheryRBAC(ctx context.Context, ssar authorizationv1client.SelfSubjectAccessReviewInterface) (bool, []MesheryRBACCapabilityResult) {
+	allowed := true
+	results := make([]MesheryRBACCapabilityResult, 0, len(mesheryRBACCapabilities))
+	for _, capability := range mesheryRBACCapabilities {
+		result := MesheryRBACCapabilityResult{
+			Name:        capability.Name,
+			Description: capability.Description,
+			Allowed:     true,
+		}
+		for _, rule := range capability.Rules {
+			missing := rbacv1.PolicyRule{APIGroups: rule.APIGroups, Resources: rule.Resources, NonResourceURLs: rule.NonResourceURLs}
+			unchecked := missing
+			for _, review := range accessReviewsForRule(rule) {
+				verb := ""
+				if review.Spec.ResourceAttributes != nil {
+					verb = review.Spec.ResourceAttributes.Verb
+				} else {
+					verb = review.Spec.NonResourceAttributes.Verb
+				}
+				resp, err := ssar.Create(ctx, &review, metav1.CreateOptions{})
+				if err != nil {
+					if result.Error == "" {
+						result.Error = err.Error()
+					}
+					if !slices.Contains(unchecked.Verbs, verb) {
+						unchecked.Verbs = append(unchecked.Verbs, verb)
+					}
+					continue
+				}
+				if !resp.Status.Allowed && !slices.Contains(missing.Verbs, verb) {
+					missing.Verbs = append(missing.Verbs, verb)
+				}
+			}
+			if len(missing.Verbs) > 0 {
+				result.Allowed = false
+				result.MissingRules = append(result.MissingRules, missing)
+			}
+			if len(unchecked.Verbs) > 0 {
+				result.Allowed = false
+				result.UncheckedRules = append(result.UncheckedRules, unchecked)
+			}
+		}
+		allowed = allowed && result.Allowed
+		results = append(results, result)
+	}
+	return allowed, results
+}
+
+// accessReviewsForRule expands a policy rule into one SelfSubjectAccessReview
+// per verb and API group/resource or non-resource URL combination.
+func accessReviewsForRule(rule rbacv1.PolicyRule) []authorizationv1.SelfSubjectAccessReview {
+	reviews := []authorizationv1.SelfSubjectAccessReview{}
+	for _, verb := range rule.Verbs {
+		if len(rule.NonResourceURLs) > 0 {
+			for _, url := range rule.NonResourceURLs {
+				reviews = append(reviews, authorizationv1.SelfSubjectAccessReview{
+					Spec: authorizationv1.SelfSubjectAccessReviewSpec{
+						NonResourceAttributes: &authorizationv1.NonResourceAttributes{Path: url, Verb: verb},
+					},
+				})
+			}
+			continue
+		}
+		for _, group := range rule.APIGroups {
+			for _, resource := range rule.Resources {
+				reviews = append(reviews, authorizationv1.SelfSubjectAccessReview{
+					Spec: authorizationv1.SelfSubjectAccessReviewSpec{
+						ResourceAttributes: &authorizationv1.ResourceAttributes{Group: group, Resource: resource, Verb: verb},
+					},
+				})
+			}
+		}
+	}
+	return reviews
+}
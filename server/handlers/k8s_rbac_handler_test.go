@@ -0,0 +1,130 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/gofrs/uuid"
+	"github.com/gorilla/mux"
+	"github.com/layer5io/meshery/server/internal/sql"
+	"github.com/layer5io/meshery/server/models"
+	"github.com/layer5io/meshkit/logger"
+	"github.com/sirupsen/logrus"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+// reviewMesheryRBACWith reviews the access with a fake clientset answering each review with review
+func reviewMesheryRBACWith(review func(authorizationv1.SelfSubjectAccessReviewSpec) (bool, error)) (bool, map[string]MesheryRBACCapabilityResult) {
+	client := fake.NewSimpleClientset()
+	client.PrependReactor("create", "selfsubjectaccessreviews", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		ssar := action.(k8stesting.CreateAction).GetObject().(*authorizationv1.SelfSubjectAccessReview)
+		allowed, err := review(ssar.Spec)
+		if err != nil {
+			return true, nil, err
+		}
+		ssar.Status.Allowed = allowed
+		return true, ssar, nil
+	})
+	allowed, results := reviewMesheryRBAC(context.Background(), client.AuthorizationV1().SelfSubjectAccessReviews())
+	byName := make(map[string]MesheryRBACCapabilityResult, len(results))
+	for _, result := range results {
+		byName[result.Name] = result
+	}
+	return allowed, byName
+}
+
+func TestReviewMesheryRBAC(t *testing.T) {
+	allowed, results := reviewMesheryRBACWith(func(authorizationv1.SelfSubjectAccessReviewSpec) (bool, error) { return true, nil })
+	if !allowed || len(results) != len(mesheryRBACCapabilities) {
+		t.Errorf("reviewMesheryRBAC() = %v, %v, want every capability allowed", allowed, results)
+	}
+
+	// The deployments cannot be deleted
+	allowed, results = reviewMesheryRBACWith(func(spec authorizationv1.SelfSubjectAccessReviewSpec) (bool, error) {
+		attrs := spec.ResourceAttributes
+		return attrs == nil || attrs.Resource != "deployments" || attrs.Verb != "delete", nil
+	})
+	deploy := results["operator_deploy"]
+	if allowed || deploy.Allowed || len(deploy.MissingRules) != 1 || !reflect.DeepEqual(deploy.MissingRules[0].Verbs, []string{"delete"}) {
+		t.Errorf("operator_deploy = %+v, want the delete verb on deployments missing", deploy)
+	}
+	if !results["discovery"].Allowed {
+		t.Errorf("discovery = %+v, want it allowed", results["discovery"])
+	}
+
+	// The reviews of the CRDs fail, each of their verbs is reported as unchecked instead of the first one only
+	allowed, results = reviewMesheryRBACWith(func(spec authorizationv1.SelfSubjectAccessReviewSpec) (bool, error) {
+		if attrs := spec.ResourceAttributes; attrs != nil && attrs.Resource == "customresourcedefinitions" {
+			return false, errors.New("the server is currently unable to handle the request")
+		}
+		return true, nil
+	})
+	crds := results["operator_crds"]
+	if allowed || crds.Allowed || crds.Error == "" || len(crds.MissingRules) != 0 {
+		t.Errorf("operator_crds = %+v, want it not allowed with the error of the review", crds)
+	}
+	if len(crds.UncheckedRules) != 1 || !reflect.DeepEqual(crds.UncheckedRules[0].Verbs, []string{"get", "create", "update"}) {
+		t.Errorf("operator_crds unchecked rules = %+v, want every verb of the rule", crds.UncheckedRules)
+	}
+	if !results["operator_deploy"].Allowed {
+		t.Errorf("operator_deploy = %+v, want the other capabilities reviewed", results["operator_deploy"])
+	}
+}
+
+func TestMesheryRBACCheckHandler(t *testing.T) {
+	apiServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var review authorizationv1.SelfSubjectAccessReview
+		if r.URL.Path != "/apis/authorization.k8s.io/v1/selfsubjectaccessreviews" || json.NewDecoder(r.Body).Decode(&review) != nil {
+			http.NotFound(w, r)
+			return
+		}
+		review.Status.Allowed = review.Spec.NonResourceAttributes == nil
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(review)
+	}))
+	defer apiServer.Close()
+
+	log, err := logger.New("test", logger.Options{LogLevel: int(logrus.InfoLevel)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	systemID := uuid.Must(uuid.NewV4())
+	h := &Handler{log: log, SystemID: &systemID, config: &models.HandlerConfig{EventBroadcaster: models.NewBroadcaster()}}
+	connectionID := uuid.Must(uuid.NewV4()).String()
+	provider := &storedContextProvider{k8sContext: models.K8sContext{
+		Name:    "staging",
+		Server:  apiServer.URL,
+		Cluster: sql.Map{"name": "staging", "cluster": map[string]interface{}{"server": apiServer.URL}},
+		Auth:    sql.Map{"name": "staging", "user": map[string]interface{}{"token": "abc"}},
+	}}
+
+	req := httptest.NewRequest(http.MethodPost, "/api/system/kubernetes/contexts/"+connectionID+"/meshery-rbac", nil)
+	req = mux.SetURLVars(req, map[string]string{"connection_id": connectionID})
+	req = req.WithContext(context.WithValue(req.Context(), models.TokenCtxKey, "token"))
+	w := httptest.NewRecorder()
+	h.MesheryRBACCheckHandler(w, req, nil, &models.User{ID: uuid.Must(uuid.NewV4()).String()}, provider)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("MesheryRBACCheckHandler() status = %d, body = %s", w.Code, w.Body.String())
+	}
+	var res MesheryRBACResponse
+	if err := json.NewDecoder(w.Body).Decode(&res); err != nil {
+		t.Fatal(err)
+	}
+	if res.ConnectionID != connectionID || res.Allowed || len(res.Capabilities) != len(mesheryRBACCapabilities) {
+		t.Fatalf("MesheryRBACCheckHandler() = %+v, want the non-resource URLs denied", res)
+	}
+	for _, capability := range res.Capabilities {
+		if capability.Allowed != (capability.Name != "discovery") {
+			t.Errorf("capability %s allowed = %v, want only discovery denied", capability.Name, capability.Allowed)
+		}
+	}
+}
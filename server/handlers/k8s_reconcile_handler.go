@@ -0,0 +1,316 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+
+	"github.com/gofrs/uuid"
+	"github.com/layer5io/meshery/server/helpers"
+	"github.com/layer5io/meshery/server/models"
+	"github.com/layer5io/meshkit/models/events"
+	"gopkg.in/yaml.v2"
+	"k8s.io/client-go/tools/clientcmd"
+)
+
+// K8sReconcileEntry identifies a context of the reconciliation plan
+type K8sReconcileEntry struct {
+	Name         string `json:"name"`
+	Server       string `json:"server"`
+	ContextID    string `json:"context_id,omitempty"`
+	ConnectionID string `json:"connection_id,omitempty"`
+	// Changed reports, for contexts present in both, whether the cluster or credentials of the kubeconfig differ from the saved ones
+	Changed bool `json:"changed,omitempty"`
+	// Managed reports whether the saved context is managed by automation, only managed contexts are pruned
+	Managed bool `json:"managed,omitempty"`
+
+	context *models.K8sContext
+}
+
+// K8sReconcilePlan is the difference between a kubeconfig and the saved connections
+//
+// swagger:model K8sReconcilePlan
+type K8sReconcilePlan struct {
+	// Contexts of the kubeconfig which are not saved
+	ToAdd []K8sReconcileEntry `json:"to_add"`
+	// Saved contexts which are not in the kubeconfig
+	ToRemove []K8sReconcileEntry `json:"to_remove"`
+	// Contexts present in both
+	InBoth []K8sReconcileEntry `json:"in_both"`
+}
+
+// swagger:route POST /api/system/kubernetes/contexts/reconcile SystemAPI idPostK8sContextsReconcile
+// Handle POST request to preview the reconciliation of the saved connections with a kubeconfig
+//
+// Returns the contexts of the uploaded kubeconfig which would be added, the saved contexts which would be removed
+// and the contexts present in both. Nothing is mutated.
+// responses:
+//
+//	200: K8sReconcilePlan
+//	400:
+//	401:
+//	500:
+func (h *Handler) K8sContextsReconcileHandler(w http.ResponseWriter, req *http.Request, _ *models.Preference, _ *models.User, provider models.Provider) {
+	token, ok := h.userToken(w, req)
+	if !ok {
+		return
+	}
+
+	plan, _, err := h.reconcileK8sContexts(req, token, provider)
+	if err != nil {
+		h.log.Error(err)
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(plan); err != nil {
+		h.log.Error(models.ErrMarshal(err, "reconciliation plan"))
+		http.Error(w, models.ErrMarshal(err, "reconciliation plan").Error(), http.StatusInternalServerError)
+	}
+}
+
+// reconcileK8sContexts computes the reconciliation plan of the saved contexts with the kubeconfig of the request,
+// returned along with the kubeconfig. The contexts of the kubeconfig are only parsed, they are not connected to.
+func (h *Handler) reconcileK8sContexts(req *http.Request, token string, provider models.Provider) (*K8sReconcilePlan, []byte, error) {
+	k8sConfigBytes, err := readK8sConfigFromBody(req)
+	if err != nil {
+		return nil, nil, err
+	}
+	kubeconfig := *k8sConfigBytes
+	if flattened, err := helpers.FlattenMinifyKubeConfig(kubeconfig); err == nil {
+		kubeconfig = flattened
+	}
+
+	fileContexts, err := parseK8sContexts(kubeconfig, h.SystemID)
+	if err != nil {
+		return nil, nil, err
+	}
+	saved, err := loadSavedK8sContexts(provider, token)
+	if err != nil {
+		return nil, nil, err
+	}
+	return newK8sReconcilePlan(fileContexts, saved), kubeconfig, nil
+}
+
+// parseK8sContexts returns the contexts of the kubeconfig without connecting to them
+func parseK8sContexts(kubeconfig []byte, instanceID *uuid.UUID) ([]*models.K8sContext, error) {
+	parsed, err := clientcmd.Load(kubeconfig)
+	if err != nil {
+		return nil, ErrInvalidKubeConfig(err, "uploaded kubeconfig")
+	}
+	kcfg := models.InternalKubeConfig{}
+	if err := yaml.Unmarshal(kubeconfig, &kcfg); err != nil {
+		return nil, ErrInvalidKubeConfig(err, "uploaded kubeconfig")
+	}
+
+	contexts := make([]*models.K8sContext, 0, len(parsed.Contexts))
+	for name := range parsed.Contexts {
+		kc, _ := kcfg.K8sContext(name, instanceID)
+		contexts = append(contexts, &kc)
+	}
+	return contexts, nil
+}
+
+// loadSavedK8sContexts pages through all of the saved contexts, irrespective of their status
+func loadSavedK8sContexts(provider models.Provider, token string) ([]*models.K8sContext, error) {
+	return loadK8sContexts(provider, token, "", "")
+}
+
+// loadK8sContexts fetches every page of the saved contexts matching the search, in the given order
+func loadK8sContexts(provider models.Provider, token, search, order string) ([]*models.K8sContext, error) {
+	return models.LoadK8sContextPages(provider, token, search, order, false)
+}
+
+// newK8sReconcilePlan matches the contexts by name and server, the IDs of the contexts
+// differ when the cluster details or credentials of a context change.
+func newK8sReconcilePlan(fileContexts, saved []*models.K8sContext) *K8sReconcilePlan {
+	key := func(ctx *models.K8sContext) string {
+		return ctx.Name + "\x00" + ctx.Server
+	}
+	savedByKey := make(map[string]*models.K8sContext, len(saved))
+	for _, ctx := range saved {
+		savedByKey[key(ctx)] = ctx
+	}
+
+	plan := &K8sReconcilePlan{
+		ToAdd:    []K8sReconcileEntry{},
+		ToRemove: []K8sReconcileEntry{},
+		InBoth:   []K8sReconcileEntry{},
+	}
+	inFile := make(map[string]bool, len(fileContexts))
+	for _, ctx := range fileContexts {
+		inFile[key(ctx)] = true
+		savedCtx, ok := savedByKey[key(ctx)]
+		if !ok {
+			plan.ToAdd = append(plan.ToAdd, newK8sReconcileEntry(ctx))
+			continue
+		}
+		entry := newK8sReconcileEntry(savedCtx)
+		entry.Changed = savedCtx.ID != ctx.ID
+		entry.context = ctx
+		plan.InBoth = append(plan.InBoth, entry)
+	}
+	for _, ctx := range saved {
+		if !inFile[key(ctx)] {
+			plan.ToRemove = append(plan.ToRemove, newK8sReconcileEntry(ctx))
+		}
+	}
+
+	for _, entries := range [][]K8sReconcileEntry{plan.ToAdd, plan.ToRemove, plan.InBoth} {
+		sort.SliceStable(entries, func(i, j int) bool {
+			return entries[i].Name < entries[j].Name
+		})
+	}
+	return plan
+}
+
+func newK8sReconcileEntry(ctx *models.K8sContext) K8sReconcileEntry {
+	return K8sReconcileEntry{
+		Name:         ctx.Name,
+		Server:       ctx.Server,
+		ContextID:    ctx.ID,
+		ConnectionID: ctx.ConnectionID,
+		Managed:      ctx.Managed,
+		context:      ctx,
+	}
+}
+
+// K8sApplyResponse is the outcome of applying a kubeconfig
+//
+// swagger:model K8sApplyResponse
+type K8sApplyResponse struct {
+	// The applied plan
+	Plan *K8sReconcilePlan `json:"plan"`
+	// Whether the saved contexts absent from the kubeconfig were removed
+	Pruned bool `json:"pruned"`
+	// Saved contexts absent from the kubeconfig which were kept as they are not managed
+	Retained []K8sReconcileEntry `json:"retained"`
+	// Outcome of saving the contexts which were added
+	Added SaveK8sContextResponse `json:"added"`
+}
+
+// swagger:route POST /api/system/kubernetes/contexts/apply SystemAPI idPostK8sContextsApply
+// Handle POST request to make the saved connections match a kubeconfig
+//
+// Saves the contexts of the uploaded kubeconfig which are not saved and deletes the saved contexts which are absent
+// from it when "prune=true" is passed, contexts present in both are left alone. Deletions only ever remove
+// managed contexts, contexts added by a human are retained. The added contexts are managed unless "managed=false"
+// is passed. Returns the applied plan.
+// responses:
+//
+//	200: K8sApplyResponse
+//	400:
+//	401:
+//	500:
+func (h *Handler) K8sContextsApplyHandler(w http.ResponseWriter, req *http.Request, prefObj *models.Preference, user *models.User, provider models.Provider) {
+	token, ok := h.userToken(w, req)
+	if !ok {
+		return
+	}
+	userID := uuid.FromStringOrNil(user.ID)
+
+	plan, kubeconfig, err := h.reconcileK8sContexts(req, token, provider)
+	if err != nil {
+		h.log.Error(err)
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	prune, _ := strconv.ParseBool(req.FormValue("prune"))
+	managed := true
+	if v, err := strconv.ParseBool(req.FormValue("managed")); err == nil {
+		managed = v
+	}
+
+	response := K8sApplyResponse{
+		Plan:     plan,
+		Pruned:   prune,
+		Retained: []K8sReconcileEntry{},
+		Added: SaveK8sContextResponse{
+			RegisteredContexts: make([]models.K8sContext, 0),
+			ConnectedContexts:  make([]models.K8sContext, 0),
+			IgnoredContexts:    make([]models.K8sContext, 0),
+			ErroredContexts:    make([]models.K8sContext, 0),
+		},
+	}
+	eventBuilder := events.NewEvent().FromUser(userID).FromSystem(*h.SystemID).WithCategory("connection").WithAction("create").
+		WithDescription(fmt.Sprintf("Kubernetes config applied, %d contexts to add, %d to remove.", len(plan.ToAdd), len(plan.ToRemove))).WithSeverity(events.Informational)
+	eventMetadata := map[string]interface{}{}
+
+	if len(plan.ToAdd) > 0 {
+		names := make(map[string]bool, len(plan.ToAdd))
+		for _, entry := range plan.ToAdd {
+			names[entry.Name] = true
+		}
+		toAdd, err := filterKubeconfigContexts(kubeconfig, names)
+		if err != nil {
+			h.log.Error(err)
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		contexts := models.K8sContextsFromKubeconfig(provider, user.ID, h.config.EventBroadcaster, toAdd, h.SystemID, eventMetadata)
+		for _, ctx := range contexts {
+			ctx.Source = models.K8sContextSourceUpload
+			ctx.Managed = managed
+			eventMetadata[ctx.Name], _, _ = h.saveK8sContext(req, provider, token, userID, ctx, eventBuilder, &response.Added, prefObj.K8sConnectionDefaultState())
+		}
+		h.config.K8scontextChannel.PublishContext()
+	}
+
+	if prune {
+		for _, entry := range plan.ToRemove {
+			if !entry.Managed {
+				response.Retained = append(response.Retained, entry)
+				continue
+			}
+			id := entry.ConnectionID
+			if id == "" {
+				id = entry.ContextID
+			}
+			h.deleteK8sContext(req.Context(), provider, token, userID, id)
+		}
+	}
+
+	event := eventBuilder.WithMetadata(eventMetadata).Build()
+	h.persistEvent(provider, event)
+	go h.config.EventBroadcaster.Publish(userID, event)
+
+	response.Added.sort()
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		h.log.Error(models.ErrMarshal(err, "kubeconfig apply"))
+		http.Error(w, models.ErrMarshal(err, "kubeconfig apply").Error(), http.StatusInternalServerError)
+	}
+}
+
+// filterKubeconfigContexts drops the contexts of the kubeconfig not in names, so that only those are connected to
+func filterKubeconfigContexts(kubeconfig []byte, names map[string]bool) ([]byte, error) {
+	cfg, err := clientcmd.Load(kubeconfig)
+	if err != nil {
+		return nil, ErrInvalidKubeConfig(err, "uploaded kubeconfig")
+	}
+	for name := range cfg.Contexts {
+		if !names[name] {
+			delete(cfg.Contexts, name)
+		}
+	}
+	if !names[cfg.CurrentContext] {
+		cfg.CurrentContext = ""
+	}
+	return clientcmd.Write(*cfg)
+}
+
+// currentContextOfKubeconfig drops all but the current-context of the kubeconfig
+func currentContextOfKubeconfig(kubeconfig []byte) ([]byte, error) {
+	cfg, err := clientcmd.Load(kubeconfig)
+	if err != nil {
+		return nil, ErrInvalidKubeConfig(err, "uploaded kubeconfig")
+	}
+	if _, ok := cfg.Contexts[cfg.CurrentContext]; !ok {
+		return nil, ErrInvalidKubeConfig(fmt.Errorf("current-context %q not found", cfg.CurrentContext), "uploaded kubeconfig")
+	}
+	return filterKubeconfigContexts(kubeconfig, map[string]bool{cfg.CurrentContext: true})
+}
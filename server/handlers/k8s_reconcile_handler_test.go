@@ -0,0 +1,87 @@
+package handlers
+
+import (
+	"testing"
+
+	"github.com/layer5io/meshery/server/models"
+	"k8s.io/client-go/tools/clientcmd"
+)
+
+func TestNewK8sReconcilePlan(t *testing.T) {
+	fileContexts := []*models.K8sContext{
+		{ID: "1", Name: "dev", Server: "https://dev:6443"},
+		{ID: "2-rotated", Name: "prod", Server: "https://prod:6443"},
+		{ID: "4", Name: "new", Server: "https://new:6443"},
+	}
+	saved := []*models.K8sContext{
+		{ID: "1", Name: "dev", Server: "https://dev:6443", ConnectionID: "c1"},
+		{ID: "2", Name: "prod", Server: "https://prod:6443", ConnectionID: "c2"},
+		{ID: "3", Name: "old", Server: "https://old:6443", ConnectionID: "c3", Managed: true},
+	}
+
+	plan := newK8sReconcilePlan(fileContexts, saved)
+
+	if len(plan.ToAdd) != 1 || plan.ToAdd[0].Name != "new" {
+		t.Errorf("to_add = %+v, want [new]", plan.ToAdd)
+	}
+	if len(plan.ToRemove) != 1 || plan.ToRemove[0].ConnectionID != "c3" || !plan.ToRemove[0].Managed {
+		t.Errorf("to_remove = %+v, want [managed old]", plan.ToRemove)
+	}
+	if len(plan.InBoth) != 2 {
+		t.Fatalf("in_both = %+v, want [dev prod]", plan.InBoth)
+	}
+	if plan.InBoth[0].Name != "dev" || plan.InBoth[0].Changed {
+		t.Errorf("in_both[0] = %+v, want unchanged dev", plan.InBoth[0])
+	}
+	if plan.InBoth[1].Name != "prod" || !plan.InBoth[1].Changed || plan.InBoth[1].ConnectionID != "c2" {
+		t.Errorf("in_both[1] = %+v, want changed prod of connection c2", plan.InBoth[1])
+	}
+}
+
+func TestCurrentContextOfKubeconfig(t *testing.T) {
+	kubeconfig := []byte(`apiVersion: v1
+kind: Config
+clusters:
+- name: dev
+  cluster:
+    server: https://dev:6443
+- name: prod
+  cluster:
+    server: https://prod:6443
+contexts:
+- name: dev
+  context:
+    cluster: dev
+    user: admin
+- name: prod
+  context:
+    cluster: prod
+    user: admin
+users:
+- name: admin
+  user:
+    token: abc
+current-context: prod
+`)
+
+	current, err := currentContextOfKubeconfig(kubeconfig)
+	if err != nil {
+		t.Fatalf("currentContextOfKubeconfig() failed with error: %s", err)
+	}
+	cfg, err := clientcmd.Load(current)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(cfg.Contexts) != 1 || cfg.Contexts["prod"] == nil || cfg.CurrentContext != "prod" {
+		t.Errorf("contexts = %v (current-context %q), want only prod", cfg.Contexts, cfg.CurrentContext)
+	}
+
+	cfg.CurrentContext = ""
+	withoutCurrent, err := clientcmd.Write(*cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := currentContextOfKubeconfig(withoutCurrent); err == nil {
+		t.Error("currentContextOfKubeconfig() expected an error for a kubeconfig without current-context")
+	}
+}
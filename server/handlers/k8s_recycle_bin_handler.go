@@ -0,0 +1,183 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gofrs/uuid"
+	"github.com/gorilla/mux"
+	"github.com/layer5io/meshery/server/machines"
+	mhelpers "github.com/layer5io/meshery/server/machines/helpers"
+	"github.com/layer5io/meshery/server/machines/kubernetes"
+	"github.com/layer5io/meshery/server/models"
+	"github.com/layer5io/meshery/server/models/connections"
+	"github.com/layer5io/meshkit/models/events"
+)
+
+// DeletedK8sContext is a context in the recycle bin
+type DeletedK8sContext struct {
+	models.K8sContext
+	// PurgeAt is when the context is deleted permanently, omitted when the deleted contexts are never purged
+	PurgeAt *time.Time `json:"purge_at,omitempty"`
+}
+
+// recycleK8sContext moves the context, whose connection was just deleted, to the recycle bin or deletes it permanently when hard
+func (h *Handler) recycleK8sContext(provider models.Provider, token, id string, hard bool) {
+	recycleBin := h.config.K8sContextRecycleBin
+	if hard {
+		if _, err := provider.DeleteK8sContext(token, id); err != nil {
+			h.log.Error(ErrFailToDelete(err, "kubernetes context"))
+		}
+		if recycleBin != nil {
+			recycleBin.Remove(id)
+		}
+		return
+	}
+
+	deletedAt := time.Now().UTC()
+	if _, err := provider.SetK8sContextDeletedAt(token, id, &deletedAt); err != nil {
+		h.log.Error(ErrFailToSave(err, "kubernetes context"))
+		return
+	}
+	if recycleBin != nil {
+		recycleBin.Add(provider, token, id, deletedAt)
+	}
+}
+
+// swagger:route GET /api/system/kubernetes/contexts/deleted SystemAPI idGetDeletedK8sContexts
+// Handle GET request for the deleted Kubernetes contexts
+//
+// Lists the contexts in the recycle bin, which are restored with POST /api/system/kubernetes/contexts/{id}/restore
+// until they are deleted permanently once "CONNECTION_DELETION_GRACE_PERIOD" is over.
+// The contexts whose grace period is over are deleted permanently as they are listed.
+// responses:
+//
+//	200: []DeletedK8sContext
+//	401:
+//	500:
+func (h *Handler) DeletedK8sContextsHandler(w http.ResponseWriter, req *http.Request, _ *models.Preference, _ *models.User, provider models.Provider) {
+	token, ok := h.userToken(w, req)
+	if !ok {
+		return
+	}
+	contexts, err := provider.GetDeletedK8sContexts(token)
+	if err != nil {
+		err = models.ErrLoadK8sContexts(err)
+		h.log.Error(err)
+		http.Error(w, "failed to get the deleted contexts", http.StatusInternalServerError)
+		return
+	}
+
+	recycleBin := h.config.K8sContextRecycleBin
+	deleted := make([]DeletedK8sContext, 0, len(contexts))
+	for _, ctx := range contexts {
+		id := ctx.ConnectionID
+		if id == "" {
+			id = ctx.ID
+		}
+		if recycleBin != nil && recycleBin.Purgeable(*ctx) {
+			h.recycleK8sContext(provider, token, id, true)
+			continue
+		}
+		deletedContext := DeletedK8sContext{K8sContext: redactK8sContext(*ctx)}
+		if recycleBin != nil {
+			deletedContext.PurgeAt = recycleBin.PurgeAt(*ctx)
+		}
+		deleted = append(deleted, deletedContext)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(deleted); err != nil {
+		h.log.Error(models.ErrMarshal(err, "deleted kubernetes contexts"))
+		http.Error(w, models.ErrMarshal(err, "deleted kubernetes contexts").Error(), http.StatusInternalServerError)
+	}
+}
+
+// swagger:route POST /api/system/kubernetes/contexts/{id}/restore SystemAPI idPostRestoreK8sContext
+// Handle POST request to restore a deleted Kubernetes context
+//
+// Takes the context out of the recycle bin and connects it again, the same way as it is reconnected.
+// The id is the connection ID of the context with remote providers.
+// responses:
+//
+//	200: K8sReconnectResponse
+//	400:
+//	401:
+//	500:
+func (h *Handler) RestoreK8sContextHandler(w http.ResponseWriter, req *http.Request, _ *models.Preference, user *models.User, provider models.Provider) {
+	token, ok := h.userToken(w, req)
+	if !ok {
+		return
+	}
+	id := mux.Vars(req)["id"]
+	userID := uuid.FromStringOrNil(user.ID)
+
+	k8sContext, err := provider.GetK8sContext(token, id)
+	if err != nil {
+		http.Error(w, "failed to get kubernetes context for the given ID", http.StatusInternalServerError)
+		return
+	}
+	if k8sContext.DeletedAt == nil {
+		http.Error(w, fmt.Sprintf("kubernetes context %s is not deleted", k8sContext.Name), http.StatusBadRequest)
+		return
+	}
+	k8sContext, err = provider.SetK8sContextDeletedAt(token, id, nil)
+	if err != nil {
+		_err := ErrFailToSave(err, "kubernetes context")
+		h.log.Error(_err)
+		http.Error(w, _err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if h.config.K8sContextRecycleBin != nil {
+		h.config.K8sContextRecycleBin.Remove(id)
+	}
+
+	// The machine of the connection was left in the deleted state, the new machine re-runs the complete flow.
+	connectionUUID := uuid.FromStringOrNil(id)
+	smInstanceTracker := h.ConnectionToStateMachineInstanceTracker
+	smInstanceTracker.Remove(connectionUUID)
+	res := K8sReconnectResponse{ConnectionID: id}
+	inst, err := mhelpers.InitializeMachineWithContext(
+		h.newK8sMachineCtx(k8sContext),
+		req.Context(),
+		connectionUUID,
+		userID,
+		smInstanceTracker,
+		h.log,
+		provider,
+		machines.InitialState,
+		"kubernetes",
+		kubernetes.AssignInitialCtx,
+	)
+	if err == nil {
+		_, err = inst.SendEvent(req.Context(), machines.Discovery, nil)
+		res.Status = connections.ConnectionStatus(inst.CurrentState)
+	}
+
+	eventBuilder := events.NewEvent().ActedUpon(connectionUUID).FromUser(userID).FromSystem(*h.SystemID).WithCategory("connection").WithAction("restore")
+	if err != nil || res.Status != connections.CONNECTED {
+		if err == nil {
+			err = fmt.Errorf("connection ended in \"%s\" state", res.Status)
+		}
+		err = ErrRestoreK8sContext(err, k8sContext.Name)
+		h.log.Error(err)
+		res.Error = err.Error()
+		eventBuilder.WithSeverity(events.Warning).WithDescription(fmt.Sprintf("Kubernetes context \"%s\" restored but not connected", k8sContext.Name)).WithMetadata(map[string]interface{}{
+			"error": err,
+		})
+	} else {
+		eventBuilder.WithSeverity(events.Success).WithDescription(fmt.Sprintf("Kubernetes context \"%s\" restored", k8sContext.Name))
+	}
+	event := eventBuilder.Build()
+	h.persistEvent(provider, event)
+	go h.config.EventBroadcaster.Publish(userID, event)
+	h.config.K8scontextChannel.PublishContext()
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(res); err != nil {
+		h.log.Error(models.ErrMarshal(err, "restore response"))
+		http.Error(w, models.ErrMarshal(err, "restore response").Error(), http.StatusInternalServerError)
+	}
+}
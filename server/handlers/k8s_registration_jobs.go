@@ -0,0 +1,204 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/gofrs/uuid"
+	"github.com/gorilla/mux"
+	"github.com/layer5io/meshery/server/models"
+	"github.com/layer5io/meshkit/models/events"
+)
+
+// k8sRegistrationJobRetention is how long a finished registration job is kept around for its status to be fetched
+const k8sRegistrationJobRetention = time.Hour
+
+const (
+	k8sRegistrationJobRunning   = "running"
+	k8sRegistrationJobCompleted = "completed"
+	k8sRegistrationJobCancelled = "cancelled"
+)
+
+// K8sRegistrationJobStatus is the status of a registration of Kubernetes components running in the background
+//
+// swagger:model K8sRegistrationJobStatus
+type K8sRegistrationJobStatus struct {
+	JobID    string   `json:"job_id"`
+	Status   string   `json:"status"`
+	Contexts []string `json:"contexts"`
+	// Results of the contexts whose registration has finished
+	Results   []models.K8sRegistrationResult `json:"results"`
+	CreatedAt time.Time                      `json:"created_at"`
+}
+
+type k8sRegistrationJob struct {
+	id        uuid.UUID
+	userID    string
+	contexts  []string
+	createdAt time.Time
+	results   *models.K8sRegistrationResults
+
+	mx        sync.Mutex
+	cancelled bool
+}
+
+func (job *k8sRegistrationJob) status() K8sRegistrationJobStatus {
+	job.mx.Lock()
+	cancelled := job.cancelled
+	job.mx.Unlock()
+
+	status := k8sRegistrationJobRunning
+	if cancelled {
+		status = k8sRegistrationJobCancelled
+	} else if job.results.Finished() {
+		status = k8sRegistrationJobCompleted
+	}
+	return K8sRegistrationJobStatus{
+		JobID:     job.id.String(),
+		Status:    status,
+		Contexts:  job.contexts,
+		Results:   job.results.Results(),
+		CreatedAt: job.createdAt,
+	}
+}
+
+// K8sRegistrationAccepted is the response to a registration of Kubernetes components accepted to run in the background
+//
+// swagger:model K8sRegistrationAccepted
+type K8sRegistrationAccepted struct {
+	Contexts []K8sRegistrationAcceptedContext `json:"contexts"`
+	// Errored are the connections of the environment whose context could not be loaded, they are not registered
+	Errored []models.K8sRegistrationResult `json:"errored,omitempty"`
+	// JobID and StatusURL are omitted when the job of the registration could not be tracked
+	JobID string `json:"job_id,omitempty"`
+	// StatusURL is polled with GET for the status and the results of the registration, and cancels it with DELETE
+	StatusURL string `json:"status_url,omitempty"`
+}
+
+// K8sRegistrationAcceptedContext is a context whose components are being registered
+type K8sRegistrationAcceptedContext struct {
+	ID     string `json:"id"`
+	Name   string `json:"name"`
+	Server string `json:"server"`
+}
+
+// k8sRegistrationJobURL returns the URL of the job of a registration
+func k8sRegistrationJobURL(id uuid.UUID) string {
+	return "/api/system/kubernetes/jobs/" + id.String()
+}
+
+// k8sRegistrationJobs tracks the registrations of Kubernetes components so that they can be cancelled
+var k8sRegistrationJobs = &registrationJobs{jobs: make(map[uuid.UUID]*k8sRegistrationJob)}
+
+type registrationJobs struct {
+	mx   sync.Mutex
+	jobs map[uuid.UUID]*k8sRegistrationJob
+}
+
+// track registers the job of the registration, which is forgotten k8sRegistrationJobRetention after it finishes
+func (rj *registrationJobs) track(userID string, contexts []*models.K8sContext, results *models.K8sRegistrationResults) (*k8sRegistrationJob, error) {
+	id, err := uuid.NewV4()
+	if err != nil {
+		return nil, models.ErrGenerateUUID(err)
+	}
+	job := &k8sRegistrationJob{
+		id:        id,
+		userID:    userID,
+		contexts:  make([]string, 0, len(contexts)),
+		createdAt: time.Now(),
+		results:   results,
+	}
+	for _, ctx := range contexts {
+		job.contexts = append(job.contexts, ctx.Name)
+	}
+
+	rj.mx.Lock()
+	rj.jobs[id] = job
+	rj.mx.Unlock()
+
+	go func() {
+		results.Wait()
+		time.Sleep(k8sRegistrationJobRetention)
+		rj.mx.Lock()
+		delete(rj.jobs, id)
+		rj.mx.Unlock()
+	}()
+	return job, nil
+}
+
+// get returns the job of the user
+func (rj *registrationJobs) get(userID string, id uuid.UUID) (*k8sRegistrationJob, bool) {
+	rj.mx.Lock()
+	defer rj.mx.Unlock()
+	job, ok := rj.jobs[id]
+	if !ok || job.userID != userID {
+		return nil, false
+	}
+	return job, true
+}
+
+// swagger:route GET /api/system/kubernetes/jobs/{job_id} SystemAPI idGetK8sRegistrationJob
+// Handle GET request for the status of a registration of Kubernetes components
+//
+// Returns the status of the registration along with the results of the contexts finished so far
+// responses:
+//
+//	200: K8sRegistrationJobStatus
+//	404:
+func (h *Handler) K8sRegistrationJobStatusHandler(w http.ResponseWriter, req *http.Request, _ *models.Preference, user *models.User, _ models.Provider) {
+	job, ok := k8sRegistrationJobs.get(user.ID, uuid.FromStringOrNil(mux.Vars(req)["job_id"]))
+	if !ok {
+		http.Error(w, fmt.Sprintf("registration job %s not found", mux.Vars(req)["job_id"]), http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(job.status()); err != nil {
+		h.log.Error(models.ErrMarshal(err, "registration job"))
+		http.Error(w, models.ErrMarshal(err, "registration job").Error(), http.StatusInternalServerError)
+	}
+}
+
+// swagger:route DELETE /api/system/kubernetes/jobs/{job_id} SystemAPI idDeleteK8sRegistrationJob
+// Handle DELETE request to cancel a registration of Kubernetes components in progress
+//
+// Stops registering further components, the components registered until then are retained.
+// The registration may be started again for the cancelled contexts.
+// responses:
+//
+//	200: K8sRegistrationJobStatus
+//	404:
+//	409:
+func (h *Handler) K8sRegistrationJobCancelHandler(w http.ResponseWriter, req *http.Request, _ *models.Preference, user *models.User, provider models.Provider) {
+	jobID := uuid.FromStringOrNil(mux.Vars(req)["job_id"])
+	job, ok := k8sRegistrationJobs.get(user.ID, jobID)
+	if !ok {
+		http.Error(w, fmt.Sprintf("registration job %s not found", mux.Vars(req)["job_id"]), http.StatusNotFound)
+		return
+	}
+	if job.results.Finished() {
+		http.Error(w, fmt.Sprintf("registration job %s has already finished", jobID), http.StatusConflict)
+		return
+	}
+
+	job.mx.Lock()
+	job.cancelled = true
+	job.mx.Unlock()
+	job.results.Cancel()
+
+	userID := uuid.FromStringOrNil(user.ID)
+	event := events.NewEvent().ActedUpon(jobID).FromUser(userID).FromSystem(*h.SystemID).WithCategory("kubernetes_components").WithAction("cancel").
+		WithSeverity(events.Informational).WithDescription(fmt.Sprintf("Registration of the components of %d Kubernetes contexts cancelled", len(job.contexts))).
+		WithMetadata(map[string]interface{}{"contexts": job.contexts}).Build()
+	h.persistEvent(provider, event)
+	go h.config.EventBroadcaster.Publish(userID, event)
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(job.status()); err != nil {
+		h.log.Error(models.ErrMarshal(err, "registration job"))
+		http.Error(w, models.ErrMarshal(err, "registration job").Error(), http.StatusInternalServerError)
+	}
+}
@@ -0,0 +1,166 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/gofrs/uuid"
+	"github.com/layer5io/meshery/server/models"
+	"github.com/spf13/viper"
+)
+
+// k8sConnectionNotTracked is the state reported for the saved connections without a state machine in this runtime of the server
+const k8sConnectionNotTracked = "not_tracked"
+
+// K8sClusterStats are the statistics of a Kubernetes connection
+type K8sClusterStats struct {
+	ContextID    string `json:"context_id"`
+	Name         string `json:"name"`
+	ConnectionID string `json:"connection_id,omitempty"`
+	State        string `json:"state"`
+	Components   int64  `json:"components"`
+	// Latency of the last ping of the connection, in milliseconds
+	PingLatencyMs float64    `json:"ping_latency_ms,omitempty"`
+	LastError     string     `json:"last_error,omitempty"`
+	LastErrorAt   *time.Time `json:"last_error_at,omitempty"`
+}
+
+// K8sStats aggregates the onboarding statistics of the Kubernetes connections
+//
+// swagger:model K8sStats
+type K8sStats struct {
+	Connections        int            `json:"connections"`
+	ConnectionsByState map[string]int `json:"connections_by_state"`
+	ComponentsTotal    int64          `json:"components_total"`
+	// Average of the latency of the last ping of the connections which were pinged, in milliseconds
+	AveragePingLatencyMs float64 `json:"average_ping_latency_ms"`
+	// Connections whose last transition failed, with their last error
+	Errored     []K8sClusterStats `json:"errored"`
+	Clusters    []K8sClusterStats `json:"clusters"`
+	GeneratedAt time.Time         `json:"generated_at"`
+}
+
+// swagger:route GET /api/system/kubernetes/stats SystemAPI idGetK8sStats
+// Handle GET request for the onboarding statistics of the Kubernetes connections
+//
+// Returns the number of connections by state, the number of registered components in total and per cluster,
+// the average ping latency and the errored connections with their last error.
+// The statistics are cached per user for "KUBERNETES_STATS_CACHE_TTL", pass "refresh=true" to bypass the cache.
+// responses:
+//
+//	200: K8sStats
+//	401:
+//	500:
+func (h *Handler) K8sStatsHandler(w http.ResponseWriter, req *http.Request, _ *models.Preference, user *models.User, provider models.Provider) {
+	token, ok := h.userToken(w, req)
+	if !ok {
+		return
+	}
+
+	stats, ok := k8sStatsCache.get(user.ID)
+	if !ok || req.URL.Query().Get("refresh") == "true" {
+		var err error
+		stats, err = h.k8sStats(provider, token)
+		if err != nil {
+			h.log.Error(err)
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		k8sStatsCache.set(user.ID, stats, viper.GetDuration("KUBERNETES_STATS_CACHE_TTL"))
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(stats); err != nil {
+		h.log.Error(models.ErrMarshal(err, "kubernetes stats"))
+		http.Error(w, models.ErrMarshal(err, "kubernetes stats").Error(), http.StatusInternalServerError)
+	}
+}
+
+// k8sStats computes the statistics of the saved connections of the user
+func (h *Handler) k8sStats(provider models.Provider, token string) (*K8sStats, error) {
+	contexts, err := loadSavedK8sContexts(provider, token)
+	if err != nil {
+		return nil, err
+	}
+
+	stats := &K8sStats{
+		Connections:        len(contexts),
+		ConnectionsByState: map[string]int{},
+		Errored:            []K8sClusterStats{},
+		Clusters:           make([]K8sClusterStats, 0, len(contexts)),
+		GeneratedAt:        time.Now(),
+	}
+	var latencyTotal time.Duration
+	pinged := 0
+	for _, ctx := range contexts {
+		cluster := K8sClusterStats{
+			ContextID:    ctx.ID,
+			Name:         ctx.Name,
+			ConnectionID: ctx.ConnectionID,
+			State:        k8sConnectionNotTracked,
+		}
+		if inst, ok := h.ConnectionToStateMachineInstanceTracker.Get(uuid.FromStringOrNil(ctx.ConnectionID)); ok {
+			cluster.State = string(inst.State())
+			if lastError, at := inst.LastError(); lastError != "" {
+				cluster.LastError, cluster.LastErrorAt = lastError, &at
+			}
+		}
+
+		count, err := models.CountK8sContextComponents(h.dbHandler, ctx.ID)
+		if err != nil {
+			h.log.Warn(ErrGetMeshModels(err))
+		}
+		cluster.Components = count
+		stats.ComponentsTotal += count
+
+		if latency, ok := k8sPingLatencies.get(ctx.ConnectionID); ok {
+			cluster.PingLatencyMs = float64(latency) / float64(time.Millisecond)
+			latencyTotal += latency
+			pinged++
+		}
+
+		stats.ConnectionsByState[cluster.State]++
+		if cluster.LastError != "" {
+			stats.Errored = append(stats.Errored, cluster)
+		}
+		stats.Clusters = append(stats.Clusters, cluster)
+	}
+	if pinged > 0 {
+		stats.AveragePingLatencyMs = float64(latencyTotal) / float64(pinged) / float64(time.Millisecond)
+	}
+
+	sort.SliceStable(stats.Clusters, func(i, j int) bool {
+		return stats.Clusters[i].Name < stats.Clusters[j].Name
+	})
+	sort.SliceStable(stats.Errored, func(i, j int) bool {
+		return stats.Errored[i].Name < stats.Errored[j].Name
+	})
+	return stats, nil
+}
+
+// k8sPingLatencies records the latency of the last ping of each connection
+var k8sPingLatencies = &pingLatencies{latencies: make(map[string]time.Duration)}
+
+type pingLatencies struct {
+	mx        sync.RWMutex
+	latencies map[string]time.Duration
+}
+
+func (pl *pingLatencies) record(connectionID string, latency time.Duration) {
+	pl.mx.Lock()
+	defer pl.mx.Unlock()
+	pl.latencies[connectionID] = latency
+}
+
+func (pl *pingLatencies) get(connectionID string) (time.Duration, bool) {
+	pl.mx.RLock()
+	defer pl.mx.RUnlock()
+	latency, ok := pl.latencies[connectionID]
+	return latency, ok
+}
+
+// k8sStatsCache caches the statistics per user
+var k8sStatsCache = newTTLCache[*K8sStats]()
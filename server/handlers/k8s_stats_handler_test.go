@@ -0,0 +1,24 @@
+package handlers
+
+import (
+	"testing"
+	"time"
+)
+
+func TestK8sStatsCache(t *testing.T) {
+	cache := newTTLCache[*K8sStats]()
+	stats := &K8sStats{Connections: 2}
+
+	cache.set("user", stats, time.Minute)
+	if got, ok := cache.get("user"); !ok || got != stats {
+		t.Errorf("get() = %v, %t, want the cached stats", got, ok)
+	}
+	if _, ok := cache.get("other"); ok {
+		t.Error("expected no stats for another user")
+	}
+
+	cache.set("user", stats, -time.Second)
+	if _, ok := cache.get("user"); ok {
+		t.Error("expected expired stats not to be returned")
+	}
+}
@@ -0,0 +1,134 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/layer5io/meshery/server/helpers"
+	"github.com/layer5io/meshery/server/models"
+	meshkube "github.com/layer5io/meshkit/utils/kubernetes"
+	"github.com/spf13/viper"
+)
+
+// K8sConfigTestResult is the outcome of testing the credentials of a context of a kubeconfig
+//
+// swagger:model K8sConfigTestResult
+type K8sConfigTestResult struct {
+	Context   string `json:"context"`
+	Server    string `json:"server"`
+	Reachable bool   `json:"reachable"`
+	Version   string `json:"version,omitempty"`
+	Error     string `json:"error,omitempty"`
+	// Timings of the phases of the request to the API server
+	Timings *models.K8sPhaseTimings `json:"timings,omitempty"`
+	// Notable features of the cluster, e.g. whether it has a default StorageClass
+	Capabilities map[string]bool `json:"capabilities,omitempty"`
+	// Skew between the clocks of Meshery and of the API server, certificates and tokens fail validation beyond its threshold
+	ClockSkew *models.ClockSkew `json:"clock_skew,omitempty"`
+}
+
+// swagger:route POST /api/system/kubernetes/kubeconfig/test SystemAPI idPostK8sConfigTest
+// Handle POST request to test the credentials of a context of a kubeconfig
+//
+// Connects to the context named by "context" of the uploaded kubeconfig and fetches the version of its API server,
+// giving up after "probe_timeout" (defaults to "KUBERNETES_PROBE_TIMEOUT"). Nothing is persisted.
+// The "dial_timeout", "tls_handshake_timeout" and "response_header_timeout" of the transport may be overridden,
+// as well as the "tls_server_name" the certificate of the API server is verified against,
+// the result carries the timings of the phases of the request, the capabilities detected in the cluster and the clock skew
+// between Meshery and the API server, flagged beyond "KUBERNETES_CLOCK_SKEW_THRESHOLD".
+// responses:
+//
+//	200: K8sConfigTestResult
+//	400:
+//	500:
+func (h *Handler) K8sConfigTestHandler(w http.ResponseWriter, req *http.Request, _ *models.Preference, _ *models.User, _ models.Provider) {
+	k8sConfigBytes, err := readK8sConfigFromBody(req)
+	if err != nil {
+		h.log.Error(err)
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	kubeconfig := *k8sConfigBytes
+	if flattened, err := helpers.FlattenMinifyKubeConfig(kubeconfig); err == nil {
+		kubeconfig = flattened
+	}
+
+	name := req.FormValue("context")
+	if name == "" {
+		http.Error(w, "Empty context name. Pass the name of the context to test in the form field \"context\"", http.StatusBadRequest)
+		return
+	}
+	contexts, err := parseK8sContexts(kubeconfig, h.SystemID)
+	if err != nil {
+		h.log.Error(err)
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	var k8sContext *models.K8sContext
+	for _, ctx := range contexts {
+		if ctx.Name == name {
+			k8sContext = ctx
+			break
+		}
+	}
+	if k8sContext == nil {
+		err := ErrInvalidKubeConfig(fmt.Errorf("context %q not found", name), "uploaded kubeconfig")
+		h.log.Error(err)
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	timeout := viper.GetDuration("KUBERNETES_PROBE_TIMEOUT")
+	if v, err := time.ParseDuration(req.FormValue("probe_timeout")); err == nil && v > 0 {
+		timeout = v
+	}
+
+	result := K8sConfigTestResult{
+		Context: k8sContext.Name,
+		Server:  k8sContext.Server,
+	}
+	// Transport timeouts of the test default to the "KUBERNETES_*_TIMEOUT" ones and can be overridden per request.
+	k8sContext.DialTimeout = req.FormValue("dial_timeout")
+	k8sContext.TLSHandshakeTimeout = req.FormValue("tls_handshake_timeout")
+	k8sContext.ResponseHeaderTimeout = req.FormValue("response_header_timeout")
+	k8sContext.TLSServerName = strings.TrimSpace(req.FormValue("tls_server_name"))
+	kubeclient, err := k8sContext.GenerateKubeHandler()
+	if err != nil {
+		result.Error = err.Error()
+	} else {
+		info, timings, err := k8sContext.ServerVersionWithTimings(req.Context(), kubeclient, timeout)
+		result.Timings = timings
+		if err != nil {
+			result.Error = err.Error()
+		} else {
+			result.Reachable = true
+			result.Version = info.String()
+			result.Capabilities = models.DetectK8sCapabilities(req.Context(), kubeclient)
+			result.ClockSkew = h.checkK8sClockSkew(req.Context(), k8sContext, kubeclient)
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(result); err != nil {
+		h.log.Error(models.ErrMarshal(err, "kubeconfig test result"))
+		http.Error(w, models.ErrMarshal(err, "kubeconfig test result").Error(), http.StatusInternalServerError)
+	}
+}
+
+// checkK8sClockSkew diagnoses the clock skew between Meshery and the API server of the context, nil when it cannot be checked.
+// Clock skew surfaces as certificate or token validation failures, hence it is diagnosed separately.
+func (h *Handler) checkK8sClockSkew(ctx context.Context, k8sContext *models.K8sContext, kubeclient *meshkube.Client) *models.ClockSkew {
+	clockSkew, err := models.CheckClockSkew(ctx, &kubeclient.RestConfig, viper.GetDuration("KUBERNETES_CLOCK_SKEW_THRESHOLD"))
+	if err != nil {
+		h.log.Warn(models.ErrCheckClockSkew(err, k8sContext.Server))
+		return nil
+	}
+	if clockSkew.Skewed {
+		h.log.Warn(models.ErrClockSkew(time.Duration(clockSkew.SkewSeconds)*time.Second, k8sContext.Server))
+	}
+	return clockSkew
+}
@@ -2,11 +2,22 @@
 package handlers
 
 import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/base64"
 	"encoding/json"
 	"fmt"
 	"io"
 	"net/http"
+	"os"
 	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"text/template"
+	"time"
 
 	"github.com/layer5io/meshery/server/machines"
 	mhelpers "github.com/layer5io/meshery/server/machines/helpers"
@@ -19,15 +30,20 @@ import (
 	"github.com/gofrs/uuid"
 	"github.com/layer5io/meshery/server/helpers"
 	"github.com/layer5io/meshery/server/models"
-	"github.com/layer5io/meshery/server/models/pattern/core"
 	_ "k8s.io/client-go/plugin/pkg/client/auth/gcp"
 
+	"github.com/layer5io/meshkit/logger"
 	"github.com/layer5io/meshkit/models/events"
 
 	"github.com/layer5io/meshkit/utils"
+	meshkube "github.com/layer5io/meshkit/utils/kubernetes"
 	"github.com/pkg/errors"
 	"github.com/sirupsen/logrus"
 	"github.com/spf13/viper"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/tools/clientcmd"
+	"k8s.io/client-go/util/retry"
 )
 
 // SaveK8sContextResponse - struct used as (json marshaled) response to requests for saving k8s contexts
@@ -36,45 +52,146 @@ type SaveK8sContextResponse struct {
 	ConnectedContexts  []models.K8sContext `json:"connected_contexts"`
 	IgnoredContexts    []models.K8sContext `json:"ignored_contexts"`
 	ErroredContexts    []models.K8sContext `json:"errored_contexts"`
+	// Whether the external file references of the kubeconfig were inlined, i.e. the stored contexts are self-contained.
+	KubeconfigFlattened bool `json:"kubeconfig_flattened"`
+	// Original names of the contexts renamed for clashing with another context of the kubeconfig, keyed by their new name.
+	RenamedContexts map[string]string `json:"renamed_contexts,omitempty"`
+	// Contexts of the same cluster and user collapsed into a single connection, when merged by cluster.
+	MergedContexts []models.K8sContextMerge `json:"merged_contexts,omitempty"`
+	// Original names of the contexts named by the naming template, keyed by their derived name.
+	NamedContexts map[string]string `json:"named_contexts,omitempty"`
+	// Failure of the current-context of the kubeconfig, which is also reported among the other contexts when it could be discovered.
+	CurrentContextFailure *K8sCurrentContextFailure `json:"current_context_failure,omitempty"`
+}
+
+// k8sContextSaveErrored is the status of a context which could not be saved
+const k8sContextSaveErrored = "errored"
+
+// K8sConfigAtomicUploadFailure - struct used as (json marshaled) response to an atomic upload of which a context failed,
+// the connections created by the upload until then are deleted.
+type K8sConfigAtomicUploadFailure struct {
+	FailedContext string `json:"failed_context"`
+	Server        string `json:"server,omitempty"`
+	Description   string `json:"description,omitempty"`
+	Error         string `json:"error,omitempty"`
+	// IDs of the connections created by the upload before the context failed, which were deleted
+	RolledBackConnections []string `json:"rolled_back_connections"`
+}
+
+// K8sContextSaveProgress is a line of the streamed response of a kubeconfig upload, written as each of the contexts is saved
+type K8sContextSaveProgress struct {
+	Context models.K8sContext `json:"context"`
+	// Status of the connection of the context, or "errored" if it could not be saved
+	Status      string `json:"status"`
+	Description string `json:"description,omitempty"`
+	Error       string `json:"error,omitempty"`
+}
+
+// k8sContextSaveStream writes the outcome of the upload as newline-delimited JSON, flushing each line
+// so that it is sent right away as a chunk. The last line holds the aggregated SaveK8sContextResponse under "summary".
+type k8sContextSaveStream struct {
+	w   http.ResponseWriter
+	enc *json.Encoder
+}
+
+func newK8sContextSaveStream(w http.ResponseWriter) *k8sContextSaveStream {
+	w.Header().Set("Content-Type", "application/x-ndjson")
+	w.WriteHeader(http.StatusOK)
+	return &k8sContextSaveStream{w: w, enc: json.NewEncoder(w)}
+}
+
+func (s *k8sContextSaveStream) progress(ctx *models.K8sContext, status string, metadata map[string]interface{}) {
+	line := K8sContextSaveProgress{Context: *ctx, Status: status}
+	line.Description, _ = metadata["description"].(string)
+	if err, ok := metadata["error"].(error); ok {
+		line.Error = err.Error()
+	}
+	s.send(line)
+}
+
+func (s *k8sContextSaveStream) summary(response SaveK8sContextResponse) {
+	s.send(map[string]interface{}{"summary": response})
+}
+
+func (s *k8sContextSaveStream) send(v interface{}) {
+	if err := s.enc.Encode(v); err != nil {
+		logrus.Error(models.ErrMarshal(err, "kubeconfig"))
+		return
+	}
+	if f, ok := s.w.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// sort orders each of the slices by context name, as the contexts are discovered in the
+// non-deterministic iteration order of the kubeconfig.
+func (r *SaveK8sContextResponse) sort() {
+	for _, contexts := range [][]models.K8sContext{r.RegisteredContexts, r.ConnectedContexts, r.IgnoredContexts, r.ErroredContexts} {
+		sort.SliceStable(contexts, func(i, j int) bool {
+			return contexts[i].Name < contexts[j].Name
+		})
+	}
 }
 
 // K8SConfigHandler is used for persisting kubernetes config and context info
 func (h *Handler) K8SConfigHandler(w http.ResponseWriter, req *http.Request, prefObj *models.Preference, user *models.User, provider models.Provider) {
-	// if req.Method != http.MethodPost && req.Method != http.MethodDelete {
-	// 	w.WriteHeader(http.StatusNotFound)
-	// 	return
-	// }
-	if req.Method == http.MethodPost {
+	switch req.Method {
+	case http.MethodPost:
 		h.addK8SConfig(user, prefObj, w, req, provider)
-		return
-	}
-	if req.Method == http.MethodDelete {
-		h.deleteK8SConfig(user, prefObj, w, req, provider)
-		return
+	default:
+		w.Header().Set("Allow", http.MethodPost)
+		http.Error(w, fmt.Sprintf("method %s not allowed", req.Method), http.StatusMethodNotAllowed)
 	}
 }
 
 // swagger:route POST /api/system/kubernetes SystemAPI idPostK8SConfig
 // Handle POST request for Kubernetes Config
 //
-// Used to add kubernetes config to System
+// Used to add kubernetes config to System.
+// With "atomic=true" either all of the contexts are saved or none: when a context fails, the connections created
+// by the upload are deleted and 422 is returned with the details of the failed context.
+// With "merge_by_cluster=true" the contexts of the same cluster and user, differing only by namespace, are saved as a single
+// connection recording their namespaces, the merges are reported in "merged_contexts".
+// With "name_template" (defaulting to the "nameTemplate" of the user's connection preferences) the connections are named
+// from the detected metadata of their clusters, e.g. "{{.Distribution}}-{{.Region}}-{{.ShortServerID}}". The contexts whose
+// metadata is not available keep their name, the original names of the others are reported in "named_contexts".
+// With "current_context_policy" (defaulting to "KUBECONFIG_CURRENT_CONTEXT_POLICY") set to "strict" the upload fails with 422 and
+// nothing is saved when the current-context of the kubeconfig could not be connected to or saved. By default ("best_effort")
+// the other contexts are saved and the failure of the current-context is reported in "current_context_failure".
 // responses:
 // 	200: k8sConfigRespWrapper
+// 	422:
 
 // The function is called only when user uploads a kube config.
 // Connections which have state as "registered" are the only new ones, hence the GraphQL K8sContext subscription only sends an update to UI if any connection has registered state.
 // A registered connection might have been regsitered previously and is not required for K8sContext Subscription to notify, but this case is not considered here.
-func (h *Handler) addK8SConfig(user *models.User, _ *models.Preference, w http.ResponseWriter, req *http.Request, provider models.Provider) {
+func (h *Handler) addK8SConfig(user *models.User, prefObj *models.Preference, w http.ResponseWriter, req *http.Request, provider models.Provider) {
+	if !models.SupportsK8sContextPersistence(provider) {
+		http.Error(w, "this provider does not support Kubernetes context persistence", http.StatusNotImplemented)
+		return
+	}
+
 	userID := uuid.FromStringOrNil(user.ID)
 
-	token, ok := req.Context().Value(models.TokenCtxKey).(string)
+	token, ok := h.userToken(w, req)
 	if !ok {
-		err := ErrRetrieveUserToken(fmt.Errorf("failed to retrieve user token"))
-		logrus.Error(err)
-		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
 
+	uploadStart := time.Now()
+	uploadCtx, uploadSpan := startK8sUploadSpan(req.Context(), "upload")
+	defer uploadSpan.End()
+	defer recordK8sUploadDuration(uploadCtx, uploadStart)
+	req = req.WithContext(uploadCtx)
+	_, parseSpan := startK8sUploadSpan(uploadCtx, "parse")
+	// The parse span is ended once the contexts are parsed, this only ends it on the early returns
+	parsed := false
+	defer func() {
+		if !parsed {
+			parseSpan.End()
+		}
+	}()
+
 	k8sConfigBytes, err := readK8sConfigFromBody(req)
 	if err != nil {
 		logrus.Error(err)
@@ -82,97 +199,367 @@ func (h *Handler) addK8SConfig(user *models.User, _ *models.Preference, w http.R
 		return
 	}
 
-	// Flatten kubeconfig. If that fails, go ahead with non-flattened config file
+	log := h.requestLogger(w, req)
+
+	// An identical upload in flight (e.g. double submit) is answered with the response of the first one.
+	uploadKey := k8sConfigUploadKey(user.ID, *k8sConfigBytes, req)
+	upload, first := k8sConfigUploads.start(uploadKey)
+	if !first {
+		upload.replay(w, req)
+		return
+	}
+	defer k8sConfigUploads.finish(uploadKey, upload)
+	w = upload.record(w)
+
+	// Mis-merged kubeconfigs may carry several contexts with the same name, which are told apart by renaming.
+	disambiguatedK8sConfig, renamedContexts, err := models.DisambiguateK8sContextNames(*k8sConfigBytes)
+	if err != nil {
+		log.Warn(err)
+	} else if len(renamedContexts) > 0 {
+		k8sConfigBytes = &disambiguatedK8sConfig
+		log.Info("renamed kubeconfig contexts with duplicate names: ", renamedContexts)
+	}
+
+	// Flatten kubeconfig. If that fails, go ahead with non-flattened config file unless flattening is required.
+	// The default is configurable through "REQUIRE_KUBECONFIG_FLATTEN" and can be overridden per request.
+	requireFlatten := viper.GetBool("REQUIRE_KUBECONFIG_FLATTEN")
+	if v, err := strconv.ParseBool(req.FormValue("require_flatten")); err == nil {
+		requireFlatten = v
+	}
 	flattenedK8sConfig, err := helpers.FlattenMinifyKubeConfig(*k8sConfigBytes)
 	if err == nil {
 		k8sConfigBytes = &flattenedK8sConfig
+	} else if requireFlatten {
+		err = ErrFlattenKubeConfig(err)
+		log.Error(err)
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	} else {
+		log.Warn(ErrFlattenKubeConfig(err))
+	}
+	flattened := err == nil
+
+	// Optionally register only the current-context of the kubeconfig, the way kubectl would use it.
+	if onlyCurrent, _ := strconv.ParseBool(req.FormValue("only_current")); onlyCurrent {
+		currentK8sConfig, err := currentContextOfKubeconfig(*k8sConfigBytes)
+		if err != nil {
+			log.Error(err)
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		k8sConfigBytes = &currentK8sConfig
+	}
+
+	// Dial a different API server URL than the one of the kubeconfig for the cluster of a context, the current-context
+	// unless "server_override_context" is given, e.g. when Meshery reaches the cluster through an internal load balancer or a tunnel.
+	var configure []func(*models.K8sContext)
+	if serverOverride := req.FormValue("server_override"); serverOverride != "" {
+		overriddenK8sConfig, originalServers, err := models.OverrideKubeConfigServer(*k8sConfigBytes, req.FormValue("server_override_context"), serverOverride)
+		if err != nil {
+			log.Error(err)
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		k8sConfigBytes = &overriddenK8sConfig
+		configure = append(configure, func(ctx *models.K8sContext) {
+			ctx.OriginalServer = originalServers[ctx.Name]
+		})
 	}
 
 	saveK8sContextResponse := SaveK8sContextResponse{
-		RegisteredContexts: make([]models.K8sContext, 0),
-		ConnectedContexts:  make([]models.K8sContext, 0),
-		IgnoredContexts:    make([]models.K8sContext, 0),
-		ErroredContexts:    make([]models.K8sContext, 0),
+		RegisteredContexts:  make([]models.K8sContext, 0),
+		ConnectedContexts:   make([]models.K8sContext, 0),
+		IgnoredContexts:     make([]models.K8sContext, 0),
+		ErroredContexts:     make([]models.K8sContext, 0),
+		KubeconfigFlattened: flattened,
+	}
+	if len(renamedContexts) > 0 {
+		saveK8sContextResponse.RenamedContexts = renamedContexts
+		configure = append(configure, func(ctx *models.K8sContext) {
+			if original, ok := renamedContexts[ctx.Name]; ok {
+				ctx.RenamedFrom = original
+			}
+		})
 	}
 
 	eventBuilder := events.NewEvent().FromUser(userID).FromSystem(*h.SystemID).WithCategory("connection").WithAction("create").
 		WithDescription("Kubernetes config uploaded.").WithSeverity(events.Informational)
 	eventMetadata := map[string]interface{}{}
-	contexts := models.K8sContextsFromKubeconfig(provider, user.ID, h.config.EventBroadcaster, *k8sConfigBytes, h.SystemID, eventMetadata)
-	len := len(contexts)
+	// Connections created by automation are marked as managed, so that a declarative apply may prune them.
+	if managed, _ := strconv.ParseBool(req.FormValue("managed")); managed {
+		configure = append(configure, func(ctx *models.K8sContext) {
+			ctx.Managed = true
+		})
+	}
+	// Proxy settings apply to every context of the uploaded kubeconfig.
+	if proxyURL := req.FormValue("proxy_url"); proxyURL != "" {
+		proxyUsername, proxyPassword := req.FormValue("proxy_username"), req.FormValue("proxy_password")
+		configure = append(configure, func(ctx *models.K8sContext) {
+			ctx.ProxyURL = proxyURL
+			ctx.ProxyUsername = proxyUsername
+			ctx.ProxyPassword = proxyPassword
+		})
+	}
+	// Transport timeouts apply to every context of the uploaded kubeconfig, overriding the "KUBERNETES_*_TIMEOUT" defaults.
+	dialTimeout, tlsHandshakeTimeout, responseHeaderTimeout := req.FormValue("dial_timeout"), req.FormValue("tls_handshake_timeout"), req.FormValue("response_header_timeout")
+	if dialTimeout != "" || tlsHandshakeTimeout != "" || responseHeaderTimeout != "" {
+		timeouts := models.K8sContext{DialTimeout: dialTimeout, TLSHandshakeTimeout: tlsHandshakeTimeout, ResponseHeaderTimeout: responseHeaderTimeout}
+		if _, err := timeouts.TransportTimeouts(); err != nil {
+			log.Error(err)
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		configure = append(configure, func(ctx *models.K8sContext) {
+			ctx.DialTimeout = dialTimeout
+			ctx.TLSHandshakeTimeout = tlsHandshakeTimeout
+			ctx.ResponseHeaderTimeout = responseHeaderTimeout
+		})
+	}
+	// API servers behind a proxy may present a certificate for another name than the host of the server URL.
+	if tlsServerName := strings.TrimSpace(req.FormValue("tls_server_name")); tlsServerName != "" {
+		configure = append(configure, func(ctx *models.K8sContext) {
+			ctx.TLSServerName = tlsServerName
+		})
+	}
+	if notes := strings.TrimSpace(req.FormValue("notes")); notes != "" {
+		configure = append(configure, func(ctx *models.K8sContext) {
+			ctx.Notes = notes
+		})
+	}
+	// MeshSync resyncs the resources of every context of the uploaded kubeconfig at the interval, once connected.
+	if syncInterval := strings.TrimSpace(req.FormValue("sync_interval")); syncInterval != "" {
+		if _, err := models.ParseK8sSyncInterval(syncInterval); err != nil {
+			log.Error(err)
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		configure = append(configure, func(ctx *models.K8sContext) {
+			ctx.SyncInterval = syncInterval
+		})
+	}
+	// Rate limits apply to every context of the uploaded kubeconfig, overriding the "KUBERNETES_CLIENT_*" defaults.
+	if qps, burst := req.FormValue("qps"), req.FormValue("burst"); qps != "" || burst != "" {
+		qpsLimit, burstLimit, err := models.ParseK8sClientRateLimits(qps, burst)
+		if err != nil {
+			log.Error(err)
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		configure = append(configure, func(ctx *models.K8sContext) {
+			ctx.QPS = qpsLimit
+			ctx.Burst = burstLimit
+		})
+	}
+	// The identity impersonated on the cluster may be overridden, e.g. to operate as a restricted service account.
+	if impersonate := k8sImpersonationFromRequest(req); impersonate != nil {
+		configure = append(configure, impersonate)
+	}
+	// Connections of ephemeral clusters (e.g. of CI) may expire, they are deleted by the expiry sweeper once expired.
+	if ttl := req.FormValue("ttl"); ttl != "" {
+		d, err := models.ParseK8sContextTTL(ttl)
+		if err != nil {
+			log.Error(err)
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		expiresAt := time.Now().Add(d)
+		configure = append(configure, func(ctx *models.K8sContext) {
+			ctx.ExpiresAt = &expiresAt
+		})
+	}
+	splitByNamespace, _ := strconv.ParseBool(req.FormValue("split_by_namespace"))
+	mergeByCluster, _ := strconv.ParseBool(req.FormValue("merge_by_cluster"))
+	if splitByNamespace && mergeByCluster {
+		http.Error(w, "contexts cannot be both split by namespace and merged by cluster", http.StatusBadRequest)
+		return
+	}
+	// The contexts of the same cluster and user, differing only by namespace, may be collapsed into a single connection.
+	// They are merged before the contexts are dialed, so that the merged ones are not.
+	if mergeByCluster {
+		mergedK8sConfig, merges, err := models.MergeKubeconfigContextsByCluster(*k8sConfigBytes)
+		if err != nil {
+			log.Error(err)
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if len(merges) > 0 {
+			k8sConfigBytes = &mergedK8sConfig
+			saveK8sContextResponse.MergedContexts = merges
+			log.Info("merged kubeconfig contexts of the same cluster and user: ", merges)
+			namespaces := make(map[string][]string, len(merges))
+			for _, merge := range merges {
+				namespaces[merge.Context] = merge.Namespaces
+			}
+			configure = append(configure, func(ctx *models.K8sContext) {
+				ctx.Namespaces = namespaces[ctx.Name]
+			})
+		}
+	}
+	contexts := models.K8sContextsFromKubeconfig(provider, user.ID, h.config.EventBroadcaster, *k8sConfigBytes, h.SystemID, eventMetadata, configure...)
+	log.Debug("connected to ", len(contexts), " contexts of the uploaded kubeconfig")
 
-	smInstanceTracker := h.ConnectionToStateMachineInstanceTracker
-	for idx, ctx := range contexts {
-		metadata := map[string]interface{}{}
-		metadata["context"] = models.RedactCredentialsForContext(ctx)
-		metadata["description"] = fmt.Sprintf("Connection established with context \"%s\" at %s", ctx.Name, ctx.Server)
+	if splitByNamespace {
+		contexts = splitK8sContextsByNamespace(contexts)
+	}
+	// Cloud provider context names are hardly readable, the connections may be named after their clusters instead.
+	nameTemplate := req.FormValue("name_template")
+	if nameTemplate == "" && prefObj != nil && prefObj.K8sConnectionPreferences != nil {
+		nameTemplate = prefObj.K8sConnectionPreferences.NameTemplate
+	}
+	if nameTemplate != "" {
+		tmpl, err := models.ParseK8sContextNameTemplate(nameTemplate)
+		if err != nil {
+			log.Error(err)
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if named := nameK8sContexts(contexts, tmpl); len(named) > 0 {
+			saveK8sContextResponse.NamedContexts = named
+			log.Info("named kubeconfig contexts by the naming template: ", named)
+		}
+	}
+	total := len(contexts)
+	parseSpan.SetAttributes(attrK8sContexts.Int(total))
+	parseSpan.End()
+	parsed = true
+	uploadSpan.SetAttributes(attrK8sContexts.Int(total))
+
+	// Optionally save only the contexts which are reachable right now, kubeconfigs tend to carry dead contexts.
+	// The probe timeout defaults to "KUBERNETES_PROBE_TIMEOUT" and can be overridden per request.
+	skipUnreachable, _ := strconv.ParseBool(req.FormValue("skip_unreachable"))
+	probeTimeout := viper.GetDuration("KUBERNETES_PROBE_TIMEOUT")
+	if v, err := time.ParseDuration(req.FormValue("probe_timeout")); err == nil && v > 0 {
+		probeTimeout = v
+	}
+
+	currentContextPolicy, err := k8sCurrentContextPolicy(req)
+	if err != nil {
+		log.Error(err)
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	strictCurrentContext := currentContextPolicy == K8sCurrentContextPolicyStrict
+	if strictCurrentContext {
+		if streamed, _ := strconv.ParseBool(req.FormValue("stream")); streamed {
+			http.Error(w, "uploads with the strict current-context policy cannot be streamed", http.StatusBadRequest)
+			return
+		}
+	}
+	// The current-context is the one the tooling of the user works with, it may be required to be connected.
+	if failure := currentK8sContextDiscoveryFailure(*k8sConfigBytes, contexts, eventMetadata); failure != nil {
+		if strictCurrentContext {
+			h.failK8sConfigUploadOnCurrentContext(w, provider, userID, eventBuilder, eventMetadata, failure)
+			return
+		}
+		saveK8sContextResponse.CurrentContextFailure = failure
+	}
+	if strictCurrentContext {
+		// Saved first, nothing was saved yet when it fails.
+		currentK8sContextFirst(contexts)
+	}
 
-		connection, err := provider.SaveK8sContext(token, *ctx)
+	// An atomic upload saves either all of the contexts or none of them, for CI pipelines.
+	atomic, _ := strconv.ParseBool(req.FormValue("atomic"))
+	if atomic {
+		if streamed, _ := strconv.ParseBool(req.FormValue("stream")); streamed {
+			http.Error(w, "atomic uploads cannot be streamed", http.StatusBadRequest)
+			return
+		}
+		// The contexts which could not be connected to are left out of contexts, nothing is saved then
+		// and the first of them is reported.
+		if failures := unreachableK8sContextsRegistrationResults(contexts, eventMetadata); len(failures) > 0 {
+			h.writeK8sConfigAtomicUploadFailure(w, K8sConfigAtomicUploadFailure{FailedContext: failures[0].ContextName, Error: failures[0].Error, RolledBackConnections: []string{}})
+			return
+		}
+	}
+	createdConnections := []string{}
+	// The users who connect the new connections manually have them ignored by default, see K8sConnectionPreferences.
+	defaultState := prefObj.K8sConnectionDefaultState()
+
+	// Optionally stream the outcome of each context as it is saved instead of waiting for all of them.
+	var stream *k8sContextSaveStream
+	if v, _ := strconv.ParseBool(req.FormValue("stream")); v {
+		stream = newK8sContextSaveStream(w)
+	}
+
+	for idx, ctx := range contexts {
+		saveStart := time.Now()
+		_, saveSpan := startK8sUploadSpan(uploadCtx, "save_context", attrK8sContextName.String(ctx.Name))
+		var err error
+		if skipUnreachable {
+			err = ctx.PingTestWithTimeout(probeTimeout)
+		}
+		var metadata map[string]interface{}
+		status, created := k8sContextSaveErrored, false
 		if err != nil {
+			metadata = newK8sContextEventMetadata(ctx)
 			saveK8sContextResponse.ErroredContexts = append(saveK8sContextResponse.ErroredContexts, *ctx)
-			metadata["description"] = fmt.Sprintf("Unable to establish connection with context \"%s\" at %s", ctx.Name, ctx.Server)
+			metadata["description"] = fmt.Sprintf("Skipped unreachable context \"%s\" at %s", ctx.Name, ctx.Server)
 			metadata["error"] = err
 		} else {
-			ctx.ConnectionID = connection.ID.String()
-			eventBuilder.ActedUpon(connection.ID)
-			status := connection.Status
-			machineCtx := &kubernetes.MachineCtx{
-				K8sContext:         *ctx,
-				MesheryCtrlsHelper: h.MesheryCtrlsHelper,
-				K8sCompRegHelper:   h.K8sCompRegHelper,
-				OperatorTracker:    h.config.OperatorTracker,
-				K8scontextChannel:  h.config.K8scontextChannel,
-				EventBroadcaster:   h.config.EventBroadcaster,
-				RegistryManager:    h.registryManager,
-			}
+			ctx.Source = models.K8sContextSourceUpload
+			metadata, status, created = h.saveK8sContext(req, provider, token, userID, ctx, eventBuilder, &saveK8sContextResponse, defaultState)
+		}
+		eventMetadata[ctx.Name] = metadata
+		saveSpan.SetAttributes(attrK8sContextStatus.String(status), attrK8sContextDuration.Int64(time.Since(saveStart).Milliseconds()))
+		saveSpan.End()
+		log.Debug("context ", ctx.Name, " at ", ctx.Server, " (ID: ", ctx.ID, ") saved with status ", status, ": ", metadata["description"])
+		if stream != nil {
+			stream.progress(ctx, status, metadata)
+		}
 
-			if status == connections.CONNECTED {
-				saveK8sContextResponse.ConnectedContexts = append(saveK8sContextResponse.ConnectedContexts, *ctx)
-				metadata["description"] = fmt.Sprintf("Connection already exists with Kubernetes context \"%s\" at %s", ctx.Name, ctx.Server)
-			} else if status == connections.IGNORED {
-				saveK8sContextResponse.IgnoredContexts = append(saveK8sContextResponse.IgnoredContexts, *ctx)
-				metadata["description"] = fmt.Sprintf("Kubernetes context \"%s\" is set to ignored state.", ctx.Name)
-			} else if status == connections.DISCOVERED {
-				saveK8sContextResponse.RegisteredContexts = append(saveK8sContextResponse.RegisteredContexts, *ctx)
-				metadata["description"] = fmt.Sprintf("Connection registered with kubernetes context \"%s\" at %s.", ctx.Name, ctx.Server)
+		if atomic && status == k8sContextSaveErrored {
+			failure := K8sConfigAtomicUploadFailure{
+				FailedContext:         ctx.Name,
+				Server:                ctx.Server,
+				RolledBackConnections: createdConnections,
 			}
-
-			inst, err := mhelpers.InitializeMachineWithContext(
-				machineCtx,
-				req.Context(),
-				connection.ID,
-				userID,
-				smInstanceTracker,
-				h.log,
-				provider,
-				machines.DefaultState,
-				"kubernetes",
-				kubernetes.AssignInitialCtx,
-			)
-			if err != nil {
-				h.log.Error(err)
+			failure.Description, _ = metadata["description"].(string)
+			if err, ok := metadata["error"].(error); ok {
+				failure.Error = err.Error()
 			}
+			for _, connectionID := range createdConnections {
+				h.deleteK8sContext(req.Context(), provider, token, userID, connectionID)
+			}
+			h.config.K8scontextChannel.PublishContext()
 
-			go func(inst *machines.StateMachine) {
-				event, err := inst.SendEvent(req.Context(), machines.EventType(mhelpers.StatusToEvent(status)), nil)
-				if err != nil {
-					_ = provider.PersistEvent(event)
-					go h.config.EventBroadcaster.Publish(userID, event)
-				}
-			}(inst)
-		}
+			eventMetadata["rolled_back_connections"] = createdConnections
+			event := eventBuilder.WithSeverity(events.Error).WithDescription(fmt.Sprintf("Kubernetes config upload rolled back, context \"%s\" failed.", ctx.Name)).WithMetadata(capK8sConfigEventMetadata(log, eventMetadata, ctx.Name, "rolled_back_connections")).Build()
+			h.persistEvent(provider, event)
+			go h.config.EventBroadcaster.Publish(userID, event)
 
-		eventMetadata[ctx.Name] = metadata
+			h.writeK8sConfigAtomicUploadFailure(w, failure)
+			return
+		}
+		if ctx.IsCurrentContext && status == k8sContextSaveErrored {
+			failure := newK8sCurrentContextFailure(ctx.Name, ctx.Server, metadata)
+			if strictCurrentContext {
+				h.failK8sConfigUploadOnCurrentContext(w, provider, userID, eventBuilder, eventMetadata, failure)
+				return
+			}
+			saveK8sContextResponse.CurrentContextFailure = failure
+		}
+		// Only the contexts registered by this upload are new, the other connections existed already.
+		if created {
+			createdConnections = append(createdConnections, ctx.ConnectionID)
+		}
 
-		if idx == len-1 {
+		if idx == total-1 {
 			h.config.K8scontextChannel.PublishContext()
 		}
 	}
 
-	event := eventBuilder.WithMetadata(eventMetadata).Build()
-	_ = provider.PersistEvent(event)
+	_, publishSpan := startK8sUploadSpan(uploadCtx, "publish_event")
+	event := eventBuilder.WithMetadata(capK8sConfigEventMetadata(log, eventMetadata)).Build()
+	h.persistEvent(provider, event)
 	go h.config.EventBroadcaster.Publish(userID, event)
+	publishSpan.End()
 
+	saveK8sContextResponse.sort()
+	if stream != nil {
+		stream.summary(saveK8sContextResponse)
+		return
+	}
 	if err := json.NewEncoder(w).Encode(saveK8sContextResponse); err != nil {
 		logrus.Error(models.ErrMarshal(err, "kubeconfig"))
 		http.Error(w, models.ErrMarshal(err, "kubeconfig").Error(), http.StatusInternalServerError)
@@ -180,25 +567,168 @@ func (h *Handler) addK8SConfig(user *models.User, _ *models.Preference, w http.R
 	}
 }
 
-// swagger:route DELETE /api/system/kubernetes SystemAPI idDeleteK8SConfig
-// Handle DELETE request for Kubernetes Config
-//
-// Used to delete kubernetes config to System
-// responses:
-// 	200:
+// failK8sConfigUploadOnCurrentContext fails the upload, of which nothing was saved, as its current-context failed
+func (h *Handler) failK8sConfigUploadOnCurrentContext(w http.ResponseWriter, provider models.Provider, userID uuid.UUID, eventBuilder *events.EventBuilder, eventMetadata map[string]interface{}, failure *K8sCurrentContextFailure) {
+	event := eventBuilder.WithSeverity(events.Error).WithDescription(fmt.Sprintf("Kubernetes config upload failed, current-context \"%s\" failed.", failure.Context)).WithMetadata(capK8sConfigEventMetadata(h.log, eventMetadata, failure.Context)).Build()
+	h.persistEvent(provider, event)
+	go h.config.EventBroadcaster.Publish(userID, event)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusUnprocessableEntity)
+	if err := json.NewEncoder(w).Encode(failure); err != nil {
+		h.log.Error(models.ErrMarshal(err, "kubeconfig"))
+	}
+}
+
+func (h *Handler) writeK8sConfigAtomicUploadFailure(w http.ResponseWriter, failure K8sConfigAtomicUploadFailure) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusUnprocessableEntity)
+	if err := json.NewEncoder(w).Encode(failure); err != nil {
+		h.log.Error(models.ErrMarshal(err, "kubeconfig"))
+	}
+}
+
+// newK8sContextEventMetadata returns the metadata describing the context in the events of the kubeconfig upload
+func newK8sContextEventMetadata(ctx *models.K8sContext) map[string]interface{} {
+	metadata := map[string]interface{}{}
+	metadata["context"] = models.RedactCredentialsForContext(ctx)
+	metadata["description"] = fmt.Sprintf("Connection established with context \"%s\" at %s", ctx.Name, ctx.Server)
+	if ctx.OriginalServer != "" {
+		metadata["original_server"] = ctx.OriginalServer
+	}
+	if ctx.RenamedFrom != "" {
+		metadata["renamed_from"] = ctx.RenamedFrom
+	}
+	if ctx.OriginalName != "" {
+		metadata["original_name"] = ctx.OriginalName
+	}
+	return metadata
+}
+
+// capK8sConfigEventMetadata caps the size of the metadata of the event of a kubeconfig upload to "EVENT_METADATA_MAX_SIZE",
+// uploads of many contexts would otherwise persist oversized events. The entries of the keep keys are always kept.
+func capK8sConfigEventMetadata(log logger.Handler, eventMetadata map[string]interface{}, keep ...string) map[string]interface{} {
+	capped, omitted := models.CapEventMetadata(eventMetadata, viper.GetInt("EVENT_METADATA_MAX_SIZE"), keep...)
+	if omitted > 0 {
+		log.Info("omitted the metadata of ", omitted, " of the ", len(eventMetadata), " entries of the kubeconfig upload event for exceeding ", viper.GetInt("EVENT_METADATA_MAX_SIZE"), " bytes")
+	}
+	return capped
+}
 
-func (h *Handler) deleteK8SConfig(_ *models.User, _ *models.Preference, w http.ResponseWriter, _ *http.Request, _ models.Provider) {
-	// prefObj.K8SConfig = nil
-	// err := provider.RecordPreferences(req, user.UserID, prefObj)
-	// if err != nil {
-	// 	logrus.Error(ErrRecordPreferences(err))
-	// 	http.Error(w, ErrRecordPreferences(err).Error(), http.StatusInternalServerError)
-	// 	return
-	// }
+// nameK8sContexts names the contexts by the naming template, returning the original names keyed by the derived ones.
+// A context whose derived name is taken by another context of the upload keeps its name, e.g. two users of the same cluster.
+func nameK8sContexts(contexts []*models.K8sContext, tmpl *template.Template) map[string]string {
+	taken := make(map[string]bool, len(contexts))
+	for _, ctx := range contexts {
+		taken[ctx.Name] = true
+	}
+	named := map[string]string{}
+	for _, ctx := range contexts {
+		candidate := *ctx
+		if !candidate.ApplyNameTemplate(tmpl) || taken[candidate.Name] {
+			continue
+		}
+		taken[candidate.Name] = true
+		ctx.OriginalName, ctx.Name = candidate.OriginalName, candidate.Name
+		named[ctx.Name] = ctx.OriginalName
+	}
+	return named
+}
+
+// saveK8sContext saves the context as a connection, records the outcome in the response and
+// transitions the state machine of the connection to its status. Returns the event metadata of the context
+// and the status of its connection, "errored" if it could not be saved, and whether the connection was created by the save.
+// New connections are ignored instead of connected when defaultState is "ignored", for the users who connect them manually.
+func (h *Handler) saveK8sContext(req *http.Request, provider models.Provider, token string, userID uuid.UUID, ctx *models.K8sContext, eventBuilder *events.EventBuilder, saveK8sContextResponse *SaveK8sContextResponse, defaultState connections.ConnectionStatus) (map[string]interface{}, string, bool) {
+	metadata := newK8sContextEventMetadata(ctx)
+
+	// Only the stored copy is compressed, the events and the response carry the context as is.
+	connection, err := provider.SaveK8sContext(token, models.CompressK8sContextForStorage(*ctx))
+	if err != nil {
+		saveK8sContextResponse.ErroredContexts = append(saveK8sContextResponse.ErroredContexts, *ctx)
+		metadata["description"] = fmt.Sprintf("Unable to establish connection with context \"%s\" at %s", ctx.Name, ctx.Server)
+		metadata["error"] = err
+		return metadata, k8sContextSaveErrored, false
+	}
+
+	ctx.ConnectionID = connection.ID.String()
+	eventBuilder.ActedUpon(connection.ID)
+	if ctx.RelaxesTLSVerification() {
+		event := events.NewEvent().ActedUpon(connection.ID).FromUser(userID).FromSystem(*h.SystemID).WithCategory("connection").WithAction("register").
+			WithSeverity(events.Warning).WithDescription(fmt.Sprintf("The certificate of the API server of Kubernetes context \"%s\" at %s is not verified as it is a local cluster and \"KUBERNETES_DEV_MODE\" is on, never turn dev mode on in production", ctx.Name, ctx.Server)).
+			WithMetadata(map[string]interface{}{
+				"connection_id": connection.ID.String(),
+				"context":       ctx.Name,
+				"server":        ctx.Server,
+			}).Build()
+		h.persistEvent(provider, event)
+		go h.config.EventBroadcaster.Publish(userID, event)
+	}
+	status := connection.Status
+	if status == connections.DISCOVERED && defaultState == connections.IGNORED {
+		status = connections.IGNORED
+	}
+	machineCtx := h.newK8sMachineCtx(*ctx)
+
+	if status == connections.CONNECTED {
+		saveK8sContextResponse.ConnectedContexts = append(saveK8sContextResponse.ConnectedContexts, *ctx)
+		metadata["description"] = fmt.Sprintf("Connection already exists with Kubernetes context \"%s\" at %s", ctx.Name, ctx.Server)
+	} else if status == connections.IGNORED {
+		saveK8sContextResponse.IgnoredContexts = append(saveK8sContextResponse.IgnoredContexts, *ctx)
+		metadata["description"] = fmt.Sprintf("Kubernetes context \"%s\" is set to ignored state.", ctx.Name)
+	} else if status == connections.DISCOVERED {
+		saveK8sContextResponse.RegisteredContexts = append(saveK8sContextResponse.RegisteredContexts, *ctx)
+		metadata["description"] = fmt.Sprintf("Connection registered with kubernetes context \"%s\" at %s.", ctx.Name, ctx.Server)
+	}
+
+	inst, err := mhelpers.InitializeMachineWithContext(
+		machineCtx,
+		req.Context(),
+		connection.ID,
+		userID,
+		h.ConnectionToStateMachineInstanceTracker,
+		h.log,
+		provider,
+		machines.DefaultState,
+		"kubernetes",
+		kubernetes.AssignInitialCtx,
+	)
+	if err != nil {
+		h.log.Error(err)
+	}
 
-	ctxID := "0" //To be replaced with actual context ID after multi context support
-	go core.DeleteK8sWorkloads(ctxID)
-	_, _ = w.Write([]byte("{}"))
+	go func(inst *machines.StateMachine) {
+		eventType := machines.EventType(mhelpers.StatusToEvent(status))
+		transitionCtx, transitionSpan := startK8sUploadSpan(req.Context(), "transition", attrConnectionID.String(connection.ID.String()), attrConnectionEvent.String(string(eventType)))
+		defer transitionSpan.End()
+		event, err := inst.SendEvent(transitionCtx, eventType, nil)
+		if err != nil {
+			h.persistEvent(provider, event)
+			go h.config.EventBroadcaster.Publish(userID, event)
+		}
+	}(inst)
+	// The provider reports the connections it has just created as discovered, whatever state they are taken to then.
+	return metadata, string(status), connection.Status == connections.DISCOVERED
+}
+
+// splitK8sContextsByNamespace replaces each of the contexts with one context per namespace accessible with it.
+// Contexts for which the namespaces cannot be enumerated are retained as is.
+func splitK8sContextsByNamespace(contexts []*models.K8sContext) []*models.K8sContext {
+	splitContexts := make([]*models.K8sContext, 0, len(contexts))
+	for _, ctx := range contexts {
+		handler, err := ctx.GenerateKubeHandler()
+		if err == nil {
+			var nsContexts []*models.K8sContext
+			nsContexts, err = ctx.SplitByNamespace(handler)
+			if err == nil {
+				splitContexts = append(splitContexts, nsContexts...)
+				continue
+			}
+		}
+		logrus.Warnf("unable to split context %s by namespace, continuing with the context as is: %v", ctx.Name, err)
+		splitContexts = append(splitContexts, ctx)
+	}
+	return splitContexts
 }
 
 // swagger:route POST /api/system/kubernetes/contexts SystemAPI idPostK8SContexts
@@ -226,7 +756,7 @@ func (h *Handler) GetContextsFromK8SConfig(w http.ResponseWriter, req *http.Requ
 	contexts := models.K8sContextsFromKubeconfig(provider, user.ID, h.config.EventBroadcaster, *k8sConfigBytes, h.SystemID, eventMetadata)
 
 	event := eventBuilder.WithMetadata(eventMetadata).Build()
-	_ = provider.PersistEvent(event)
+	h.persistEvent(provider, event)
 	go h.config.EventBroadcaster.Publish(userUUID, event)
 
 	err = json.NewEncoder(w).Encode(contexts)
@@ -240,16 +770,39 @@ func (h *Handler) GetContextsFromK8SConfig(w http.ResponseWriter, req *http.Requ
 // swagger:route GET /api/system/kubernetes/ping?connection_id={id} SystemAPI idGetKubernetesPing
 // Handle GET request for Kubernetes ping
 //
-// Fetches server version to simulate ping
+// Fetches server version to simulate ping and reports it along with the timings of the phases (DNS, connect, TLS handshake, first byte) of the request
+// and the status of the connection with its reason.
+// With "environment" (and "orgID") instead of "connection_id" every Kubernetes connection of the environment is pinged,
+// a connection whose context cannot be loaded is reported with its error.
+// A connection whose stored configuration is missing its server or credentials is answered with 422 identifying what is missing.
+// With "namespace" the liveness of the namespace is checked by listing its pods instead of fetching the server version,
+// for credentials scoped to the namespace, and whether it is "reachable" is reported along with the error if not.
+// The server version of a connection is cached for "KUBERNETES_PING_CACHE_TTL", a cached ping reports
+// "cached" along with its "age" in seconds, pass "fresh=true" to bypass the cache.
+// With "include_nodes=true" the count of the nodes by readiness and their allocatable CPU and memory are reported as "nodes",
+// listed within "KUBERNETES_PROBE_TIMEOUT". Listing the nodes requires read access to them, "nodes_error" is reported without it.
 // responses:
 // 	200:
+// 	422:
 
 // KubernetesPingHandler - fetches server version to simulate ping
-func (h *Handler) KubernetesPingHandler(w http.ResponseWriter, req *http.Request, _ *models.Preference, _ *models.User, provider models.Provider) {
-	token, ok := req.Context().Value(models.TokenCtxKey).(string)
+func (h *Handler) KubernetesPingHandler(w http.ResponseWriter, req *http.Request, _ *models.Preference, user *models.User, provider models.Provider) {
+	token, ok := h.userToken(w, req)
 	if !ok {
-		w.WriteHeader(http.StatusInternalServerError)
-		fmt.Fprintf(w, "failed to get the token for the user")
+		return
+	}
+
+	// Credentials scoped to a namespace may not be allowed to fetch the server version, the namespace is checked instead.
+	namespace := req.URL.Query().Get("namespace")
+	if namespace != "" {
+		if errs := validation.IsDNS1123Label(namespace); len(errs) > 0 {
+			http.Error(w, fmt.Sprintf("invalid namespace %q: %s", namespace, strings.Join(errs, ", ")), http.StatusBadRequest)
+			return
+		}
+	}
+
+	if environment := req.URL.Query().Get("environment"); environment != "" {
+		h.pingK8sEnvironment(w, req, provider, token, environment, namespace)
 		return
 	}
 
@@ -263,6 +816,25 @@ func (h *Handler) KubernetesPingHandler(w http.ResponseWriter, req *http.Request
 			return
 		}
 
+		// A context stored without a usable configuration would fail with a generic error, or dial the in-cluster API server.
+		if missing := k8sContext.MissingConfig(); len(missing) > 0 {
+			err := models.ErrUnusableK8sContext(k8sContext.Name, missing)
+			h.log.Error(err)
+			userID := uuid.FromStringOrNil(user.ID)
+			event := events.NewEvent().ActedUpon(uuid.FromStringOrNil(connectionID)).FromUser(userID).FromSystem(*h.SystemID).WithCategory("connection").WithAction("ping").
+				WithSeverity(events.Warning).WithDescription(fmt.Sprintf("Kubernetes context \"%s\" is missing its %s, upload its kubeconfig again to refresh the connection", k8sContext.Name, strings.Join(missing, ", "))).
+				WithMetadata(map[string]interface{}{
+					"connection_id": connectionID,
+					"context":       k8sContext.Name,
+					"missing":       missing,
+					"error":         err,
+				}).Build()
+			h.persistEvent(provider, event)
+			go h.config.EventBroadcaster.Publish(userID, event)
+			http.Error(w, err.Error(), http.StatusUnprocessableEntity)
+			return
+		}
+
 		// Create handler for the context
 		kubeclient, err := k8sContext.GenerateKubeHandler()
 		if err != nil {
@@ -270,15 +842,17 @@ func (h *Handler) KubernetesPingHandler(w http.ResponseWriter, req *http.Request
 			fmt.Fprintf(w, "failed to get kubernetes config for the user")
 			return
 		}
-		version, err := kubeclient.KubeClient.ServerVersion()
+		response, err := h.pingK8sContext(req.Context(), &k8sContext, kubeclient, connectionID, namespace, req.URL.Query().Get("fresh") == "true")
 		if err != nil {
 			logrus.Error(ErrKubeVersion(err))
 			http.Error(w, ErrKubeVersion(err).Error(), http.StatusInternalServerError)
 			return
 		}
-		if err = json.NewEncoder(w).Encode(map[string]string{
-			"server_version": version.String(),
-		}); err != nil {
+		if req.URL.Query().Get("include_nodes") == "true" {
+			h.pingK8sNodes(req.Context(), response, &k8sContext, kubeclient)
+		}
+
+		if err = json.NewEncoder(w).Encode(response); err != nil {
 			err = errors.Wrap(err, "unable to marshal the payload")
 			logrus.Error(models.ErrMarshal(err, "kube-server-version"))
 			http.Error(w, models.ErrMarshal(err, "kube-server-version").Error(), http.StatusInternalServerError)
@@ -288,35 +862,555 @@ func (h *Handler) KubernetesPingHandler(w http.ResponseWriter, req *http.Request
 	http.Error(w, "Empty contextID. Pass the context ID(in query parameter \"context\") of the kuberenetes to be pinged", http.StatusBadRequest)
 }
 
+// pingK8sContext fetches the server version of the cluster of the context along with the status of its connection.
+// The server version is served from k8sServerVersions unless fresh, or expired.
+// With a namespace, the liveness of the namespace is checked instead, see pingK8sNamespace.
+func (h *Handler) pingK8sContext(ctx context.Context, k8sContext *models.K8sContext, kubeclient *meshkube.Client, connectionID, namespace string, fresh bool) (map[string]interface{}, error) {
+	if namespace != "" {
+		return h.pingK8sNamespace(ctx, k8sContext, kubeclient, connectionID, namespace), nil
+	}
+	cached, ok := k8sServerVersions.get(connectionID)
+	if !ok || fresh {
+		var err error
+		cached, err = h.fetchK8sServerVersion(ctx, k8sContext, kubeclient, connectionID)
+		if err != nil {
+			return nil, err
+		}
+		k8sServerVersions.set(connectionID, cached, viper.GetDuration("KUBERNETES_PING_CACHE_TTL"))
+		ok = false
+	}
+	response := map[string]interface{}{
+		"server_version": cached.version,
+		"timings":        cached.timings,
+		"cached":         ok,
+		"age":            time.Since(cached.fetchedAt).Round(time.Millisecond).Seconds(),
+	}
+	if inst, ok := h.ConnectionToStateMachineInstanceTracker.Get(uuid.FromStringOrNil(connectionID)); ok {
+		response["status"] = inst.State()
+		response["status_reason"] = inst.StatusReason()
+	}
+	return response, nil
+}
+
+// fetchK8sServerVersion fetches the server version of the cluster of the context
+func (h *Handler) fetchK8sServerVersion(ctx context.Context, k8sContext *models.K8sContext, kubeclient *meshkube.Client, connectionID string) (k8sServerVersion, error) {
+	start := time.Now()
+	version, timings, err := k8sContext.ServerVersionWithTimings(ctx, kubeclient, 0)
+	if err != nil {
+		return k8sServerVersion{}, err
+	}
+	k8sPingLatencies.record(connectionID, time.Since(start))
+	return k8sServerVersion{version: version.String(), timings: timings, fetchedAt: time.Now()}, nil
+}
+
+// pingK8sNodes adds the summary of the nodes of the cluster of the context to the ping response, or the error listing them,
+// giving up after "KUBERNETES_PROBE_TIMEOUT"
+func (h *Handler) pingK8sNodes(ctx context.Context, response map[string]interface{}, k8sContext *models.K8sContext, kubeclient *meshkube.Client) {
+	if timeout := viper.GetDuration("KUBERNETES_PROBE_TIMEOUT"); timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+	nodes, err := models.SummarizeK8sNodes(ctx, kubeclient.KubeClient)
+	if err != nil {
+		h.log.Debug("unable to list the nodes of kubernetes context ", k8sContext.Name, ": ", err)
+		response["nodes_error"] = err.Error()
+		return
+	}
+	response["nodes"] = nodes
+}
+
+// k8sServerVersions caches the server version of the clusters per connection, for dashboards polling the ping
+var k8sServerVersions = newTTLCache[k8sServerVersion]()
+
+// k8sServerVersion is the server version of a cluster, as fetched at fetchedAt
+type k8sServerVersion struct {
+	version   string
+	timings   *models.K8sPhaseTimings
+	fetchedAt time.Time
+}
+
+// pingK8sNamespace checks the liveness of the namespace of the cluster of the context, reporting whether it is reachable
+// along with the error if not, rather than failing the ping
+func (h *Handler) pingK8sNamespace(ctx context.Context, k8sContext *models.K8sContext, kubeclient *meshkube.Client, connectionID, namespace string) map[string]interface{} {
+	start := time.Now()
+	timings, err := k8sContext.NamespaceLivenessWithTimings(ctx, kubeclient, namespace, 0)
+	response := map[string]interface{}{
+		"namespace": namespace,
+		"reachable": err == nil,
+		"timings":   timings,
+	}
+	if err != nil {
+		h.log.Debug("namespace ", namespace, " of kubernetes context ", k8sContext.Name, " is not reachable: ", err)
+		response["error"] = err.Error()
+	} else {
+		k8sPingLatencies.record(connectionID, time.Since(start))
+	}
+	if inst, ok := h.ConnectionToStateMachineInstanceTracker.Get(uuid.FromStringOrNil(connectionID)); ok {
+		response["status"] = inst.State()
+		response["status_reason"] = inst.StatusReason()
+	}
+	return response
+}
+
+// K8sEnvironmentPingResult is the result of pinging a connection of an environment
+type K8sEnvironmentPingResult struct {
+	ConnectionID string                 `json:"connection_id"`
+	Context      string                 `json:"context"`
+	Server       string                 `json:"server"`
+	Ping         map[string]interface{} `json:"ping,omitempty"`
+	Error        string                 `json:"error,omitempty"`
+}
+
+// pingK8sEnvironment pings the Kubernetes connections of the environment concurrently
+func (h *Handler) pingK8sEnvironment(w http.ResponseWriter, req *http.Request, provider models.Provider, token, environment, namespace string) {
+	contexts, failures, err := k8sContextsOfEnvironment(req, provider, token, environment, req.URL.Query().Get("orgID"))
+	if err != nil {
+		h.log.Error(err)
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	fresh := req.URL.Query().Get("fresh") == "true"
+	includeNodes := req.URL.Query().Get("include_nodes") == "true"
+	results := make([]K8sEnvironmentPingResult, len(contexts))
+	var wg sync.WaitGroup
+	for i, k8sContext := range contexts {
+		wg.Add(1)
+		go func(i int, k8sContext *models.K8sContext) {
+			defer wg.Done()
+			result := K8sEnvironmentPingResult{ConnectionID: k8sContext.ConnectionID, Context: k8sContext.Name, Server: k8sContext.Server}
+			err := k8sContext.ValidateConfig()
+			var kubeclient *meshkube.Client
+			if err == nil {
+				kubeclient, err = k8sContext.GenerateKubeHandler()
+			}
+			if err == nil {
+				result.Ping, err = h.pingK8sContext(req.Context(), k8sContext, kubeclient, k8sContext.ConnectionID, namespace, fresh)
+			}
+			if err == nil && includeNodes {
+				h.pingK8sNodes(req.Context(), result.Ping, k8sContext, kubeclient)
+			}
+			if err != nil {
+				result.Error = err.Error()
+			}
+			results[i] = result
+		}(i, k8sContext)
+	}
+	wg.Wait()
+	for _, failure := range failures {
+		results = append(results, K8sEnvironmentPingResult{ConnectionID: failure.ConnectionID, Error: failure.Err.Error()})
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(map[string]interface{}{
+		"environment": environment,
+		"results":     results,
+	}); err != nil {
+		logrus.Error(models.ErrMarshal(err, "kube-server-version"))
+		http.Error(w, models.ErrMarshal(err, "kube-server-version").Error(), http.StatusInternalServerError)
+	}
+}
+
 // swagger:route POST /api/system/kubernetes/register SystemAPI idPostK8SRegistration
 // Handle registration request for Kubernetes components
 //
-// Used to register Kubernetes components to Meshery from a kubeconfig file
+// Used to register Kubernetes components to Meshery from a kubeconfig file,
+// or from the Kubernetes connections of the environment named by "environment" (and "orgID"). The connections of the environment
+// whose context cannot be loaded are reported as failed, the others are registered.
+// Registration happens in the background unless "sync=true" (or "wait=true") is passed, in which case
+// the per-context results are returned once the registration has finished. With "timeout" (e.g. "60s")
+// the request gives up waiting after the timeout and responds with 504 and the contexts finished so far,
+// while the registration continues in the background.
+// Components are associated with the Kubernetes model of the version of the cluster,
+// unless a "model_version" is passed to pin the version of the model.
+// Components are registered against the "kubernetes" registry host with the ID of the context as its metadata,
+// unless a "registry_host" (and "registry_host_metadata") is passed for federated registries.
+// The custom resources used in the excluded namespaces only (kube-system, kube-public and kube-node-lease by default)
+// are not registered, "exclude_namespaces" overrides the comma separated namespaces excluded.
+// With "kinds" (comma separated group/kind pairs, e.g. "apps/Deployment,Pod") only the components of those kinds are registered,
+// the registration event reports the kinds which were not found in the cluster.
+// "component_labels" (a JSON object, e.g. {"team": "payments"}) are merged into the metadata of each component,
+// without overwriting the metadata taken from the model.
+// The response to a registration in the background lists the contexts along with the job of the registration,
+// whose "status_url" (also the Location header) is polled with GET and cancels the registration with DELETE.
+// With "log_level" (e.g. "debug") the registration is logged at that level, tagged with the X-Correlation-ID response header.
+// With "stream=true" along with "sync=true" the outcome of each component is streamed as newline-delimited JSON while
+// the registration runs, the last line holds the per-context results under "summary".
 // responses:
 //
-//		202:
+//		200:
+//		202: K8sRegistrationAccepted
 //	 400:
 //	 500:
+//	 504:
 func (h *Handler) K8sRegistrationHandler(w http.ResponseWriter, req *http.Request, _ *models.Preference, user *models.User, provider models.Provider) {
-	k8sConfigBytes, err := readK8sConfigFromBody(req)
+	// The contexts of the connections of an environment are registered instead of the ones of an uploaded kubeconfig.
+	var environmentContexts []*models.K8sContext
+	var environmentFailures []k8sEnvironmentFailure
+	var k8sConfigBytes *[]byte
+	var err error
+	if environment := req.FormValue("environment"); environment != "" {
+		token, ok := h.userToken(w, req)
+		if !ok {
+			return
+		}
+		environmentContexts, environmentFailures, err = k8sContextsOfEnvironment(req, provider, token, environment, req.FormValue("orgID"))
+	} else {
+		k8sConfigBytes, err = readK8sConfigFromBody(req)
+	}
 	if err != nil {
 		logrus.Error(err)
 		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
+	log := h.requestLogger(w, req)
 
-	contexts := models.K8sContextsFromKubeconfig(provider, user.ID, h.config.EventBroadcaster, *k8sConfigBytes, h.SystemID, map[string]interface{}{}) // here we are not concerned for the events becuase inside the middleware the contexts would have been verified.
-	h.K8sCompRegHelper.UpdateContexts(contexts).RegisterComponents(contexts, []models.K8sRegistrationFunction{mcore.RegisterK8sMeshModelComponents}, h.registryManager, h.config.EventBroadcaster, provider, user.ID, false)
-	if _, err = w.Write([]byte(http.StatusText(http.StatusAccepted))); err != nil {
-		logrus.Error(ErrWriteResponse)
-		logrus.Error(err)
-		http.Error(w, ErrWriteResponse.Error(), http.StatusInternalServerError)
+	registrationOptions, err := k8sComponentsRegistrationOptionsFromRequest(req)
+	if err != nil {
+		log.Error(err)
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	sync, _ := strconv.ParseBool(req.FormValue("sync"))
+	wait, _ := strconv.ParseBool(req.FormValue("wait"))
+	streamed, _ := strconv.ParseBool(req.FormValue("stream"))
+	if streamed && !sync && !wait {
+		http.Error(w, "the registration can be streamed only along with sync=true", http.StatusBadRequest)
+		return
+	}
+
+	// here we are not concerned for the events becuase inside the middleware the contexts would have been verified,
+	// the metadata is only used to report the contexts which could not be connected to.
+	eventMetadata := map[string]interface{}{}
+	contexts := environmentContexts
+	if k8sConfigBytes != nil {
+		var configure []func(*models.K8sContext)
+		if impersonate := k8sImpersonationFromRequest(req); impersonate != nil {
+			configure = append(configure, impersonate)
+		}
+		contexts = models.K8sContextsFromKubeconfig(provider, user.ID, h.config.EventBroadcaster, *k8sConfigBytes, h.SystemID, eventMetadata, configure...)
+	}
+	registrationFunc := mcore.RegisterK8sMeshModelComponentsWithOptions(registrationOptions)
+	for _, ctx := range contexts {
+		log.Debug("registering the components of context ", ctx.Name, " at ", ctx.Server, " (ID: ", ctx.ID, ")")
+	}
+	// A synchronous registration may stream the outcome of each component as it is registered.
+	var stream *k8sRegistrationStream
+	var progress models.K8sComponentsRegistrationProgress
+	if streamed {
+		stream = &k8sRegistrationStream{}
+		progress = stream.progress
+	}
+	results := h.K8sCompRegHelper.UpdateContexts(contexts).RegisterComponentsWithProgress(log, progress, contexts, []models.K8sRegistrationFunction{registrationFunc}, h.registryManager, h.config.EventBroadcaster, provider, user.ID, false)
+	// The registration can be cancelled through the job, e.g. when started against the wrong cluster.
+	accepted := K8sRegistrationAccepted{Contexts: make([]K8sRegistrationAcceptedContext, 0, len(contexts)), Errored: erroredK8sEnvironmentRegistrationResults(environmentFailures)}
+	for _, ctx := range contexts {
+		accepted.Contexts = append(accepted.Contexts, K8sRegistrationAcceptedContext{ID: ctx.ID, Name: ctx.Name, Server: ctx.Server})
+	}
+	if job, err := k8sRegistrationJobs.track(user.ID, contexts, results); err != nil {
+		log.Warn(err)
+	} else {
+		accepted.JobID, accepted.StatusURL = job.id.String(), k8sRegistrationJobURL(job.id)
+		w.Header().Set("Location", accepted.StatusURL)
+	}
+
+	if sync || wait {
+		if stream != nil {
+			stream.open(w)
+		}
+		var registrationResults []models.K8sRegistrationResult
+		status := http.StatusOK
+		finished := true
+		if timeout, err := time.ParseDuration(req.FormValue("timeout")); err == nil && timeout > 0 {
+			registrationResults, finished = results.WaitWithTimeout(timeout)
+			if !finished {
+				// The registration continues in the background, report the contexts finished so far.
+				status = http.StatusGatewayTimeout
+			}
+		} else {
+			registrationResults = results.Wait()
+		}
+		registrationResults = append(registrationResults, unreachableK8sContextsRegistrationResults(contexts, eventMetadata)...)
+		registrationResults = append(registrationResults, accepted.Errored...)
+		if stream != nil {
+			stream.close(registrationResults, finished)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(status)
+		if err := json.NewEncoder(w).Encode(registrationResults); err != nil {
+			h.log.Error(models.ErrMarshal(err, "registration results"))
+		}
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusAccepted)
+	if err := json.NewEncoder(w).Encode(accepted); err != nil {
+		h.log.Error(models.ErrMarshal(err, "accepted registration"))
+	}
+}
+
+// k8sComponentsRegistrationOptionsFromRequest returns the options of the registration of the components given in the request
+func k8sComponentsRegistrationOptionsFromRequest(req *http.Request) (mcore.K8sComponentsRegistrationOptions, error) {
+	var err error
+	// Federated registries route the components to different logical registries by the registry host.
+	registrationOptions := mcore.K8sComponentsRegistrationOptions{
+		ModelVersion: req.FormValue("model_version"),
+		Hostname:     req.FormValue("registry_host"),
+		HostMetadata: req.FormValue("registry_host_metadata"),
+	}
+	if registrationOptions.Hostname != "" {
+		if err := models.ValidateRegistryHostname(registrationOptions.Hostname); err != nil {
+			return registrationOptions, err
+		}
+	}
+	if registrationOptions.HostMetadata != "" {
+		if err := models.ValidateRegistryHostMetadata(registrationOptions.HostMetadata); err != nil {
+			return registrationOptions, err
+		}
+	}
+	// The namespaces excluded from the component discovery default to "KUBERNETES_COMPONENT_EXCLUDED_NAMESPACES",
+	// an empty "exclude_namespaces" excludes none.
+	if _, ok := req.Form["exclude_namespaces"]; ok {
+		registrationOptions.ExcludeNamespaces = []string{}
+		for _, ns := range strings.Split(req.FormValue("exclude_namespaces"), ",") {
+			if ns = strings.TrimSpace(ns); ns != "" {
+				registrationOptions.ExcludeNamespaces = append(registrationOptions.ExcludeNamespaces, ns)
+			}
+		}
+	}
+	// A curated list of kinds restricts the registration to those kinds, the ones not found are reported in the event.
+	if kinds := req.FormValue("kinds"); kinds != "" {
+		registrationOptions.Kinds, err = mcore.ParseK8sComponentKinds(kinds)
+		if err != nil {
+			return registrationOptions, err
+		}
+	}
+	// Labels of the user's own, e.g. the team or cost center, are attached to the components to filter them in the registry.
+	if labels := req.FormValue("component_labels"); labels != "" {
+		registrationOptions.Labels, err = mcore.ParseK8sComponentLabels(labels)
+		if err != nil {
+			return registrationOptions, err
+		}
+	}
+	return registrationOptions, nil
+}
+
+// k8sRegistrationStream writes the outcome of each component of a registration as newline-delimited JSON, see k8sContextSaveStream.
+// The outcomes reported before the stream is opened are written once it is, the ones reported after it is closed are dropped,
+// e.g. when the registration goes on in the background after the timeout.
+type k8sRegistrationStream struct {
+	mx      sync.Mutex
+	stream  *k8sContextSaveStream
+	pending []models.K8sComponentRegistrationOutcome
+	closed  bool
+}
+
+func (s *k8sRegistrationStream) progress(outcome models.K8sComponentRegistrationOutcome) {
+	s.mx.Lock()
+	defer s.mx.Unlock()
+	if s.closed {
+		return
+	}
+	if s.stream == nil {
+		s.pending = append(s.pending, outcome)
+		return
+	}
+	s.stream.send(outcome)
+}
+
+func (s *k8sRegistrationStream) open(w http.ResponseWriter) {
+	s.mx.Lock()
+	defer s.mx.Unlock()
+	s.stream = newK8sContextSaveStream(w)
+	for _, outcome := range s.pending {
+		s.stream.send(outcome)
+	}
+	s.pending = nil
+}
+
+func (s *k8sRegistrationStream) close(results []models.K8sRegistrationResult, finished bool) {
+	s.mx.Lock()
+	defer s.mx.Unlock()
+	s.closed = true
+	s.stream.send(map[string]interface{}{"summary": results, "finished": finished})
+}
+
+// k8sImpersonationFromRequest returns the configuration overriding the identity impersonated by the contexts with the "as"
+// user and the comma separated "as_groups" of the request, nil when the impersonation of the kubeconfig applies.
+func k8sImpersonationFromRequest(req *http.Request) func(*models.K8sContext) {
+	username := req.FormValue("as")
+	if username == "" {
+		return nil
+	}
+	groups := []string{}
+	for _, group := range strings.Split(req.FormValue("as_groups"), ",") {
+		if group = strings.TrimSpace(group); group != "" {
+			groups = append(groups, group)
+		}
+	}
+	return func(ctx *models.K8sContext) {
+		ctx.SetImpersonation(username, groups)
+	}
+}
+
+// unreachableK8sContextsRegistrationResults reports the contexts of the kubeconfig which could not be connected to,
+// and hence were not attempted to register components for.
+func unreachableK8sContextsRegistrationResults(contexts []*models.K8sContext, eventMetadata map[string]interface{}) []models.K8sRegistrationResult {
+	connected := make(map[string]bool, len(contexts))
+	for _, ctx := range contexts {
+		connected[ctx.Name] = true
+	}
+
+	results := []models.K8sRegistrationResult{}
+	for name, m := range eventMetadata {
+		if connected[name] {
+			continue
+		}
+		result := models.K8sRegistrationResult{
+			ContextName: name,
+			Status:      models.K8sRegistrationFailed,
+		}
+		if metadata, ok := m.(map[string]interface{}); ok {
+			if err, ok := metadata["error"].(error); ok {
+				result.Error = err.Error()
+			}
+		}
+		results = append(results, result)
+	}
+	return results
+}
+
+// erroredK8sEnvironmentRegistrationResults reports the connections of an environment whose context could not be loaded
+// as failed registrations
+func erroredK8sEnvironmentRegistrationResults(failures []k8sEnvironmentFailure) []models.K8sRegistrationResult {
+	results := []models.K8sRegistrationResult{}
+	for _, failure := range failures {
+		results = append(results, models.K8sRegistrationResult{
+			ConnectionID: failure.ConnectionID,
+			Status:       models.K8sRegistrationFailed,
+			Error:        failure.Err.Error(),
+		})
+	}
+	return results
+}
+
+// discoveryKubeconfig returns the kubeconfig to discover the contexts from along with its source.
+// The base64 encoded kubeconfig of "KUBECONFIG_CONTENT" is preferred, for containers in which the kubeconfig
+// is injected through the environment rather than mounted, over the kubeconfig of the config folder.
+// Next, the kubeconfig may be read from the secret "KUBECONFIG_SECRET" (namespace/name[/key]) through the in-cluster client,
+// for in-cluster deployments onboarding the clusters through the secrets they manage.
+// With "KUBECONFIG_DIRECTORY_MODE" the kubeconfigs of all the files of the config folder are merged instead of reading its "config" file.
+func (h *Handler) discoveryKubeconfig() (string, string, error) {
+	if content := viper.GetString("KUBECONFIG_CONTENT"); content != "" {
+		data, err := base64.StdEncoding.DecodeString(strings.TrimSpace(content))
+		if err != nil {
+			return "", models.K8sContextSourceEnv, ErrDecodeKubeconfigContent(err)
+		}
+		return string(data), models.K8sContextSourceEnv, nil
+	}
+
+	if secret := viper.GetString("KUBECONFIG_SECRET"); secret != "" {
+		ref, err := models.ParseKubeconfigSecretRef(secret)
+		if err != nil {
+			return "", models.K8sContextSourceSecret, err
+		}
+		data, err := models.ReadKubeconfigSecretInCluster(context.Background(), ref)
+		return string(data), models.K8sContextSourceSecret, err
 	}
+
+	if viper.GetBool("KUBECONFIG_DIRECTORY_MODE") {
+		data, err := mergeKubeconfigDirectory(h.config.KubeConfigFolder)
+		return data, models.K8sContextSourceFilesystem, err
+	}
+
+	kubeconfigSource := fmt.Sprintf("file://%s", filepath.Join(h.config.KubeConfigFolder, "config"))
+	data, err := utils.ReadFileSource(kubeconfigSource)
+	return data, models.K8sContextSourceFilesystem, err
 }
 
+// mergeKubeconfigDirectory merges the kubeconfigs of the files of dir the same way as kubectl merges the paths of KUBECONFIG,
+// the first file in lexical order wins on conflicting entries. Hidden files, e.g. the "..data" of mounted secrets, are skipped
+// along with the files which are not kubeconfigs.
+func mergeKubeconfigDirectory(dir string) (string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return "", err
+	}
+
+	var paths []string
+	for _, entry := range entries {
+		if strings.HasPrefix(entry.Name(), ".") {
+			continue
+		}
+		path := filepath.Join(dir, entry.Name())
+		// Stat follows the symlinks of mounted secrets and config maps to the files
+		if info, err := os.Stat(path); err != nil || !info.Mode().IsRegular() {
+			continue
+		}
+		if _, err := clientcmd.LoadFromFile(path); err != nil {
+			logrus.Warnf("skipping %s of the kubeconfig directory: %v", path, err)
+			continue
+		}
+		paths = append(paths, path)
+	}
+	if len(paths) == 0 {
+		return "", fmt.Errorf("no kubeconfig found in directory %s", dir)
+	}
+
+	merged, err := (&clientcmd.ClientConfigLoadingRules{Precedence: paths}).Load()
+	if err != nil {
+		return "", err
+	}
+	data, err := clientcmd.Write(*merged)
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+// Outcomes of the discovery of a context
+const (
+	K8sDiscoverySaved   = "saved"
+	K8sDiscoveryErrored = "errored"
+)
+
+// K8sDiscoveryOutcome is the outcome of the discovery of a context
+type K8sDiscoveryOutcome struct {
+	Context      string `json:"context"`
+	Server       string `json:"server,omitempty"`
+	Status       string `json:"status"`
+	ConnectionID string `json:"connection_id,omitempty"`
+	Error        string `json:"error,omitempty"`
+}
+
+// K8sDiscoverySummary is the outcome of the discovery of the contexts of the kubeconfig, or of the in-cluster context
+type K8sDiscoverySummary struct {
+	Source   string                `json:"source"`
+	Saved    int                   `json:"saved"`
+	Errored  int                   `json:"errored"`
+	Outcomes []K8sDiscoveryOutcome `json:"contexts"`
+}
+
+func (s *K8sDiscoverySummary) saved(ctx *models.K8sContext) {
+	s.Saved++
+	s.Outcomes = append(s.Outcomes, K8sDiscoveryOutcome{Context: ctx.Name, Server: ctx.Server, Status: K8sDiscoverySaved, ConnectionID: ctx.ConnectionID})
+}
+
+func (s *K8sDiscoverySummary) errored(name, server string, err error) {
+	s.Errored++
+	s.Outcomes = append(s.Outcomes, K8sDiscoveryOutcome{Context: name, Server: server, Status: K8sDiscoveryErrored, Error: err.Error()})
+}
+
+// DiscoverK8SContextFromKubeConfig saves the contexts of the kubeconfig Meshery Server is configured with, see discoveryKubeconfig,
+// or else the in-cluster context. A context which fails to be discovered or saved does not stop the discovery of the others,
+// the outcome of each context is reported by a single event once the discovery completes.
+// Returns the saved contexts whose connections are to be managed.
 func (h *Handler) DiscoverK8SContextFromKubeConfig(userID string, token string, prov models.Provider) ([]*models.K8sContext, error) {
 	var contexts []*models.K8sContext
-	// userUUID := uuid.FromStringOrNil(userID)
 
 	// Get meshery instance ID
 	mid, ok := viper.Get("INSTANCE_ID").(*uuid.UUID)
@@ -324,85 +1418,133 @@ func (h *Handler) DiscoverK8SContextFromKubeConfig(userID string, token string,
 		return contexts, models.ErrMesheryInstanceID
 	}
 
-	// Attempt to get kubeconfig from the filesystem
 	if h.config == nil {
 		return contexts, ErrInvalidK8SConfigNil
 	}
-	kubeconfigSource := fmt.Sprintf("file://%s", filepath.Join(h.config.KubeConfigFolder, "config"))
-	data, err := utils.ReadFileSource(kubeconfigSource)
+	data, source, err := h.discoveryKubeconfig()
+	// The kubeconfigs configured explicitly are not fallen back from
+	if (source == models.K8sContextSourceEnv || source == models.K8sContextSourceSecret) && err != nil {
+		return contexts, err
+	}
 
-	eventMetadata := map[string]interface{}{}
-	metadata := map[string]interface{}{}
+	var discovered []*models.K8sContext
+	summary := &K8sDiscoverySummary{Source: source, Outcomes: []K8sDiscoveryOutcome{}}
 	if err != nil {
 		// Could be an in-cluster deployment
-		ctxName := "in-cluster"
-
-		cc, err := models.NewK8sContextFromInClusterConfig(ctxName, mid)
-		if err != nil {
-			metadata["description"] = "Failed to import in-cluster kubeconfig."
-			metadata["error"] = err
-			logrus.Warn("failed to generate in cluster context: ", err)
+		summary.Source = models.K8sContextSourceInCluster
+		cc, err := models.NewK8sContextFromInClusterConfig("in-cluster", mid)
+		if err == models.ErrMesheryNotInCluster {
+			// Neither a kubeconfig nor a cluster, there is nothing to discover
 			return contexts, err
 		}
-		if cc == nil {
-			metadata["description"] = "No contexts detected in the in-cluster kubeconfig."
-			err := fmt.Errorf("nil context generated from in cluster config")
-			logrus.Warn(err)
-			return contexts, err
+		if err == nil && cc == nil {
+			err = fmt.Errorf("nil context generated from in cluster config")
 		}
-		cc.DeploymentType = "in_cluster"
-		conn, err := prov.SaveK8sContext(token, *cc)
 		if err != nil {
-			metadata["description"] = fmt.Sprintf("Unable to establish connection with context \"%s\" at %s", cc.Name, cc.Server)
-			metadata["error"] = err
-			logrus.Warn("failed to save the context for incluster: ", err)
-			return contexts, err
+			summary.errored("in-cluster", "", err)
+		} else {
+			cc.DeploymentType = models.K8sContextDeploymentInCluster
+			cc.Source = models.K8sContextSourceInCluster
+			discovered = append(discovered, cc)
 		}
-		h.log.Debug(conn)
-		if conn.ShouldConnectionBeManaged() {
-			cc.ConnectionID = conn.ID.String()
-			contexts = append(contexts, cc)
-			metadata["context"] = models.RedactCredentialsForContext(cc)
-			eventMetadata["in-cluster"] = metadata
+	} else {
+		cfg, err := helpers.FlattenMinifyKubeConfig([]byte(data))
+		if err != nil {
+			return contexts, err
 		}
-		return contexts, nil
-	}
 
-	cfg, err := helpers.FlattenMinifyKubeConfig([]byte(data))
-	if err != nil {
-		return contexts, err
+		eventMetadata := map[string]interface{}{}
+		discovered = models.K8sContextsFromKubeconfig(prov, userID, h.config.EventBroadcaster, cfg, mid, eventMetadata)
+		// The contexts which could not be connected to are left out of the discovered ones
+		for _, result := range unreachableK8sContextsRegistrationResults(discovered, eventMetadata) {
+			summary.errored(result.ContextName, "", errors.New(result.Error))
+		}
+		for _, ctx := range discovered {
+			ctx.DeploymentType = models.K8sContextDeploymentOutOfCluster
+			ctx.Source = source
+		}
 	}
 
-	ctxs := models.K8sContextsFromKubeconfig(prov, userID, h.config.EventBroadcaster, cfg, mid, eventMetadata)
-
-	// Do not persist the generated contexts
-	// consolidate this func and addK8sConfig. In this we explicitly updated status as well as this func perfomr greeedy upload so while consolidating make sure to handle the case.
-	for _, ctx := range ctxs {
-		metadata := map[string]interface{}{}
-		metadata["context"] = models.RedactCredentialsForContext(ctx)
-		metadata["description"] = fmt.Sprintf("K8S context \"%s\" discovered with cluster at %s", ctx.Name, ctx.Server)
-		metadata["description"] = fmt.Sprintf("Connection established with context \"%s\" at %s", ctx.Name, ctx.Server)
-		ctx.DeploymentType = "out_of_cluster"
-		conn, err := prov.SaveK8sContext(token, *ctx)
+	for _, ctx := range discovered {
+		conn, err := saveK8sContextWithRetry(prov, token, *ctx)
 		if err != nil {
-			logrus.Warn("failed to save the context: ", err)
-			metadata["description"] = fmt.Sprintf("Unable to establish connection with context \"%s\" at %s", ctx.Name, ctx.Server)
-			metadata["error"] = err
+			summary.errored(ctx.Name, ctx.Server, err)
 			continue
 		}
 		ctx.ConnectionID = conn.ID.String()
-		h.log.Debug(conn)
+		summary.saved(ctx)
 		if conn.ShouldConnectionBeManaged() {
 			contexts = append(contexts, ctx)
 		}
 	}
+
+	h.publishK8sDiscoverySummary(prov, uuid.FromStringOrNil(userID), *mid, summary)
 	return contexts, nil
 }
 
+// publishK8sDiscoverySummary emits the event reporting the outcome of each discovered context, a warning when some errored
+func (h *Handler) publishK8sDiscoverySummary(prov models.Provider, userID, instanceID uuid.UUID, summary *K8sDiscoverySummary) {
+	sort.SliceStable(summary.Outcomes, func(i, j int) bool {
+		return summary.Outcomes[i].Context < summary.Outcomes[j].Context
+	})
+	severity := events.Informational
+	if summary.Errored > 0 {
+		severity = events.Warning
+	}
+	event := events.NewEvent().FromUser(userID).FromSystem(instanceID).WithCategory("connection").WithAction("discovery").WithSeverity(severity).
+		WithDescription(fmt.Sprintf("Kubernetes contexts discovered from %s: %d saved, %d errored", summary.Source, summary.Saved, summary.Errored)).
+		WithMetadata(map[string]interface{}{
+			"source":   summary.Source,
+			"saved":    summary.Saved,
+			"errored":  summary.Errored,
+			"contexts": summary.Outcomes,
+		}).Build()
+	h.persistEvent(prov, event)
+	go h.config.EventBroadcaster.Publish(userID, event)
+}
+
+// saveK8sContextWithRetry saves the context retrying with exponential backoff, so that the discovery
+// at startup is resilient to a provider which is still warming up. Only the transient failures are retried,
+// see models.IsTransientProviderError.
+// The number of attempts and the initial backoff are configurable through "K8S_CONTEXT_SAVE_RETRIES" and "K8S_CONTEXT_SAVE_BACKOFF".
+func saveK8sContextWithRetry(prov models.Provider, token string, k8sContext models.K8sContext) (connections.Connection, error) {
+	backoff := wait.Backoff{
+		Steps:    viper.GetInt("K8S_CONTEXT_SAVE_RETRIES"),
+		Duration: viper.GetDuration("K8S_CONTEXT_SAVE_BACKOFF"),
+		Factor:   2,
+		Jitter:   0.1,
+		Cap:      30 * time.Second,
+	}
+	if backoff.Steps < 1 {
+		backoff.Steps = 1
+	}
+
+	k8sContext = models.CompressK8sContextForStorage(k8sContext)
+	var conn connections.Connection
+	attempt := 0
+	err := retry.OnError(backoff, models.IsTransientProviderError, func() error {
+		attempt++
+		var err error
+		conn, err = prov.SaveK8sContext(token, k8sContext)
+		if models.IsTransientProviderError(err) && attempt < backoff.Steps {
+			logrus.Warnf("failed to save the context %s (attempt %d of %d), retrying: %v", k8sContext.Name, attempt, backoff.Steps, err)
+		}
+		return err
+	})
+	return conn, err
+}
+
+// maxDecompressedK8sConfigSize caps the size of a gzip compressed kubeconfig after decompression
+// to guard against decompression bombs.
+const maxDecompressedK8sConfigSize = 32 << 20
+
+// gzipMagic are the leading bytes of every gzip stream.
+var gzipMagic = []byte{0x1f, 0x8b}
+
 func readK8sConfigFromBody(req *http.Request) (*[]byte, error) {
 	_ = req.ParseMultipartForm(1 << 20)
 
-	k8sfile, _, err := req.FormFile("k8sfile")
+	k8sfile, header, err := req.FormFile("k8sfile")
 	if err != nil {
 		return nil, ErrFormFile(err)
 	}
@@ -414,5 +1556,39 @@ func readK8sConfigFromBody(req *http.Request) (*[]byte, error) {
 	if err != nil {
 		return nil, ErrReadConfig(err)
 	}
+
+	// The kubeconfig is treated as gzip compressed if the request or the form part declares so, or if it begins with the gzip magic bytes.
+	isGzip := req.Header.Get("Content-Encoding") == "gzip" || header.Header.Get("Content-Encoding") == "gzip" || bytes.HasPrefix(k8sConfigBytes, gzipMagic)
+	if isGzip {
+		k8sConfigBytes, err = decompressK8sConfig(k8sConfigBytes)
+		if err != nil {
+			return nil, ErrDecompressConfig(err)
+		}
+	}
+
+	k8sConfigBytes, err = models.NormalizeKubeconfig(k8sConfigBytes)
+	if err != nil {
+		return nil, err
+	}
 	return &k8sConfigBytes, nil
 }
+
+func decompressK8sConfig(data []byte) ([]byte, error) {
+	gr, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = gr.Close()
+	}()
+
+	// Read one byte past the limit to detect configs exceeding it.
+	decompressed, err := io.ReadAll(io.LimitReader(gr, maxDecompressedK8sConfigSize+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(decompressed) > maxDecompressedK8sConfigSize {
+		return nil, fmt.Errorf("decompressed kubeconfig exceeds the limit of %d bytes", maxDecompressedK8sConfigSize)
+	}
+	return decompressed, nil
+}
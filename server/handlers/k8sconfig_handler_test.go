@@ -0,0 +1,870 @@
+package handlers
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gofrs/uuid"
+	"github.com/layer5io/meshery/server/helpers"
+	"github.com/layer5io/meshery/server/internal/sql"
+	"github.com/layer5io/meshery/server/machines"
+	"github.com/layer5io/meshery/server/models"
+	"github.com/layer5io/meshery/server/models/connections"
+	"github.com/layer5io/meshkit/database"
+	"github.com/layer5io/meshkit/logger"
+	"github.com/layer5io/meshkit/models/controllers"
+	"github.com/layer5io/meshkit/models/events"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/viper"
+	"k8s.io/client-go/tools/clientcmd"
+)
+
+const testKubeconfig = `apiVersion: v1
+kind: Config
+clusters:
+- cluster:
+    server: https://127.0.0.1:6443
+  name: test
+contexts:
+- context:
+    cluster: test
+    user: test
+  name: test
+current-context: test
+users:
+- name: test
+  user:
+    token: abc
+`
+
+func newK8sConfigUploadRequest(t *testing.T, content []byte, headers map[string]string) *http.Request {
+	t.Helper()
+	body := &bytes.Buffer{}
+	mw := multipart.NewWriter(body)
+	fw, err := mw.CreateFormFile("k8sfile", "config")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := fw.Write(content); err != nil {
+		t.Fatal(err)
+	}
+	if err := mw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	req := httptest.NewRequest(http.MethodPost, "/api/system/kubernetes", body)
+	req.Header.Set("Content-Type", mw.FormDataContentType())
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+	return req
+}
+
+func gzipBytes(t *testing.T, data []byte) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	gw := gzip.NewWriter(&buf)
+	if _, err := gw.Write(data); err != nil {
+		t.Fatal(err)
+	}
+	if err := gw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestReadK8sConfigFromBody(t *testing.T) {
+	tests := []struct {
+		name    string
+		content []byte
+		headers map[string]string
+	}{
+		{
+			name:    "uncompressed",
+			content: []byte(testKubeconfig),
+		},
+		{
+			name:    "gzip detected by magic bytes",
+			content: gzipBytes(t, []byte(testKubeconfig)),
+		},
+		{
+			name:    "gzip declared by Content-Encoding",
+			content: gzipBytes(t, []byte(testKubeconfig)),
+			headers: map[string]string{"Content-Encoding": "gzip"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := readK8sConfigFromBody(newK8sConfigUploadRequest(t, tt.content, tt.headers))
+			if err != nil {
+				t.Fatalf("readK8sConfigFromBody() failed with error: %s", err)
+			}
+			if string(*got) != testKubeconfig {
+				t.Errorf("readK8sConfigFromBody() = %q, want %q", string(*got), testKubeconfig)
+			}
+		})
+	}
+}
+
+func TestReadK8sConfigFromBodyDecompressionBomb(t *testing.T) {
+	bomb := gzipBytes(t, make([]byte, maxDecompressedK8sConfigSize+1))
+	if _, err := readK8sConfigFromBody(newK8sConfigUploadRequest(t, bomb, nil)); err == nil {
+		t.Error("readK8sConfigFromBody() expected an error for a kubeconfig exceeding the decompressed size limit")
+	}
+}
+
+func TestReadK8sConfigFromBodyNormalization(t *testing.T) {
+	headerless := strings.TrimPrefix(testKubeconfig, "apiVersion: v1\nkind: Config\n")
+	got, err := readK8sConfigFromBody(newK8sConfigUploadRequest(t, []byte(headerless), nil))
+	if err != nil {
+		t.Fatalf("readK8sConfigFromBody() failed with error: %s", err)
+	}
+	cfg, err := clientcmd.Load(*got)
+	if err != nil {
+		t.Fatalf("clientcmd.Load() of the normalized kubeconfig failed with error: %s", err)
+	}
+	if cfg.CurrentContext != "test" || cfg.Clusters["test"] == nil {
+		t.Errorf("normalized kubeconfig lost its contents: %s", string(*got))
+	}
+	if !strings.HasPrefix(string(*got), "apiVersion: v1\nkind: Config\n") {
+		t.Errorf("readK8sConfigFromBody() = %q, want apiVersion and kind injected", string(*got))
+	}
+
+	for name, content := range map[string]string{
+		"manifest":  "apiVersion: v1\nkind: Pod\nmetadata:\n  name: test\n",
+		"unrelated": "foo: bar\n",
+	} {
+		if _, err := readK8sConfigFromBody(newK8sConfigUploadRequest(t, []byte(content), nil)); err == nil {
+			t.Errorf("readK8sConfigFromBody() expected an error for %s", name)
+		}
+	}
+}
+
+func TestSaveK8sContextResponseSort(t *testing.T) {
+	resp := SaveK8sContextResponse{
+		RegisteredContexts: []models.K8sContext{{Name: "staging"}, {Name: "dev"}, {Name: "prod"}},
+		ErroredContexts:    []models.K8sContext{{Name: "b"}, {Name: "a"}},
+	}
+	resp.sort()
+
+	if got := []string{resp.RegisteredContexts[0].Name, resp.RegisteredContexts[1].Name, resp.RegisteredContexts[2].Name}; got[0] != "dev" || got[1] != "prod" || got[2] != "staging" {
+		t.Errorf("registered contexts ordered as %v, want [dev prod staging]", got)
+	}
+	if resp.ErroredContexts[0].Name != "a" || resp.ErroredContexts[1].Name != "b" {
+		t.Errorf("errored contexts ordered as [%s %s], want [a b]", resp.ErroredContexts[0].Name, resp.ErroredContexts[1].Name)
+	}
+}
+
+func TestK8sContextSaveStream(t *testing.T) {
+	rec := httptest.NewRecorder()
+	stream := newK8sContextSaveStream(rec)
+	stream.progress(&models.K8sContext{Name: "dev"}, "DISCOVERED", map[string]interface{}{"description": "registered"})
+	stream.progress(&models.K8sContext{Name: "prod"}, k8sContextSaveErrored, map[string]interface{}{"error": errors.New("unreachable")})
+	stream.summary(SaveK8sContextResponse{KubeconfigFlattened: true})
+
+	if !rec.Flushed {
+		t.Error("expected the lines to be flushed")
+	}
+	if ct := rec.Header().Get("Content-Type"); ct != "application/x-ndjson" {
+		t.Errorf("content type = %s, want application/x-ndjson", ct)
+	}
+
+	var lines []string
+	scanner := bufio.NewScanner(rec.Body)
+	for scanner.Scan() {
+		lines = append(lines, scanner.Text())
+	}
+	if len(lines) != 3 {
+		t.Fatalf("got %d lines, want 3: %v", len(lines), lines)
+	}
+	var progress K8sContextSaveProgress
+	if err := json.Unmarshal([]byte(lines[1]), &progress); err != nil {
+		t.Fatal(err)
+	}
+	if progress.Context.Name != "prod" || progress.Status != k8sContextSaveErrored || progress.Error != "unreachable" {
+		t.Errorf("progress = %+v, want errored prod", progress)
+	}
+	var summary struct {
+		Summary SaveK8sContextResponse `json:"summary"`
+	}
+	if err := json.Unmarshal([]byte(lines[2]), &summary); err != nil || !summary.Summary.KubeconfigFlattened {
+		t.Errorf("last line = %s, want the summary", lines[2])
+	}
+}
+
+func TestDiscoveryKubeconfig(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "config"), []byte("from-file"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	h := &Handler{config: &models.HandlerConfig{KubeConfigFolder: dir}}
+	t.Cleanup(func() { viper.Set("KUBECONFIG_CONTENT", "") })
+
+	viper.Set("KUBECONFIG_CONTENT", base64.StdEncoding.EncodeToString([]byte(testKubeconfig)))
+	data, source, err := h.discoveryKubeconfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if source != models.K8sContextSourceEnv || data != testKubeconfig {
+		t.Errorf("expected the kubeconfig of KUBECONFIG_CONTENT, got %q from %s", data, source)
+	}
+	if _, err := helpers.FlattenMinifyKubeConfig([]byte(data)); err != nil {
+		t.Errorf("expected the decoded kubeconfig to flatten, got %s", err)
+	}
+
+	viper.Set("KUBECONFIG_CONTENT", "not base64!")
+	if _, source, err := h.discoveryKubeconfig(); err == nil || source != models.K8sContextSourceEnv {
+		t.Error("expected an error for a KUBECONFIG_CONTENT which is not base64 encoded")
+	}
+
+	viper.Set("KUBECONFIG_CONTENT", "")
+	data, source, err = h.discoveryKubeconfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if source != models.K8sContextSourceFilesystem || data != "from-file" {
+		t.Errorf("expected the kubeconfig of the config folder, got %q from %s", data, source)
+	}
+}
+
+func TestDiscoveryKubeconfigDirectory(t *testing.T) {
+	dir := t.TempDir()
+	prodKubeconfig := strings.ReplaceAll(testKubeconfig, "test", "prod")
+	files := map[string]string{
+		"dev":       testKubeconfig,
+		"prod":      prodKubeconfig,
+		"README.md": "# kubeconfigs of the clusters: [",
+		".hidden":   strings.ReplaceAll(testKubeconfig, "test", "hidden"),
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	h := &Handler{config: &models.HandlerConfig{KubeConfigFolder: dir}}
+	viper.Set("KUBECONFIG_DIRECTORY_MODE", true)
+	t.Cleanup(func() { viper.Set("KUBECONFIG_DIRECTORY_MODE", false) })
+
+	data, source, err := h.discoveryKubeconfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if source != models.K8sContextSourceFilesystem {
+		t.Errorf("expected the filesystem source, got %s", source)
+	}
+	cfg, err := clientcmd.Load([]byte(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(cfg.Contexts) != 2 || cfg.Contexts["test"] == nil || cfg.Contexts["prod"] == nil {
+		t.Errorf("expected the contexts of dev and prod, got %v", cfg.Contexts)
+	}
+	if cfg.CurrentContext != "test" {
+		t.Errorf("expected the current-context of the first file, got %s", cfg.CurrentContext)
+	}
+
+	if _, _, err := (&Handler{config: &models.HandlerConfig{KubeConfigFolder: t.TempDir()}}).discoveryKubeconfig(); err == nil {
+		t.Error("expected an error for a directory without kubeconfigs")
+	}
+}
+
+type nonPersistingProvider struct {
+	models.Provider
+}
+
+func (nonPersistingProvider) SupportsK8sContextPersistence() bool { return false }
+
+func TestAddK8SConfigUnsupportedProvider(t *testing.T) {
+	h := &Handler{}
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPost, "/api/system/kubernetes", nil)
+	h.addK8SConfig(&models.User{}, nil, w, req, nonPersistingProvider{})
+
+	if w.Code != http.StatusNotImplemented {
+		t.Errorf("addK8SConfig() status = %d, want %d", w.Code, http.StatusNotImplemented)
+	}
+}
+
+func TestK8SConfigHandlerMethodNotAllowed(t *testing.T) {
+	h := &Handler{}
+	for _, method := range []string{http.MethodGet, http.MethodPut, http.MethodDelete} {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(method, "/api/system/kubernetes", nil)
+		h.K8SConfigHandler(w, req, nil, &models.User{}, nil)
+
+		if w.Code != http.StatusMethodNotAllowed {
+			t.Errorf("%s status = %d, want %d", method, w.Code, http.StatusMethodNotAllowed)
+		}
+		if allow := w.Header().Get("Allow"); allow != "POST" {
+			t.Errorf("%s Allow = %q, want %q", method, allow, "POST")
+		}
+	}
+}
+
+// storedContextProvider returns the stored context and records the events persisted
+type storedContextProvider struct {
+	models.Provider
+	k8sContext models.K8sContext
+	events     []*events.Event
+}
+
+func (p *storedContextProvider) GetK8sContext(_, _ string) (models.K8sContext, error) {
+	return p.k8sContext, nil
+}
+
+func (p *storedContextProvider) PersistEvent(event *events.Event) error {
+	p.events = append(p.events, event)
+	return nil
+}
+
+func TestKubernetesPingHandlerCorruptContext(t *testing.T) {
+	log, err := logger.New("test", logger.Options{LogLevel: int(logrus.InfoLevel)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	systemID := uuid.Must(uuid.NewV4())
+	h := &Handler{log: log, SystemID: &systemID, config: &models.HandlerConfig{EventBroadcaster: models.NewBroadcaster()}}
+	// The connection was stored with its cluster but without its credentials
+	provider := &storedContextProvider{k8sContext: models.K8sContext{
+		Name:    "prod",
+		Server:  "https://127.0.0.1:6443",
+		Cluster: sql.Map{"name": "prod", "cluster": map[string]interface{}{"server": "https://127.0.0.1:6443"}},
+		Auth:    sql.Map{"name": "prod", "user": map[string]interface{}{}},
+	}}
+	connectionID := uuid.Must(uuid.NewV4()).String()
+
+	req := httptest.NewRequest(http.MethodGet, "/api/system/kubernetes/ping?connection_id="+connectionID, nil)
+	req = req.WithContext(context.WithValue(req.Context(), models.TokenCtxKey, "token"))
+	w := httptest.NewRecorder()
+	h.KubernetesPingHandler(w, req, nil, &models.User{ID: uuid.Must(uuid.NewV4()).String()}, provider)
+
+	if w.Code != http.StatusUnprocessableEntity {
+		t.Errorf("KubernetesPingHandler() status = %d, want %d", w.Code, http.StatusUnprocessableEntity)
+	}
+	if !strings.Contains(w.Body.String(), "missing its credentials") {
+		t.Errorf("KubernetesPingHandler() body = %q, want the missing credentials identified", w.Body.String())
+	}
+	if len(provider.events) != 1 || provider.events[0].Severity != events.Warning || provider.events[0].ActedUpon.String() != connectionID {
+		t.Errorf("events = %+v, want a warning about the connection", provider.events)
+	}
+}
+
+func TestK8sRegistrationStream(t *testing.T) {
+	stream := &k8sRegistrationStream{}
+	// Components may be registered before the response is written
+	stream.progress(models.K8sComponentRegistrationOutcome{ContextID: "ctx", Kind: "Pod", APIVersion: "v1", Status: models.K8sComponentRegistered})
+
+	w := httptest.NewRecorder()
+	stream.open(w)
+	stream.progress(models.K8sComponentRegistrationOutcome{ContextID: "ctx", Kind: "Widget", APIVersion: "example.com/v1", Status: models.K8sComponentFailed, Error: "registry unavailable"})
+	stream.close([]models.K8sRegistrationResult{{ContextID: "ctx", Status: models.K8sRegistrationSucceeded, ComponentsCount: 1}}, true)
+	// The registration may go on in the background once the stream is closed
+	stream.progress(models.K8sComponentRegistrationOutcome{ContextID: "ctx", Kind: "Service", APIVersion: "v1", Status: models.K8sComponentRegistered})
+
+	if ct := w.Header().Get("Content-Type"); ct != "application/x-ndjson" {
+		t.Errorf("Content-Type = %q, want application/x-ndjson", ct)
+	}
+	lines := strings.Split(strings.TrimSpace(w.Body.String()), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("streamed %d lines, want 2 components and the summary: %s", len(lines), w.Body.String())
+	}
+	var outcome models.K8sComponentRegistrationOutcome
+	if err := json.Unmarshal([]byte(lines[1]), &outcome); err != nil || outcome.Kind != "Widget" || outcome.Status != models.K8sComponentFailed || outcome.Error == "" {
+		t.Errorf("second line = %s, want the failure of Widget", lines[1])
+	}
+	var summary struct {
+		Summary  []models.K8sRegistrationResult `json:"summary"`
+		Finished bool                           `json:"finished"`
+	}
+	if err := json.Unmarshal([]byte(lines[2]), &summary); err != nil || len(summary.Summary) != 1 || !summary.Finished {
+		t.Errorf("last line = %s, want the summary of the registration", lines[2])
+	}
+}
+
+func TestK8sConfigHandlersWithoutToken(t *testing.T) {
+	log, err := logger.New("test", logger.Options{LogLevel: int(logrus.InfoLevel)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	systemID := uuid.Must(uuid.NewV4())
+	h := &Handler{log: log, SystemID: &systemID, config: &models.HandlerConfig{EventBroadcaster: models.NewBroadcaster()}}
+	user := &models.User{ID: uuid.Must(uuid.NewV4()).String()}
+	provider := &storedContextProvider{}
+
+	tests := []struct {
+		name    string
+		req     *http.Request
+		handler func(http.ResponseWriter, *http.Request, *models.Preference, *models.User, models.Provider)
+	}{
+		{"upload", httptest.NewRequest(http.MethodPost, "/api/system/kubernetes", strings.NewReader(testKubeconfig)), h.K8SConfigHandler},
+		{"ping", httptest.NewRequest(http.MethodGet, "/api/system/kubernetes/ping?connection_id="+uuid.Must(uuid.NewV4()).String(), nil), h.KubernetesPingHandler},
+		{"registration", httptest.NewRequest(http.MethodPost, "/api/system/meshmodels/components/register?environment=prod", nil), h.K8sRegistrationHandler},
+		{"rbac", httptest.NewRequest(http.MethodGet, "/api/system/kubernetes/contexts/"+uuid.Must(uuid.NewV4()).String()+"/meshery-rbac", nil), h.MesheryRBACCheckHandler},
+		{"primary", httptest.NewRequest(http.MethodGet, "/api/system/kubernetes/primary", nil), h.PrimaryK8sContextHandler},
+		{"stats", httptest.NewRequest(http.MethodGet, "/api/system/kubernetes/stats", nil), h.K8sStatsHandler},
+		{"reconcile", httptest.NewRequest(http.MethodPost, "/api/system/kubernetes/contexts/reconcile", strings.NewReader(testKubeconfig)), h.K8sContextsReconcileHandler},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			tt.handler(w, tt.req, nil, user, provider)
+
+			if w.Code != http.StatusUnauthorized {
+				t.Errorf("status = %d, want %d", w.Code, http.StatusUnauthorized)
+			}
+			var body map[string]string
+			if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+				t.Fatalf("response is not JSON: %s", w.Body.String())
+			}
+			if body["code"] != ErrRetrieveUserTokenCode || body["error"] == "" {
+				t.Errorf("response = %v, want the error retrieving the user token", body)
+			}
+		})
+	}
+}
+
+func TestKubernetesPingHandlerNamespace(t *testing.T) {
+	// The credentials are scoped to the namespace team-a, the server version is forbidden
+	apiServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v1/namespaces/team-a/pods" {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusForbidden)
+			_, _ = w.Write([]byte(`{"kind":"Status","apiVersion":"v1","status":"Failure","reason":"Forbidden","code":403}`))
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"kind":"PodList","apiVersion":"v1","items":[]}`))
+	}))
+	defer apiServer.Close()
+
+	log, err := logger.New("test", logger.Options{LogLevel: int(logrus.InfoLevel)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	systemID := uuid.Must(uuid.NewV4())
+	h := &Handler{
+		log:                                     log,
+		SystemID:                                &systemID,
+		config:                                  &models.HandlerConfig{EventBroadcaster: models.NewBroadcaster()},
+		ConnectionToStateMachineInstanceTracker: &machines.ConnectionToStateMachineInstanceTracker{ConnectToInstanceMap: map[uuid.UUID]*machines.StateMachine{}},
+	}
+	provider := &storedContextProvider{k8sContext: models.K8sContext{
+		Name:    "team-a",
+		Server:  apiServer.URL,
+		Cluster: sql.Map{"name": "team-a", "cluster": map[string]interface{}{"server": apiServer.URL}},
+		Auth:    sql.Map{"name": "team-a", "user": map[string]interface{}{"token": "abc"}},
+	}}
+	ping := func(namespace string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/api/system/kubernetes/ping?connection_id="+uuid.Must(uuid.NewV4()).String()+"&namespace="+namespace, nil)
+		req = req.WithContext(context.WithValue(req.Context(), models.TokenCtxKey, "token"))
+		w := httptest.NewRecorder()
+		h.KubernetesPingHandler(w, req, nil, &models.User{ID: uuid.Must(uuid.NewV4()).String()}, provider)
+		return w
+	}
+
+	tests := []struct {
+		namespace string
+		reachable bool
+	}{
+		{"team-a", true},
+		{"team-b", false},
+	}
+	for _, tt := range tests {
+		w := ping(tt.namespace)
+		if w.Code != http.StatusOK {
+			t.Fatalf("ping of namespace %s status = %d, want %d: %s", tt.namespace, w.Code, http.StatusOK, w.Body.String())
+		}
+		var response map[string]interface{}
+		if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+			t.Fatal(err)
+		}
+		if response["namespace"] != tt.namespace || response["reachable"] != tt.reachable {
+			t.Errorf("ping of namespace %s = %v, want reachable %t", tt.namespace, response, tt.reachable)
+		}
+		if _, ok := response["error"]; ok == tt.reachable {
+			t.Errorf("ping of namespace %s = %v, want the error only when not reachable", tt.namespace, response)
+		}
+	}
+
+	if w := ping("Team_A"); w.Code != http.StatusBadRequest {
+		t.Errorf("ping of an invalid namespace status = %d, want %d", w.Code, http.StatusBadRequest)
+	}
+}
+
+func TestKubernetesPingHandlerCachesServerVersion(t *testing.T) {
+	var requests int32
+	apiServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"major":"1","minor":"29","gitVersion":"v1.29.0"}`))
+	}))
+	defer apiServer.Close()
+	viper.Set("KUBERNETES_PING_CACHE_TTL", time.Minute)
+	t.Cleanup(func() { viper.Set("KUBERNETES_PING_CACHE_TTL", 0) })
+
+	log, err := logger.New("test", logger.Options{LogLevel: int(logrus.InfoLevel)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	systemID := uuid.Must(uuid.NewV4())
+	h := &Handler{
+		log:                                     log,
+		SystemID:                                &systemID,
+		config:                                  &models.HandlerConfig{EventBroadcaster: models.NewBroadcaster()},
+		ConnectionToStateMachineInstanceTracker: &machines.ConnectionToStateMachineInstanceTracker{ConnectToInstanceMap: map[uuid.UUID]*machines.StateMachine{}},
+	}
+	provider := &storedContextProvider{k8sContext: models.K8sContext{
+		Name:    "cached",
+		Server:  apiServer.URL,
+		Cluster: sql.Map{"name": "cached", "cluster": map[string]interface{}{"server": apiServer.URL}},
+		Auth:    sql.Map{"name": "cached", "user": map[string]interface{}{"token": "abc"}},
+	}}
+	connectionID := uuid.Must(uuid.NewV4()).String()
+	ping := func(query string) map[string]interface{} {
+		req := httptest.NewRequest(http.MethodGet, "/api/system/kubernetes/ping?connection_id="+connectionID+query, nil)
+		req = req.WithContext(context.WithValue(req.Context(), models.TokenCtxKey, "token"))
+		w := httptest.NewRecorder()
+		h.KubernetesPingHandler(w, req, nil, &models.User{ID: uuid.Must(uuid.NewV4()).String()}, provider)
+		if w.Code != http.StatusOK {
+			t.Fatalf("ping status = %d, want %d: %s", w.Code, http.StatusOK, w.Body.String())
+		}
+		var response map[string]interface{}
+		if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+			t.Fatal(err)
+		}
+		return response
+	}
+
+	if response := ping(""); response["cached"] != false || response["server_version"] != "v1.29.0" {
+		t.Errorf("first ping = %v, want the version fetched from the cluster", response)
+	}
+	fetched := atomic.LoadInt32(&requests)
+	if response := ping(""); response["cached"] != true || response["server_version"] != "v1.29.0" {
+		t.Errorf("second ping = %v, want the cached version", response)
+	}
+	if got := atomic.LoadInt32(&requests); got != fetched {
+		t.Errorf("second ping made %d requests to the cluster, want none", got-fetched)
+	}
+	if response := ping("&fresh=true"); response["cached"] != false {
+		t.Errorf("fresh ping = %v, want the version fetched from the cluster", response)
+	}
+	if got := atomic.LoadInt32(&requests); got == fetched {
+		t.Error("fresh ping made no request to the cluster, want the cache bypassed")
+	}
+}
+
+// discoveryProvider saves the contexts other than the ones of failNames
+type discoveryProvider struct {
+	storedContextProvider
+	failNames map[string]bool
+}
+
+func (p *discoveryProvider) SaveK8sContext(_ string, k8sContext models.K8sContext) (connections.Connection, error) {
+	if p.failNames[k8sContext.Name] {
+		return connections.Connection{}, errors.New("provider unavailable")
+	}
+	return connections.Connection{ID: uuid.Must(uuid.NewV4()), Status: connections.DISCOVERED}, nil
+}
+
+func TestDiscoverK8SContextFromKubeConfigSummary(t *testing.T) {
+	apiServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/api/v1/namespaces/kube-system":
+			_, _ = w.Write([]byte(`{"kind":"Namespace","apiVersion":"v1","metadata":{"name":"kube-system","uid":"` + uuid.Must(uuid.NewV4()).String() + `"}}`))
+		case "/version":
+			_, _ = w.Write([]byte(`{"major":"1","minor":"29","gitVersion":"v1.29.0"}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer apiServer.Close()
+	kubeconfig := `apiVersion: v1
+kind: Config
+clusters:
+- cluster:
+    server: ` + apiServer.URL + `
+  name: reachable
+- cluster:
+    server: http://127.0.0.1:1
+  name: unreachable
+contexts:
+- context: {cluster: reachable, user: test}
+  name: saved
+- context: {cluster: reachable, user: test}
+  name: rejected
+- context: {cluster: unreachable, user: test}
+  name: unreachable
+current-context: saved
+users:
+- name: test
+  user:
+    token: abc
+`
+	instanceID := uuid.Must(uuid.NewV4())
+	viper.Set("INSTANCE_ID", &instanceID)
+	viper.Set("KUBECONFIG_CONTENT", base64.StdEncoding.EncodeToString([]byte(kubeconfig)))
+	t.Cleanup(func() { viper.Set("KUBECONFIG_CONTENT", "") })
+
+	log, err := logger.New("test", logger.Options{LogLevel: int(logrus.InfoLevel)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	h := &Handler{log: log, SystemID: &instanceID, config: &models.HandlerConfig{EventBroadcaster: models.NewBroadcaster()}}
+	provider := &discoveryProvider{failNames: map[string]bool{"rejected": true}}
+
+	contexts, err := h.DiscoverK8SContextFromKubeConfig(uuid.Must(uuid.NewV4()).String(), "token", provider)
+	if err != nil {
+		t.Fatalf("DiscoverK8SContextFromKubeConfig() failed with error: %s", err)
+	}
+	if len(contexts) != 1 || contexts[0].Name != "saved" {
+		t.Errorf("DiscoverK8SContextFromKubeConfig() = %v, want the saved context only", contexts)
+	}
+	if len(provider.events) != 1 {
+		t.Fatalf("%d events emitted, want a single summary", len(provider.events))
+	}
+	event := provider.events[0]
+	if event.Action != "discovery" || event.Severity != events.Warning {
+		t.Errorf("summary event = %+v, want a discovery warning", event)
+	}
+	outcomes, _ := event.Metadata["contexts"].([]K8sDiscoveryOutcome)
+	statuses := []string{}
+	for _, outcome := range outcomes {
+		statuses = append(statuses, outcome.Context+"="+outcome.Status)
+		if outcome.Status == K8sDiscoveryErrored && outcome.Error == "" {
+			t.Errorf("outcome of %s has no error", outcome.Context)
+		}
+	}
+	if got := strings.Join(statuses, ","); got != "rejected=errored,saved=saved,unreachable=errored" {
+		t.Errorf("outcomes = %s, want every context reported", got)
+	}
+}
+
+func TestCurrentK8sContextDiscoveryFailure(t *testing.T) {
+	eventMetadata := map[string]interface{}{
+		"test": map[string]interface{}{
+			"context":     models.RedactCredentialsForContext(&models.K8sContext{Name: "test", Server: "https://127.0.0.1:6443"}),
+			"description": "Unable to establish connection with context \"test\" at https://127.0.0.1:6443",
+			"error":       errors.New("connection refused"),
+		},
+	}
+	failure := currentK8sContextDiscoveryFailure([]byte(testKubeconfig), []*models.K8sContext{{Name: "other"}}, eventMetadata)
+	if failure == nil || failure.Context != "test" || failure.Server != "https://127.0.0.1:6443" || failure.Error != "connection refused" {
+		t.Errorf("currentK8sContextDiscoveryFailure() = %+v, want the failure of the current-context test", failure)
+	}
+	if failure := currentK8sContextDiscoveryFailure([]byte(testKubeconfig), []*models.K8sContext{{Name: "test", IsCurrentContext: true}}, eventMetadata); failure != nil {
+		t.Errorf("currentK8sContextDiscoveryFailure() = %+v, want no failure once the current-context is discovered", failure)
+	}
+
+	contexts := []*models.K8sContext{{Name: "a"}, {Name: "b"}, {Name: "test", IsCurrentContext: true}}
+	currentK8sContextFirst(contexts)
+	if contexts[0].Name != "test" || contexts[1].Name != "a" || contexts[2].Name != "b" {
+		t.Errorf("currentK8sContextFirst() = [%s %s %s], want [test a b]", contexts[0].Name, contexts[1].Name, contexts[2].Name)
+	}
+}
+
+func TestK8sCurrentContextPolicy(t *testing.T) {
+	viper.Set("KUBECONFIG_CURRENT_CONTEXT_POLICY", K8sCurrentContextPolicyStrict)
+	defer viper.Set("KUBECONFIG_CURRENT_CONTEXT_POLICY", nil)
+
+	for query, want := range map[string]string{
+		"":                                    K8sCurrentContextPolicyStrict,
+		"?current_context_policy=best_effort": K8sCurrentContextPolicyBestEffort,
+		"?current_context_policy=strict":      K8sCurrentContextPolicyStrict,
+	} {
+		policy, err := k8sCurrentContextPolicy(httptest.NewRequest(http.MethodPost, "/api/system/kubernetes"+query, nil))
+		if err != nil || policy != want {
+			t.Errorf("k8sCurrentContextPolicy(%q) = %q, %v, want %q", query, policy, err, want)
+		}
+	}
+	if _, err := k8sCurrentContextPolicy(httptest.NewRequest(http.MethodPost, "/api/system/kubernetes?current_context_policy=lenient", nil)); err == nil {
+		t.Error("k8sCurrentContextPolicy() expected an error for an unknown policy")
+	}
+}
+
+// flakyProvider fails to save the contexts with the errors in turn
+type flakyProvider struct {
+	models.Provider
+	errs  []error
+	saves int
+}
+
+func (p *flakyProvider) SaveK8sContext(_ string, _ models.K8sContext) (connections.Connection, error) {
+	p.saves++
+	if len(p.errs) == 0 {
+		return connections.Connection{}, nil
+	}
+	err := p.errs[0]
+	p.errs = p.errs[1:]
+	return connections.Connection{}, err
+}
+
+func TestSaveK8sContextWithRetry(t *testing.T) {
+	viper.Set("K8S_CONTEXT_SAVE_RETRIES", 3)
+	viper.Set("K8S_CONTEXT_SAVE_BACKOFF", time.Millisecond)
+	defer viper.Set("K8S_CONTEXT_SAVE_RETRIES", nil)
+	defer viper.Set("K8S_CONTEXT_SAVE_BACKOFF", nil)
+
+	transient := &flakyProvider{errs: []error{
+		models.ErrUnreachableRemoteProvider(errors.New("connection refused")),
+		models.ErrPost(errors.New("failed to save the connection"), "", http.StatusServiceUnavailable),
+	}}
+	if _, err := saveK8sContextWithRetry(transient, "token", models.K8sContext{Name: "staging"}); err != nil || transient.saves != 3 {
+		t.Errorf("saveK8sContextWithRetry() = %v after %d saves, want the transient failures retried", err, transient.saves)
+	}
+
+	for _, err := range []error{
+		models.ErrContextAlreadyPersisted,
+		models.ErrPost(errors.New("failed to save the connection"), "", http.StatusBadRequest),
+		errors.New("invalid context"),
+	} {
+		permanent := &flakyProvider{errs: []error{err}}
+		if _, got := saveK8sContextWithRetry(permanent, "token", models.K8sContext{Name: "staging"}); got != err || permanent.saves != 1 {
+			t.Errorf("saveK8sContextWithRetry() = %v after %d saves, want %v without retrying", got, permanent.saves, err)
+		}
+	}
+}
+
+func TestAddK8SConfigAtomicUnreachableContext(t *testing.T) {
+	log, err := logger.New("test", logger.Options{LogLevel: int(logrus.InfoLevel)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	systemID := uuid.Must(uuid.NewV4())
+	h := &Handler{log: log, SystemID: &systemID, config: &models.HandlerConfig{EventBroadcaster: models.NewBroadcaster()}}
+	provider := &storedContextProvider{}
+
+	// Nothing listens on the server of the kubeconfig, the upload fails before any context is saved
+	kubeconfig := strings.Replace(testKubeconfig, "https://127.0.0.1:6443", "https://127.0.0.1:1", 1)
+	req := newK8sConfigUploadRequest(t, []byte(kubeconfig), nil)
+	req.URL.RawQuery = "atomic=true"
+	req = req.WithContext(context.WithValue(req.Context(), models.TokenCtxKey, "token"))
+	w := httptest.NewRecorder()
+	h.addK8SConfig(&models.User{ID: uuid.Must(uuid.NewV4()).String()}, &models.Preference{}, w, req, provider)
+
+	if w.Code != http.StatusUnprocessableEntity {
+		t.Fatalf("addK8SConfig() status = %d, want %d: %s", w.Code, http.StatusUnprocessableEntity, w.Body.String())
+	}
+	var failure K8sConfigAtomicUploadFailure
+	if err := json.NewDecoder(w.Body).Decode(&failure); err != nil {
+		t.Fatal(err)
+	}
+	if failure.FailedContext != "test" || failure.Error == "" || len(failure.RolledBackConnections) != 0 {
+		t.Errorf("addK8SConfig() failure = %+v, want the unreachable context test reported with nothing rolled back", failure)
+	}
+}
+
+// statusProvider takes the connections to the statuses their machines transition to
+type statusProvider struct {
+	discoveryProvider
+}
+
+func (p *statusProvider) UpdateConnectionStatusByID(_ string, connectionID uuid.UUID, status connections.ConnectionStatus, _ string) (*connections.Connection, int, error) {
+	return &connections.Connection{ID: connectionID, Status: status}, http.StatusOK, nil
+}
+
+func (p *statusProvider) GetGenericPersister() *database.Handler {
+	return nil
+}
+
+// uploadK8sConfigOfFakeCluster uploads a kubeconfig of the contexts saved and rejected of a fake cluster to addK8SConfig,
+// the provider fails to save the rejected context. Returns the response and the server of the cluster.
+func uploadK8sConfigOfFakeCluster(t *testing.T, currentContext, query string, pref *models.Preference) (*httptest.ResponseRecorder, string) {
+	apiServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/api/v1/namespaces/kube-system":
+			_, _ = w.Write([]byte(`{"kind":"Namespace","apiVersion":"v1","metadata":{"name":"kube-system","uid":"` + uuid.Must(uuid.NewV4()).String() + `"}}`))
+		case "/version":
+			_, _ = w.Write([]byte(`{"major":"1","minor":"29","gitVersion":"v1.29.0"}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(apiServer.Close)
+	kubeconfig := `apiVersion: v1
+kind: Config
+clusters:
+- cluster:
+    server: ` + apiServer.URL + `
+  name: test
+contexts:
+- context: {cluster: test, user: test}
+  name: saved
+- context: {cluster: test, user: test}
+  name: rejected
+current-context: ` + currentContext + `
+users:
+- name: test
+  user:
+    token: abc
+`
+	log, err := logger.New("test", logger.Options{LogLevel: int(logrus.InfoLevel)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	systemID := uuid.Must(uuid.NewV4())
+	h := &Handler{
+		log:                                     log,
+		SystemID:                                &systemID,
+		config:                                  &models.HandlerConfig{EventBroadcaster: models.NewBroadcaster(), K8scontextChannel: models.NewContextHelper(), OperatorTracker: models.NewOperatorTracker(true)},
+		ConnectionToStateMachineInstanceTracker: &machines.ConnectionToStateMachineInstanceTracker{ConnectToInstanceMap: map[uuid.UUID]*machines.StateMachine{}},
+		MesheryCtrlsHelper:                      models.NewMesheryControllersHelper(log, controllers.OperatorDeploymentConfig{}, nil),
+	}
+	provider := &statusProvider{discoveryProvider{failNames: map[string]bool{"rejected": true}}}
+	user := &models.User{ID: uuid.Must(uuid.NewV4()).String()}
+
+	req := newK8sConfigUploadRequest(t, []byte(kubeconfig), nil)
+	req.URL.RawQuery = query
+	ctx := context.WithValue(req.Context(), models.TokenCtxKey, "token")
+	ctx = context.WithValue(ctx, models.UserCtxKey, user)
+	req = req.WithContext(context.WithValue(ctx, models.SystemIDKey, &systemID))
+	w := httptest.NewRecorder()
+	h.addK8SConfig(user, pref, w, req, provider)
+	return w, apiServer.URL
+}
+
+func TestAddK8SConfigAtomicRollbackIgnoredByDefault(t *testing.T) {
+	// The strict current-context policy saves the current-context first, so that it is rolled back once the other one fails
+	pref := &models.Preference{K8sConnectionPreferences: &models.K8sConnectionPreferences{DefaultState: connections.IGNORED}}
+	w, _ := uploadK8sConfigOfFakeCluster(t, "saved", "atomic=true&current_context_policy=strict", pref)
+
+	if w.Code != http.StatusUnprocessableEntity {
+		t.Fatalf("addK8SConfig() status = %d, want %d: %s", w.Code, http.StatusUnprocessableEntity, w.Body.String())
+	}
+	var failure K8sConfigAtomicUploadFailure
+	if err := json.NewDecoder(w.Body).Decode(&failure); err != nil {
+		t.Fatal(err)
+	}
+	if failure.FailedContext != "rejected" || len(failure.RolledBackConnections) != 1 {
+		t.Errorf("addK8SConfig() failure = %+v, want the ignored connection of saved rolled back", failure)
+	}
+}
+
+func TestAddK8SConfigCurrentContextSaveFailure(t *testing.T) {
+	w, server := uploadK8sConfigOfFakeCluster(t, "rejected", "current_context_policy=best_effort", &models.Preference{})
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("addK8SConfig() status = %d, want %d: %s", w.Code, http.StatusOK, w.Body.String())
+	}
+	var response SaveK8sContextResponse
+	if err := json.NewDecoder(w.Body).Decode(&response); err != nil {
+		t.Fatal(err)
+	}
+	if failure := response.CurrentContextFailure; failure == nil || failure.Context != "rejected" || failure.Server != server || failure.Error == "" {
+		t.Errorf("addK8SConfig() current_context_failure = %+v, want the failure of rejected at %s", failure, server)
+	}
+}
@@ -0,0 +1,200 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/gofrs/uuid"
+	"github.com/layer5io/meshery/server/models"
+)
+
+// k8sConfigUploadRequest describes the multipart form fields accepted by addK8SConfig.
+// It is not used for decoding, it only backs the served JSON Schema of the request,
+// hence keep it in sync when adding new form fields to the upload.
+type k8sConfigUploadRequest struct {
+	// Kubeconfig file, optionally gzip compressed
+	K8sFile []byte `json:"k8sfile" schema:"required,binary"`
+	// Create one connection per namespace accessible with each of the contexts
+	SplitByNamespace bool `json:"split_by_namespace,omitempty"`
+	// Save only the current-context of the kubeconfig
+	OnlyCurrent bool `json:"only_current,omitempty"`
+	// Mark the connections as managed by automation, only managed connections are pruned by a declarative apply
+	Managed bool `json:"managed,omitempty"`
+	// Stream the outcome of each context as newline-delimited JSON as it is saved, the last line holds the summary
+	Stream bool `json:"stream,omitempty"`
+	// Log level for the processing of this upload only (e.g. "debug"), the logs are tagged with the X-Correlation-ID response header
+	LogLevel string `json:"log_level,omitempty"`
+	// Fail the upload if the external file references of the kubeconfig cannot be inlined
+	RequireFlatten bool `json:"require_flatten,omitempty"`
+	// Save only the contexts whose API server is reachable, unreachable contexts are reported as errored
+	SkipUnreachable bool `json:"skip_unreachable,omitempty"`
+	// Timeout of the reachability probe as a Go duration (e.g. "5s"), used with skip_unreachable
+	ProbeTimeout string `json:"probe_timeout,omitempty"`
+	// API server URL to dial instead of the server of the cluster of server_override_context, the CA and credentials of the kubeconfig are kept
+	ServerOverride string `json:"server_override,omitempty"`
+	// Context whose cluster dials server_override, the current-context of the kubeconfig when empty
+	ServerOverrideContext string `json:"server_override_context,omitempty"`
+	// Proxy used to reach the Kubernetes API servers, schemes http, https, socks5 and socks5h are supported
+	ProxyURL string `json:"proxy_url,omitempty"`
+	// Username used to authenticate with the proxy
+	ProxyUsername string `json:"proxy_username,omitempty"`
+	// Password used to authenticate with the proxy
+	ProxyPassword string `json:"proxy_password,omitempty"`
+	// Timeout of dialing the API servers as a Go duration (e.g. "10s"), defaults to "KUBERNETES_DIAL_TIMEOUT"
+	DialTimeout string `json:"dial_timeout,omitempty"`
+	// Timeout of the TLS handshake with the API servers as a Go duration, defaults to "KUBERNETES_TLS_HANDSHAKE_TIMEOUT"
+	TLSHandshakeTimeout string `json:"tls_handshake_timeout,omitempty"`
+	// Timeout of waiting for the response headers of the API servers as a Go duration, defaults to "KUBERNETES_RESPONSE_HEADER_TIMEOUT"
+	ResponseHeaderTimeout string `json:"response_header_timeout,omitempty"`
+	// Name the certificates of the API servers are verified against instead of the host of their URL, for API servers behind a proxy
+	TLSServerName string `json:"tls_server_name,omitempty"`
+	// Free-text notes of the connections, e.g. "shared staging, do not delete"
+	Notes string `json:"notes,omitempty"`
+	// Interval as a Go duration (e.g. "5m") at which MeshSync resyncs the resources of the clusters, at least "30s"
+	SyncInterval string `json:"sync_interval,omitempty"`
+	// Queries per second the requests to the API servers are limited to, defaults to "KUBERNETES_CLIENT_QPS"
+	QPS float32 `json:"qps,omitempty"`
+	// Burst of requests to the API servers allowed above the qps, defaults to "KUBERNETES_CLIENT_BURST"
+	Burst int `json:"burst,omitempty"`
+	// User impersonated on the clusters instead of the one set by "as" in the kubeconfig, e.g. a restricted service account
+	As string `json:"as,omitempty"`
+	// Comma separated groups impersonated along with the "as" user
+	AsGroups string `json:"as_groups,omitempty"`
+	// Time to live of the connections as a Go duration (e.g. "2h"), expired connections are deleted in the background
+	TTL string `json:"ttl,omitempty"`
+	// Save either all of the contexts or none, the upload fails with 422 and the connections it created are deleted when a context fails
+	Atomic bool `json:"atomic,omitempty"`
+	// Save the contexts of the same cluster and user, differing only by namespace, as a single connection
+	MergeByCluster bool `json:"merge_by_cluster,omitempty"`
+	// Go template naming the connections from the detected metadata of their clusters, e.g. "{{.Distribution}}-{{.Region}}-{{.ShortServerID}}"
+	NameTemplate string `json:"name_template,omitempty"`
+	// Whether the upload fails when its current-context cannot be connected to or saved, defaults to "KUBECONFIG_CURRENT_CONTEXT_POLICY"
+	CurrentContextPolicy string `json:"current_context_policy,omitempty" schema:"enum=best_effort|strict"`
+}
+
+// swagger:route GET /api/system/kubernetes/schema SystemAPI idGetK8SConfigSchema
+// Handle GET request for the JSON Schemas of the kubernetes config upload
+//
+// Returns JSON Schemas describing the multipart request accepted by POST /api/system/kubernetes and the SaveK8sContextResponse returned by it
+// responses:
+//
+//	200:
+func (h *Handler) K8SConfigSchemaHandler(w http.ResponseWriter, _ *http.Request) {
+	request := jsonSchemaForType(reflect.TypeOf(k8sConfigUploadRequest{}))
+	request["$schema"] = "https://json-schema.org/draft/2020-12/schema"
+	request["title"] = "K8sConfigUploadRequest"
+	request["description"] = "multipart/form-data fields accepted by POST /api/system/kubernetes"
+
+	response := jsonSchemaForType(reflect.TypeOf(SaveK8sContextResponse{}))
+	response["$schema"] = "https://json-schema.org/draft/2020-12/schema"
+	response["title"] = "SaveK8sContextResponse"
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(map[string]interface{}{
+		"request":  request,
+		"response": response,
+	}); err != nil {
+		h.log.Error(models.ErrMarshal(err, "kubernetes config schema"))
+		http.Error(w, models.ErrMarshal(err, "kubernetes config schema").Error(), http.StatusInternalServerError)
+	}
+}
+
+var (
+	uuidType = reflect.TypeOf(uuid.UUID{})
+	timeType = reflect.TypeOf(time.Time{})
+)
+
+// jsonSchemaForType derives a JSON Schema from the "json" tags of the given type,
+// the "schema" tag can be used to mark a field as "required" and/or "binary", and to restrict it to "enum=a|b".
+func jsonSchemaForType(t reflect.Type) map[string]interface{} {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	switch t {
+	case uuidType:
+		return map[string]interface{}{"type": "string", "format": "uuid"}
+	case timeType:
+		return map[string]interface{}{"type": "string", "format": "date-time"}
+	}
+
+	switch t.Kind() {
+	case reflect.Struct:
+		properties := map[string]interface{}{}
+		required := []string{}
+		for i := 0; i < t.NumField(); i++ {
+			field := t.Field(i)
+			if !field.IsExported() {
+				continue
+			}
+			name := strings.Split(field.Tag.Get("json"), ",")[0]
+			if name == "-" {
+				continue
+			}
+			if name == "" {
+				name = field.Name
+			}
+
+			var prop map[string]interface{}
+			schemaTag := field.Tag.Get("schema")
+			if strings.Contains(schemaTag, "binary") {
+				prop = map[string]interface{}{"type": "string", "format": "binary"}
+			} else {
+				prop = jsonSchemaForType(field.Type)
+			}
+			for _, option := range strings.Split(schemaTag, ",") {
+				if values, ok := strings.CutPrefix(option, "enum="); ok {
+					prop["enum"] = strings.Split(values, "|")
+				}
+			}
+			properties[name] = prop
+			if strings.Contains(schemaTag, "required") {
+				required = append(required, name)
+			}
+		}
+		schema := map[string]interface{}{
+			"type":       "object",
+			"properties": properties,
+		}
+		if len(required) > 0 {
+			schema["required"] = required
+		}
+		return schema
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return map[string]interface{}{"type": "string", "format": "byte"}
+		}
+		return map[string]interface{}{"type": "array", "items": jsonSchemaForType(t.Elem())}
+	case reflect.Map:
+		return map[string]interface{}{"type": "object", "additionalProperties": jsonSchemaForType(t.Elem())}
+	case reflect.String:
+		return map[string]interface{}{"type": "string"}
+	case reflect.Bool:
+		return map[string]interface{}{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return map[string]interface{}{"type": "integer"}
+	case reflect.Float32, reflect.Float64:
+		return map[string]interface{}{"type": "number"}
+	}
+	return map[string]interface{}{}
+}
+
+// swagger:route GET /api/system/kubernetes/auth-plugins SystemAPI idGetK8sAuthPlugins
+// Handle GET request for the kubeconfig auth methods supported by this build
+//
+// Reports which auth-provider plugins are registered in this build of Meshery Server and whether exec credential plugins are supported,
+// so that clients can warn about unsupported auth methods before uploading a kubeconfig
+// responses:
+//
+//	200:
+func (h *Handler) K8sAuthPluginsHandler(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(models.K8sAuthPlugins()); err != nil {
+		h.log.Error(models.ErrMarshal(err, "kubernetes auth plugins"))
+		http.Error(w, models.ErrMarshal(err, "kubernetes auth plugins").Error(), http.StatusInternalServerError)
+	}
+}
@@ -0,0 +1,22 @@
+package handlers
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestK8sConfigUploadRequestSchema(t *testing.T) {
+	schema := jsonSchemaForType(reflect.TypeOf(k8sConfigUploadRequest{}))
+	properties := schema["properties"].(map[string]interface{})
+
+	policy, ok := properties["current_context_policy"].(map[string]interface{})
+	if !ok {
+		t.Fatal("current_context_policy missing from the schema of the upload")
+	}
+	if enum := policy["enum"]; !reflect.DeepEqual(enum, []string{K8sCurrentContextPolicyBestEffort, K8sCurrentContextPolicyStrict}) {
+		t.Errorf("current_context_policy enum = %v, want the policies", enum)
+	}
+	if required := schema["required"]; !reflect.DeepEqual(required, []string{"k8sfile"}) {
+		t.Errorf("required = %v, want k8sfile only", required)
+	}
+}
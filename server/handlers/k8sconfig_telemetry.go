@@ -0,0 +1,46 @@
+package handlers
+
+import (
+	"context"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// instrumentationName names the tracer and the meter of the kubeconfig upload pipeline
+const instrumentationName = "github.com/layer5io/meshery/server/handlers"
+
+// The tracer and the meter are obtained from the global providers, they delegate to the OTLP
+// providers registered by the server when "OTEL_ENABLED" is set and are no-ops otherwise.
+var (
+	k8sUploadTracer = otel.Tracer(instrumentationName)
+
+	k8sUploadDuration, _ = otel.Meter(instrumentationName).Float64Histogram(
+		"meshery.kubeconfig.upload.duration",
+		metric.WithUnit("s"),
+		metric.WithDescription("End-to-end latency of the kubeconfig uploads"),
+	)
+)
+
+// Attributes of the spans of the kubeconfig upload pipeline
+const (
+	attrK8sContexts        = attribute.Key("meshery.kubeconfig.contexts")
+	attrK8sContextName     = attribute.Key("meshery.kubernetes.context.name")
+	attrK8sContextStatus   = attribute.Key("meshery.kubernetes.context.status")
+	attrK8sContextDuration = attribute.Key("meshery.kubernetes.context.duration_ms")
+	attrConnectionID       = attribute.Key("meshery.connection.id")
+	attrConnectionEvent    = attribute.Key("meshery.connection.event")
+)
+
+// startK8sUploadSpan starts a span of a phase of the kubeconfig upload
+func startK8sUploadSpan(ctx context.Context, phase string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	return k8sUploadTracer.Start(ctx, "kubeconfig."+phase, trace.WithAttributes(attrs...))
+}
+
+// recordK8sUploadDuration records the end-to-end latency of the kubeconfig upload started at start
+func recordK8sUploadDuration(ctx context.Context, start time.Time) {
+	k8sUploadDuration.Record(ctx, time.Since(start).Seconds())
+}
@@ -0,0 +1,112 @@
+package handlers
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"sync"
+)
+
+// k8sConfigUploads deduplicates identical kubeconfig uploads in flight, e.g. on double submit,
+// so that the contexts are not saved twice before the deduplication of the connections settles.
+var k8sConfigUploads = &inflightK8sConfigUploads{uploads: make(map[string]*inflightK8sConfigUpload)}
+
+type inflightK8sConfigUploads struct {
+	mx      sync.Mutex
+	uploads map[string]*inflightK8sConfigUpload
+}
+
+// inflightK8sConfigUpload records the response of an upload so that identical uploads
+// received while it is in flight can be answered with it.
+type inflightK8sConfigUpload struct {
+	done   chan struct{}
+	header http.Header
+	status int
+	body   bytes.Buffer
+}
+
+// k8sConfigUploadKey identifies an upload by the user, the kubeconfig and the options of the upload
+func k8sConfigUploadKey(userID string, kubeconfig []byte, req *http.Request) string {
+	hash := sha256.New()
+	_, _ = hash.Write([]byte(userID))
+	_, _ = hash.Write([]byte{0})
+	_, _ = hash.Write(kubeconfig)
+	_, _ = hash.Write([]byte{0})
+	_, _ = hash.Write([]byte(req.Form.Encode()))
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+// start registers the upload, the returned bool reports whether this is the first of the identical uploads in flight.
+// Otherwise the upload in flight is returned, to be waited on.
+func (iu *inflightK8sConfigUploads) start(key string) (*inflightK8sConfigUpload, bool) {
+	iu.mx.Lock()
+	defer iu.mx.Unlock()
+	if upload, ok := iu.uploads[key]; ok {
+		return upload, false
+	}
+	upload := &inflightK8sConfigUpload{
+		done:   make(chan struct{}),
+		header: http.Header{},
+		status: http.StatusOK,
+	}
+	iu.uploads[key] = upload
+	return upload, true
+}
+
+// finish releases the uploads waiting on the upload
+func (iu *inflightK8sConfigUploads) finish(key string, upload *inflightK8sConfigUpload) {
+	iu.mx.Lock()
+	delete(iu.uploads, key)
+	iu.mx.Unlock()
+	close(upload.done)
+}
+
+// record returns a ResponseWriter which writes to w while recording the response for the waiting uploads
+func (upload *inflightK8sConfigUpload) record(w http.ResponseWriter) http.ResponseWriter {
+	return &recordingResponseWriter{ResponseWriter: w, upload: upload}
+}
+
+// replay waits for the upload to finish and writes its response to w
+func (upload *inflightK8sConfigUpload) replay(w http.ResponseWriter, req *http.Request) {
+	select {
+	case <-upload.done:
+	case <-req.Context().Done():
+		return
+	}
+	for k, v := range upload.header {
+		w.Header()[k] = v
+	}
+	w.WriteHeader(upload.status)
+	_, _ = w.Write(upload.body.Bytes())
+}
+
+type recordingResponseWriter struct {
+	http.ResponseWriter
+	upload      *inflightK8sConfigUpload
+	wroteHeader bool
+}
+
+func (rw *recordingResponseWriter) WriteHeader(status int) {
+	if !rw.wroteHeader {
+		rw.wroteHeader = true
+		rw.upload.status = status
+		rw.upload.header = rw.ResponseWriter.Header().Clone()
+	}
+	rw.ResponseWriter.WriteHeader(status)
+}
+
+func (rw *recordingResponseWriter) Write(b []byte) (int, error) {
+	if !rw.wroteHeader {
+		rw.WriteHeader(http.StatusOK)
+	}
+	rw.upload.body.Write(b)
+	return rw.ResponseWriter.Write(b)
+}
+
+// Flush lets streamed uploads flush through the recording writer
+func (rw *recordingResponseWriter) Flush() {
+	if f, ok := rw.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
@@ -0,0 +1,42 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestInflightK8sConfigUploads(t *testing.T) {
+	uploads := &inflightK8sConfigUploads{uploads: make(map[string]*inflightK8sConfigUpload)}
+
+	upload, first := uploads.start("key")
+	if !first {
+		t.Fatal("start() expected the first upload to proceed")
+	}
+	duplicate, first := uploads.start("key")
+	if first || duplicate != upload {
+		t.Fatal("start() expected the identical upload to wait on the upload in flight")
+	}
+
+	replayed := httptest.NewRecorder()
+	replayDone := make(chan struct{})
+	go func() {
+		duplicate.replay(replayed, httptest.NewRequest(http.MethodPost, "/api/system/kubernetes", nil))
+		close(replayDone)
+	}()
+
+	w := upload.record(httptest.NewRecorder())
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	_, _ = w.Write([]byte(`{"registered_contexts":[]}`))
+	uploads.finish("key", upload)
+	<-replayDone
+
+	if replayed.Code != http.StatusCreated || replayed.Body.String() != `{"registered_contexts":[]}` || replayed.Header().Get("Content-Type") != "application/json" {
+		t.Errorf("replayed response = %d %s %v, want the response of the upload in flight", replayed.Code, replayed.Body.String(), replayed.Header())
+	}
+
+	if _, first := uploads.start("key"); !first {
+		t.Error("start() expected a new upload to proceed once the previous one finished")
+	}
+}
@@ -1,13 +1,18 @@
 package handlers
 
 import (
+	"context"
 	"encoding/json"
+	"fmt"
 	"net/http"
 	"strconv"
 
+	"github.com/gofrs/uuid"
 	"github.com/gorilla/mux"
 	"github.com/layer5io/meshery/server/models"
+	"github.com/layer5io/meshkit/models/events"
 	"github.com/layer5io/meshsync/pkg/model"
+	"github.com/spf13/viper"
 	"gorm.io/gorm/clause"
 )
 
@@ -261,3 +266,112 @@ func (h *Handler) DeleteMeshSyncResource(rw http.ResponseWriter, r *http.Request
 		h.log.Error(models.ErrDelete(err, "meshsync data", http.StatusInternalServerError))
 	}
 }
+
+// swagger:route GET /api/system/kubernetes/contexts/{connection_id}/meshsync SystemAPI idGetMeshSyncHealth
+// Handle GET request for the health of MeshSync in a Kubernetes context
+//
+// Reports whether MeshSync is deployed, the status of its pods and when the last resource it discovered was persisted
+// responses:
+//
+//	200: MeshSyncHealth
+//	400:
+//	401:
+//	500:
+func (h *Handler) GetMeshSyncHealth(w http.ResponseWriter, req *http.Request, _ *models.Preference, _ *models.User, provider models.Provider) {
+	token, ok := h.userToken(w, req)
+	if !ok {
+		return
+	}
+
+	connectionID := mux.Vars(req)["connection_id"]
+	k8sContext, err := provider.GetK8sContext(token, connectionID)
+	if err != nil {
+		http.Error(w, "failed to get kubernetes context for the given ID", http.StatusInternalServerError)
+		return
+	}
+
+	kubeclient, err := k8sContext.GenerateKubeHandler()
+	if err != nil {
+		h.log.Error(ErrInvalidKubeHandler(err, "Meshery"))
+		http.Error(w, ErrInvalidKubeHandler(err, "Meshery").Error(), http.StatusBadRequest)
+		return
+	}
+
+	health, err := models.GetMeshSyncHealth(req.Context(), kubeclient, k8sContext, connectionID, h.config.OperatorTracker)
+	if err != nil {
+		h.log.Error(ErrGetMeshSyncHealth(err, k8sContext.Name))
+		http.Error(w, ErrGetMeshSyncHealth(err, k8sContext.Name).Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(health); err != nil {
+		h.log.Error(models.ErrMarshal(err, "meshsync health"))
+		http.Error(w, models.ErrMarshal(err, "meshsync health").Error(), http.StatusInternalServerError)
+	}
+}
+
+// MeshSyncResyncAccepted is the response to a resync of the resources of a Kubernetes context accepted to run in the background
+//
+// swagger:model MeshSyncResyncAccepted
+type MeshSyncResyncAccepted struct {
+	ConnectionID string `json:"connection_id"`
+	Context      string `json:"context"`
+}
+
+// swagger:route POST /api/system/kubernetes/contexts/{connection_id}/resync SystemAPI idPostMeshSyncResync
+// Handle POST request to resync the resources discovered by MeshSync in a Kubernetes context
+//
+// MeshSync is asked for all of the resources of the cluster, refreshing the inventory of the resources which drifted from the cluster,
+// the components of the context are not registered again. The resync runs in the background, bounded by "MESHSYNC_RESYNC_TIMEOUT",
+// events report when it starts and when it completes or fails.
+// responses:
+//
+//	202: MeshSyncResyncAccepted
+//	401:
+//	500:
+func (h *Handler) MeshSyncResyncHandler(w http.ResponseWriter, req *http.Request, _ *models.Preference, user *models.User, provider models.Provider) {
+	token, ok := h.userToken(w, req)
+	if !ok {
+		return
+	}
+	connectionID := mux.Vars(req)["connection_id"]
+	k8sContext, err := provider.GetK8sContext(token, connectionID)
+	if err != nil {
+		http.Error(w, "failed to get kubernetes context for the given ID", http.StatusInternalServerError)
+		return
+	}
+
+	// the request is done long before the resync is
+	go h.resyncMeshSync(context.Background(), provider, uuid.FromStringOrNil(user.ID), connectionID, k8sContext)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusAccepted)
+	if err := json.NewEncoder(w).Encode(MeshSyncResyncAccepted{ConnectionID: connectionID, Context: k8sContext.Name}); err != nil {
+		h.log.Error(models.ErrMarshal(err, "meshsync resync"))
+	}
+}
+
+// resyncMeshSync resyncs the resources of the context, reporting its progress through events
+func (h *Handler) resyncMeshSync(ctx context.Context, provider models.Provider, userID uuid.UUID, connectionID string, k8sContext models.K8sContext) {
+	// the events built by a builder share their storage, hence a builder per event
+	newEvent := func() *events.EventBuilder {
+		return events.NewEvent().ActedUpon(uuid.FromStringOrNil(connectionID)).FromUser(userID).FromSystem(*h.SystemID).WithCategory("connection").WithAction("resync")
+	}
+	publish := func(event *events.Event) {
+		h.persistEvent(provider, event)
+		go h.config.EventBroadcaster.Publish(userID, event)
+	}
+
+	publish(newEvent().WithSeverity(events.Informational).WithDescription(fmt.Sprintf("Resync of the resources of Kubernetes context %s started.", k8sContext.Name)).Build())
+
+	persisted, err := h.MesheryCtrlsHelper.ResyncMeshsyncData(ctx, k8sContext, viper.GetDuration("MESHSYNC_RESYNC_TIMEOUT"))
+	if err != nil {
+		h.log.Error(err)
+		publish(newEvent().WithSeverity(events.Error).WithDescription(fmt.Sprintf("Resync of the resources of Kubernetes context %s failed.", k8sContext.Name)).
+			WithMetadata(map[string]interface{}{"error": err}).Build())
+		return
+	}
+	publish(newEvent().WithSeverity(events.Success).WithDescription(fmt.Sprintf("Resync of the resources of Kubernetes context %s completed, %d resources refreshed.", k8sContext.Name, persisted)).
+		WithMetadata(map[string]interface{}{"resources": persisted}).Build())
+}
@@ -0,0 +1,70 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"github.com/layer5io/meshery/server/models"
+)
+
+// swagger:route GET /api/system/kubernetes/contexts/{connection_id}/operator-manifest SystemAPI idGetOperatorManifest
+// Handle GET request for the manifests of Meshery Operator for a Kubernetes context
+//
+// Returns the manifests Meshery deploys to the cluster of the context (Meshery Operator, Meshery Broker and MeshSync),
+// rendered for the version of the cluster, for the admins to review and apply them (e.g. through GitOps) where Meshery
+// is not permitted to deploy the operator itself. Pass "format=yaml" for the manifests alone as YAML.
+// responses:
+//
+//	200: OperatorManifest
+//	400:
+//	401:
+//	500:
+func (h *Handler) OperatorManifestHandler(w http.ResponseWriter, req *http.Request, _ *models.Preference, _ *models.User, provider models.Provider) {
+	token, ok := h.userToken(w, req)
+	if !ok {
+		return
+	}
+	connectionID := mux.Vars(req)["connection_id"]
+	k8sContext, err := provider.GetK8sContext(token, connectionID)
+	if err != nil {
+		http.Error(w, "failed to get kubernetes context for the given ID", http.StatusInternalServerError)
+		return
+	}
+
+	// The version detected when the context was discovered, else the one of the cluster right now.
+	kubeVersion := k8sContext.Version
+	if kubeVersion == "" {
+		kubeclient, err := k8sContext.GenerateKubeHandler()
+		if err != nil {
+			h.log.Error(ErrInvalidKubeHandler(err, "Meshery"))
+			http.Error(w, ErrInvalidKubeHandler(err, "Meshery").Error(), http.StatusBadRequest)
+			return
+		}
+		version, err := kubeclient.KubeClient.Discovery().ServerVersion()
+		if err != nil {
+			h.log.Error(ErrKubeVersion(err))
+			http.Error(w, ErrKubeVersion(err).Error(), http.StatusInternalServerError)
+			return
+		}
+		kubeVersion = version.GitVersion
+	}
+
+	manifest, err := h.MesheryCtrlsHelper.OperatorManifest(kubeVersion)
+	if err != nil {
+		h.log.Error(err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	if req.URL.Query().Get("format") == "yaml" {
+		w.Header().Set("Content-Type", "application/yaml")
+		_, _ = w.Write([]byte(manifest.Manifest))
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(manifest); err != nil {
+		h.log.Error(models.ErrMarshal(err, "operator manifest"))
+		http.Error(w, models.ErrMarshal(err, "operator manifest").Error(), http.StatusInternalServerError)
+	}
+}
@@ -0,0 +1,94 @@
+package handlers
+
+import (
+	"io"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/gofrs/uuid"
+	"github.com/layer5io/meshkit/errors"
+	"github.com/layer5io/meshkit/logger"
+	"github.com/sirupsen/logrus"
+)
+
+// correlationIDHeader carries the ID under which the request scoped logs are captured
+const correlationIDHeader = "X-Correlation-ID"
+
+// requestLogger returns the logger for the request. With "log_level" (e.g. "debug") more verbose than the global
+// level, a logger scoped to the request at that level is returned, its entries carry a correlation ID which is
+// also returned to the client in the X-Correlation-ID header. Otherwise the global logger is returned.
+func (h *Handler) requestLogger(w http.ResponseWriter, req *http.Request) logger.Handler {
+	level, err := logrus.ParseLevel(req.FormValue("log_level"))
+	if err != nil || level <= h.log.GetLevel() {
+		return h.log
+	}
+	correlationID, err := uuid.NewV4()
+	if err != nil {
+		return h.log
+	}
+	w.Header().Set(correlationIDHeader, correlationID.String())
+	return newCorrelatedLogger(h.log, level, correlationID.String())
+}
+
+// correlatedLogger logs at its own level with the correlation ID of the request
+type correlatedLogger struct {
+	// The global logger, serving the controller and database loggers
+	logger.Handler
+	entry *logrus.Entry
+}
+
+func newCorrelatedLogger(global logger.Handler, level logrus.Level, correlationID string) *correlatedLogger {
+	log := logrus.New()
+	log.SetFormatter(&logrus.TextFormatter{
+		TimestampFormat: time.RFC3339,
+		FullTimestamp:   true,
+	})
+	log.SetOutput(os.Stdout)
+	log.SetLevel(level)
+	return &correlatedLogger{
+		Handler: global,
+		entry:   log.WithFields(logrus.Fields{"app": "meshery", "correlation_id": correlationID}),
+	}
+}
+
+func (l *correlatedLogger) Info(description ...interface{}) {
+	l.entry.Log(logrus.InfoLevel, description...)
+}
+
+func (l *correlatedLogger) Debug(description ...interface{}) {
+	l.entry.Log(logrus.DebugLevel, description...)
+}
+
+func (l *correlatedLogger) Warn(err error) {
+	l.logError(logrus.WarnLevel, err)
+}
+
+func (l *correlatedLogger) Error(err error) {
+	l.logError(logrus.ErrorLevel, err)
+}
+
+func (l *correlatedLogger) logError(level logrus.Level, err error) {
+	if err == nil {
+		return
+	}
+	l.entry.WithFields(logrus.Fields{
+		"code":                  errors.GetCode(err),
+		"severity":              errors.GetSeverity(err),
+		"short-description":     errors.GetSDescription(err),
+		"probable-cause":        errors.GetCause(err),
+		"suggested-remediation": errors.GetRemedy(err),
+	}).Log(level, err.Error())
+}
+
+func (l *correlatedLogger) SetLevel(level logrus.Level) {
+	l.entry.Logger.SetLevel(level)
+}
+
+func (l *correlatedLogger) GetLevel() logrus.Level {
+	return l.entry.Logger.GetLevel()
+}
+
+func (l *correlatedLogger) UpdateLogOutput(w io.Writer) {
+	l.entry.Logger.SetOutput(w)
+}
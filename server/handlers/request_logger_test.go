@@ -0,0 +1,39 @@
+package handlers
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/layer5io/meshkit/logger"
+	"github.com/sirupsen/logrus"
+)
+
+func TestRequestLogger(t *testing.T) {
+	global, err := logger.New("test", logger.Options{LogLevel: int(logrus.InfoLevel)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	h := &Handler{log: global}
+
+	rec := httptest.NewRecorder()
+	if log := h.requestLogger(rec, httptest.NewRequest("POST", "/api/system/kubernetes/register", nil)); log != global {
+		t.Error("expected the global logger without log_level")
+	}
+	if log := h.requestLogger(rec, httptest.NewRequest("POST", "/api/system/kubernetes/register?log_level=warn", nil)); log != global {
+		t.Error("expected the global logger for a level less verbose than the global one")
+	}
+	if rec.Header().Get(correlationIDHeader) != "" {
+		t.Error("expected no correlation ID for the global logger")
+	}
+
+	log := h.requestLogger(rec, httptest.NewRequest("POST", "/api/system/kubernetes/register?log_level=debug", nil))
+	if log == global || log.GetLevel() != logrus.DebugLevel {
+		t.Errorf("expected a request scoped logger at debug level, got level %s", log.GetLevel())
+	}
+	if global.GetLevel() != logrus.InfoLevel {
+		t.Error("expected the global level to be left alone")
+	}
+	if rec.Header().Get(correlationIDHeader) == "" {
+		t.Error("expected the correlation ID to be returned")
+	}
+}
@@ -0,0 +1,45 @@
+package handlers
+
+import (
+	"sync"
+	"time"
+)
+
+// ttlCache caches values per key until their ttl elapses, e.g. the responses of the clusters polled by dashboards
+type ttlCache[V any] struct {
+	mx      sync.Mutex
+	entries map[string]ttlCacheEntry[V]
+}
+
+type ttlCacheEntry[V any] struct {
+	value   V
+	expires time.Time
+}
+
+func newTTLCache[V any]() *ttlCache[V] {
+	return &ttlCache[V]{entries: make(map[string]ttlCacheEntry[V])}
+}
+
+// get returns the value cached for the key unless it expired
+func (c *ttlCache[V]) get(key string) (V, bool) {
+	c.mx.Lock()
+	defer c.mx.Unlock()
+	entry, ok := c.entries[key]
+	if !ok || time.Now().After(entry.expires) {
+		delete(c.entries, key)
+		var zero V
+		return zero, false
+	}
+	return entry.value, true
+}
+
+// set caches the value for ttl, nothing is cached without a key or a ttl
+func (c *ttlCache[V]) set(key string, value V, ttl time.Duration) {
+	c.mx.Lock()
+	defer c.mx.Unlock()
+	if key == "" || ttl <= 0 {
+		delete(c.entries, key)
+		return
+	}
+	c.entries[key] = ttlCacheEntry[V]{value: value, expires: time.Now().Add(ttl)}
+}
@@ -12,6 +12,7 @@ import (
 	"github.com/pkg/errors"
 
 	"github.com/layer5io/meshery/server/models"
+	"github.com/layer5io/meshery/server/models/connections"
 )
 
 // UserHandler returns info about the logged in user
@@ -165,6 +166,24 @@ func (h *Handler) UserPrefsHandler(w http.ResponseWriter, req *http.Request, pre
 		}
 	}
 
+	if prefObj.K8sConnectionPreferences != nil {
+		switch state := prefObj.K8sConnectionPreferences.DefaultState; state {
+		case "", connections.CONNECTED, connections.IGNORED:
+		default:
+			err := fmt.Errorf("invalid default state %q of the kubernetes connections, expected %q or %q", state, connections.CONNECTED, connections.IGNORED)
+			h.log.Error(ErrSavingUserPreference(err))
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if nameTemplate := prefObj.K8sConnectionPreferences.NameTemplate; nameTemplate != "" {
+			if _, err := models.ParseK8sContextNameTemplate(nameTemplate); err != nil {
+				h.log.Error(ErrSavingUserPreference(err))
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+		}
+	}
+
 	if err := provider.RecordPreferences(req, user.UserID, prefObj); err != nil {
 		err := fmt.Errorf("unable to save user preferences: %v", err)
 		h.log.Error(ErrSavingUserPreference(err))
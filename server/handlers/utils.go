@@ -1,8 +1,14 @@
 package handlers
 
 import (
+	"encoding/json"
+	"fmt"
 	"net/http"
 	"strconv"
+
+	"github.com/layer5io/meshery/server/models"
+	"github.com/layer5io/meshkit/errors"
+	"github.com/layer5io/meshkit/models/events"
 )
 
 const (
@@ -36,3 +42,30 @@ func getPaginationParams(req *http.Request) (page, offset, limit int, search, or
 	}
 	return
 }
+
+// persistEvent persists the event using the provider, see models.PersistEvent.
+// The event is auxiliary to the request and should not fail the request for the user.
+func (h *Handler) persistEvent(provider models.Provider, event *events.Event) {
+	models.PersistEvent(provider, h.log, event)
+}
+
+// userToken returns the token of the user injected in the context of the request by the middlewares.
+// When there is none, it responds with 401 and the error as JSON, and returns false.
+func (h *Handler) userToken(w http.ResponseWriter, req *http.Request) (string, bool) {
+	token, ok := req.Context().Value(models.TokenCtxKey).(string)
+	if ok {
+		return token, true
+	}
+
+	err := ErrRetrieveUserToken(fmt.Errorf("no user token in the context of the request"))
+	h.log.Error(err)
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusUnauthorized)
+	if err := json.NewEncoder(w).Encode(map[string]string{
+		"code":  errors.GetCode(err),
+		"error": err.Error(),
+	}); err != nil {
+		h.log.Error(models.ErrMarshal(err, "user token error"))
+	}
+	return "", false
+}
@@ -57,6 +57,11 @@ func FlattenMinifyKubeConfig(config []byte) ([]byte, error) {
 			return key, value
 		}
 
+		// A token file holds the bearer token itself, which is inlined as the token of the user
+		if key == "tokenFile" {
+			return "token", strings.TrimSpace(data)
+		}
+
 		// Encode data as base64
 		return fmt.Sprintf("%s-data", key), base64.StdEncoding.EncodeToString([]byte(data))
 	})
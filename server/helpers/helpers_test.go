@@ -0,0 +1,35 @@
+package helpers
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestFlattenMinifyKubeConfigTokenFile(t *testing.T) {
+	tokenFile := filepath.Join(t.TempDir(), "token")
+	if err := os.WriteFile(tokenFile, []byte("abc\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	kubeconfig := `apiVersion: v1
+kind: Config
+users:
+- name: sa
+  user:
+    tokenFile: ` + tokenFile + `
+- name: dev
+  user:
+    tokenFile: /home/dev/.kube/token
+`
+	flattened, err := FlattenMinifyKubeConfig([]byte(kubeconfig))
+	if err != nil {
+		t.Fatalf("FlattenMinifyKubeConfig() failed with error: %s", err)
+	}
+	if !strings.Contains(string(flattened), "token: abc\n") || strings.Contains(string(flattened), tokenFile) {
+		t.Errorf("expected the readable token file to be inlined as the token, got:\n%s", flattened)
+	}
+	if !strings.Contains(string(flattened), "tokenFile: /home/dev/.kube/token") {
+		t.Errorf("expected the unreadable token file to be kept, got:\n%s", flattened)
+	}
+}
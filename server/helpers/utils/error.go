@@ -0,0 +1,18 @@
+package utils
+
+import (
+	"fmt"
+
+	"github.com/layer5io/meshkit/errors"
+)
+
+// Please reference the following before contributing an error code:
+// https://docs.meshery.io/project/contributing/contributing-error
+// https://github.com/meshery/meshkit/blob/master/errors/errors.go
+const (
+	ErrWriteSVGCode = "1615"
+)
+
+func ErrWriteSVG(err error, name string) error {
+	return errors.New(ErrWriteSVGCode, errors.Alert, []string{fmt.Sprintf("failed to write the SVGs of %s", name)}, []string{err.Error()}, []string{"The directory of the SVGs is not writable.", "The bucket of the SVG storage is not reachable or the credentials are not permitted to write to it."}, []string{"Check the SVG_STORAGE_* settings and the permissions of the SVG storage."})
+}
@@ -0,0 +1,209 @@
+package utils
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	v4 "github.com/aws/aws-sdk-go-v2/aws/signer/v4"
+	"github.com/layer5io/meshkit/logger"
+	"github.com/spf13/viper"
+)
+
+// SVG storage backends selectable through "SVG_STORAGE_BACKEND"
+const (
+	FileSystemSVGStorage = "filesystem"
+	S3SVGStorage         = "s3"
+)
+
+// SVGStore stores the SVGs of the components and models.
+type SVGStore interface {
+	// Put stores the svg under the slash separated key and returns the reference the UI loads it from
+	Put(ctx context.Context, key string, svg []byte) (string, error)
+}
+
+// FileSystemSVGStore stores the SVGs under the static assets of the UI, which do not survive restarts of ephemeral deployments.
+type FileSystemSVGStore struct {
+	Root string
+}
+
+func (s *FileSystemSVGStore) Put(_ context.Context, key string, svg []byte) (string, error) {
+	p := filepath.Join(s.Root, filepath.FromSlash(key))
+	if err := os.MkdirAll(filepath.Dir(p), 0777); err != nil {
+		return "", err
+	}
+	if err := os.WriteFile(p, svg, 0644); err != nil {
+		return "", err
+	}
+	return filepath.Join(strings.TrimPrefix(s.Root, "../../"), filepath.FromSlash(key)), nil
+}
+
+// S3SVGStore stores the SVGs in a bucket of an S3 compatible object storage, e.g. Amazon S3 or
+// Google Cloud Storage through its interoperable XML API with HMAC keys.
+type S3SVGStore struct {
+	// Endpoint is the base URL of the object storage, the bucket is addressed by path.
+	Endpoint string
+	Region   string
+	Bucket   string
+	Prefix   string
+	// PublicURL is the base URL the SVGs are served from, defaults to the URL of the bucket.
+	PublicURL   string
+	Credentials aws.Credentials
+
+	client *http.Client
+	signer *v4.Signer
+}
+
+func (s *S3SVGStore) Put(ctx context.Context, key string, svg []byte) (string, error) {
+	key = path.Join(s.Prefix, key)
+	url := fmt.Sprintf("%s/%s/%s", strings.TrimSuffix(s.Endpoint, "/"), s.Bucket, key)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, url, bytes.NewReader(svg))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "image/svg+xml")
+
+	hash := sha256.Sum256(svg)
+	payloadHash := hex.EncodeToString(hash[:])
+	req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+	if err := s.signer.SignHTTP(ctx, s.Credentials, req, payloadHash, "s3", s.Region, time.Now()); err != nil {
+		return "", err
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		return "", fmt.Errorf("storing %s in bucket %s failed with status %s", key, s.Bucket, resp.Status)
+	}
+
+	publicURL := s.PublicURL
+	if publicURL == "" {
+		publicURL = fmt.Sprintf("%s/%s", strings.TrimSuffix(s.Endpoint, "/"), s.Bucket)
+	}
+	return fmt.Sprintf("%s/%s", strings.TrimSuffix(publicURL, "/"), key), nil
+}
+
+// emptyPayloadHash is the SHA-256 of the empty payload of the requests fetching the SVGs
+const emptyPayloadHash = "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"
+
+// Get fetches the SVG of a reference returned by Put, returning it along with its key in the bucket.
+// References not served from the PublicURL of the store are rejected.
+func (s *S3SVGStore) Get(ctx context.Context, ref string) ([]byte, string, error) {
+	publicURL := s.PublicURL
+	if publicURL == "" {
+		publicURL = fmt.Sprintf("%s/%s", strings.TrimSuffix(s.Endpoint, "/"), s.Bucket)
+	}
+	key, ok := strings.CutPrefix(ref, strings.TrimSuffix(publicURL, "/")+"/")
+	if !ok {
+		return nil, "", fmt.Errorf("%s is not served from %s", ref, publicURL)
+	}
+	url := fmt.Sprintf("%s/%s/%s", strings.TrimSuffix(s.Endpoint, "/"), s.Bucket, key)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, "", err
+	}
+	req.Header.Set("X-Amz-Content-Sha256", emptyPayloadHash)
+	if err := s.signer.SignHTTP(ctx, s.Credentials, req, emptyPayloadHash, "s3", s.Region, time.Now()); err != nil {
+		return nil, "", err
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		return nil, "", fmt.Errorf("fetching %s from bucket %s failed with status %s", key, s.Bucket, resp.Status)
+	}
+	svg, err := io.ReadAll(io.LimitReader(resp.Body, maxSVGSize))
+	if err != nil {
+		return nil, "", err
+	}
+	return svg, key, nil
+}
+
+// maxSVGSize bounds the size of the SVGs fetched from the store
+const maxSVGSize = 4 << 20
+
+// NewSVGStoreFromConfig returns the SVG store selected through "SVG_STORAGE_BACKEND", the file system by default.
+// The "s3" backend is configured through "SVG_STORAGE_ENDPOINT", "SVG_STORAGE_REGION", "SVG_STORAGE_BUCKET",
+// "SVG_STORAGE_PREFIX", "SVG_STORAGE_PUBLIC_URL", "SVG_STORAGE_ACCESS_KEY_ID" and "SVG_STORAGE_SECRET_ACCESS_KEY".
+func NewSVGStoreFromConfig() (SVGStore, error) {
+	switch backend := viper.GetString("SVG_STORAGE_BACKEND"); backend {
+	case "", FileSystemSVGStorage:
+		return &FileSystemSVGStore{Root: UI}, nil
+	case S3SVGStorage:
+		store := &S3SVGStore{
+			Endpoint:  viper.GetString("SVG_STORAGE_ENDPOINT"),
+			Region:    viper.GetString("SVG_STORAGE_REGION"),
+			Bucket:    viper.GetString("SVG_STORAGE_BUCKET"),
+			Prefix:    viper.GetString("SVG_STORAGE_PREFIX"),
+			PublicURL: viper.GetString("SVG_STORAGE_PUBLIC_URL"),
+			Credentials: aws.Credentials{
+				AccessKeyID:     viper.GetString("SVG_STORAGE_ACCESS_KEY_ID"),
+				SecretAccessKey: viper.GetString("SVG_STORAGE_SECRET_ACCESS_KEY"),
+			},
+			client: &http.Client{Timeout: 30 * time.Second},
+			signer: v4.NewSigner(),
+		}
+		if store.Bucket == "" {
+			return nil, fmt.Errorf("SVG_STORAGE_BUCKET is required by the %s svg storage", backend)
+		}
+		if store.Region == "" {
+			store.Region = "us-east-1"
+		}
+		if store.Endpoint == "" {
+			store.Endpoint = fmt.Sprintf("https://s3.%s.amazonaws.com", store.Region)
+		}
+		return store, nil
+	default:
+		return nil, fmt.Errorf("unknown svg storage %q, expected %q or %q", backend, FileSystemSVGStorage, S3SVGStorage)
+	}
+}
+
+var (
+	svgStore   SVGStore = &FileSystemSVGStore{Root: UI}
+	svgLog     logger.Handler
+	svgStoreMx sync.RWMutex
+)
+
+// SetSVGLogger sets the logger the failures to write the SVGs to the store are logged with, they are not logged when unset.
+func SetSVGLogger(log logger.Handler) {
+	svgStoreMx.Lock()
+	defer svgStoreMx.Unlock()
+	svgLog = log
+}
+
+func getSVGLogger() logger.Handler {
+	svgStoreMx.RLock()
+	defer svgStoreMx.RUnlock()
+	return svgLog
+}
+
+// SetSVGStore sets the store the SVGs of the registered components and models are written to.
+func SetSVGStore(store SVGStore) {
+	svgStoreMx.Lock()
+	defer svgStoreMx.Unlock()
+	svgStore = store
+}
+
+// GetSVGStore returns the store the SVGs of the registered components and models are written to.
+func GetSVGStore() SVGStore {
+	svgStoreMx.RLock()
+	defer svgStoreMx.RUnlock()
+	return svgStore
+}
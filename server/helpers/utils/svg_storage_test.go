@@ -0,0 +1,74 @@
+package utils
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	v4 "github.com/aws/aws-sdk-go-v2/aws/signer/v4"
+)
+
+func TestS3SVGStorePut(t *testing.T) {
+	var gotPath, gotBody, gotAuth string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		gotPath, gotBody, gotAuth = r.URL.Path, string(body), r.Header.Get("Authorization")
+	}))
+	defer srv.Close()
+
+	store := &S3SVGStore{
+		Endpoint:    srv.URL,
+		Region:      "us-east-1",
+		Bucket:      "svgs",
+		Prefix:      "meshmodels",
+		PublicURL:   "https://cdn.example.com",
+		Credentials: aws.Credentials{AccessKeyID: "id", SecretAccessKey: "secret"},
+		client:      srv.Client(),
+		signer:      v4.NewSigner(),
+	}
+	ref, err := store.Put(context.Background(), "kubernetes/color/pod-color.svg", []byte("<svg/>"))
+	if err != nil {
+		t.Fatalf("Put() error = %v", err)
+	}
+	if gotPath != "/svgs/meshmodels/kubernetes/color/pod-color.svg" || gotBody != "<svg/>" || gotAuth == "" {
+		t.Errorf("Put() stored %s %q with authorization %q", gotPath, gotBody, gotAuth)
+	}
+	if want := "https://cdn.example.com/meshmodels/kubernetes/color/pod-color.svg"; ref != want {
+		t.Errorf("Put() = %s, want %s", ref, want)
+	}
+}
+
+func TestS3SVGStoreGet(t *testing.T) {
+	var gotPath, gotAuth string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotPath, gotAuth = r.URL.Path, r.Header.Get("Authorization")
+		_, _ = w.Write([]byte("<svg/>"))
+	}))
+	defer srv.Close()
+
+	store := &S3SVGStore{
+		Endpoint:    srv.URL,
+		Region:      "us-east-1",
+		Bucket:      "svgs",
+		PublicURL:   "https://cdn.example.com/",
+		Credentials: aws.Credentials{AccessKeyID: "id", SecretAccessKey: "secret"},
+		client:      srv.Client(),
+		signer:      v4.NewSigner(),
+	}
+	svg, key, err := store.Get(context.Background(), "https://cdn.example.com/meshmodels/kubernetes/color/pod-color.svg")
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	if gotPath != "/svgs/meshmodels/kubernetes/color/pod-color.svg" || gotAuth == "" {
+		t.Errorf("Get() fetched %s with authorization %q", gotPath, gotAuth)
+	}
+	if string(svg) != "<svg/>" || key != "meshmodels/kubernetes/color/pod-color.svg" {
+		t.Errorf("Get() = %q, %s, want the SVG of meshmodels/kubernetes/color/pod-color.svg", svg, key)
+	}
+	if _, _, err := store.Get(context.Background(), "https://elsewhere.example.com/pod-color.svg"); err == nil {
+		t.Error("Get() of a reference not served from the store succeeded, want an error")
+	}
+}
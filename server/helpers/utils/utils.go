@@ -1,11 +1,13 @@
 package utils
 
 import (
+	"context"
 	"crypto/md5"
 	"encoding/hex"
 	"encoding/json"
 	"fmt"
 	"os"
+	"path"
 	"path/filepath"
 	"regexp"
 	"strconv"
@@ -136,122 +138,75 @@ func writeHashCheckSVG(key string, val string) {
 	hashCheckSVG[key] = val
 	mx.Unlock()
 }
-func writeSVGHelper(metadata map[string]interface{}, dirname, filename string) {
+
+// writeSVGHelper writes the SVGs of the metadata under dirname, the SVGs already written under the same scope are reused.
+func writeSVGHelper(store SVGStore, metadata map[string]interface{}, scope, dirname, filename string) {
 	filename = strings.ToLower(filename)
 	successCreatingDirectory := false
 	defer func() {
 		if successCreatingDirectory {
+			mx.Lock()
 			UISVGPaths = append(UISVGPaths, filepath.Join(UI, dirname))
+			mx.Unlock()
 		}
 	}()
-	if metadata["svgColor"] != "" {
-		path := filepath.Join(UI, dirname, "color")
-		err := os.MkdirAll(path, 0777)
-		if err != nil {
-			fmt.Println(err)
-			return
-		}
-		successCreatingDirectory = true
-
-		x, ok := metadata["svgColor"].(string)
-		if ok {
-			hash := md5.Sum([]byte(x))
-			hashString := hex.EncodeToString(hash[:])
-			pathsvg := hashCheckSVG[hashString]
-			if pathsvg != "" { // the image has already been loaded, point the component to that path
-				metadata["svgColor"] = pathsvg
-				goto White
-			}
-			f, err := os.Create(filepath.Join(path, filename+"-color.svg"))
-			if err != nil {
-				fmt.Println(err)
-				return
-			}
-			_, err = f.WriteString(x)
-			if err != nil {
-				fmt.Println(err)
-				return
-			}
-			metadata["svgColor"] = getRelativePathForAPI(filepath.Join(dirname, "color", filename+"-color.svg")) //Replace the actual SVG with path to SVG
-			writeHashCheckSVG(hashString, metadata["svgColor"].(string))
-		}
+	variants := []struct {
+		key    string
+		folder string
+	}{
+		{"svgColor", "color"},
+		{"svgWhite", "white"},
+		{"svgComplete", "complete"},
 	}
-White:
-	if metadata["svgWhite"] != "" {
-		path := filepath.Join(UI, dirname, "white")
-		err := os.MkdirAll(path, 0777)
-		if err != nil {
-			fmt.Println(err)
-			return
+	for _, variant := range variants {
+		x, ok := metadata[variant.key].(string)
+		if !ok || x == "" {
+			continue
 		}
-		successCreatingDirectory = true
-
-		x, ok := metadata["svgWhite"].(string)
-		if ok {
-			hash := md5.Sum([]byte(x))
-			hashString := hex.EncodeToString(hash[:])
-			pathsvg := hashCheckSVG[hashString]
-			if pathsvg != "" { // the image has already been loaded, point the component to that path
-				metadata["svgWhite"] = pathsvg
-				goto Complete
-			}
-			f, err := os.Create(filepath.Join(path, filename+"-white.svg"))
-			if err != nil {
-				fmt.Println(err)
-				return
-			}
-			_, err = f.WriteString(x)
-			if err != nil {
-				fmt.Println(err)
-				return
-			}
-			metadata["svgWhite"] = getRelativePathForAPI(filepath.Join(dirname, "white", filename+"-white.svg")) //Replace the actual SVG with path to SVG
-			writeHashCheckSVG(hashString, metadata["svgWhite"].(string))
+		hash := md5.Sum([]byte(x))
+		hashString := path.Join(scope, hex.EncodeToString(hash[:]))
+		mx.Lock()
+		pathsvg := hashCheckSVG[hashString]
+		mx.Unlock()
+		if pathsvg != "" { // the image has already been loaded, point the component to that path
+			metadata[variant.key] = pathsvg
+			continue
 		}
-	}
-Complete:
-	if metadata["svgComplete"] != "" {
-		path := filepath.Join(UI, dirname, "complete")
-		err := os.MkdirAll(path, 0777)
+		ref, err := store.Put(context.Background(), path.Join(dirname, variant.folder, filename+"-"+variant.folder+".svg"), []byte(x))
 		if err != nil {
-			fmt.Println(err)
+			if log := getSVGLogger(); log != nil {
+				log.Error(ErrWriteSVG(err, filename))
+			}
 			return
 		}
 		successCreatingDirectory = true
-
-		x, ok := metadata["svgComplete"].(string)
-		if ok {
-			hash := md5.Sum([]byte(x))
-			hashString := hex.EncodeToString(hash[:])
-			pathsvg := hashCheckSVG[hashString]
-			if pathsvg != "" { // the image has already been loaded, point the component to that path
-				metadata["svgComplete"] = pathsvg
-				return
-			}
-			f, err := os.Create(filepath.Join(path, filename+"-complete.svg"))
-			if err != nil {
-				fmt.Println(err)
-				return
-			}
-			_, err = f.WriteString(x)
-			if err != nil {
-				fmt.Println(err)
-				return
-			}
-			metadata["svgComplete"] = getRelativePathForAPI(filepath.Join(dirname, "complete", filename+"-complete.svg")) //Replace the actual SVG with path to SVG
-			writeHashCheckSVG(hashString, metadata["svgComplete"].(string))
-		}
+		metadata[variant.key] = ref //Replace the actual SVG with the reference to the SVG
+		writeHashCheckSVG(hashString, ref)
 	}
 }
-func WriteSVGsOnFileSystem(comp *v1alpha1.ComponentDefinition) {
+
+// WriteSVGs replaces the SVGs of the component and its model with the references to them in the configured SVG store.
+func WriteSVGs(comp *v1alpha1.ComponentDefinition) {
+	writeSVGs(comp, "")
+}
+
+// WriteSVGsForContext is WriteSVGs with the SVGs written under the directory of the Kubernetes context,
+// so that the registrations of different contexts never write to the same SVGs.
+func WriteSVGsForContext(comp *v1alpha1.ComponentDefinition, ctxID string) {
+	writeSVGs(comp, ctxID)
+}
+
+func writeSVGs(comp *v1alpha1.ComponentDefinition, ctxID string) {
+	store := GetSVGStore()
 	if comp.Metadata == nil {
 		comp.Metadata = make(map[string]interface{})
 	}
 	if comp.Model.Metadata == nil {
 		comp.Model.Metadata = make(map[string]interface{})
 	}
-	writeSVGHelper(comp.Metadata, comp.Model.Name, comp.Kind)             //Write SVG on components
-	writeSVGHelper(comp.Model.Metadata, comp.Model.Name, comp.Model.Name) //Write SVG on models
+	dirname := path.Join(comp.Model.Name, ctxID)
+	writeSVGHelper(store, comp.Metadata, ctxID, dirname, comp.Kind)             //Write SVG on components
+	writeSVGHelper(store, comp.Model.Metadata, ctxID, dirname, comp.Model.Name) //Write SVG on models
 }
 
 func DeleteSVGsFromFileSystem() {
@@ -259,10 +214,6 @@ func DeleteSVGsFromFileSystem() {
 		os.RemoveAll(path)
 	}
 }
-func getRelativePathForAPI(path string) string {
-	ui := strings.TrimPrefix(UI, "../../")
-	return filepath.Join(ui, path)
-}
 
 func SliceContains(elements []string, name string) bool {
 	for _, ele := range elements {
@@ -12,6 +12,8 @@ const (
 	ErrInititalizeK8sMachineCode  = "1543"
 	ErrAssetMachineCtxCode        = "1544"
 	ErrInvalidTypeCode            = "1551"
+	ErrWebhookNotificationCode    = "1575"
+	ErrTransientTransitionCode    = "1582"
 )
 
 func ErrInvalidTransition(from, to StateType) error {
@@ -33,3 +35,11 @@ func ErrAssertMachineCtx(err error) error {
 func ErrInvalidType(err error) error {
 	return errors.New(ErrInvalidTypeCode, errors.Alert, []string{"Provided connection id is invalid"}, []string{err.Error()}, []string{"Provided ID is not a valid uuid."}, []string{"Hard delete and reinitialise the connection process."})
 }
+
+func ErrWebhookNotification(err error, url string) error {
+	return errors.New(ErrWebhookNotificationCode, errors.Alert, []string{fmt.Sprintf("Failed to notify the webhook %s of the connection state change", url)}, []string{err.Error()}, []string{"The webhook is unreachable or responded with an error."}, []string{"Verify that the URL configured through CONNECTION_WEBHOOK_URL is reachable from Meshery Server and accepts POST requests."})
+}
+
+func ErrTransientTransition(err error, kind string, failures int) error {
+	return errors.New(ErrTransientTransitionCode, errors.Alert, []string{fmt.Sprintf("Transition of the %s connection failed %d consecutive time(s), within the grace of the failure policy", kind, failures)}, []string{err.Error()}, []string{"The connection is temporarily unreachable, e.g. a network blip."}, []string{"No action is needed if the next transition succeeds. The connection is reported as errored once the failures exceed CONNECTION_FAILURE_THRESHOLD or CONNECTION_FAILURE_GRACE_PERIOD."})
+}
@@ -0,0 +1,53 @@
+package machines
+
+import (
+	"time"
+
+	"github.com/spf13/viper"
+)
+
+// FailurePolicy decides when the failed transitions of a connection are surfaced, i.e. the connection is
+// reported as ERRORED with a Critical event, so that a transient failure does not alarm the user.
+// Failures are surfaced once Threshold consecutive transitions failed or, with a GracePeriod,
+// once the transitions kept failing for that long. A successful transition resets the count.
+//
+// A zero Threshold only considers the GracePeriod. Without both, every failure is surfaced.
+type FailurePolicy struct {
+	Threshold   int
+	GracePeriod time.Duration
+
+	failures       int
+	firstFailureAt time.Time
+}
+
+// NewFailurePolicyFromConfig returns the policy configured through "CONNECTION_FAILURE_THRESHOLD"
+// and "CONNECTION_FAILURE_GRACE_PERIOD".
+func NewFailurePolicyFromConfig() *FailurePolicy {
+	return &FailurePolicy{
+		Threshold:   viper.GetInt("CONNECTION_FAILURE_THRESHOLD"),
+		GracePeriod: viper.GetDuration("CONNECTION_FAILURE_GRACE_PERIOD"),
+	}
+}
+
+// failed records a failed transition at the given time and reports whether it is to be surfaced,
+// along with the number of consecutive failures so far.
+func (fp *FailurePolicy) failed(at time.Time) (bool, int) {
+	if fp.failures == 0 {
+		fp.firstFailureAt = at
+	}
+	fp.failures++
+
+	if fp.Threshold <= 0 && fp.GracePeriod <= 0 {
+		return true, fp.failures
+	}
+	if fp.Threshold > 0 && fp.failures >= fp.Threshold {
+		return true, fp.failures
+	}
+	return fp.GracePeriod > 0 && at.Sub(fp.firstFailureAt) >= fp.GracePeriod, fp.failures
+}
+
+// succeeded resets the consecutive failures
+func (fp *FailurePolicy) succeeded() {
+	fp.failures = 0
+	fp.firstFailureAt = time.Time{}
+}
@@ -0,0 +1,43 @@
+package machines
+
+import (
+	"testing"
+	"time"
+)
+
+func TestFailurePolicy(t *testing.T) {
+	now := time.Now()
+
+	threshold := &FailurePolicy{Threshold: 3}
+	for i := 1; i <= 2; i++ {
+		if surfaced, failures := threshold.failed(now); surfaced || failures != i {
+			t.Errorf("failure %d: surfaced = %t, failures = %d, want false, %d", i, surfaced, failures, i)
+		}
+	}
+	threshold.succeeded()
+	if surfaced, failures := threshold.failed(now); surfaced || failures != 1 {
+		t.Errorf("expected a success to reset the failures, got surfaced = %t, failures = %d", surfaced, failures)
+	}
+	threshold.failed(now)
+	if surfaced, _ := threshold.failed(now); !surfaced {
+		t.Error("expected the third consecutive failure to be surfaced")
+	}
+
+	window := &FailurePolicy{GracePeriod: time.Minute}
+	if surfaced, _ := window.failed(now); surfaced {
+		t.Error("expected the first failure within the grace period not to be surfaced")
+	}
+	if surfaced, _ := window.failed(now.Add(30 * time.Second)); surfaced {
+		t.Error("expected a failure within the grace period not to be surfaced")
+	}
+	if surfaced, _ := window.failed(now.Add(time.Minute)); !surfaced {
+		t.Error("expected the failures persisting for the grace period to be surfaced")
+	}
+
+	if surfaced, _ := (&FailurePolicy{}).failed(now); !surfaced {
+		t.Error("expected every failure to be surfaced without threshold and grace period")
+	}
+	if surfaced, _ := (&FailurePolicy{Threshold: 1}).failed(now); !surfaced {
+		t.Error("expected the first failure to be surfaced with a threshold of 1")
+	}
+}
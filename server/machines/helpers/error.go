@@ -1,18 +1,30 @@
 package helpers
 
 import (
+	"fmt"
+
 	"github.com/layer5io/meshery/server/models"
 	"github.com/layer5io/meshkit/errors"
 )
 
 var (
-	ErrAutoRegisterCode = "1556"
+	ErrAutoRegisterCode            = "1556"
+	ErrDeleteExpiredConnectionCode = "1618"
+	ErrSweepExpiredConnectionsCode = "1619"
 )
 
 func ErrAutoRegister(err error, connType string) error {
 	return errors.New(ErrAutoRegisterCode, errors.Alert, []string{}, []string{}, []string{}, []string{})
 }
 
+func ErrDeleteExpiredConnection(err error, ctxName string) error {
+	return errors.New(ErrDeleteExpiredConnectionCode, errors.Alert, []string{fmt.Sprintf("Unable to delete the expired Kubernetes connection %s.", ctxName)}, []string{err.Error()}, []string{"The remote provider is not reachable.", "The connection is not in a state it can be deleted from."}, []string{"The connection is deleted on the next sweep, or delete it manually."})
+}
+
+func ErrSweepExpiredConnections(err error) error {
+	return errors.New(ErrSweepExpiredConnectionsCode, errors.Alert, []string{"Unable to list the Kubernetes connections to sweep the expired ones."}, []string{err.Error()}, []string{"The remote provider is not reachable."}, []string{"The expired connections are swept on the next sweep."})
+}
+
 func IsConnectionUpdateErr(err error) bool {
 	return errors.GetCode(err) == models.ErrUpdateConnectionStatusCode
 }
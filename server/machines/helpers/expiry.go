@@ -0,0 +1,166 @@
+package helpers
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/gofrs/uuid"
+	"github.com/layer5io/meshery/server/machines"
+	"github.com/layer5io/meshery/server/machines/kubernetes"
+	"github.com/layer5io/meshery/server/models"
+	"github.com/layer5io/meshkit/logger"
+	"github.com/layer5io/meshkit/models/events"
+	"github.com/spf13/viper"
+)
+
+// ConnectionExpirySweeper periodically deletes the Kubernetes connections whose expiry is past,
+// e.g. the connections of ephemeral CI clusters uploaded with a ttl.
+// The expired contexts are moved to the recycle bin, as when deleted by the user, and their components are unregistered.
+type ConnectionExpirySweeper struct {
+	Interval time.Duration
+
+	smInstanceTracker *machines.ConnectionToStateMachineInstanceTracker
+	recycleBin        *models.K8sContextRecycleBin
+	log               logger.Handler
+	eventBroadcast    *models.Broadcast
+	now               func() time.Time
+}
+
+// NewConnectionExpirySweeperFromConfig returns the expiry sweeper running every "CONNECTION_EXPIRY_SWEEP_INTERVAL",
+// the expired contexts are deleted permanently right away when recycleBin is nil.
+func NewConnectionExpirySweeperFromConfig(smInstanceTracker *machines.ConnectionToStateMachineInstanceTracker, recycleBin *models.K8sContextRecycleBin, log logger.Handler, eventBroadcast *models.Broadcast) *ConnectionExpirySweeper {
+	interval := viper.GetDuration("CONNECTION_EXPIRY_SWEEP_INTERVAL")
+	if interval <= 0 {
+		interval = time.Minute
+	}
+	return &ConnectionExpirySweeper{
+		Interval:          interval,
+		smInstanceTracker: smInstanceTracker,
+		recycleBin:        recycleBin,
+		log:               log,
+		eventBroadcast:    eventBroadcast,
+		now:               time.Now,
+	}
+}
+
+// Run sweeps the expired connections every interval until ctx is done
+func (s *ConnectionExpirySweeper) Run(ctx context.Context) {
+	ticker := time.NewTicker(s.Interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s.sweep(ctx)
+		}
+	}
+}
+
+// sweep deletes the expired connections, in the same way as they are deleted by the user who last acted upon them.
+// The expired contexts with no tracked machine, e.g. after Meshery Server restarted, are deleted with the credentials
+// of the actors of the tracked machines of the same provider.
+func (s *ConnectionExpirySweeper) sweep(ctx context.Context) {
+	now := s.now()
+	actors := make(map[string]*machines.StateMachine)
+	for _, inst := range s.smInstanceTracker.List() {
+		actorCtx, ok := inst.ActorContext(ctx)
+		if !ok || inst.Provider == nil {
+			continue
+		}
+		if token, _ := actorCtx.Value(models.TokenCtxKey).(string); token != "" {
+			actors[token] = inst
+		}
+		machineCtx, ok := inst.Context.(*kubernetes.MachineCtx)
+		if !ok || !machineCtx.K8sContext.Expired(now) || !inst.Accepts(machines.Delete) {
+			continue
+		}
+		s.delete(context.WithValue(actorCtx, models.ProviderCtxKey, inst.Provider), inst, machineCtx)
+	}
+
+	for token, inst := range actors {
+		actorCtx, _ := inst.ActorContext(ctx)
+		s.sweepUntracked(actorCtx, inst.Provider, token, inst.UserID, now)
+	}
+}
+
+// sweepUntracked deletes the expired contexts of the provider which have no tracked machine
+func (s *ConnectionExpirySweeper) sweepUntracked(ctx context.Context, provider models.Provider, token string, userID uuid.UUID, now time.Time) {
+	contexts, err := models.LoadK8sContextPages(provider, token, "", "", false)
+	if err != nil {
+		s.log.Error(ErrSweepExpiredConnections(err))
+		return
+	}
+	for _, k8sContext := range contexts {
+		if !k8sContext.Expired(now) || k8sContext.DeletedAt != nil {
+			continue
+		}
+		id := k8sContext.ConnectionID
+		if id == "" {
+			id = k8sContext.ID
+		}
+		if _, tracked := s.smInstanceTracker.Get(uuid.FromStringOrNil(id)); tracked {
+			continue
+		}
+		if !s.recycle(provider, token, id, *k8sContext) {
+			continue
+		}
+		if db := provider.GetGenericPersister(); db != nil {
+			if _, err := models.UnregisterAllK8sContextComponents(db, k8sContext.ID); err != nil {
+				s.log.Error(models.ErrUnregisterK8sContextComponents(err, k8sContext.Name))
+			}
+		}
+		s.publish(ctx, provider, uuid.FromStringOrNil(id), userID, *k8sContext)
+	}
+}
+
+func (s *ConnectionExpirySweeper) delete(ctx context.Context, inst *machines.StateMachine, machineCtx *kubernetes.MachineCtx) {
+	if _, err := inst.SendEvent(ctx, machines.Delete, nil); err != nil {
+		s.log.Error(ErrDeleteExpiredConnection(err, machineCtx.K8sContext.Name))
+		return
+	}
+	s.smInstanceTracker.Remove(inst.ID)
+
+	token, _ := ctx.Value(models.TokenCtxKey).(string)
+	if !s.recycle(inst.Provider, token, inst.ID.String(), machineCtx.K8sContext) {
+		return
+	}
+	s.publish(ctx, inst.Provider, inst.ID, inst.UserID, machineCtx.K8sContext)
+}
+
+// recycle moves the context of the expired connection to the recycle bin, or deletes it permanently when there is none.
+// Reports whether the context was deleted.
+func (s *ConnectionExpirySweeper) recycle(provider models.Provider, token, id string, k8sContext models.K8sContext) bool {
+	if s.recycleBin == nil {
+		if _, err := provider.DeleteK8sContext(token, id); err != nil {
+			s.log.Error(ErrDeleteExpiredConnection(err, k8sContext.Name))
+			return false
+		}
+		return true
+	}
+	deletedAt := s.now().UTC()
+	if _, err := provider.SetK8sContextDeletedAt(token, id, &deletedAt); err != nil {
+		s.log.Error(ErrDeleteExpiredConnection(err, k8sContext.Name))
+		return false
+	}
+	s.recycleBin.Add(provider, token, id, deletedAt)
+	return true
+}
+
+func (s *ConnectionExpirySweeper) publish(ctx context.Context, provider models.Provider, connectionID, userID uuid.UUID, k8sContext models.K8sContext) {
+	sysID, _ := ctx.Value(models.SystemIDKey).(*uuid.UUID)
+	event := events.NewEvent().ActedUpon(connectionID).FromUser(userID).FromSystem(*sysID).WithCategory("connection").WithAction("delete").
+		WithSeverity(events.Informational).WithDescription(fmt.Sprintf("Kubernetes connection %s expired and was deleted", k8sContext.Name)).
+		WithMetadata(map[string]interface{}{
+			"connection_id": connectionID,
+			"context":       k8sContext.Name,
+			"server":        k8sContext.Server,
+			"expires_at":    k8sContext.ExpiresAt,
+		}).Build()
+	models.PersistEvent(provider, s.log, event)
+	if s.eventBroadcast != nil {
+		go s.eventBroadcast.Publish(userID, event)
+	}
+	s.log.Info("deleted expired kubernetes connection ", connectionID)
+}
@@ -0,0 +1,150 @@
+package helpers
+
+import (
+	"context"
+	"encoding/json"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/gofrs/uuid"
+	"github.com/layer5io/meshery/server/machines"
+	"github.com/layer5io/meshery/server/machines/kubernetes"
+	"github.com/layer5io/meshery/server/models"
+	"github.com/layer5io/meshery/server/models/connections"
+	"github.com/layer5io/meshkit/database"
+	"github.com/layer5io/meshkit/logger"
+	"github.com/layer5io/meshkit/models/events"
+	"github.com/layer5io/meshkit/models/meshmodel/core/v1alpha1"
+	meshmodel "github.com/layer5io/meshkit/models/meshmodel/registry"
+)
+
+// expiryProvider serves the saved contexts and records the ones moved to the recycle bin
+type expiryProvider struct {
+	models.Provider
+	db       *database.Handler
+	contexts []*models.K8sContext
+	recycled []string
+}
+
+func (p *expiryProvider) GetK8sContexts(_, _, _, _, _, _ string, _ bool) ([]byte, error) {
+	return json.Marshal(models.MesheryK8sContextPage{Contexts: p.contexts, TotalCount: len(p.contexts)})
+}
+
+func (p *expiryProvider) SetK8sContextDeletedAt(_, id string, deletedAt *time.Time) (models.K8sContext, error) {
+	p.recycled = append(p.recycled, id)
+	for _, ctx := range p.contexts {
+		if ctx.ConnectionID == id {
+			ctx.DeletedAt = deletedAt
+			return *ctx, nil
+		}
+	}
+	return models.K8sContext{}, nil
+}
+
+func (p *expiryProvider) UpdateConnectionStatusByID(_ string, _ uuid.UUID, _ connections.ConnectionStatus, _ string) (*connections.Connection, int, error) {
+	return &connections.Connection{}, 200, nil
+}
+
+func (p *expiryProvider) GetGenericPersister() *database.Handler {
+	return p.db
+}
+
+func (p *expiryProvider) PersistEvent(_ *events.Event) error {
+	return nil
+}
+
+type noopAction struct{}
+
+func (noopAction) ExecuteOnEntry(context.Context, interface{}, interface{}) (machines.EventType, *events.Event, error) {
+	return machines.NoOp, nil, nil
+}
+
+func (noopAction) Execute(context.Context, interface{}, interface{}) (machines.EventType, *events.Event, error) {
+	return machines.NoOp, nil, nil
+}
+
+func (noopAction) ExecuteOnExit(context.Context, interface{}, interface{}) (machines.EventType, *events.Event, error) {
+	return machines.NoOp, nil, nil
+}
+
+func TestConnectionExpirySweeperSweep(t *testing.T) {
+	log, _ := logger.New("test", logger.Options{})
+	db, err := database.New(database.Options{Engine: database.SQLITE, Filename: filepath.Join(t.TempDir(), "registry.db")})
+	if err != nil {
+		t.Fatalf("failed to open the database: %s", err)
+	}
+	if err := db.AutoMigrate(&models.K8sComponentsRegistryHost{}, &models.K8sComponentsRegistrationCheckpoint{}); err != nil {
+		t.Fatal(err)
+	}
+	reg, err := meshmodel.NewRegistryManager(&db)
+	if err != nil {
+		t.Fatalf("failed to create the registry: %s", err)
+	}
+
+	now := time.Now()
+	expired := now.Add(-time.Minute)
+	tracked := &models.K8sContext{ID: "tracked", ConnectionID: uuid.Must(uuid.NewV4()).String(), Name: "ci-tracked", ExpiresAt: &expired}
+	untracked := &models.K8sContext{ID: "untracked", ConnectionID: uuid.Must(uuid.NewV4()).String(), Name: "ci-untracked", ExpiresAt: &expired}
+	live := &models.K8sContext{ID: "live", ConnectionID: uuid.Must(uuid.NewV4()).String(), Name: "staging"}
+	provider := &expiryProvider{db: &db, contexts: []*models.K8sContext{tracked, untracked, live}}
+
+	for _, ctxID := range []string{untracked.ID, live.ID} {
+		comp := v1alpha1.ComponentDefinition{
+			TypeMeta: v1alpha1.TypeMeta{Kind: "Deployment", APIVersion: "apps/v1"},
+			Model:    v1alpha1.Model{Name: "kubernetes", Version: "v1.27.3", Category: v1alpha1.Category{Name: "Orchestration & Management"}},
+			Metadata: map[string]interface{}{},
+			Schema:   "{}",
+		}
+		if err := reg.RegisterEntity(models.K8sComponentsHost(ctxID), comp); err != nil {
+			t.Fatal(err)
+		}
+		if err := models.SaveK8sComponentsRegistrationCheckpoint(&db, &models.K8sComponentsRegistrationCheckpoint{ContextID: ctxID, Registered: []string{"apps/v1/Deployment"}}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// only the connection of the tracked context has a machine, which was last acted upon by the user
+	tracker := &machines.ConnectionToStateMachineInstanceTracker{ConnectToInstanceMap: map[uuid.UUID]*machines.StateMachine{}}
+	inst := &machines.StateMachine{
+		ID:           uuid.FromStringOrNil(tracked.ConnectionID),
+		Name:         "kubernetes",
+		CurrentState: machines.CONNECTED,
+		States: machines.States{
+			machines.CONNECTED: machines.State{Events: machines.Events{machines.Delete: machines.DELETED}},
+			machines.DELETED:   machines.State{Action: noopAction{}},
+		},
+		Context:  &kubernetes.MachineCtx{K8sContext: *tracked},
+		Log:      log,
+		Provider: provider,
+	}
+	tracker.Add(inst.ID, inst)
+	sysID := uuid.Must(uuid.NewV4())
+	actorCtx := context.WithValue(context.Background(), models.UserCtxKey, &models.User{ID: uuid.Must(uuid.NewV4()).String()})
+	actorCtx = context.WithValue(actorCtx, models.SystemIDKey, &sysID)
+	actorCtx = context.WithValue(actorCtx, models.TokenCtxKey, "token")
+	_, _ = inst.SendEvent(actorCtx, machines.NoOp, nil)
+
+	recycleBin := models.NewK8sContextRecycleBinFromConfig(log)
+	sweeper := NewConnectionExpirySweeperFromConfig(tracker, recycleBin, log, nil)
+	sweeper.now = func() time.Time { return now }
+	sweeper.sweep(context.Background())
+
+	if len(provider.recycled) != 2 || provider.recycled[0] != tracked.ConnectionID || provider.recycled[1] != untracked.ConnectionID {
+		t.Fatalf("recycled %v, want the tracked and then the untracked expired contexts", provider.recycled)
+	}
+	if inst.CurrentState != machines.DELETED {
+		t.Errorf("machine state = %s, want %s", inst.CurrentState, machines.DELETED)
+	}
+	if _, ok := tracker.Get(inst.ID); ok {
+		t.Error("expected the machine of the expired connection to be untracked")
+	}
+	for ctxID, want := range map[string]int64{untracked.ID: 0, live.ID: 1} {
+		if count, err := models.CountK8sContextComponents(&db, ctxID); err != nil || count != want {
+			t.Errorf("components of %s = %d, %v, want %d", ctxID, count, err, want)
+		}
+		if checkpoint, err := models.GetK8sComponentsRegistrationCheckpoint(&db, ctxID); err != nil || (checkpoint != nil) != (want > 0) {
+			t.Errorf("registration checkpoint of %s = %v, %v, want it cleared along with the components", ctxID, checkpoint, err)
+		}
+	}
+}
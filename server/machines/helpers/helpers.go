@@ -87,6 +87,13 @@ func InitializeMachineWithContext(
 		return nil, err
 	}
 	inst.Provider = provider
+	inst.FailurePolicy = machines.NewFailurePolicyFromConfig()
+	if mtype == "kubernetes" {
+		inst.Webhook = machines.NewWebhookFromConfig(log)
+		if k8sMachineCtx, ok := machineCtx.(*kubernetes.MachineCtx); ok {
+			inst.DescribeStatus = machines.StatusReasonWithSource(k8sMachineCtx.K8sContext.Source)
+		}
+	}
 	_, err = inst.Start(ctx, machineCtx, log, initFunc)
 	smInstanceTracker.Add(ID, inst)
 	if err != nil {
@@ -0,0 +1,116 @@
+package helpers
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/gofrs/uuid"
+	"github.com/layer5io/meshery/server/machines"
+	"github.com/layer5io/meshery/server/machines/kubernetes"
+	"github.com/layer5io/meshery/server/models"
+	"github.com/layer5io/meshkit/logger"
+	"github.com/layer5io/meshkit/models/events"
+	"github.com/spf13/viper"
+)
+
+// ConnectionRecoveryController periodically re-pings the Kubernetes connections whose last transition failed
+// and drives the ones which became reachable again back to connected, for clusters which recover on their own.
+type ConnectionRecoveryController struct {
+	Interval     time.Duration
+	ProbeTimeout time.Duration
+
+	smInstanceTracker *machines.ConnectionToStateMachineInstanceTracker
+	log               logger.Handler
+	eventBroadcast    *models.Broadcast
+	// ping checks the reachability of the cluster of the connection
+	ping func(k8sContext models.K8sContext, timeout time.Duration) error
+}
+
+// NewConnectionRecoveryControllerFromConfig returns the recovery controller configured through
+// "CONNECTION_RECOVERY_INTERVAL" and "KUBERNETES_PROBE_TIMEOUT", nil when "CONNECTION_RECOVERY_ENABLED" is not set.
+func NewConnectionRecoveryControllerFromConfig(smInstanceTracker *machines.ConnectionToStateMachineInstanceTracker, log logger.Handler, eventBroadcast *models.Broadcast) *ConnectionRecoveryController {
+	if !viper.GetBool("CONNECTION_RECOVERY_ENABLED") {
+		return nil
+	}
+	interval := viper.GetDuration("CONNECTION_RECOVERY_INTERVAL")
+	if interval <= 0 {
+		interval = time.Minute
+	}
+	return &ConnectionRecoveryController{
+		Interval:          interval,
+		ProbeTimeout:      viper.GetDuration("KUBERNETES_PROBE_TIMEOUT"),
+		smInstanceTracker: smInstanceTracker,
+		log:               log,
+		eventBroadcast:    eventBroadcast,
+		ping: func(k8sContext models.K8sContext, timeout time.Duration) error {
+			return k8sContext.PingTestWithTimeout(timeout)
+		},
+	}
+}
+
+// Run reconciles the errored connections every interval until ctx is done
+func (c *ConnectionRecoveryController) Run(ctx context.Context) {
+	if c == nil {
+		return
+	}
+	c.log.Info("recovering errored kubernetes connections every ", c.Interval)
+	ticker := time.NewTicker(c.Interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			c.reconcile(ctx)
+		}
+	}
+}
+
+// reconcile attempts to recover each errored connection once, the connections which are still unreachable are left as is
+func (c *ConnectionRecoveryController) reconcile(ctx context.Context) {
+	for _, inst := range c.smInstanceTracker.List() {
+		if lastError, _ := inst.LastError(); lastError == "" {
+			continue
+		}
+		machineCtx, ok := inst.Context.(*kubernetes.MachineCtx)
+		if !ok || !inst.Accepts(machines.Connect) {
+			continue
+		}
+		actorCtx, ok := inst.ActorContext(ctx)
+		if !ok {
+			continue
+		}
+		if err := c.ping(machineCtx.K8sContext, c.ProbeTimeout); err != nil {
+			c.log.Debug("kubernetes connection ", inst.ID, " is still unreachable: ", err)
+			continue
+		}
+		c.recover(actorCtx, inst, machineCtx)
+	}
+}
+
+func (c *ConnectionRecoveryController) recover(ctx context.Context, inst *machines.StateMachine, machineCtx *kubernetes.MachineCtx) {
+	inst, err := InitializeMachineWithContext(machineCtx, ctx, inst.ID, inst.UserID, c.smInstanceTracker, c.log, inst.Provider, machines.InitialState, "kubernetes", kubernetes.AssignInitialCtx)
+	if err != nil {
+		c.log.Error(err)
+		return
+	}
+	if _, err := inst.SendEvent(ctx, machines.Connect, nil); err != nil {
+		c.log.Error(err)
+		return
+	}
+
+	sysID, _ := ctx.Value(models.SystemIDKey).(*uuid.UUID)
+	event := events.NewEvent().ActedUpon(inst.ID).FromUser(inst.UserID).FromSystem(*sysID).WithCategory("connection").WithAction("recover").
+		WithSeverity(events.Success).WithDescription(fmt.Sprintf("Kubernetes connection %s is reachable again and was reconnected", machineCtx.K8sContext.Name)).
+		WithMetadata(map[string]interface{}{
+			"connection_id": inst.ID,
+			"context":       machineCtx.K8sContext.Name,
+			"server":        machineCtx.K8sContext.Server,
+		}).Build()
+	models.PersistEvent(inst.Provider, c.log, event)
+	if c.eventBroadcast != nil {
+		go c.eventBroadcast.Publish(inst.UserID, event)
+	}
+	c.log.Info("recovered kubernetes connection ", inst.ID)
+}
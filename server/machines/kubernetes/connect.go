@@ -33,7 +33,7 @@ func (ca *ConnectAction) Execute(ctx context.Context, machineCtx interface{}, da
 	k8sContexts := []models.K8sContext{machinectx.K8sContext}
 	ctrlHelper := machinectx.MesheryCtrlsHelper.UpdateCtxControllerHandlers(k8sContexts).
 		UpdateOperatorsStatusMap(machinectx.OperatorTracker).DeployUndeployedOperators(machinectx.OperatorTracker)
-	ctrlHelper.UpdateMeshsynDataHandlers(ctx, uuid.FromStringOrNil(machinectx.K8sContext.ConnectionID), userUUID, *sysID, provider)
+	ctrlHelper.UpdateMeshsynDataHandlers(ctx, machinectx.K8sContext.ID, uuid.FromStringOrNil(machinectx.K8sContext.ConnectionID), userUUID, *sysID, provider, machinectx.K8sContext.MeshSyncInterval())
 
 	return machines.NoOp, nil, nil
 }
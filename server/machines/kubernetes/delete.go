@@ -2,11 +2,13 @@ package kubernetes
 
 import (
 	"context"
+	"fmt"
 	"time"
 
 	"github.com/gofrs/uuid"
 	"github.com/layer5io/meshery/server/machines"
 	"github.com/layer5io/meshery/server/models"
+	mcore "github.com/layer5io/meshery/server/models/meshmodel/core"
 	"github.com/layer5io/meshkit/models/events"
 )
 
@@ -33,6 +35,9 @@ func (da *DeleteAction) Execute(ctx context.Context, machineCtx interface{}, dat
 	k8sContexts := []models.K8sContext{machinectx.K8sContext}
 	machinectx.MesheryCtrlsHelper.UpdateOperatorsStatusMap(machinectx.OperatorTracker).UndeployDeployedOperators(machinectx.OperatorTracker)
 
+	// the helper is locked while the data handlers are being connected to the broker
+	go machinectx.MesheryCtrlsHelper.RemoveMeshsyncDataHandler(machinectx.K8sContext.ID)
+
 	_ctx, cancel := context.WithTimeout(ctx, 100*time.Millisecond)
 	defer cancel()
 	context.AfterFunc(_ctx, func() {
@@ -41,6 +46,19 @@ func (da *DeleteAction) Execute(ctx context.Context, machineCtx interface{}, dat
 
 	go models.FlushMeshSyncData(ctx, machinectx.K8sContext, provider, machinectx.EventBroadcaster, user.ID, sysID)
 
+	// The custom resource definitions of a deleted connection are no longer watched,
+	// so that its components are not registered again as they are created
+	mcore.StopK8sComponentsWatch(machinectx.K8sContext.ConnectionID)
+
+	// The components are registered again once the context is restored from the recycle bin
+	if provider != nil && provider.GetGenericPersister() != nil {
+		if _, err := models.UnregisterAllK8sContextComponents(provider.GetGenericPersister(), machinectx.K8sContext.ID); err != nil {
+			err = models.ErrUnregisterK8sContextComponents(err, machinectx.K8sContext.Name)
+			return machines.NoOp, eventBuilder.WithSeverity(events.Warning).WithDescription(fmt.Sprintf("Connection of %s deleted, its components are left in the registry.", machinectx.K8sContext.Name)).
+				WithMetadata(map[string]interface{}{"error": err}).Build(), nil
+		}
+	}
+
 	return machines.NoOp, nil, nil
 }
 
@@ -32,6 +32,9 @@ func (da *DisconnectAction) Execute(ctx context.Context, machineCtx interface{},
 	k8sContexts := []models.K8sContext{machinectx.K8sContext}
 	machinectx.MesheryCtrlsHelper.UndeployDeployedOperators(machinectx.OperatorTracker)
 
+	// the helper is locked while the data handlers are being connected to the broker
+	go machinectx.MesheryCtrlsHelper.RemoveMeshsyncDataHandler(machinectx.K8sContext.ID)
+
 	_ctx, cancel := context.WithTimeout(ctx, 100*time.Millisecond)
 	defer cancel()
 	context.AfterFunc(_ctx, func() {
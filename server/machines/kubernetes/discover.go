@@ -48,7 +48,7 @@ func (da *DiscoverAction) Execute(ctx context.Context, machineCtx interface{}, d
 	}
 	token, _ := ctx.Value(models.TokenCtxKey).(string)
 
-	_, err = provider.SaveK8sContext(token, machinectx.K8sContext)
+	_, err = provider.SaveK8sContext(token, models.CompressK8sContextForStorage(machinectx.K8sContext))
 	if errors.Is(err, models.ErrContextAlreadyPersisted) {
 		machinectx.log.Info(fmt.Sprintf("context already persisted (\"%s\" at %s)", k8sContext.Name, k8sContext.Server))
 	} else if err != nil {
@@ -51,3 +51,14 @@ func AssignClientSetToContext(machinectx *MachineCtx, eventBuilder *events.Event
 	machinectx.clientset = handler
 	return nil
 }
+
+// SetSyncInterval applies the sync interval of the context, already validated, to the running MeshSync data handler of the connection,
+// the helper keeps the interval to apply it anew whenever the connection is connected.
+func (mc *MachineCtx) SetSyncInterval(syncInterval string) {
+	if mc.MesheryCtrlsHelper == nil {
+		return
+	}
+	interval := models.K8sContext{SyncInterval: syncInterval}.MeshSyncInterval()
+	// the helper is locked while the data handlers are being connected to the broker
+	go mc.MesheryCtrlsHelper.SetMeshsyncInterval(mc.K8sContext.ID, interval)
+}
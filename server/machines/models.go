@@ -4,10 +4,12 @@ import (
 	"context"
 	"fmt"
 	"sync"
+	"time"
 
 	"github.com/gofrs/uuid"
 	"github.com/layer5io/meshery/server/models"
 	"github.com/layer5io/meshery/server/models/connections"
+	"github.com/layer5io/meshkit/errors"
 	"github.com/layer5io/meshkit/logger"
 	"github.com/layer5io/meshkit/models/events"
 )
@@ -81,6 +83,105 @@ type StateMachine struct {
 	Log logger.Handler
 
 	Provider models.Provider
+
+	// Webhook notified of the state changes of the machine, optional.
+	Webhook *Webhook
+
+	// FailurePolicy decides when failed transitions are surfaced, optional.
+	// Without a policy every failed transition is surfaced.
+	FailurePolicy *FailurePolicy
+
+	// DescribeStatus explains the state of the machine after each transition, optional.
+	// Without it the reason is derived by DefaultStatusReason.
+	DescribeStatus StatusReasonFunc
+
+	// Error of the last transition, cleared by a successful transition
+	lastError   string
+	lastErrorAt time.Time
+
+	// One line explanation of the current state, persisted along with the status of the connection
+	statusReason string
+
+	// Actor of the last event sent to the machine, on whose behalf the machine acts when no one else does (e.g. recovery)
+	actor struct {
+		user     *models.User
+		systemID *uuid.UUID
+		token    string
+	}
+
+	// Most recent transitions, oldest first, bounded by transitionHistorySize
+	history []Transition
+}
+
+// transitionHistorySize is the number of transitions retained by a machine
+const transitionHistorySize = 50
+
+// Transition is an event sent to a machine along with its outcome
+type Transition struct {
+	Event EventType `json:"event"`
+	From  StateType `json:"from"`
+	To    StateType `json:"to"`
+	Error string    `json:"error,omitempty"`
+	At    time.Time `json:"at"`
+}
+
+// History returns the most recent transitions of the machine, oldest first
+func (sm *StateMachine) History() []Transition {
+	sm.mx.RLock()
+	defer sm.mx.RUnlock()
+	history := make([]Transition, len(sm.history))
+	copy(history, sm.history)
+	return history
+}
+
+// State returns the current state of the machine
+func (sm *StateMachine) State() StateType {
+	sm.mx.RLock()
+	defer sm.mx.RUnlock()
+	return sm.CurrentState
+}
+
+// StatusReason returns the one line explanation of the current state of the machine
+func (sm *StateMachine) StatusReason() string {
+	sm.mx.RLock()
+	defer sm.mx.RUnlock()
+	return sm.statusReason
+}
+
+// ActorContext returns parent carrying the user, system ID and token of the actor of the last event sent to the machine,
+// for the events sent to the machine in the background. Reports false if no event was sent to the machine yet.
+func (sm *StateMachine) ActorContext(parent context.Context) (context.Context, bool) {
+	sm.mx.RLock()
+	defer sm.mx.RUnlock()
+	if sm.actor.user == nil || sm.actor.systemID == nil {
+		return parent, false
+	}
+	ctx := context.WithValue(parent, models.UserCtxKey, sm.actor.user)
+	ctx = context.WithValue(ctx, models.SystemIDKey, sm.actor.systemID)
+	ctx = context.WithValue(ctx, models.TokenCtxKey, sm.actor.token)
+	return ctx, true
+}
+
+// Accepts reports whether the event is a valid transition from the current state of the machine
+func (sm *StateMachine) Accepts(event EventType) bool {
+	sm.mx.RLock()
+	defer sm.mx.RUnlock()
+	_, err := sm.getNextState(event)
+	return err == nil
+}
+
+func (sm *StateMachine) describeStatus(event EventType, state StateType, err error) string {
+	if sm.DescribeStatus != nil {
+		return sm.DescribeStatus(event, state, err)
+	}
+	return DefaultStatusReason(event, state, err)
+}
+
+// LastError returns the error of the last transition and when it happened, empty if the last transition succeeded
+func (sm *StateMachine) LastError() (string, time.Time) {
+	sm.mx.RLock()
+	defer sm.mx.RUnlock()
+	return sm.lastError, sm.lastErrorAt
 }
 
 func (sm *StateMachine) AssignProvider(provider models.Provider) *StateMachine {
@@ -129,6 +230,82 @@ func (sm *StateMachine) getNextState(event EventType) (StateType, error) {
 // wherever possible use the userID and systemID from context as the events can be created from other comps or actors and not only user actors.
 // In cases when the event is received as part of some other event and not explicitly created by an actor, use the useID and systemID of the actor who initially invoked the machine.
 func (sm *StateMachine) SendEvent(ctx context.Context, eventType EventType, payload interface{}) (*events.Event, error) {
+	sm.mx.Lock()
+	previousState := sm.CurrentState
+	if user, ok := ctx.Value(models.UserCtxKey).(*models.User); ok && user != nil {
+		sm.actor.user = user
+		sm.actor.systemID, _ = ctx.Value(models.SystemIDKey).(*uuid.UUID)
+		sm.actor.token, _ = ctx.Value(models.TokenCtxKey).(string)
+	}
+	sm.mx.Unlock()
+
+	event, err := sm.sendEvent(ctx, eventType, payload)
+
+	// A failure within the grace of the failure policy is reported with a Warning, the connection
+	// is reported as errored with a Critical event only once the failures persist.
+	surfaced := true
+	sm.mx.Lock()
+	if err != nil {
+		failures := 1
+		if sm.FailurePolicy != nil {
+			surfaced, failures = sm.FailurePolicy.failed(time.Now())
+		}
+		if surfaced {
+			sm.lastError, sm.lastErrorAt = err.Error(), time.Now()
+			sm.statusReason = sm.describeStatus(eventType, sm.CurrentState, err)
+			if event != nil {
+				event.Severity = events.Critical
+			}
+		} else {
+			sm.Log.Warn(ErrTransientTransition(err, sm.Name, failures))
+			if event != nil {
+				event.Severity = events.Warning
+			}
+		}
+	} else {
+		if sm.FailurePolicy != nil {
+			sm.FailurePolicy.succeeded()
+		}
+		sm.lastError, sm.lastErrorAt = "", time.Time{}
+	}
+	transition := Transition{Event: eventType, From: previousState, To: sm.CurrentState, At: time.Now()}
+	if err != nil {
+		transition.Error = err.Error()
+	}
+	if len(sm.history) == transitionHistorySize {
+		sm.history = sm.history[1:]
+	}
+	sm.history = append(sm.history, transition)
+	statusReason, currentState := sm.statusReason, sm.CurrentState
+	sm.mx.Unlock()
+
+	// The status of the connection is left as is by a failed transition, only the reason of the failure is recorded.
+	if err != nil && surfaced && sm.Provider != nil && errors.GetCode(err) != models.ErrUpdateConnectionStatusCode {
+		token, _ := ctx.Value(models.TokenCtxKey).(string)
+		if _, _, updateErr := sm.Provider.UpdateConnectionStatusByID(token, sm.ID, connections.ConnectionStatus(currentState), statusReason); updateErr != nil {
+			sm.Log.Debug("unable to record the status reason of connection ", sm.ID, ": ", updateErr)
+		}
+	}
+
+	if sm.Webhook != nil && notifiesWebhook(previousState, currentState, err != nil && surfaced) {
+		notification := StateChangeNotification{
+			ConnectionID:  sm.ID,
+			Kind:          sm.Name,
+			UserID:        sm.UserID,
+			PreviousState: previousState,
+			CurrentState:  currentState,
+			Event:         event,
+			Timestamp:     time.Now(),
+		}
+		if err != nil {
+			notification.Error = err.Error()
+		}
+		sm.Webhook.Notify(notification)
+	}
+	return event, err
+}
+
+func (sm *StateMachine) sendEvent(ctx context.Context, eventType EventType, payload interface{}) (*events.Event, error) {
 	user, _ := ctx.Value(models.UserCtxKey).(*models.User)
 	sysID, _ := ctx.Value(models.SystemIDKey).(*uuid.UUID)
 	userUUID := uuid.FromStringOrNil(user.ID)
@@ -138,6 +315,7 @@ func (sm *StateMachine) SendEvent(ctx context.Context, eventType EventType, payl
 	defer sm.mx.Unlock()
 	var event *events.Event
 	var err error
+	requestedEvent := eventType
 	for {
 		if eventType == NoOp {
 			break
@@ -202,9 +380,10 @@ func (sm *StateMachine) SendEvent(ctx context.Context, eventType EventType, payl
 		sm.CurrentState = nextState
 	}
 
+	sm.statusReason = sm.describeStatus(requestedEvent, sm.CurrentState, nil)
 	if sm.Provider != nil && eventType != Exit {
 		token, _ := ctx.Value(models.TokenCtxKey).(string)
-		connection, statusCode, err := sm.Provider.UpdateConnectionStatusByID(token, sm.ID, connections.ConnectionStatus(sm.CurrentState))
+		connection, statusCode, err := sm.Provider.UpdateConnectionStatusByID(token, sm.ID, connections.ConnectionStatus(sm.CurrentState), sm.statusReason)
 
 		if err != nil {
 			// In this case should the current state be again set to previous state i.e. should we rollback. But not only state should be rollback but other actions as well, rn we don't rollback state.
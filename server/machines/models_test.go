@@ -0,0 +1,45 @@
+package machines
+
+import (
+	"context"
+	"testing"
+
+	"github.com/gofrs/uuid"
+	"github.com/layer5io/meshery/server/models"
+	"github.com/layer5io/meshkit/logger"
+)
+
+func TestActorContext(t *testing.T) {
+	log, _ := logger.New("test", logger.Options{})
+	sm := &StateMachine{
+		CurrentState: REGISTERED,
+		States: States{
+			REGISTERED: State{Events: Events{Connect: CONNECTED}},
+		},
+		Log: log,
+	}
+	if !sm.Accepts(Connect) || sm.Accepts(Disconnect) {
+		t.Error("expected the machine to accept connect and not disconnect from the registered state")
+	}
+
+	if _, ok := sm.ActorContext(context.Background()); ok {
+		t.Error("expected no actor before any event was sent to the machine")
+	}
+
+	sysID := uuid.Must(uuid.NewV4())
+	ctx := context.WithValue(context.Background(), models.UserCtxKey, &models.User{ID: "user"})
+	ctx = context.WithValue(ctx, models.SystemIDKey, &sysID)
+	ctx = context.WithValue(ctx, models.TokenCtxKey, "token")
+	_, _ = sm.SendEvent(ctx, NoOp, nil)
+
+	actorCtx, ok := sm.ActorContext(context.Background())
+	if !ok {
+		t.Fatal("expected the actor of the last event to be recorded")
+	}
+	if user, _ := actorCtx.Value(models.UserCtxKey).(*models.User); user == nil || user.ID != "user" {
+		t.Errorf("actor user = %v, want user", user)
+	}
+	if token, _ := actorCtx.Value(models.TokenCtxKey).(string); token != "token" {
+		t.Errorf("actor token = %q, want token", token)
+	}
+}
@@ -29,3 +29,14 @@ func (smt *ConnectionToStateMachineInstanceTracker) Add(id uuid.UUID, inst *Stat
 	defer smt.mx.Unlock()
 	smt.ConnectToInstanceMap[id] = inst
 }
+
+// List returns the machines of all the tracked connections
+func (smt *ConnectionToStateMachineInstanceTracker) List() []*StateMachine {
+	smt.mx.RLock()
+	defer smt.mx.RUnlock()
+	insts := make([]*StateMachine, 0, len(smt.ConnectToInstanceMap))
+	for _, inst := range smt.ConnectToInstanceMap {
+		insts = append(insts, inst)
+	}
+	return insts
+}
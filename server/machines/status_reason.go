@@ -0,0 +1,61 @@
+package machines
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/layer5io/meshkit/errors"
+)
+
+// StatusReasonFunc explains in one line why a machine is in its state.
+// err is the error of the transition triggered by the event, nil if the machine transitioned to the state.
+type StatusReasonFunc func(event EventType, state StateType, err error) string
+
+// maxStatusReasonLength bounds the reason derived from a transition error, which may carry whole API responses
+const maxStatusReasonLength = 200
+
+// DefaultStatusReason explains the states of the machines whose connections are not attributed to a source
+func DefaultStatusReason(event EventType, state StateType, err error) string {
+	if err != nil {
+		// The long description of meshkit errors may be empty, the short one is used then
+		cause := err.Error()
+		if cause == "" {
+			cause = errors.GetSDescription(err)
+		}
+		cause, _, _ = strings.Cut(cause, "\n")
+		if len(cause) > maxStatusReasonLength {
+			cause = cause[:maxStatusReasonLength] + "..."
+		}
+		return fmt.Sprintf("%s failed: %s", event, cause)
+	}
+
+	switch state {
+	case IGNORED:
+		return "ignored by user"
+	case DISCONNECTED:
+		return "disconnected by user"
+	case DELETED:
+		return "deleted by user"
+	case NOTFOUND:
+		return "not found"
+	case MAINTENANCE:
+		return "under maintenance"
+	}
+	return string(state)
+}
+
+// StatusReasonWithSource attributes the discovered, registered and connected states to the source the connection
+// was onboarded through (e.g. "registered via upload").
+func StatusReasonWithSource(source string) StatusReasonFunc {
+	return func(event EventType, state StateType, err error) string {
+		reason := DefaultStatusReason(event, state, err)
+		if err != nil || source == "" {
+			return reason
+		}
+		switch state {
+		case DISCOVERED, REGISTERED, CONNECTED:
+			return fmt.Sprintf("%s via %s", reason, strings.ReplaceAll(source, "_", " "))
+		}
+		return reason
+	}
+}
@@ -0,0 +1,31 @@
+package machines
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestStatusReason(t *testing.T) {
+	upload := StatusReasonWithSource("upload")
+	tests := []struct {
+		name   string
+		reason StatusReasonFunc
+		event  EventType
+		state  StateType
+		err    error
+		want   string
+	}{
+		{"registered via upload", upload, Register, REGISTERED, nil, "registered via upload"},
+		{"in cluster", StatusReasonWithSource("in_cluster"), Connect, CONNECTED, nil, "connected via in cluster"},
+		{"ignored", upload, Ignore, IGNORED, nil, "ignored by user"},
+		{"failed", upload, Connect, REGISTERED, fmt.Errorf("dial timeout\nretrying"), "connect failed: dial timeout"},
+		{"without source", DefaultStatusReason, Register, REGISTERED, nil, "registered"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.reason(tt.event, tt.state, tt.err); got != tt.want {
+				t.Errorf("status reason = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,119 @@
+package machines
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gofrs/uuid"
+	"github.com/layer5io/meshkit/logger"
+	"github.com/layer5io/meshkit/models/events"
+	"github.com/spf13/viper"
+)
+
+// WebhookSignatureHeader carries the hex encoded HMAC-SHA256 of the request body, keyed with the webhook secret.
+const WebhookSignatureHeader = "X-Meshery-Signature-256"
+
+var webhookClient = &http.Client{Timeout: 10 * time.Second}
+
+// Webhook notifies an external system of the state changes of connections.
+type Webhook struct {
+	URL     string
+	Secret  string
+	Retries int
+	Backoff time.Duration
+	Log     logger.Handler
+}
+
+// StateChangeNotification is the payload POSTed to the webhook. Error is set when the transition failed,
+// the connection is then left in CurrentState.
+type StateChangeNotification struct {
+	ConnectionID  uuid.UUID     `json:"connection_id"`
+	Kind          string        `json:"kind"`
+	UserID        uuid.UUID     `json:"user_id"`
+	PreviousState StateType     `json:"previous_state"`
+	CurrentState  StateType     `json:"current_state"`
+	Error         string        `json:"error,omitempty"`
+	Event         *events.Event `json:"event,omitempty"`
+	Timestamp     time.Time     `json:"timestamp"`
+}
+
+// notifiesWebhook reports whether the webhook is notified of a transition, it is notified when the connection
+// becomes connected and when a transition of the connection fails.
+func notifiesWebhook(previousState, currentState StateType, failed bool) bool {
+	return failed || (currentState == CONNECTED && previousState != CONNECTED)
+}
+
+// NewWebhookFromConfig returns the webhook configured through "CONNECTION_WEBHOOK_URL",
+// or nil when no webhook is configured.
+func NewWebhookFromConfig(log logger.Handler) *Webhook {
+	url := viper.GetString("CONNECTION_WEBHOOK_URL")
+	if url == "" {
+		return nil
+	}
+	return &Webhook{
+		URL:     url,
+		Secret:  viper.GetString("CONNECTION_WEBHOOK_SECRET"),
+		Retries: viper.GetInt("CONNECTION_WEBHOOK_RETRIES"),
+		Backoff: viper.GetDuration("CONNECTION_WEBHOOK_BACKOFF"),
+		Log:     log,
+	}
+}
+
+// Notify delivers the notification in the background, retrying with exponential backoff on failure.
+func (wh *Webhook) Notify(notification StateChangeNotification) {
+	go func() {
+		body, err := json.Marshal(notification)
+		if err != nil {
+			wh.Log.Error(ErrWebhookNotification(err, wh.URL))
+			return
+		}
+
+		backoff := wh.Backoff
+		for attempt := 0; ; attempt++ {
+			err = wh.post(body)
+			if err == nil {
+				return
+			}
+			if attempt >= wh.Retries {
+				break
+			}
+			time.Sleep(backoff)
+			backoff *= 2
+		}
+		wh.Log.Error(ErrWebhookNotification(err, wh.URL))
+	}()
+}
+
+func (wh *Webhook) post(body []byte) error {
+	req, err := http.NewRequest(http.MethodPost, wh.URL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if wh.Secret != "" {
+		req.Header.Set(WebhookSignatureHeader, "sha256="+SignWebhookPayload(wh.Secret, body))
+	}
+
+	resp, err := webhookClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("webhook responded with status %s", resp.Status)
+	}
+	return nil
+}
+
+// SignWebhookPayload returns the hex encoded HMAC-SHA256 of the body, receivers use it to verify the notifications.
+func SignWebhookPayload(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	_, _ = mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
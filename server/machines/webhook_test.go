@@ -0,0 +1,89 @@
+package machines
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gofrs/uuid"
+	"github.com/layer5io/meshkit/logger"
+)
+
+func TestWebhookNotify(t *testing.T) {
+	var mx sync.Mutex
+	attempts := 0
+	delivered := make(chan struct{})
+	var signature, expectedSignature string
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mx.Lock()
+		defer mx.Unlock()
+		attempts++
+		// fail the first delivery to exercise the retry
+		if attempts == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		body, _ := io.ReadAll(r.Body)
+		signature = r.Header.Get(WebhookSignatureHeader)
+		expectedSignature = "sha256=" + SignWebhookPayload("secret", body)
+		close(delivered)
+	}))
+	defer ts.Close()
+
+	log, err := logger.New("test", logger.Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	wh := &Webhook{
+		URL:     ts.URL,
+		Secret:  "secret",
+		Retries: 3,
+		Backoff: 10 * time.Millisecond,
+		Log:     log,
+	}
+	wh.Notify(StateChangeNotification{
+		ConnectionID:  uuid.Must(uuid.NewV4()),
+		Kind:          "kubernetes",
+		PreviousState: REGISTERED,
+		CurrentState:  CONNECTED,
+		Timestamp:     time.Now(),
+	})
+
+	select {
+	case <-delivered:
+	case <-time.After(5 * time.Second):
+		t.Fatal("webhook was not notified")
+	}
+
+	mx.Lock()
+	defer mx.Unlock()
+	if attempts != 2 {
+		t.Errorf("webhook notified in %d attempts, want 2", attempts)
+	}
+	if signature != expectedSignature {
+		t.Errorf("signature = %s, want %s", signature, expectedSignature)
+	}
+}
+
+func TestNotifiesWebhook(t *testing.T) {
+	tests := []struct {
+		previousState, currentState StateType
+		failed                      bool
+		want                        bool
+	}{
+		{REGISTERED, CONNECTED, false, true},
+		{CONNECTED, CONNECTED, false, false},
+		{CONNECTED, DISCONNECTED, false, false},
+		{DISCOVERED, REGISTERED, false, false},
+		{REGISTERED, REGISTERED, true, true},
+	}
+	for _, tt := range tests {
+		if got := notifiesWebhook(tt.previousState, tt.currentState, tt.failed); got != tt.want {
+			t.Errorf("notifiesWebhook(%s, %s, %t) = %t, want %t", tt.previousState, tt.currentState, tt.failed, got, tt.want)
+		}
+	}
+}
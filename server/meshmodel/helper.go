@@ -114,7 +114,7 @@ func (erh *EntityRegistrationHelper) generateComponents(pathToComponents string)
 			// Only register components that have been marked as published
 			if comp.Metadata != nil && comp.Metadata["published"] == true {
 				// Generate SVGs for the component and save them on the file system
-				utils.WriteSVGsOnFileSystem(&comp)
+				utils.WriteSVGs(&comp)
 				erh.componentChan <- comp
 			}
 		}
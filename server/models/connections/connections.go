@@ -65,14 +65,16 @@ type GrafanaCred struct {
 
 // swagger:response Connection
 type Connection struct {
-	ID           uuid.UUID                      `json:"id,omitempty" db:"id"`
-	Name         string                         `json:"name,omitempty" db:"name"`
-	CredentialID uuid.UUID                      `json:"credential_id,omitempty" db:"credential_id"`
-	Type         string                         `json:"type,omitempty" db:"type"`
-	SubType      string                         `json:"sub_type,omitempty" db:"sub_type"`
-	Kind         string                         `json:"kind,omitempty" db:"kind"`
-	Metadata     map[string]interface{}         `json:"metadata,omitempty" db:"metadata"`
-	Status       ConnectionStatus               `json:"status,omitempty" db:"status"`
+	ID           uuid.UUID              `json:"id,omitempty" db:"id"`
+	Name         string                 `json:"name,omitempty" db:"name"`
+	CredentialID uuid.UUID              `json:"credential_id,omitempty" db:"credential_id"`
+	Type         string                 `json:"type,omitempty" db:"type"`
+	SubType      string                 `json:"sub_type,omitempty" db:"sub_type"`
+	Kind         string                 `json:"kind,omitempty" db:"kind"`
+	Metadata     map[string]interface{} `json:"metadata,omitempty" db:"metadata"`
+	Status       ConnectionStatus       `json:"status,omitempty" db:"status"`
+	// One line explanation of the status, e.g. "registered via upload" or "connect failed: dial timeout"
+	StatusReason string                         `json:"status_reason,omitempty" db:"status_reason"`
 	UserID       *uuid.UUID                     `json:"user_id,omitempty" db:"user_id"`
 	CreatedAt    time.Time                      `json:"created_at,omitempty" db:"created_at"`
 	UpdatedAt    time.Time                      `json:"updated_at,omitempty" db:"updated_at"`
@@ -189,6 +189,11 @@ func (l *DefaultLocalProvider) HandleUnAuthenticated(w http.ResponseWriter, req
 	http.Redirect(w, req, "/user/login", http.StatusFound)
 }
 
+// SupportsK8sContextPersistence returns true if the contexts persister of the provider is set
+func (l *DefaultLocalProvider) SupportsK8sContextPersistence() bool {
+	return l.MesheryK8sContextPersister != nil
+}
+
 func (l *DefaultLocalProvider) SaveK8sContext(_ string, k8sContext K8sContext) (connections.Connection, error) {
 	return l.MesheryK8sContextPersister.SaveMesheryK8sContext(k8sContext)
 }
@@ -222,6 +227,30 @@ func (l *DefaultLocalProvider) GetK8sContext(_, id string) (K8sContext, error) {
 	return l.MesheryK8sContextPersister.GetMesheryK8sContext(id)
 }
 
+func (l *DefaultLocalProvider) SetK8sContextPinned(_, id string, pinned bool) (K8sContext, error) {
+	return l.MesheryK8sContextPersister.SetMesheryK8sContextPinned(id, pinned)
+}
+
+func (l *DefaultLocalProvider) SetK8sContextNotes(_, id, notes string) (K8sContext, error) {
+	return l.MesheryK8sContextPersister.SetMesheryK8sContextNotes(id, notes)
+}
+
+func (l *DefaultLocalProvider) SetK8sContextSyncInterval(_, id, syncInterval string) (K8sContext, error) {
+	return l.MesheryK8sContextPersister.SetMesheryK8sContextSyncInterval(id, syncInterval)
+}
+
+func (l *DefaultLocalProvider) PatchK8sContextsLabels(_ string, ids []string, patch K8sContextLabelsPatch) []K8sContextLabelsResult {
+	return l.MesheryK8sContextPersister.PatchMesheryK8sContextsLabels(ids, patch)
+}
+
+func (l *DefaultLocalProvider) SetK8sContextDeletedAt(_, id string, deletedAt *time.Time) (K8sContext, error) {
+	return l.MesheryK8sContextPersister.SetMesheryK8sContextDeletedAt(id, deletedAt)
+}
+
+func (l *DefaultLocalProvider) GetDeletedK8sContexts(_ string) ([]*K8sContext, error) {
+	return l.MesheryK8sContextPersister.GetDeletedMesheryK8sContexts()
+}
+
 func (l *DefaultLocalProvider) LoadAllK8sContext(token string) ([]*K8sContext, error) {
 	page := 0
 	pageSize := 25
@@ -995,7 +1024,7 @@ func (l *DefaultLocalProvider) UpdateConnection(_ *http.Request, _ *connections.
 	return nil, ErrLocalProviderSupport
 }
 
-func (l *DefaultLocalProvider) UpdateConnectionStatusByID(token string, connectionID uuid.UUID, connectionStatus connections.ConnectionStatus) (*connections.Connection, int, error) {
+func (l *DefaultLocalProvider) UpdateConnectionStatusByID(token string, connectionID uuid.UUID, connectionStatus connections.ConnectionStatus, statusReason string) (*connections.Connection, int, error) {
 	return nil, http.StatusForbidden, ErrLocalProviderSupport
 }
 
@@ -3,6 +3,7 @@ package models
 
 import (
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/layer5io/meshkit/errors"
@@ -105,6 +106,37 @@ const (
 	ErrPrometheusScanCode                 = "1549"
 	ErrGrafanaScanCode                    = "1550"
 	ErrDBCreateCode                       = "1557"
+	ErrInvalidProxyURLCode                = "1572"
+	ErrClockSkewCode                      = "1573"
+	ErrInvalidServerURLCode               = "1574"
+	ErrCompressK8sContextCode             = "1577"
+	ErrInvalidK8sMeshModelTemplateCode    = "1578"
+	ErrNoPrimaryK8sContextCode            = "1579"
+	ErrExecCredentialBinaryNotFoundCode   = "1580"
+	ErrInvalidTransportTimeoutCode        = "1583"
+	ErrTokenFileNotReadableCode           = "1584"
+	ErrInvalidRegistryHostCode            = "1585"
+	ErrInvalidConnectionTTLCode           = "1587"
+	ErrInvalidK8sClientRateLimitCode      = "1589"
+	ErrInvalidKubeconfigStructureCode     = "1593"
+	ErrUnusableK8sContextCode             = "1596"
+	ErrInvalidK8sContextNameTemplateCode  = "1598"
+	ErrInvalidKubeconfigSecretRefCode     = "1599"
+	ErrReadKubeconfigSecretCode           = "1600"
+	ErrInvalidPortableK8sContextCode      = "1601"
+	ErrInvalidK8sSyncIntervalCode         = "1602"
+	ErrMeshSyncNotConnectedCode           = "1603"
+	ErrMeshSyncResyncTimeoutCode          = "1604"
+	ErrInvalidK8sContextLabelsCode        = "1606"
+	ErrK8sContextLabelsNotAppliedCode     = "1607"
+	ErrRenderOperatorManifestCode         = "1608"
+	ErrCheckClockSkewCode                 = "1613"
+	ErrInvalidRegistryHostMetadataCode    = "1616"
+	ErrUnregisterK8sContextComponentsCode = "1617"
+	ErrServerOverrideContextNotFoundCode  = "1620"
+	ErrLoadK8sContextsCode                = "1624"
+	ErrMeshSyncResyncCancelledCode        = "1626"
+	ErrPersistEventOfActionCode           = "1609"
 )
 
 var (
@@ -132,6 +164,7 @@ var (
 	ErrContextID               = errors.New(ErrContextIDCode, errors.Alert, []string{"Error: Context ID is empty"}, []string{}, []string{}, []string{})
 	ErrMesheryInstanceID       = errors.New(ErrMesheryInstanceIDCode, errors.Alert, []string{"Error: Meshery Instance ID is empty or is invalid"}, []string{}, []string{}, []string{})
 	ErrMesheryNotInCluster     = errors.New(ErrMesheryNotInClusterCode, errors.Alert, []string{"Error: Meshery is not running inside a cluster"}, []string{}, []string{}, []string{})
+	ErrNoPrimaryK8sContext     = errors.New(ErrNoPrimaryK8sContextCode, errors.Alert, []string{"No reachable Kubernetes connection to use as the primary one"}, []string{"Meshery is not running in a cluster or its cluster is unreachable, and none of the out-of-cluster connections is reachable."}, []string{"No Kubernetes connection is saved.", "The API servers of the saved connections are unreachable."}, []string{"Upload a kubeconfig of a reachable cluster or set \"KUBERNETES_PRIMARY_CONTEXT\" to the connection to use."})
 	ErrContextAlreadyPersisted = errors.New(ErrContextAlreadyPersistedCode, errors.Alert, []string{"kubernetes context already persisted with provider"}, []string{"kubernetes context already persisted with provider"}, []string{}, []string{})
 )
 
@@ -375,6 +408,10 @@ func ErrUnreachableKubeAPI(err error, server string) error {
 	return errors.New(ErrUnreachableKubeAPICode, errors.Alert, []string{fmt.Sprintf("Error communicating with KubeAPI at %s.", server)}, []string{err.Error()}, []string{"The Kubernetes API server is not reachable.", "Credentials are invalid."}, []string{"Verify network connectivity and Kubernetes API responsiveness between Meshery Server and your cluster.", "Ensure client credential is not expired and is properly formed.", "Remove the cluster credential and enable 'insecure-skip-tls-verify'."})
 }
 
+func ErrInvalidProxyURL(err error, proxyURL string) error {
+	return errors.New(ErrInvalidProxyURLCode, errors.Alert, []string{fmt.Sprintf("Invalid proxy URL %s.", proxyURL)}, []string{err.Error()}, []string{"The proxy URL is malformed.", "The proxy scheme is not supported."}, []string{"Use a proxy URL of the form http://host:port, https://host:port or socks5://host:port."})
+}
+
 func ErrFlushMeshSyncData(err error, contextName, server string) error {
 	return errors.New(ErrFlushMeshSyncDataCode, errors.Alert, []string{"Unable to flush MeshSync data for context %s at %s "}, []string{err.Error()}, []string{"Meshery Database handler is not accessible to perform operations"}, []string{"Restart Meshery Server or Perform Hard Reset"})
 }
@@ -406,3 +443,119 @@ func ErrPrometheusScan(err error) error {
 func ErrDBCreate(err error) error {
 	return errors.New(ErrDBCreateCode, errors.Alert, []string{"Unable to create record"}, []string{err.Error()}, []string{"Record already exist", "Database connection is not reachable"}, []string{"Delete the record or try updating the record instead of recreating", "Rest the database connection"})
 }
+
+func ErrClockSkew(skew time.Duration, server string) error {
+	return errors.New(ErrClockSkewCode, errors.Alert, []string{fmt.Sprintf("Clock of the Kubernetes API server at %s is skewed by %s from the clock of Meshery Server.", server, skew)}, []string{"Certificates and tokens may be reported as expired or not yet valid because of the clock skew."}, []string{"The clock of the Meshery Server host or of the cluster nodes is not synchronized."}, []string{"Synchronize the clocks of the Meshery Server host and of the cluster nodes, for example using NTP."})
+}
+
+func ErrCheckClockSkew(err error, server string) error {
+	return errors.New(ErrCheckClockSkewCode, errors.Alert, []string{fmt.Sprintf("unable to check the clock skew with the Kubernetes API server at %s", server)}, []string{err.Error()}, []string{"The API server did not respond with its Date header.", "The API server is not reachable."}, []string{"Make sure the API server is reachable from Meshery Server."})
+}
+
+func ErrInvalidServerURL(err error, server string) error {
+	return errors.New(ErrInvalidServerURLCode, errors.Alert, []string{fmt.Sprintf("Invalid Kubernetes API server URL %s.", server)}, []string{err.Error()}, []string{"The API server URL is malformed or is not an http(s) URL."}, []string{"Use an API server URL of the form https://host:port."})
+}
+
+func ErrServerOverrideContextNotFound(contextName string) error {
+	return errors.New(ErrServerOverrideContextNotFoundCode, errors.Alert, []string{fmt.Sprintf("Context %s of the server override not found.", contextName)}, []string{fmt.Sprintf("The kubeconfig has no context %s, or its cluster is missing.", contextName)}, []string{"The context named for the server override is not in the kubeconfig.", "The kubeconfig has no current-context."}, []string{"Name a context of the kubeconfig whose cluster the server override applies to, or set the current-context of the kubeconfig."})
+}
+
+func ErrCompressK8sContext(err error, name string) error {
+	return errors.New(ErrCompressK8sContextCode, errors.Alert, []string{fmt.Sprintf("Unable to compress or decompress the credentials of the Kubernetes context %s.", name)}, []string{err.Error()}, []string{"The stored credentials of the context are not valid gzip compressed data.", "The credentials of the context could not be serialized."}, []string{"Delete the connection and upload the kubeconfig again."})
+}
+
+func ErrInvalidK8sMeshModelTemplate(err error, path string) error {
+	return errors.New(ErrInvalidK8sMeshModelTemplateCode, errors.Alert, []string{fmt.Sprintf("Malformed Kubernetes model template %s, falling back to the default metadata.", path)}, []string{err.Error()}, []string{"The model template is not valid JSON or a field of it has an unexpected type."}, []string{"Fix the offending field of the model template or restore it from the Meshery release."})
+}
+
+func ErrExecCredentialBinaryNotFound(err error, command string) error {
+	return errors.New(ErrExecCredentialBinaryNotFoundCode, errors.Alert, []string{fmt.Sprintf("exec credential binary '%s' not found on server", command)}, []string{fmt.Sprintf("exec credential binary '%s' not found on server: %s", command, err.Error())}, []string{"The kubeconfig authenticates through an exec credential plugin (e.g. aws-iam-authenticator, gke-gcloud-auth-plugin) which is not installed where Meshery Server runs.", "The plugin is installed but not on the PATH of Meshery Server."}, []string{fmt.Sprintf("Install %s on the host or in the image of Meshery Server and make sure it is on the PATH.", command), "Upload a kubeconfig with static credentials (e.g. a service account token) instead."})
+}
+
+func ErrInvalidTransportTimeout(err error, name, value string) error {
+	return errors.New(ErrInvalidTransportTimeoutCode, errors.Alert, []string{fmt.Sprintf("Invalid %s %s.", name, value)}, []string{err.Error()}, []string{"The timeout is not a valid Go duration.", "The timeout is negative."}, []string{"Use a Go duration for the timeout, e.g. \"5s\" or \"1m30s\", or \"0\" for no timeout."})
+}
+
+func ErrTokenFileNotReadable(err error, tokenFile string) error {
+	return errors.New(ErrTokenFileNotReadableCode, errors.Alert, []string{fmt.Sprintf("token file '%s' not readable on server", tokenFile)}, []string{fmt.Sprintf("token file '%s' not readable on server: %s", tokenFile, err.Error())}, []string{"The kubeconfig references the bearer token through a tokenFile, which exists where the kubeconfig was created but not where Meshery Server runs.", "Meshery Server lacks the permissions to read the token file."}, []string{"Inline the token in the kubeconfig (\"token\" instead of \"tokenFile\") before uploading it.", "Mount the token file at the same path for Meshery Server, as is the case for the projected service account token in-cluster."})
+}
+
+func ErrInvalidRegistryHost(err error, hostname string) error {
+	return errors.New(ErrInvalidRegistryHostCode, errors.Alert, []string{fmt.Sprintf("Invalid registry host %s.", hostname)}, []string{err.Error()}, []string{"The hostname of the registry host is not a valid DNS subdomain."}, []string{"Use a lowercase hostname made of alphanumeric characters, '-' and '.', e.g. \"kubernetes\" or \"registry.example.com\"."})
+}
+
+func ErrInvalidRegistryHostMetadata(err error, metadata string) error {
+	return errors.New(ErrInvalidRegistryHostMetadataCode, errors.Alert, []string{fmt.Sprintf("Invalid registry host metadata %q.", metadata)}, []string{err.Error()}, []string{"The metadata of the registry host is too long or is not printable text."}, []string{fmt.Sprintf("Use printable text of at most %d characters without leading or trailing whitespace.", maxRegistryHostMetadataLength)})
+}
+
+func ErrUnregisterK8sContextComponents(err error, ctxName string) error {
+	return errors.New(ErrUnregisterK8sContextComponentsCode, errors.Alert, []string{fmt.Sprintf("Unable to unregister the components of the deleted Kubernetes context %s.", ctxName)}, []string{err.Error()}, []string{"The registry database is not reachable."}, []string{"The components are left in the registry, check the database of Meshery Server."})
+}
+
+func ErrInvalidConnectionTTL(err error, ttl string) error {
+	return errors.New(ErrInvalidConnectionTTLCode, errors.Alert, []string{fmt.Sprintf("Invalid connection ttl %s.", ttl)}, []string{err.Error()}, []string{"The ttl is not a valid Go duration.", "The ttl is not positive."}, []string{"Use a positive Go duration for the ttl, e.g. \"2h\" or \"30m\"."})
+}
+
+func ErrInvalidK8sClientRateLimit(err error, name, value string) error {
+	return errors.New(ErrInvalidK8sClientRateLimitCode, errors.Alert, []string{fmt.Sprintf("Invalid %s %s.", name, value)}, []string{err.Error()}, []string{"The qps is not a number or the burst is not an integer.", "The rate limit is negative."}, []string{"Use a positive number for the qps, e.g. \"20\", and a positive integer for the burst, e.g. \"40\", or leave them empty for the defaults."})
+}
+
+func ErrInvalidKubeconfigStructure(err error) error {
+	return errors.New(ErrInvalidKubeconfigStructureCode, errors.Alert, []string{"The file is not a kubeconfig."}, []string{err.Error()}, []string{"The file is not YAML or JSON.", "The file is a different kind of Kubernetes manifest.", "The file has none of the clusters, contexts and users of a kubeconfig."}, []string{"Upload the kubeconfig of the cluster, e.g. as written by \"kubectl config view --raw\"."})
+}
+
+func ErrUnusableK8sContext(name string, missing []string) error {
+	return errors.New(ErrUnusableK8sContextCode, errors.Alert, []string{fmt.Sprintf("Kubernetes context %s is not usable", name)}, []string{fmt.Sprintf("the stored configuration of Kubernetes context %s is missing its %s", name, strings.Join(missing, ", "))}, []string{"The connection was saved from an incomplete kubeconfig.", "The stored configuration of the connection was corrupted."}, []string{"Upload the kubeconfig of the cluster again to refresh the configuration of the connection."})
+}
+
+func ErrInvalidK8sContextNameTemplate(err error, nameTemplate string) error {
+	return errors.New(ErrInvalidK8sContextNameTemplateCode, errors.Alert, []string{fmt.Sprintf("invalid naming template %q of the kubernetes connections", nameTemplate)}, []string{err.Error()}, []string{"The template is not a valid Go template.", "The template refers to a field which is not available to name the connections."}, []string{"Use the fields Name, Cluster, Namespace, Server, Host, Distribution, Region, Version and ShortServerID, e.g. \"{{.Distribution}}-{{.Region}}-{{.ShortServerID}}\"."})
+}
+
+func ErrInvalidKubeconfigSecretRef(ref string) error {
+	return errors.New(ErrInvalidKubeconfigSecretRefCode, errors.Alert, []string{fmt.Sprintf("invalid kubeconfig secret %q", ref)}, []string{fmt.Sprintf("%q is not of the form namespace/name or namespace/name/key", ref)}, []string{"\"KUBECONFIG_SECRET\" does not name the secret holding the kubeconfig."}, []string{"Set \"KUBECONFIG_SECRET\" to the namespace and the name of the secret, optionally followed by the key holding the kubeconfig, e.g. \"meshery/clusters/config\"."})
+}
+
+func ErrReadKubeconfigSecret(err error, ref string) error {
+	return errors.New(ErrReadKubeconfigSecretCode, errors.Alert, []string{fmt.Sprintf("unable to read the kubeconfig of secret %s", ref)}, []string{err.Error()}, []string{"The secret does not exist or does not hold the key.", "The service account of Meshery Server is not allowed to get the secret.", "Meshery Server is not running in a cluster."}, []string{"Create the secret with the kubeconfig under the key, e.g. `kubectl create secret generic clusters -n meshery --from-file=config=./kubeconfig`.", "Grant the service account of Meshery Server get on the secret."})
+}
+
+func ErrInvalidPortableK8sContext(err error) error {
+	return errors.New(ErrInvalidPortableK8sContextCode, errors.Alert, []string{"invalid portable kubernetes context"}, []string{err.Error()}, []string{"The portable context was exported by another version of Meshery or has been modified.", "The portable context was exported without its credentials."}, []string{"Export the connection again with \"include_credentials=true\" and \"confirm=true\" and import the exported JSON as is."})
+}
+
+func ErrInvalidK8sSyncInterval(err error, interval string) error {
+	return errors.New(ErrInvalidK8sSyncIntervalCode, errors.Alert, []string{fmt.Sprintf("Invalid sync interval %s.", interval)}, []string{err.Error()}, []string{"The sync interval is not a valid Go duration.", fmt.Sprintf("The sync interval is shorter than the minimum of %s.", MinK8sSyncInterval)}, []string{fmt.Sprintf("Use a Go duration of at least %s for the sync interval, e.g. \"5m\", or leave it empty to not resync periodically.", MinK8sSyncInterval)})
+}
+
+func ErrMeshSyncNotConnected(ctxName string) error {
+	return errors.New(ErrMeshSyncNotConnectedCode, errors.Alert, []string{fmt.Sprintf("MeshSync is not connected for Kubernetes context %s", ctxName)}, []string{"Meshery Server is not subscribed to the resources discovered by MeshSync in the cluster of the context."}, []string{"The connection of the context is not connected.", "Meshery Broker is not reachable from Meshery Server.", "Meshery Operator is not deployed in the cluster."}, []string{"Connect the connection of the context and make sure Meshery Broker is exposed, then resync again."})
+}
+
+func ErrMeshSyncResyncTimeout(ctxName string, timeout time.Duration) error {
+	return errors.New(ErrMeshSyncResyncTimeoutCode, errors.Alert, []string{fmt.Sprintf("MeshSync did not resync Kubernetes context %s within %s", ctxName, timeout)}, []string{"MeshSync did not reply with its store of the resources of the cluster."}, []string{"MeshSync is not running or not connected to Meshery Broker.", "The cluster holds too many resources to be resynced in time."}, []string{"Check the health of MeshSync with GET /api/system/kubernetes/contexts/{connection_id}/meshsync and resync again."})
+}
+
+func ErrInvalidK8sContextLabels(err error) error {
+	return errors.New(ErrInvalidK8sContextLabelsCode, errors.Alert, []string{"Invalid labels of Kubernetes contexts"}, []string{err.Error()}, []string{"A label key or value is not a valid Kubernetes label.", "The label selector is not a valid Kubernetes label selector."}, []string{"Use label keys and values, and selectors, following the syntax of the Kubernetes labels, e.g. \"env=staging\"."})
+}
+
+func ErrK8sContextLabelsNotApplied(failedID string) error {
+	return errors.New(ErrK8sContextLabelsNotAppliedCode, errors.Alert, []string{"Labels of the Kubernetes context not updated"}, []string{fmt.Sprintf("The labels of the contexts are updated together and those of the context %s could not be updated.", failedID)}, []string{"The update of the labels of another context failed, the labels of all the contexts were left unchanged."}, []string{fmt.Sprintf("Fix the failure of the context %s, or leave it out, and update the labels again.", failedID)})
+}
+
+func ErrRenderOperatorManifest(err error, kubeVersion string) error {
+	return errors.New(ErrRenderOperatorManifestCode, errors.Alert, []string{fmt.Sprintf("Unable to render the manifests of Meshery Operator for Kubernetes %s", kubeVersion)}, []string{err.Error()}, []string{"The Meshery Operator chart could not be fetched from its repository.", "The chart does not support the version of the cluster."}, []string{fmt.Sprintf("Make sure %s is reachable from Meshery Server.", ChartRepo), "Check the supported Kubernetes versions of the Meshery Operator chart."})
+}
+
+func ErrLoadK8sContexts(err error) error {
+	return errors.New(ErrLoadK8sContextsCode, errors.Alert, []string{"Unable to load the saved Kubernetes contexts"}, []string{err.Error()}, []string{"The provider is not reachable.", "The provider responded with an error."}, []string{"Make sure the provider is reachable from Meshery Server and try again."})
+}
+
+func ErrMeshSyncResyncCancelled(err error, ctxName string) error {
+	return errors.New(ErrMeshSyncResyncCancelledCode, errors.Alert, []string{fmt.Sprintf("Resync of Kubernetes context %s was cancelled", ctxName)}, []string{err.Error()}, []string{"The request of the resync was cancelled before MeshSync replied, e.g. the client disconnected."}, []string{"Resync again and wait for the response."})
+}
+
+func ErrPersistEventOfAction(err error, category, action string) error {
+	return errors.New(ErrPersistEventOfActionCode, errors.Alert, []string{fmt.Sprintf("failed to persist event with category \"%s\" and action \"%s\"", category, action)}, []string{err.Error()}, []string{"The database of the events is not reachable.", "The provider failed to persist the event."}, []string{"Check the health of the database of Meshery Server, or of the remote provider."})
+}
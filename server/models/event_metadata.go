@@ -0,0 +1,66 @@
+package models
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+)
+
+// DefaultEventMetadataMaxSize is the default of "EVENT_METADATA_MAX_SIZE", the size in bytes the serialized metadata
+// of the events aggregating many entries (e.g. one per context of a kubeconfig upload) is capped to
+const DefaultEventMetadataMaxSize = 256 * 1024
+
+// EventMetadataOmittedEntries is the key of the metadata counting the entries omitted by CapEventMetadata
+const EventMetadataOmittedEntries = "omitted_entries"
+
+// CapEventMetadata caps the serialized size of the metadata of an event to maxSize bytes, so that an event aggregating
+// many entries does not end up as an oversized row of the events store. When the metadata is larger, the entries are kept
+// in the order of their keys until one does not fit and the number of the others is recorded under "omitted_entries".
+// The entries of the keep keys are always kept. A maxSize of 0 or less does not cap the metadata.
+// Returns the metadata, a copy when entries were omitted, and the number of entries omitted.
+func CapEventMetadata(metadata map[string]interface{}, maxSize int, keep ...string) (map[string]interface{}, int) {
+	if maxSize <= 0 || eventMetadataSize(metadata) <= maxSize {
+		return metadata, 0
+	}
+
+	capped := make(map[string]interface{}, len(metadata))
+	kept := make(map[string]bool, len(keep))
+	for _, key := range keep {
+		if value, ok := metadata[key]; ok {
+			capped[key] = value
+			kept[key] = true
+		}
+	}
+	keys := make([]string, 0, len(metadata))
+	for key := range metadata {
+		if !kept[key] {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+
+	// Room is left for the count of the omitted entries
+	size := eventMetadataSize(capped) + len(fmt.Sprintf(`,%q:%d`, EventMetadataOmittedEntries, len(keys)))
+	omitted := 0
+	for _, key := range keys {
+		// Each entry adds its key, its value and the separators
+		entrySize := eventMetadataSize(map[string]interface{}{key: metadata[key]}) - 1
+		if omitted > 0 || size+entrySize > maxSize {
+			omitted++
+			continue
+		}
+		capped[key] = metadata[key]
+		size += entrySize
+	}
+	capped[EventMetadataOmittedEntries] = omitted
+	return capped, omitted
+}
+
+// eventMetadataSize returns the size of the metadata serialized as JSON, the way it is persisted
+func eventMetadataSize(metadata map[string]interface{}) int {
+	byt, err := json.Marshal(metadata)
+	if err != nil {
+		return len(fmt.Sprintf("%v", metadata))
+	}
+	return len(byt)
+}
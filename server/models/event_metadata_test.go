@@ -0,0 +1,48 @@
+package models
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestCapEventMetadata(t *testing.T) {
+	metadata := map[string]interface{}{"rolled_back_connections": []string{"a", "b"}}
+	for i := 0; i < 100; i++ {
+		metadata[fmt.Sprintf("context-%03d", i)] = map[string]interface{}{
+			"description": fmt.Sprintf("Unable to establish connection with context \"context-%03d\"", i),
+			"error":       strings.Repeat("x", 200),
+		}
+	}
+
+	if capped, omitted := CapEventMetadata(metadata, 0); omitted != 0 || len(capped) != len(metadata) {
+		t.Errorf("CapEventMetadata() omitted %d entries, want the metadata not to be capped without a maximum size", omitted)
+	}
+
+	maxSize := 4096
+	capped, omitted := CapEventMetadata(metadata, maxSize, "rolled_back_connections")
+	byt, err := json.Marshal(capped)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(byt) > maxSize {
+		t.Errorf("capped metadata is %d bytes, want at most %d", len(byt), maxSize)
+	}
+	if omitted == 0 || capped[EventMetadataOmittedEntries] != omitted {
+		t.Errorf("omitted %d entries, recorded %v, want the omitted entries counted", omitted, capped[EventMetadataOmittedEntries])
+	}
+	if _, ok := capped["rolled_back_connections"]; !ok {
+		t.Error("capped metadata is missing the kept entry")
+	}
+	// The first contexts are kept, the count of the omitted ones accounts for the others
+	if _, ok := capped["context-000"]; !ok {
+		t.Error("capped metadata is missing the first context")
+	}
+	if kept := len(capped) - 2; kept+omitted != 100 {
+		t.Errorf("kept %d and omitted %d contexts, want all of the 100 contexts accounted for", kept, omitted)
+	}
+	if _, ok := metadata[EventMetadataOmittedEntries]; ok {
+		t.Error("CapEventMetadata() modified the metadata it was given")
+	}
+}
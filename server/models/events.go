@@ -1,12 +1,35 @@
 package models
 
 import (
+	"time"
+
 	"github.com/gofrs/uuid"
+	"github.com/layer5io/meshkit/logger"
 	"github.com/layer5io/meshkit/models/events"
+	"github.com/sirupsen/logrus"
 )
 
+// PersistEvent persists the event using the provider.
+// Failures are logged and counted (see EventPersistFailures) instead of being returned,
+// as the event is auxiliary to the operation which emitted it. Without a provider, the event is not persisted.
+func PersistEvent(provider Provider, log logger.Handler, event *events.Event) {
+	if provider == nil || event == nil {
+		return
+	}
+	if err := provider.PersistEvent(event); err != nil {
+		countEventPersistFailure(event)
+		err = ErrPersistEventOfAction(err, event.Category, event.Action)
+		if log == nil {
+			logrus.Warn(err)
+			return
+		}
+		log.Warn(err)
+	}
+}
+
 type MesheryEvents interface {
 	GetAllEvents(eventFilter *events.EventsFilter, userID uuid.UUID) (*EventsResponse, error)
+	GetAllEventsInTimeRange(eventFilter *events.EventsFilter, from, to time.Time, userID uuid.UUID) (*EventsResponse, error)
 	GetEventTypes(userID uuid.UUID) (map[string]interface{}, error)
 	PersistEvent(data *events.Event) error
 	DeleteEvent(eventID uuid.UUID) error
@@ -1,10 +1,11 @@
 package models
 
 import (
+	"time"
+
 	"github.com/gofrs/uuid"
 	"github.com/layer5io/meshkit/database"
 	"github.com/layer5io/meshkit/models/events"
-	"gorm.io/gorm/clause"
 )
 
 // EventsPersister assists with persisting events in local SQLite DB
@@ -45,9 +46,26 @@ func (e *EventsPersister) GetEventTypes(userID uuid.UUID) (map[string]interface{
 }
 
 func (e *EventsPersister) GetAllEvents(eventsFilter *events.EventsFilter, userID uuid.UUID) (*EventsResponse, error) {
+	return e.GetAllEventsInTimeRange(eventsFilter, time.Time{}, time.Time{}, userID)
+}
+
+// GetAllEventsInTimeRange returns the events created between from and to (inclusive), a zero bound leaves the range open on that side
+func (e *EventsPersister) GetAllEventsInTimeRange(eventsFilter *events.EventsFilter, from, to time.Time, userID uuid.UUID) (*EventsResponse, error) {
 	eventsDB := []*events.Event{}
 	finder := e.DB.Model(&events.Event{}).Where("user_id = ?", userID)
 
+	if len(eventsFilter.ActedUpon) != 0 {
+		finder = finder.Where("acted_upon IN ?", eventsFilter.ActedUpon)
+	}
+
+	if !from.IsZero() {
+		finder = finder.Where("created_at >= ?", from)
+	}
+
+	if !to.IsZero() {
+		finder = finder.Where("created_at <= ?", to)
+	}
+
 	if len(eventsFilter.Category) != 0 {
 		finder = finder.Where("category IN ?", eventsFilter.Category)
 	}
@@ -68,12 +86,15 @@ func (e *EventsPersister) GetAllEvents(eventsFilter *events.EventsFilter, userID
 		finder = finder.Where("status = ?", eventsFilter.Status)
 	}
 
-	sortOn := SanitizeOrderInput(eventsFilter.SortOn, []string{"created_at", "updated_at", "name"})
+	order := "desc"
 	if eventsFilter.Order == "asc" {
-		finder = finder.Order(sortOn)
-	} else {
-		finder = finder.Order(clause.OrderByColumn{Column: clause.Column{Name: sortOn}, Desc: true})
+		order = "asc"
+	}
+	sortOn := SanitizeOrderInput(eventsFilter.SortOn+" "+order, []string{"created_at", "updated_at", "name"})
+	if sortOn == "" {
+		sortOn = "created_at " + order
 	}
+	finder = finder.Order(sortOn)
 
 	var count int64
 	finder.Count(&count)
@@ -0,0 +1,59 @@
+package models
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/gofrs/uuid"
+	"github.com/layer5io/meshkit/database"
+	"github.com/layer5io/meshkit/models/events"
+)
+
+func TestGetAllEventsInTimeRange(t *testing.T) {
+	db, err := database.New(database.Options{Engine: database.SQLITE, Filename: filepath.Join(t.TempDir(), "events.db")})
+	if err != nil {
+		t.Fatalf("failed to open the database: %s", err)
+	}
+	if err := db.AutoMigrate(&events.Event{}); err != nil {
+		t.Fatalf("failed to migrate the database: %s", err)
+	}
+	persister := &EventsPersister{DB: &db}
+
+	userID, _ := uuid.NewV4()
+	connectionID, _ := uuid.NewV4()
+	otherConnectionID, _ := uuid.NewV4()
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	for i, e := range []struct {
+		actedUpon uuid.UUID
+		severity  events.EventSeverity
+	}{
+		{connectionID, events.Informational},
+		{connectionID, events.Error},
+		{otherConnectionID, events.Error},
+		{connectionID, events.Error},
+	} {
+		id, _ := uuid.NewV4()
+		event := &events.Event{ID: id, UserID: &userID, ActedUpon: e.actedUpon, Severity: e.severity, Status: events.Unread, CreatedAt: start.Add(time.Duration(i) * time.Hour)}
+		if err := persister.PersistEvent(event); err != nil {
+			t.Fatalf("PersistEvent() failed with error: %s", err)
+		}
+	}
+
+	filter := &events.EventsFilter{ActedUpon: []string{connectionID.String()}, Severity: []string{string(events.Error)}, SortOn: "created_at"}
+	result, err := persister.GetAllEventsInTimeRange(filter, start.Add(30*time.Minute), start.Add(2*time.Hour), userID)
+	if err != nil {
+		t.Fatalf("GetAllEventsInTimeRange() failed with error: %s", err)
+	}
+	if result.TotalCount != 1 || len(result.Events) != 1 || result.Events[0].ActedUpon != connectionID || !result.Events[0].CreatedAt.Equal(start.Add(time.Hour)) {
+		t.Errorf("GetAllEventsInTimeRange() = %+v, want the error of the connection created in the time range only", result.Events)
+	}
+
+	result, err = persister.GetAllEventsInTimeRange(&events.EventsFilter{ActedUpon: []string{connectionID.String()}, Limit: 2, SortOn: "created_at"}, time.Time{}, time.Time{}, userID)
+	if err != nil {
+		t.Fatalf("GetAllEventsInTimeRange() failed with error: %s", err)
+	}
+	if result.TotalCount != 3 || len(result.Events) != 2 {
+		t.Errorf("GetAllEventsInTimeRange() returned %d of %d events, want a page of 2 of the 3 events of the connection", len(result.Events), result.TotalCount)
+	}
+}
@@ -0,0 +1,39 @@
+package models
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/layer5io/meshkit/logger"
+	"github.com/layer5io/meshkit/models/events"
+	"github.com/sirupsen/logrus"
+)
+
+// failingEventsProvider fails to persist the events
+type failingEventsProvider struct {
+	Provider
+	persisted int
+}
+
+func (p *failingEventsProvider) PersistEvent(_ *events.Event) error {
+	p.persisted++
+	return errors.New("database is locked")
+}
+
+func TestPersistEvent(t *testing.T) {
+	log, err := logger.New("test", logger.Options{LogLevel: int(logrus.InfoLevel)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	event := events.NewEvent().WithCategory("connection").WithAction("recover").Build()
+
+	// The failure is logged as a meshkit error, the logger panics on the others
+	provider := &failingEventsProvider{}
+	PersistEvent(provider, log, event)
+	PersistEvent(provider, nil, event)
+	PersistEvent(provider, log, nil)
+	if provider.persisted != 2 {
+		t.Errorf("%d events persisted, want 2", provider.persisted)
+	}
+	PersistEvent(nil, log, event)
+}
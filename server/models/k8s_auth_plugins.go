@@ -0,0 +1,62 @@
+package models
+
+import (
+	"strings"
+
+	"k8s.io/client-go/rest"
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
+)
+
+// K8sAuthPlugin describes the support for a kubeconfig auth method in this build of Meshery Server
+type K8sAuthPlugin struct {
+	Name string `json:"name"`
+	// Kind is either "auth-provider" (users[].user.auth-provider) or "exec" (users[].user.exec)
+	Kind string `json:"kind"`
+	// Registered reports whether the auth-provider plugin is compiled in
+	Registered bool `json:"registered"`
+	// Supported reports whether kubeconfigs using the auth method can be connected to
+	Supported bool   `json:"supported"`
+	Message   string `json:"message,omitempty"`
+}
+
+// knownK8sAuthProviders are the auth-provider plugins client-go has shipped
+var knownK8sAuthProviders = []string{"gcp", "azure", "oidc"}
+
+// K8sAuthPlugins reports which of the auth methods of kubeconfigs are available at runtime.
+//
+// client-go does not expose the registered auth-provider plugins, hence each of them is probed by instantiating it.
+// Since client-go v0.26 the gcp and azure plugins are stubs which always fail, pointing to their exec replacements,
+// such plugins are reported as registered but unsupported.
+func K8sAuthPlugins() []K8sAuthPlugin {
+	plugins := make([]K8sAuthPlugin, 0, len(knownK8sAuthProviders)+1)
+	for _, name := range knownK8sAuthProviders {
+		plugin := K8sAuthPlugin{
+			Name: name,
+			Kind: "auth-provider",
+		}
+		_, err := rest.GetAuthProvider("", &clientcmdapi.AuthProviderConfig{Name: name, Config: map[string]string{}}, nil)
+		switch {
+		case err == nil:
+			plugin.Registered, plugin.Supported = true, true
+		case strings.Contains(err.Error(), "no Auth Provider found"):
+			plugin.Message = "auth-provider plugin is not compiled into this build of Meshery Server"
+		case strings.Contains(err.Error(), "has been removed"):
+			plugin.Registered = true
+			plugin.Message = err.Error()
+		default:
+			// The plugin is available, it only failed to validate the empty config it was probed with
+			plugin.Registered, plugin.Supported = true, true
+		}
+		plugins = append(plugins, plugin)
+	}
+
+	// Exec credential plugins are built into client-go, they only require the plugin binary to be present on the host of Meshery Server
+	plugins = append(plugins, K8sAuthPlugin{
+		Name:       "exec",
+		Kind:       "exec",
+		Registered: true,
+		Supported:  true,
+		Message:    "the credential plugin command must be available on the host of Meshery Server",
+	})
+	return plugins
+}
@@ -0,0 +1,74 @@
+package models
+
+import (
+	"context"
+
+	"github.com/layer5io/meshkit/utils/kubernetes"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// Capabilities of the clusters detected when testing the contexts or on demand
+const (
+	K8sCapabilityNetworkPolicies     = "network_policies"
+	K8sCapabilityDefaultStorageClass = "default_storage_class"
+	K8sCapabilityIngressController   = "ingress_controller"
+	K8sCapabilityServiceMesh         = "service_mesh"
+)
+
+// serviceMeshAPIGroups are the API groups of the custom resources installed along with the known service meshes
+var serviceMeshAPIGroups = map[string]bool{
+	"networking.istio.io":       true, // Istio
+	"policy.linkerd.io":         true, // Linkerd
+	"consul.hashicorp.com":      true, // Consul
+	"kuma.io":                   true, // Kuma
+	"config.openservicemesh.io": true, // Open Service Mesh
+	"appmesh.k8s.aws":           true, // AWS App Mesh
+	"maistra.io":                true, // OpenShift Service Mesh
+}
+
+// storageClassDefaultAnnotations mark the default StorageClass, the beta one is still set by some provisioners
+var storageClassDefaultAnnotations = []string{"storageclass.kubernetes.io/is-default-class", "storageclass.beta.kubernetes.io/is-default-class"}
+
+// DetectK8sCapabilities detects the notable features of the cluster which the deployment of designs depends upon.
+// The detection is best effort, the capabilities which could not be detected (e.g. for lack of permissions) are left out.
+//   - network_policies: the NetworkPolicy API is served, whether the policies are enforced depends on the network plugin
+//   - default_storage_class: a StorageClass is annotated as the default one
+//   - ingress_controller: an IngressClass is installed
+//   - service_mesh: the custom resources of a known service mesh are installed
+func DetectK8sCapabilities(ctx context.Context, handler *kubernetes.Client) map[string]bool {
+	capabilities := map[string]bool{}
+
+	if resources, err := handler.KubeClient.Discovery().ServerResourcesForGroupVersion("networking.k8s.io/v1"); err == nil {
+		capabilities[K8sCapabilityNetworkPolicies] = false
+		for _, resource := range resources.APIResources {
+			if resource.Name == "networkpolicies" {
+				capabilities[K8sCapabilityNetworkPolicies] = true
+			}
+		}
+	}
+
+	if storageClasses, err := handler.KubeClient.StorageV1().StorageClasses().List(ctx, metav1.ListOptions{}); err == nil {
+		capabilities[K8sCapabilityDefaultStorageClass] = false
+		for _, sc := range storageClasses.Items {
+			for _, annotation := range storageClassDefaultAnnotations {
+				if sc.Annotations[annotation] == "true" {
+					capabilities[K8sCapabilityDefaultStorageClass] = true
+				}
+			}
+		}
+	}
+
+	if ingressClasses, err := handler.KubeClient.NetworkingV1().IngressClasses().List(ctx, metav1.ListOptions{}); err == nil {
+		capabilities[K8sCapabilityIngressController] = len(ingressClasses.Items) > 0
+	}
+
+	if groups, err := handler.KubeClient.Discovery().ServerGroups(); err == nil {
+		capabilities[K8sCapabilityServiceMesh] = false
+		for _, group := range groups.Groups {
+			if serviceMeshAPIGroups[group.Name] {
+				capabilities[K8sCapabilityServiceMesh] = true
+			}
+		}
+	}
+	return capabilities
+}
@@ -0,0 +1,71 @@
+package models
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"k8s.io/client-go/rest"
+)
+
+// DefaultClockSkewThreshold is the skew between the clocks of Meshery and a cluster
+// beyond which certificate and token validation is likely to fail.
+const DefaultClockSkewThreshold = 30 * time.Second
+
+// ClockSkew is the diagnostic of the clock skew between Meshery and a Kubernetes API server.
+type ClockSkew struct {
+	ServerTime       time.Time `json:"server_time"`
+	LocalTime        time.Time `json:"local_time"`
+	SkewSeconds      float64   `json:"skew_seconds"`
+	ThresholdSeconds float64   `json:"threshold_seconds"`
+	Skewed           bool      `json:"skewed"`
+	Message          string    `json:"message,omitempty"`
+}
+
+// CheckClockSkew compares the Date header of the API server response against the local time.
+// Skew is positive when the API server clock is ahead of Meshery's.
+func CheckClockSkew(ctx context.Context, restConfig *rest.Config, threshold time.Duration) (*ClockSkew, error) {
+	client, err := rest.HTTPClientFor(restConfig)
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, strings.TrimSuffix(restConfig.Host, "/")+"/version", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	start := time.Now()
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	end := time.Now()
+
+	date := resp.Header.Get("Date")
+	if date == "" {
+		return nil, fmt.Errorf("API server response does not carry a Date header")
+	}
+	serverTime, err := http.ParseTime(date)
+	if err != nil {
+		return nil, err
+	}
+
+	// The Date header has a one second resolution and is generated at some point
+	// during the round trip, hence compare it with the middle of the round trip.
+	localTime := start.Add(end.Sub(start) / 2)
+	skew := serverTime.Sub(localTime)
+	diagnostic := &ClockSkew{
+		ServerTime:       serverTime,
+		LocalTime:        localTime,
+		SkewSeconds:      skew.Round(time.Second).Seconds(),
+		ThresholdSeconds: threshold.Seconds(),
+	}
+	if skew.Abs() > threshold+time.Second {
+		diagnostic.Skewed = true
+		diagnostic.Message = fmt.Sprintf("Clock of the API server at %s is skewed by %s from the clock of Meshery Server, which exceeds the threshold of %s. Certificate and token validation may fail until the clocks are synchronized.", restConfig.Host, skew.Round(time.Second), threshold)
+	}
+	return diagnostic, nil
+}
@@ -0,0 +1,43 @@
+package models
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"k8s.io/client-go/rest"
+)
+
+func TestCheckClockSkew(t *testing.T) {
+	tests := []struct {
+		name   string
+		offset time.Duration
+		skewed bool
+	}{
+		{name: "synchronized clocks", offset: 0, skewed: false},
+		{name: "API server ahead", offset: 5 * time.Minute, skewed: true},
+		{name: "API server behind", offset: -5 * time.Minute, skewed: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Date", time.Now().Add(tt.offset).UTC().Format(http.TimeFormat))
+				_, _ = w.Write([]byte(`{"gitVersion":"v1.28.3"}`))
+			}))
+			defer ts.Close()
+
+			got, err := CheckClockSkew(context.Background(), &rest.Config{Host: ts.URL}, DefaultClockSkewThreshold)
+			if err != nil {
+				t.Fatalf("CheckClockSkew() failed with error: %s", err)
+			}
+			if got.Skewed != tt.skewed {
+				t.Errorf("CheckClockSkew() skewed = %v, want %v (skew %vs)", got.Skewed, tt.skewed, got.SkewSeconds)
+			}
+			if tt.skewed && got.Message == "" {
+				t.Error("CheckClockSkew() expected a diagnostic message for skewed clocks")
+			}
+		})
+	}
+}
@@ -0,0 +1,105 @@
+package models
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/layer5io/meshkit/models/meshmodel/core/v1alpha1"
+)
+
+// K8sComponentKind is a kind registered as components, along with the versions of its API registered
+type K8sComponentKind struct {
+	// Group of the API of the kind, empty for the core API group
+	Group    string   `json:"group"`
+	Kind     string   `json:"kind"`
+	Versions []string `json:"versions"`
+}
+
+// K8sComponentKindComparison is a kind registered as components for both of the compared contexts
+type K8sComponentKindComparison struct {
+	Group     string   `json:"group"`
+	Kind      string   `json:"kind"`
+	VersionsA []string `json:"versions_a"`
+	VersionsB []string `json:"versions_b"`
+	// VersionsDiffer is set when the versions of the API of the kind registered for the contexts are not the same,
+	// e.g. a CRD served at v1beta1 in one cluster and v1 in the other
+	VersionsDiffer bool `json:"versions_differ"`
+}
+
+// K8sComponentsComparison is the difference between the components registered for two contexts, by kind
+type K8sComponentsComparison struct {
+	OnlyInA []K8sComponentKind           `json:"only_in_a"`
+	OnlyInB []K8sComponentKind           `json:"only_in_b"`
+	InBoth  []K8sComponentKindComparison `json:"in_both"`
+}
+
+// CompareK8sComponents compares the components registered for two contexts by the group and kind of their API,
+// reporting the kinds registered for one of them only and the versions of the kinds registered for both.
+// The kinds are sorted by group then kind.
+func CompareK8sComponents(a, b []v1alpha1.ComponentDefinition) K8sComponentsComparison {
+	kindsA, kindsB := k8sComponentKinds(a), k8sComponentKinds(b)
+	comparison := K8sComponentsComparison{
+		OnlyInA: []K8sComponentKind{},
+		OnlyInB: []K8sComponentKind{},
+		InBoth:  []K8sComponentKindComparison{},
+	}
+	for key, kindA := range kindsA {
+		kindB, ok := kindsB[key]
+		if !ok {
+			comparison.OnlyInA = append(comparison.OnlyInA, *kindA)
+			continue
+		}
+		comparison.InBoth = append(comparison.InBoth, K8sComponentKindComparison{
+			Group:          kindA.Group,
+			Kind:           kindA.Kind,
+			VersionsA:      kindA.Versions,
+			VersionsB:      kindB.Versions,
+			VersionsDiffer: strings.Join(kindA.Versions, ",") != strings.Join(kindB.Versions, ","),
+		})
+	}
+	for key, kindB := range kindsB {
+		if _, ok := kindsA[key]; !ok {
+			comparison.OnlyInB = append(comparison.OnlyInB, *kindB)
+		}
+	}
+
+	sortKinds := func(kinds []K8sComponentKind) {
+		sort.Slice(kinds, func(i, j int) bool {
+			if kinds[i].Group != kinds[j].Group {
+				return kinds[i].Group < kinds[j].Group
+			}
+			return kinds[i].Kind < kinds[j].Kind
+		})
+	}
+	sortKinds(comparison.OnlyInA)
+	sortKinds(comparison.OnlyInB)
+	sort.Slice(comparison.InBoth, func(i, j int) bool {
+		if comparison.InBoth[i].Group != comparison.InBoth[j].Group {
+			return comparison.InBoth[i].Group < comparison.InBoth[j].Group
+		}
+		return comparison.InBoth[i].Kind < comparison.InBoth[j].Kind
+	})
+	return comparison
+}
+
+// k8sComponentKinds groups the components by the group and kind of their API, keyed by group/kind
+func k8sComponentKinds(comps []v1alpha1.ComponentDefinition) map[string]*K8sComponentKind {
+	kinds := make(map[string]*K8sComponentKind, len(comps))
+	for _, comp := range comps {
+		group, version := "", comp.APIVersion
+		if i := strings.LastIndex(comp.APIVersion, "/"); i >= 0 {
+			group, version = comp.APIVersion[:i], comp.APIVersion[i+1:]
+		}
+		key := group + "/" + comp.Kind
+		kind, ok := kinds[key]
+		if !ok {
+			kind = &K8sComponentKind{Group: group, Kind: comp.Kind, Versions: []string{}}
+			kinds[key] = kind
+		}
+		kind.Versions = append(kind.Versions, version)
+	}
+	for _, kind := range kinds {
+		sort.Strings(kind.Versions)
+	}
+	return kinds
+}
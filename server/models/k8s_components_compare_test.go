@@ -0,0 +1,45 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/layer5io/meshkit/models/meshmodel/core/v1alpha1"
+)
+
+func TestCompareK8sComponents(t *testing.T) {
+	component := func(apiVersion, kind string) v1alpha1.ComponentDefinition {
+		return v1alpha1.ComponentDefinition{TypeMeta: v1alpha1.TypeMeta{APIVersion: apiVersion, Kind: kind}}
+	}
+	a := []v1alpha1.ComponentDefinition{
+		component("v1", "Pod"),
+		component("apps/v1", "Deployment"),
+		component("example.com/v1beta1", "Widget"),
+		component("example.com/v1", "Gadget"),
+	}
+	b := []v1alpha1.ComponentDefinition{
+		component("v1", "Pod"),
+		component("apps/v1", "Deployment"),
+		component("example.com/v1", "Widget"),
+		component("batch/v1", "CronJob"),
+	}
+
+	comparison := CompareK8sComponents(a, b)
+	if len(comparison.OnlyInA) != 1 || comparison.OnlyInA[0].Group != "example.com" || comparison.OnlyInA[0].Kind != "Gadget" {
+		t.Errorf("OnlyInA = %+v, want example.com/Gadget", comparison.OnlyInA)
+	}
+	if len(comparison.OnlyInB) != 1 || comparison.OnlyInB[0].Group != "batch" || comparison.OnlyInB[0].Kind != "CronJob" {
+		t.Errorf("OnlyInB = %+v, want batch/CronJob", comparison.OnlyInB)
+	}
+	if len(comparison.InBoth) != 3 {
+		t.Fatalf("InBoth = %+v, want Pod, Deployment and Widget", comparison.InBoth)
+	}
+	for _, kind := range comparison.InBoth {
+		if differ := kind.Kind == "Widget"; kind.VersionsDiffer != differ {
+			t.Errorf("VersionsDiffer of %s = %t, want %t", kind.Kind, kind.VersionsDiffer, differ)
+		}
+	}
+	// Sorted by group, the core API group first
+	if comparison.InBoth[0].Kind != "Pod" || comparison.InBoth[1].Kind != "Deployment" {
+		t.Errorf("InBoth = %+v, want the kinds sorted by group", comparison.InBoth)
+	}
+}
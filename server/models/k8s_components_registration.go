@@ -4,17 +4,23 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
-	"io"
-	"os"
-	"path/filepath"
+	"strings"
 	"sync"
+	"time"
+	"unicode"
 
 	"github.com/gofrs/uuid"
+	guuid "github.com/google/uuid"
 
+	"github.com/layer5io/meshkit/database"
 	"github.com/layer5io/meshkit/logger"
 	"github.com/layer5io/meshkit/models/events"
+	"github.com/layer5io/meshkit/models/meshmodel/core/types"
+	"github.com/layer5io/meshkit/models/meshmodel/core/v1alpha1"
 	meshmodel "github.com/layer5io/meshkit/models/meshmodel/registry"
 	"github.com/spf13/viper"
+	"gorm.io/gorm"
+	"k8s.io/apimachinery/pkg/util/validation"
 )
 
 const k8sMeshModelPath = "../meshmodel/kubernetes/model_template.json"
@@ -27,8 +33,6 @@ const (
 	Registering
 )
 
-var K8sMeshModelMetadata = make(map[string]interface{})
-
 // INstead define a set of actions
 func (rs RegistrationStatus) String() string {
 	switch rs {
@@ -72,16 +76,163 @@ func (cg *ComponentsRegistrationHelper) UpdateContexts(ctxs []*K8sContext) *Comp
 	return cg
 }
 
-type K8sRegistrationFunction func(provider *Provider, ctxt context.Context, config []byte, ctxID string, connectionID string, userID string, MesheryInstanceID uuid.UUID, reg *meshmodel.RegistryManager, eb *Broadcast, ctxName string) error
+// K8sRegistrationFunction registers components for the context and returns the number of components registered
+type K8sRegistrationFunction func(provider *Provider, ctxt context.Context, config []byte, ctxID string, connectionID string, userID string, MesheryInstanceID uuid.UUID, reg *meshmodel.RegistryManager, eb *Broadcast, ctxName string) (int, error)
+
+const (
+	K8sRegistrationSucceeded = "succeeded"
+	K8sRegistrationFailed    = "failed"
+	K8sRegistrationSkipped   = "skipped"
+	K8sRegistrationCancelled = "cancelled"
+)
+
+// K8sRegistrationResult is the outcome of the components registration for a context
+type K8sRegistrationResult struct {
+	ContextID       string `json:"context_id,omitempty"`
+	ContextName     string `json:"context_name"`
+	ConnectionID    string `json:"connection_id,omitempty"`
+	Status          string `json:"status"`
+	ComponentsCount int    `json:"components_count"`
+	Error           string `json:"error,omitempty"`
+}
+
+// Outcomes of the registration of a component
+const (
+	K8sComponentRegistered = "registered"
+	K8sComponentFailed     = "failed"
+	K8sComponentExcluded   = "excluded"
+	K8sComponentRejected   = "rejected"
+)
+
+// K8sComponentRegistrationOutcome is the outcome of the registration of a component of a context
+type K8sComponentRegistrationOutcome struct {
+	ContextID  string `json:"context_id"`
+	Kind       string `json:"kind"`
+	APIVersion string `json:"apiVersion"`
+	Status     string `json:"status"`
+	Error      string `json:"error,omitempty"`
+}
+
+// K8sComponentsRegistrationProgress receives the outcome of each component as it is registered,
+// it is called concurrently by the registrations of different contexts.
+type K8sComponentsRegistrationProgress func(K8sComponentRegistrationOutcome)
+
+// K8sComponentsRegistrationProgressFromContext returns the callback receiving the outcome of each component registered, nil if there is none
+func K8sComponentsRegistrationProgressFromContext(ctx context.Context) K8sComponentsRegistrationProgress {
+	progress, _ := ctx.Value(K8sComponentsRegistrationProgressCtxKey).(K8sComponentsRegistrationProgress)
+	return progress
+}
+
+// K8sRegistrationResults accumulates the per-context results of a RegisterComponents call
+type K8sRegistrationResults struct {
+	wg      sync.WaitGroup
+	mx      sync.Mutex
+	results []K8sRegistrationResult
+	// number of contexts whose registration is in progress
+	pending int
+
+	ctx    context.Context
+	cancel context.CancelFunc
+}
+
+func newK8sRegistrationResults() *K8sRegistrationResults {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &K8sRegistrationResults{ctx: ctx, cancel: cancel}
+}
+
+func (rr *K8sRegistrationResults) start() {
+	rr.mx.Lock()
+	defer rr.mx.Unlock()
+	rr.pending++
+	rr.wg.Add(1)
+}
+
+func (rr *K8sRegistrationResults) done(result K8sRegistrationResult) {
+	rr.mx.Lock()
+	defer rr.mx.Unlock()
+	rr.results = append(rr.results, result)
+	rr.pending--
+	rr.wg.Done()
+}
+
+func (rr *K8sRegistrationResults) add(result K8sRegistrationResult) {
+	rr.mx.Lock()
+	defer rr.mx.Unlock()
+	rr.results = append(rr.results, result)
+}
+
+// Cancel stops the registration in progress, the components registered until then are retained.
+// The registration of the cancelled contexts is reported as cancelled.
+func (rr *K8sRegistrationResults) Cancel() {
+	rr.cancel()
+}
+
+// Finished reports whether the registration of all the contexts has finished
+func (rr *K8sRegistrationResults) Finished() bool {
+	rr.mx.Lock()
+	defer rr.mx.Unlock()
+	return rr.pending == 0
+}
+
+// Results returns the results of the contexts whose registration has finished so far
+func (rr *K8sRegistrationResults) Results() []K8sRegistrationResult {
+	return rr.snapshot()
+}
+
+// Wait blocks until the registration of all the contexts has finished and returns their results
+func (rr *K8sRegistrationResults) Wait() []K8sRegistrationResult {
+	rr.wg.Wait()
+	return rr.snapshot()
+}
+
+// WaitWithTimeout is like Wait but gives up after the timeout, the registration continues in the background.
+// The returned bool reports whether the registration finished, if it did not the results of the contexts finished so far are returned.
+func (rr *K8sRegistrationResults) WaitWithTimeout(timeout time.Duration) ([]K8sRegistrationResult, bool) {
+	done := make(chan struct{})
+	go func() {
+		rr.wg.Wait()
+		close(done)
+	}()
+
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+	select {
+	case <-done:
+		return rr.snapshot(), true
+	case <-timer.C:
+		return rr.snapshot(), false
+	}
+}
+
+func (rr *K8sRegistrationResults) snapshot() []K8sRegistrationResult {
+	rr.mx.Lock()
+	defer rr.mx.Unlock()
+	return append([]K8sRegistrationResult{}, rr.results...)
+}
 
 // start registration of components for the contexts
-func (cg *ComponentsRegistrationHelper) RegisterComponents(ctxs []*K8sContext, regFunc []K8sRegistrationFunction, reg *meshmodel.RegistryManager, eventsBrodcaster *Broadcast, provider Provider, userID string, skip bool) {
+//
+// Registration happens in the background, the returned results can be waited on for the per-context outcome.
+func (cg *ComponentsRegistrationHelper) RegisterComponents(ctxs []*K8sContext, regFunc []K8sRegistrationFunction, reg *meshmodel.RegistryManager, eventsBrodcaster *Broadcast, provider Provider, userID string, skip bool) *K8sRegistrationResults {
+	return cg.RegisterComponentsWithLogger(cg.log, ctxs, regFunc, reg, eventsBrodcaster, provider, userID, skip)
+}
+
+// RegisterComponentsWithLogger is like RegisterComponents but logs the registration with the given logger,
+// which is also passed on to the registration functions through the context (see LoggerFromContext).
+func (cg *ComponentsRegistrationHelper) RegisterComponentsWithLogger(log logger.Handler, ctxs []*K8sContext, regFunc []K8sRegistrationFunction, reg *meshmodel.RegistryManager, eventsBrodcaster *Broadcast, provider Provider, userID string, skip bool) *K8sRegistrationResults {
+	return cg.RegisterComponentsWithProgress(log, nil, ctxs, regFunc, reg, eventsBrodcaster, provider, userID, skip)
+}
+
+// RegisterComponentsWithProgress is like RegisterComponentsWithLogger but also reports the outcome of each component to progress,
+// which is passed on to the registration functions through the context (see K8sComponentsRegistrationProgressFromContext).
+func (cg *ComponentsRegistrationHelper) RegisterComponentsWithProgress(log logger.Handler, progress K8sComponentsRegistrationProgress, ctxs []*K8sContext, regFunc []K8sRegistrationFunction, reg *meshmodel.RegistryManager, eventsBrodcaster *Broadcast, provider Provider, userID string, skip bool) *K8sRegistrationResults {
+	results := newK8sRegistrationResults()
 	/* If flag "SKIP_COMP_GEN" is set but the registration is invoked in form of API request explicitly,
 	then flag should not be respected and to control this behaviour skip is introduced.
 	In case of API requests "skip" is set to false, otherise true and behaviour is controlled by "SKIP_COMP_GEN".
 	*/
 	if viper.GetBool("SKIP_COMP_GEN") && skip {
-		return
+		return results
 	}
 
 	userUUID, _ := uuid.FromString(userID)
@@ -97,63 +248,316 @@ func (cg *ComponentsRegistrationHelper) RegisterComponents(ctxs []*K8sContext, r
 		status, ok := cg.ctxRegStatusMap[ctxID]
 		if !ok || status != NotRegistered {
 			cg.mx.Unlock()
+			reason := "components are already registered"
+			if status == Registering {
+				reason = "registration of the components is already in progress"
+			}
+			results.add(K8sRegistrationResult{
+				ContextID:    ctxID,
+				ContextName:  ctxName,
+				ConnectionID: ctx.ConnectionID,
+				Status:       K8sRegistrationSkipped,
+				Error:        reason,
+			})
 			continue
 		}
 
 		// update the status
 		cg.ctxRegStatusMap[ctxID] = Registering
 		cg.mx.Unlock()
-		cg.log.Info("Registration of ", ctxName, " components started for contextID: ", ctxID)
+		log.Info("Registration of ", ctxName, " components started for contextID: ", ctxID)
 
 		event := events.NewEvent().ActedUpon(connectionID).FromSystem(*ctx.MesheryInstanceID).WithSeverity(events.Informational).WithCategory("connection").WithAction(Registering.String()).FromUser(userUUID).WithDescription(fmt.Sprintf("Registration for Kubernetes context %s started", ctxName)).Build()
 		err := provider.PersistEvent(event)
 		if err != nil {
 			// Even if event was not persisted continue with the operation and publish the event to user.
-			cg.log.Warn(err)
+			log.Warn(err)
 		}
 		eventsBrodcaster.Publish(userUUID, event)
 
+		results.start()
 		go func(ctx *K8sContext) {
-			// set the status to RegistrationComplete
+			result := K8sRegistrationResult{
+				ContextID:    ctxID,
+				ContextName:  ctxName,
+				ConnectionID: ctx.ConnectionID,
+				Status:       K8sRegistrationSucceeded,
+			}
+			// set the status to RegistrationComplete, a cancelled registration may be started again
 			defer func() {
 				cg.mx.Lock()
-				cg.ctxRegStatusMap[ctxID] = RegistrationComplete
+				if result.Status == K8sRegistrationCancelled {
+					cg.ctxRegStatusMap[ctxID] = NotRegistered
+				} else {
+					cg.ctxRegStatusMap[ctxID] = RegistrationComplete
+				}
 				cg.mx.Unlock()
 
-				cg.log.Info("components registered for context ", ctxName, " ID:", ctxID)
+				results.done(result)
+				log.Info("components registered for context ", ctxName, " ID:", ctxID)
 			}()
 
 			// start registration
 			cfg, err := ctx.GenerateKubeConfig()
 			if err != nil {
-				cg.log.Error(err)
+				log.Error(err)
+				result.Status, result.Error = K8sRegistrationFailed, err.Error()
 				return
 			}
+			regCtx := context.WithValue(results.ctx, LoggerCtxKey, log)
+			if progress != nil {
+				regCtx = context.WithValue(regCtx, K8sComponentsRegistrationProgressCtxKey, progress)
+			}
 			for _, f := range regFunc {
-				err = f(&provider, context.Background(), cfg, ctxID, ctx.ConnectionID, userID, *ctx.MesheryInstanceID, reg, eventsBrodcaster, ctxName)
+				count, err := f(&provider, regCtx, cfg, ctxID, ctx.ConnectionID, userID, *ctx.MesheryInstanceID, reg, eventsBrodcaster, ctxName)
+				result.ComponentsCount += count
+				if regCtx.Err() != nil {
+					log.Info("registration of ", ctxName, " components cancelled for contextID: ", ctxID)
+					result.Status, result.Error = K8sRegistrationCancelled, regCtx.Err().Error()
+					return
+				}
 				if err != nil {
-					cg.log.Error(err)
+					log.Error(err)
+					result.Status, result.Error = K8sRegistrationFailed, err.Error()
 					return
 				}
 			}
 		}(ctx)
 	}
+	return results
+}
+
+// DefaultK8sComponentsHostname is the hostname of the registry host the components of the clusters are registered against
+const DefaultK8sComponentsHostname = "kubernetes"
+
+// K8sComponentsHost returns the registry host under which the components of the context are registered
+func K8sComponentsHost(ctxID string) meshmodel.Host {
+	return K8sComponentsHostFor(DefaultK8sComponentsHostname, ctxID, ctxID)
+}
+
+// K8sComponentsHostFor returns the registry host with the given hostname and metadata, for federated registries
+// which route the components of the clusters to different logical registries.
+// The hostname defaults to "kubernetes" and the metadata to the ID of the context.
+func K8sComponentsHostFor(hostname, metadata, ctxID string) meshmodel.Host {
+	if hostname == "" {
+		hostname = DefaultK8sComponentsHostname
+	}
+	if metadata == "" {
+		metadata = ctxID
+	}
+	return meshmodel.Host{
+		Hostname: hostname,
+		Metadata: metadata,
+	}
+}
+
+// maxRegistryHostMetadataLength bounds the metadata of a registry host, which is part of its identity
+const maxRegistryHostMetadataLength = 253
+
+// ValidateRegistryHostMetadata verifies that the metadata of a registry host is printable text of at most 253 characters
+func ValidateRegistryHostMetadata(metadata string) error {
+	if len(metadata) > maxRegistryHostMetadataLength {
+		return ErrInvalidRegistryHostMetadata(fmt.Errorf("longer than %d characters", maxRegistryHostMetadataLength), metadata)
+	}
+	for _, r := range metadata {
+		if !unicode.IsPrint(r) {
+			return ErrInvalidRegistryHostMetadata(fmt.Errorf("contains the non-printable character %q", r), metadata)
+		}
+	}
+	if strings.TrimSpace(metadata) != metadata {
+		return ErrInvalidRegistryHostMetadata(fmt.Errorf("has leading or trailing whitespace"), metadata)
+	}
+	return nil
+}
+
+// ValidateRegistryHostname verifies that the hostname of a registry host is a DNS subdomain (e.g. "kubernetes", "registry.example.com")
+func ValidateRegistryHostname(hostname string) error {
+	if errs := validation.IsDNS1123Subdomain(hostname); len(errs) > 0 {
+		return ErrInvalidRegistryHost(fmt.Errorf("%s", strings.Join(errs, ", ")), hostname)
+	}
+	return nil
+}
+
+// k8sComponentsHostID returns the ID of the registry host the components of the kubernetes context are registered against
+func k8sComponentsHostID(db *database.Handler, ctxID string) (guuid.UUID, error) {
+	host, err := GetK8sComponentsRegistryHost(db, ctxID)
+	if err != nil {
+		return guuid.UUID{}, err
+	}
+	// The registry identifies the hosts by the hash of their JSON representation
+	byt, err := json.Marshal(host)
+	if err != nil {
+		return guuid.UUID{}, ErrMarshal(err, "registry host")
+	}
+	return guuid.NewSHA1(guuid.UUID{}, byt), nil
+}
+
+// CountK8sContextComponents returns the number of components registered in the registry for the kubernetes context
+func CountK8sContextComponents(db *database.Handler, ctxID string) (int64, error) {
+	hostID, err := k8sComponentsHostID(db, ctxID)
+	if err != nil {
+		return 0, err
+	}
+	var count int64
+	err = db.Model(&v1alpha1.ComponentDefinitionDB{}).
+		Joins("JOIN registries ON registries.entity = component_definition_dbs.id").
+		Where("registries.registrant_id = ?", hostID).
+		Count(&count).Error
+	return count, err
+}
+
+// GetK8sContextComponents returns the components registered in the registry for the kubernetes context
+func GetK8sContextComponents(db *database.Handler, ctxID string) ([]v1alpha1.ComponentDefinition, error) {
+	type componentDefinitionWithModel struct {
+		ComponentDefinitionDB v1alpha1.ComponentDefinitionDB `gorm:"embedded"`
+		ModelDB               v1alpha1.ModelDB               `gorm:"embedded"`
+		CategoryDB            v1alpha1.CategoryDB            `gorm:"embedded"`
+	}
+
+	hostID, err := k8sComponentsHostID(db, ctxID)
+	if err != nil {
+		return nil, err
+	}
+
+	var componentDefinitionsWithModel []componentDefinitionWithModel
+	err = db.Model(&v1alpha1.ComponentDefinitionDB{}).
+		Select("component_definition_dbs.*, model_dbs.*,category_dbs.*").
+		Joins("JOIN model_dbs ON component_definition_dbs.model_id = model_dbs.id").
+		Joins("JOIN category_dbs ON model_dbs.category_id = category_dbs.id").
+		Joins("JOIN registries ON registries.entity = component_definition_dbs.id").
+		Where("registries.registrant_id = ?", hostID).
+		Scan(&componentDefinitionsWithModel).Error
+	if err != nil {
+		return nil, err
+	}
+
+	comps := make([]v1alpha1.ComponentDefinition, 0, len(componentDefinitionsWithModel))
+	for _, cm := range componentDefinitionsWithModel {
+		comps = append(comps, cm.ComponentDefinitionDB.GetComponentDefinition(cm.ModelDB.GetModel(cm.CategoryDB.GetCategory(db))))
+	}
+	return comps, nil
 }
 
 // Caches k8sMeshModel metadatas in memory to use at the time of dynamic k8s component generation
 func init() {
-	f, err := os.Open(filepath.Join(k8sMeshModelPath))
+	ReloadK8sMeshModelMetadata()
+}
+
+// LoggerFromContext returns the request scoped logger of the context, or fallback if there is none
+func LoggerFromContext(ctx context.Context, fallback logger.Handler) logger.Handler {
+	if log, ok := ctx.Value(LoggerCtxKey).(logger.Handler); ok && log != nil {
+		return log
+	}
+	return fallback
+}
+
+// UpdateK8sContextComponentsMetadata calls update with each of the components registered for the kubernetes context
+// and persists the metadata of the components it reports as updated. Only the components registered by the host of the
+// context are updated, the components of the same kinds registered by other hosts are rows of their own.
+// Returns the number of components updated.
+func UpdateK8sContextComponentsMetadata(db *database.Handler, ctxID string, update func(comp *v1alpha1.ComponentDefinition) bool) (int, error) {
+	hostID, err := k8sComponentsHostID(db, ctxID)
+	if err != nil {
+		return 0, err
+	}
+
+	var rows []v1alpha1.ComponentDefinitionDB
+	err = db.Model(&v1alpha1.ComponentDefinitionDB{}).
+		Select("component_definition_dbs.*").
+		Joins("JOIN registries ON registries.entity = component_definition_dbs.id").
+		Where("registries.registrant_id = ?", hostID).
+		Find(&rows).Error
+	if err != nil {
+		return 0, err
+	}
+
+	modelsByID := make(map[guuid.UUID]v1alpha1.Model)
+	updated := 0
+	for _, row := range rows {
+		model, ok := modelsByID[row.ModelID]
+		if !ok {
+			var modelDB v1alpha1.ModelDB
+			if err := db.First(&modelDB, "id = ?", row.ModelID).Error; err != nil {
+				return updated, err
+			}
+			var categoryDB v1alpha1.CategoryDB
+			_ = db.First(&categoryDB, "id = ?", modelDB.CategoryID).Error
+			model = modelDB.GetModel(categoryDB.GetCategory(db))
+			modelsByID[row.ModelID] = model
+		}
+
+		comp := row.GetComponentDefinition(model)
+		if !update(&comp) {
+			continue
+		}
+		metadata, err := json.Marshal(comp.Metadata)
+		if err != nil {
+			return updated, ErrMarshal(err, "component metadata")
+		}
+		if err := db.Model(&v1alpha1.ComponentDefinitionDB{}).Where("id = ?", row.ID).Update("metadata", metadata).Error; err != nil {
+			return updated, err
+		}
+		updated++
+	}
+	return updated, nil
+}
+
+// UnregisterK8sContextComponents removes the components of the given kind and apiVersions registered for the kubernetes context
+// from the registry, e.g. when their custom resource definition was deleted from the cluster. Returns the number of components removed.
+func UnregisterK8sContextComponents(db *database.Handler, ctxID, kind string, apiVersions []string) (int, error) {
+	hostID, err := k8sComponentsHostID(db, ctxID)
 	if err != nil {
-		return
+		return 0, err
 	}
-	byt, err := io.ReadAll(f)
+	// A resumed registration would skip the unregistered components recorded by the checkpoint
+	if err := DeleteK8sComponentsRegistrationCheckpoint(db, ctxID); err != nil {
+		return 0, err
+	}
+
+	var ids []guuid.UUID
+	err = db.Model(&v1alpha1.ComponentDefinitionDB{}).
+		Joins("JOIN registries ON registries.entity = component_definition_dbs.id").
+		Where("registries.registrant_id = ? AND component_definition_dbs.kind = ? AND component_definition_dbs.api_version IN ?", hostID, kind, apiVersions).
+		Pluck("component_definition_dbs.id", &ids).Error
+	if err != nil || len(ids) == 0 {
+		return 0, err
+	}
+	return unregisterK8sComponents(db, hostID, ids)
+}
+
+// UnregisterAllK8sContextComponents removes all the components registered for the kubernetes context from the registry,
+// once its connection is deleted. Returns the number of components removed.
+func UnregisterAllK8sContextComponents(db *database.Handler, ctxID string) (int, error) {
+	hostID, err := k8sComponentsHostID(db, ctxID)
 	if err != nil {
-		return
+		return 0, err
+	}
+	// A resumed registration would skip the unregistered components recorded by the checkpoint
+	if err := DeleteK8sComponentsRegistrationCheckpoint(db, ctxID); err != nil {
+		return 0, err
+	}
+
+	var ids []guuid.UUID
+	err = db.Model(&meshmodel.Registry{}).
+		Where("registrant_id = ? AND type = ?", hostID, types.ComponentDefinition).
+		Pluck("entity", &ids).Error
+	if err != nil || len(ids) == 0 {
+		return 0, err
 	}
-	m := make(map[string]interface{})
-	err = json.Unmarshal(byt, &m)
+	return unregisterK8sComponents(db, hostID, ids)
+}
+
+// unregisterK8sComponents removes the components with the given IDs registered by the host
+func unregisterK8sComponents(db *database.Handler, hostID guuid.UUID, ids []guuid.UUID) (int, error) {
+	// Each registration creates its own component, so the components are removed along with their registry entries
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("registrant_id = ? AND entity IN ?", hostID, ids).Delete(&meshmodel.Registry{}).Error; err != nil {
+			return err
+		}
+		return tx.Where("id IN ?", ids).Delete(&v1alpha1.ComponentDefinitionDB{}).Error
+	})
 	if err != nil {
-		return
+		return 0, err
 	}
-	K8sMeshModelMetadata = m
+	return len(ids), nil
 }
@@ -0,0 +1,53 @@
+package models
+
+import (
+	"errors"
+	"time"
+
+	"github.com/layer5io/meshkit/database"
+	"gorm.io/gorm"
+)
+
+// K8sComponentsRegistrationCheckpoint records the components registered for a context by a registration which did not complete,
+// either failing partway or leaving components which failed to register, so that the next registration of the context
+// resumes from where it left off and registers the remaining components only.
+type K8sComponentsRegistrationCheckpoint struct {
+	ContextID string `json:"context_id" gorm:"primaryKey"`
+	// Hostname and ModelVersion the components were registered with, a registration with other ones starts from scratch
+	Hostname     string `json:"hostname"`
+	ModelVersion string `json:"model_version,omitempty"`
+	// Registered are the components registered so far, as apiVersion/kind
+	Registered []string `json:"registered" gorm:"serializer:json"`
+	// Error the registration failed with, empty when it completed with components which failed to register
+	Error     string    `json:"error,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// GetK8sComponentsRegistrationCheckpoint returns the checkpoint of the registration of the components of the context,
+// nil when the last registration completed
+func GetK8sComponentsRegistrationCheckpoint(db *database.Handler, ctxID string) (*K8sComponentsRegistrationCheckpoint, error) {
+	var checkpoint K8sComponentsRegistrationCheckpoint
+	err := db.Where("context_id = ?", ctxID).First(&checkpoint).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &checkpoint, nil
+}
+
+// SaveK8sComponentsRegistrationCheckpoint creates or updates the checkpoint of the registration of the components of the context
+func SaveK8sComponentsRegistrationCheckpoint(db *database.Handler, checkpoint *K8sComponentsRegistrationCheckpoint) error {
+	db.Lock()
+	defer db.Unlock()
+	return db.Save(checkpoint).Error
+}
+
+// DeleteK8sComponentsRegistrationCheckpoint removes the checkpoint of the context once its registration completed
+func DeleteK8sComponentsRegistrationCheckpoint(db *database.Handler, ctxID string) error {
+	db.Lock()
+	defer db.Unlock()
+	return db.Where("context_id = ?", ctxID).Delete(&K8sComponentsRegistrationCheckpoint{}).Error
+}
@@ -0,0 +1,40 @@
+package models
+
+import (
+	"errors"
+	"time"
+
+	"github.com/layer5io/meshkit/database"
+	meshmodel "github.com/layer5io/meshkit/models/meshmodel/registry"
+	"gorm.io/gorm"
+)
+
+// K8sComponentsRegistryHost records the registry host the components of a context were last registered against,
+// so that they are found again when registered against a custom host of a federated registry.
+type K8sComponentsRegistryHost struct {
+	ContextID string    `json:"context_id" gorm:"primaryKey"`
+	Hostname  string    `json:"hostname"`
+	Metadata  string    `json:"metadata"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// GetK8sComponentsRegistryHost returns the registry host the components of the context are registered against,
+// the default one when they were never registered against a custom host
+func GetK8sComponentsRegistryHost(db *database.Handler, ctxID string) (meshmodel.Host, error) {
+	var host K8sComponentsRegistryHost
+	err := db.Where("context_id = ?", ctxID).First(&host).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return K8sComponentsHost(ctxID), nil
+	}
+	if err != nil {
+		return meshmodel.Host{}, err
+	}
+	return K8sComponentsHostFor(host.Hostname, host.Metadata, ctxID), nil
+}
+
+// SaveK8sComponentsRegistryHost records the registry host the components of the context are registered against
+func SaveK8sComponentsRegistryHost(db *database.Handler, ctxID string, host meshmodel.Host) error {
+	db.Lock()
+	defer db.Unlock()
+	return db.Save(&K8sComponentsRegistryHost{ContextID: ctxID, Hostname: host.Hostname, Metadata: host.Metadata}).Error
+}
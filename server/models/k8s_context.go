@@ -9,7 +9,11 @@ import (
 	"errors"
 	"fmt"
 	"net"
+	"net/http"
+	"net/url"
 	"os"
+	"os/exec"
+	"strconv"
 	"time"
 
 	"github.com/gofrs/uuid"
@@ -20,8 +24,14 @@ import (
 	meshsyncmodel "github.com/layer5io/meshsync/pkg/model"
 	"github.com/sirupsen/logrus"
 	"gopkg.in/yaml.v2"
+	authorizationv1 "k8s.io/api/authorization/v1"
 	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/version"
+	"k8s.io/client-go/dynamic"
+	k8s "k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
 	"k8s.io/client-go/tools/clientcmd"
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
 )
 
 type K8sContext struct {
@@ -39,6 +49,83 @@ type K8sContext struct {
 	UpdatedAt          *time.Time `json:"updated_at,omitempty" yaml:"updated_at,omitempty"`
 	CreatedAt          *time.Time `json:"created_at,omitempty" yaml:"created_at,omitempty"`
 	ConnectionID       string     `json:"connection_id,omitempty" yaml:"connection_id,omitempty"`
+	// Namespace the context is scoped to, set when a context is split into one connection per namespace.
+	Namespace string `json:"namespace,omitempty" yaml:"namespace,omitempty"`
+	// Namespaces of the contexts of the same cluster and user merged into this one, see MergeKubeconfigContextsByCluster.
+	Namespaces []string `json:"namespaces,omitempty" gorm:"serializer:json" yaml:"namespaces,omitempty"`
+	// Proxy through which the API server is dialed, http, https and socks5 proxies are supported.
+	ProxyURL string `json:"proxy_url,omitempty" yaml:"proxy_url,omitempty"`
+	// The credentials of the proxy are persisted along with the credentials of the context, see BeforeSave.
+	ProxyUsername string `json:"proxy_username,omitempty" gorm:"-" yaml:"proxy_username,omitempty"`
+	ProxyPassword string `json:"proxy_password,omitempty" gorm:"-" yaml:"proxy_password,omitempty"`
+	// Timeouts of the transport to the API server as Go durations (e.g. "5s"), the "KUBERNETES_*_TIMEOUT" defaults apply when empty.
+	DialTimeout           string `json:"dial_timeout,omitempty" yaml:"dial_timeout,omitempty"`
+	TLSHandshakeTimeout   string `json:"tls_handshake_timeout,omitempty" yaml:"tls_handshake_timeout,omitempty"`
+	ResponseHeaderTimeout string `json:"response_header_timeout,omitempty" yaml:"response_header_timeout,omitempty"`
+	// TLSServerName overrides the name the certificate of the API server is verified against (and sent as SNI),
+	// for API servers behind a proxy whose certificate does not match the host of the server URL.
+	TLSServerName string `json:"tls_server_name,omitempty" yaml:"tls_server_name,omitempty"`
+	// Rate limits of the requests to the API server, the "KUBERNETES_CLIENT_QPS" and "KUBERNETES_CLIENT_BURST" defaults apply when 0.
+	QPS   float32 `json:"qps,omitempty" yaml:"qps,omitempty"`
+	Burst int     `json:"burst,omitempty" yaml:"burst,omitempty"`
+	// Server of the kubeconfig, set when the context dials an overridden API server URL instead.
+	OriginalServer string `json:"original_server,omitempty" yaml:"original_server,omitempty"`
+	// Source records how the context was onboarded, one of the K8sContextSource* values.
+	Source string `json:"source,omitempty" yaml:"source,omitempty"`
+	// Managed marks the contexts created by automation, only those are ever pruned by a declarative apply.
+	Managed bool `json:"managed,omitempty" yaml:"managed,omitempty"`
+	// Pinned marks the contexts the user keeps at hand, listed first when asked to.
+	Pinned bool `json:"pinned,omitempty" yaml:"pinned,omitempty"`
+	// Notes are free-text annotations of the connection (e.g. "shared staging, do not delete"), only ever shown to the users.
+	Notes string `json:"notes,omitempty" yaml:"notes,omitempty"`
+	// SyncInterval is the interval as a Go duration (e.g. "5m") at which MeshSync resyncs the resources of the cluster,
+	// at least MinK8sSyncInterval, MeshSync only syncs once connected and then on changes when empty.
+	SyncInterval string `json:"sync_interval,omitempty" yaml:"sync_interval,omitempty"`
+	// Labels organize the connections (e.g. "env=staging"), the contexts are selected by them with Kubernetes label selectors.
+	Labels map[string]string `json:"labels,omitempty" gorm:"serializer:json" yaml:"labels,omitempty"`
+	// ExpiresAt is when the connection of the context is deleted by the expiry sweeper, never when nil.
+	ExpiresAt *time.Time `json:"expires_at,omitempty" yaml:"expires_at,omitempty"`
+	// DeletedAt is when the context was deleted, it is kept in the recycle bin for a grace period during which it may be restored.
+	DeletedAt *time.Time `json:"deleted_at,omitempty" yaml:"deleted_at,omitempty"`
+	// IsCurrentContext reports whether the context is the current-context of the kubeconfig it was read from.
+	IsCurrentContext bool `json:"is_current_context,omitempty" gorm:"-" yaml:"is_current_context,omitempty"`
+	// RenamedFrom is the name of the context in the kubeconfig, set when it was renamed for clashing with another context.
+	RenamedFrom string `json:"renamed_from,omitempty" gorm:"-" yaml:"renamed_from,omitempty"`
+	// OriginalName is the name of the context before it was named by a naming template, see ApplyNameTemplate.
+	OriginalName string `json:"original_name,omitempty" yaml:"original_name,omitempty"`
+}
+
+// Deployment types of the contexts, whether Meshery runs in the cluster of the context or out of it
+const (
+	K8sContextDeploymentInCluster    = "in_cluster"
+	K8sContextDeploymentOutOfCluster = "out_of_cluster"
+)
+
+// Sources through which the contexts are onboarded
+const (
+	K8sContextSourceUpload     = "upload"
+	K8sContextSourceFilesystem = "filesystem"
+	K8sContextSourceInCluster  = "in_cluster"
+	K8sContextSourceEnv        = "env"
+	K8sContextSourceSecret     = "secret"
+	K8sContextSourceImport     = "import"
+)
+
+// ParseK8sContextTTL parses the time to live of a connection given as a Go duration (e.g. "2h").
+func ParseK8sContextTTL(ttl string) (time.Duration, error) {
+	v, err := time.ParseDuration(ttl)
+	if err != nil {
+		return 0, ErrInvalidConnectionTTL(err, ttl)
+	}
+	if v <= 0 {
+		return 0, ErrInvalidConnectionTTL(fmt.Errorf("ttl must be positive"), ttl)
+	}
+	return v, nil
+}
+
+// Expired reports whether the context has an expiry which is past at the given time
+func (kc *K8sContext) Expired(now time.Time) bool {
+	return kc.ExpiresAt != nil && !now.Before(*kc.ExpiresAt)
 }
 
 type InternalKubeConfig struct {
@@ -139,8 +226,16 @@ func NewK8sContextWithServerID(
 
 // K8sContextsFromKubeconfig takes in a kubeconfig and meshery instance ID and generates
 // kubernetes contexts from it
-func K8sContextsFromKubeconfig(provider Provider, userID string, eventChan *Broadcast, kubeconfig []byte, instanceID *uuid.UUID, eventMetadata map[string]interface{}) []*K8sContext {
+//
+// The optional configure funcs are applied to each of the contexts before connecting to it,
+// for settings which are not part of the kubeconfig (e.g. proxy).
+// Contexts whose name clashes with another context are renamed (see DisambiguateK8sContextNames).
+func K8sContextsFromKubeconfig(provider Provider, userID string, eventChan *Broadcast, kubeconfig []byte, instanceID *uuid.UUID, eventMetadata map[string]interface{}, configure ...func(*K8sContext)) []*K8sContext {
 	kcs := []*K8sContext{}
+	kubeconfig, renamed, err := DisambiguateK8sContextNames(kubeconfig)
+	if err != nil {
+		return kcs
+	}
 	parsed, err := clientcmd.Load(kubeconfig)
 	if err != nil {
 		return kcs
@@ -157,10 +252,41 @@ func K8sContextsFromKubeconfig(provider Provider, userID string, eventChan *Broa
 		var msg string
 		metadata := map[string]interface{}{}
 		kc, _ := kcfg.K8sContext(name, instanceID)
+		kc.IsCurrentContext = name == parsed.CurrentContext
+		if original, ok := renamed[name]; ok {
+			kc.RenamedFrom = original
+		}
+		for _, fn := range configure {
+			fn(&kc)
+		}
 		eventBuilder := events.NewEvent().ActedUpon(uuid.FromStringOrNil(kc.ConnectionID)).WithCategory("connection").WithAction("register").FromSystem(*instanceID).FromUser(userUUID)
 
 		metadata["context"] = RedactCredentialsForContext(&kc)
 
+		if err := kc.CheckExecCredentialBinary(); err != nil {
+			_ = eventBuilder.WithSeverity(events.Error).WithDescription(fmt.Sprintf("Exec credential binary '%s' not found, skipping context %s", kc.ExecCredentialCommand(), kc.Name)).WithMetadata(map[string]interface{}{
+				"error": err,
+			}).Build()
+			metadata["error"] = err
+			metadata["description"] = fmt.Sprintf("exec credential binary '%s' not found on server, skipping context \"%s\"", kc.ExecCredentialCommand(), kc.Name)
+			eventMetadata[name] = metadata
+
+			logrus.Warn(err)
+			continue
+		}
+
+		if err := kc.CheckTokenFile(); err != nil {
+			_ = eventBuilder.WithSeverity(events.Error).WithDescription(fmt.Sprintf("Token file '%s' not readable, skipping context %s", kc.TokenFile(), kc.Name)).WithMetadata(map[string]interface{}{
+				"error": err,
+			}).Build()
+			metadata["error"] = err
+			metadata["description"] = fmt.Sprintf("token file '%s' not readable on server, skipping context \"%s\"", kc.TokenFile(), kc.Name)
+			eventMetadata[name] = metadata
+
+			logrus.Warn(err)
+			continue
+		}
+
 		handler, err := kc.GenerateKubeHandler()
 		if err != nil {
 			msg = fmt.Sprintf("error generating kubernetes handler, skipping context %s: %v", err, kc.Name)
@@ -239,7 +365,6 @@ func K8sContextsFromKubeconfig(provider Provider, userID string, eventChan *Broa
 			kcs = append(kcs, &kc)
 			continue
 		}
-
 		kcs = append(kcs, &kc)
 	}
 
@@ -332,6 +457,10 @@ func K8sContextGenerateID(kc K8sContext) (string, error) {
 		"meshery": kc.MesheryInstanceID.String(),
 		"name":    kc.Name,
 	}
+	// Only namespace scoped contexts include the namespace so that the IDs of the existing contexts remain unchanged.
+	if kc.Namespace != "" {
+		data["namespace"] = kc.Namespace
+	}
 
 	byt, err := json.Marshal(data)
 	if err != nil {
@@ -346,6 +475,18 @@ func K8sContextGenerateID(kc K8sContext) (string, error) {
 // GenerateKubeConfig will generate a kubeconfig from the context object
 // and will set the "current-context" to the current context's name
 func (kc K8sContext) GenerateKubeConfig() ([]byte, error) {
+	kc, err := kc.Decompress()
+	if err != nil {
+		return nil, err
+	}
+
+	contextInfo := map[string]interface{}{
+		"cluster": kc.Cluster["name"],
+		"user":    kc.Auth["name"],
+	}
+	if kc.Namespace != "" {
+		contextInfo["namespace"] = kc.Namespace
+	}
 	cfg := map[string]interface{}{
 		"apiVersion": "v1",
 		"clusters": []map[string]interface{}{
@@ -353,11 +494,8 @@ func (kc K8sContext) GenerateKubeConfig() ([]byte, error) {
 		},
 		"contexts": []map[string]interface{}{
 			{
-				"context": map[string]interface{}{
-					"cluster": kc.Cluster["name"],
-					"user":    kc.Auth["name"],
-				},
-				"name": kc.Name,
+				"context": contextInfo,
+				"name":    kc.Name,
 			},
 		},
 		"current-context": kc.Name,
@@ -380,7 +518,203 @@ func (kc *K8sContext) GenerateKubeHandler() (*kubernetes.Client, error) {
 		return nil, err
 	}
 
-	return kubernetes.New(cfg)
+	restConfig, err := kubernetes.DetectKubeConfig(cfg)
+	if err != nil {
+		return nil, err
+	}
+	if err := kc.configureProxy(restConfig); err != nil {
+		return nil, err
+	}
+	kc.configureTLSServerName(restConfig)
+	kc.configureLocalTLS(restConfig)
+	if err := kc.configureTransport(restConfig); err != nil {
+		return nil, err
+	}
+	if err := kc.configureRateLimit(restConfig); err != nil {
+		return nil, err
+	}
+	if err := kc.configureImpersonation(restConfig); err != nil {
+		return nil, err
+	}
+
+	return newKubeClient(restConfig)
+}
+
+// newKubeClient creates the kubernetes client from the rest config the same way as kubernetes.New does for a kubeconfig
+func newKubeClient(restConfig *rest.Config) (*kubernetes.Client, error) {
+	kclient, err := k8s.NewForConfig(restConfig)
+	if err != nil {
+		return nil, kubernetes.ErrNewKubeClient(err)
+	}
+
+	dyclient, err := dynamic.NewForConfig(restConfig)
+	if err != nil {
+		return nil, kubernetes.ErrNewDynClient(err)
+	}
+
+	return &kubernetes.Client{
+		RestConfig:        *restConfig,
+		DynamicKubeClient: dyclient,
+		KubeClient:        kclient,
+	}, nil
+}
+
+// configureProxy routes the traffic to the API server through the proxy of the context, if any.
+// The proxy credentials, when set, take precedence over the ones present in the proxy URL.
+func (kc *K8sContext) configureProxy(restConfig *rest.Config) error {
+	if kc.ProxyURL == "" {
+		return nil
+	}
+
+	proxyURL, err := url.Parse(kc.ProxyURL)
+	if err != nil {
+		return ErrInvalidProxyURL(err, kc.ProxyURL)
+	}
+	switch proxyURL.Scheme {
+	case "http", "https", "socks5", "socks5h":
+	default:
+		return ErrInvalidProxyURL(fmt.Errorf("unsupported proxy scheme \"%s\"", proxyURL.Scheme), kc.ProxyURL)
+	}
+
+	if kc.ProxyUsername != "" {
+		proxyURL.User = url.UserPassword(kc.ProxyUsername, kc.ProxyPassword)
+	}
+	// The transport dials the socks5 proxy itself and authenticates using the credentials of the URL.
+	restConfig.Proxy = http.ProxyURL(proxyURL)
+	return nil
+}
+
+// cachedAuthProviderKeys are the keys under which the auth-provider plugins
+// (gcp, azure, oidc) cache the credentials acquired by them in the kubeconfig.
+var cachedAuthProviderKeys = []string{"access-token", "expiry", "expires-on", "expires-in", "id-token"}
+
+// RefreshKubeConfigAuth drops the credentials cached by the auth-provider plugins in the kubeconfig
+// so that the next client generated from it acquires fresh credentials through the plugin.
+// Exec plugins do not cache credentials in the kubeconfig and re-run on the next client creation.
+//
+// The returned bool reports whether the kubeconfig uses an auth-provider/exec plugin at all,
+// if it does not, there is nothing to refresh and the kubeconfig is returned as is.
+func RefreshKubeConfigAuth(kubeconfig []byte) ([]byte, bool, error) {
+	cfg, err := clientcmd.Load(kubeconfig)
+	if err != nil {
+		return kubeconfig, false, err
+	}
+
+	refreshable := false
+	for _, authInfo := range cfg.AuthInfos {
+		if authInfo.Exec != nil {
+			refreshable = true
+		}
+		if authInfo.AuthProvider != nil {
+			refreshable = true
+			for _, key := range cachedAuthProviderKeys {
+				delete(authInfo.AuthProvider.Config, key)
+			}
+		}
+	}
+	if !refreshable {
+		return kubeconfig, false, nil
+	}
+
+	refreshed, err := clientcmd.Write(*cfg)
+	if err != nil {
+		return kubeconfig, true, err
+	}
+	return refreshed, true, nil
+}
+
+// OverrideKubeConfigServer points the cluster of the given context of the kubeconfig, its current-context when empty,
+// at the given API server URL, for when the server of the kubeconfig is not reachable from Meshery but another URL is
+// (load balancers, tunnels). The other clusters of the kubeconfig are left as is.
+// The CA and credentials are kept, and the TLS server name is pinned to the original host unless set already,
+// so that the serving certificate of the API server still verifies against the override.
+//
+// The returned map holds the original server of each context of the overridden cluster, keyed by context name.
+func OverrideKubeConfigServer(kubeconfig []byte, contextName, server string) ([]byte, map[string]string, error) {
+	serverURL, err := url.Parse(server)
+	if err != nil {
+		return kubeconfig, nil, ErrInvalidServerURL(err, server)
+	}
+	if (serverURL.Scheme != "http" && serverURL.Scheme != "https") || serverURL.Host == "" {
+		return kubeconfig, nil, ErrInvalidServerURL(fmt.Errorf("server URL must be an absolute http or https URL"), server)
+	}
+
+	cfg, err := clientcmd.Load(kubeconfig)
+	if err != nil {
+		return kubeconfig, nil, ErrInvalidKubeconfigStructure(err)
+	}
+
+	if contextName == "" {
+		contextName = cfg.CurrentContext
+	}
+	overriddenCtx, ok := cfg.Contexts[contextName]
+	if !ok {
+		return kubeconfig, nil, ErrServerOverrideContextNotFound(contextName)
+	}
+	cluster, ok := cfg.Clusters[overriddenCtx.Cluster]
+	if !ok {
+		return kubeconfig, nil, ErrServerOverrideContextNotFound(contextName)
+	}
+
+	// the contexts sharing the cluster dial the override as well
+	originalServers := make(map[string]string)
+	for name, ctx := range cfg.Contexts {
+		if ctx.Cluster == overriddenCtx.Cluster {
+			originalServers[name] = cluster.Server
+		}
+	}
+	if cluster.TLSServerName == "" && !cluster.InsecureSkipTLSVerify {
+		if originalURL, err := url.Parse(cluster.Server); err == nil {
+			cluster.TLSServerName = originalURL.Hostname()
+		}
+	}
+	cluster.Server = server
+
+	overridden, err := clientcmd.Write(*cfg)
+	if err != nil {
+		return kubeconfig, nil, ErrMarshal(err, "kube config")
+	}
+	return overridden, originalServers, nil
+}
+
+// SplitByNamespace fans out the context into one context per namespace accessible with it,
+// the namespaces in which the user of the context may list pods.
+// Each of the returned contexts is scoped to its namespace and named after the original context suffixed with the namespace.
+func (kc *K8sContext) SplitByNamespace(handler *kubernetes.Client) ([]*K8sContext, error) {
+	return kc.splitByNamespace(context.TODO(), handler.KubeClient)
+}
+
+func (kc *K8sContext) splitByNamespace(ctx context.Context, client k8s.Interface) ([]*K8sContext, error) {
+	namespaces, err := client.CoreV1().Namespaces().List(ctx, v1.ListOptions{})
+	if err != nil {
+		return nil, ErrUnreachableKubeAPI(err, kc.Server)
+	}
+
+	ctxs := make([]*K8sContext, 0, len(namespaces.Items))
+	for _, ns := range namespaces.Items {
+		review, err := client.AuthorizationV1().SelfSubjectAccessReviews().Create(ctx, &authorizationv1.SelfSubjectAccessReview{
+			Spec: authorizationv1.SelfSubjectAccessReviewSpec{
+				ResourceAttributes: &authorizationv1.ResourceAttributes{Namespace: ns.Name, Verb: "list", Resource: "pods"},
+			},
+		}, v1.CreateOptions{})
+		if err != nil {
+			return nil, ErrUnreachableKubeAPI(err, kc.Server)
+		}
+		if !review.Status.Allowed {
+			continue
+		}
+		nsCtx := *kc
+		nsCtx.Name = fmt.Sprintf("%s-%s", kc.Name, ns.Name)
+		nsCtx.Namespace = ns.Name
+		nsCtx.ConnectionID = ""
+		ID, err := K8sContextGenerateID(nsCtx)
+		if err != nil {
+			return nil, err
+		}
+		nsCtx.ID = ID
+		ctxs = append(ctxs, &nsCtx)
+	}
+	return ctxs, nil
 }
 
 func (kc *K8sContext) AssignVersion(handler *kubernetes.Client) error {
@@ -396,12 +730,19 @@ func (kc *K8sContext) AssignVersion(handler *kubernetes.Client) error {
 // PingTest uses the k8scontext to to "ping" the kubernetes cluster
 // if the return value is nil then the succeeds or else it has failed
 func (kc K8sContext) PingTest() error {
+	return kc.PingTestWithTimeout(1 * time.Second)
+}
+
+// PingTestWithTimeout checks the liveness of the API server of the context, giving up after the timeout
+func (kc K8sContext) PingTestWithTimeout(timeout time.Duration) error {
 	h, err := kc.GenerateKubeHandler()
 	if err != nil {
 		return err
 	}
 
-	res := h.KubeClient.DiscoveryClient.RESTClient().Get().RequestURI("/livez").Timeout(1 * time.Second).Do(context.TODO())
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	res := h.KubeClient.DiscoveryClient.RESTClient().Get().RequestURI("/livez").Timeout(timeout).Do(ctx)
 	if res.Error() != nil {
 		return ErrUnreachableKubeAPI(res.Error(), kc.Server)
 	}
@@ -409,6 +750,120 @@ func (kc K8sContext) PingTest() error {
 	return nil
 }
 
+// RedactKubeConfig replaces the credentials of the kubeconfig with "REDACTED", including the settings of the
+// auth-provider plugins and the environment of the exec plugins, for the kubeconfig to be shared (e.g. for support)
+func RedactKubeConfig(kubeconfig []byte) ([]byte, error) {
+	cfg, err := clientcmd.Load(kubeconfig)
+	if err != nil {
+		return nil, err
+	}
+	if err := clientcmdapi.RedactSecrets(cfg); err != nil {
+		return nil, err
+	}
+	for _, authInfo := range cfg.AuthInfos {
+		if authInfo.AuthProvider != nil {
+			for key := range authInfo.AuthProvider.Config {
+				authInfo.AuthProvider.Config[key] = "REDACTED"
+			}
+		}
+		if authInfo.Exec != nil {
+			for i := range authInfo.Exec.Env {
+				authInfo.Exec.Env[i].Value = "REDACTED"
+			}
+		}
+	}
+	return clientcmd.Write(*cfg)
+}
+
+// ExecCredentialCommand returns the command of the exec credential plugin of the context
+// (e.g. aws-iam-authenticator), empty when the context does not authenticate through one
+func (kc K8sContext) ExecCredentialCommand() string {
+	user, _ := kc.Auth["user"].(map[string]interface{})
+	execConfig, _ := user["exec"].(map[string]interface{})
+	command, _ := execConfig["command"].(string)
+	return command
+}
+
+// CheckExecCredentialBinary verifies that the exec credential plugin of the context is installed on the server,
+// otherwise the context would only fail once a client is created for it
+func (kc K8sContext) CheckExecCredentialBinary() error {
+	command := kc.ExecCredentialCommand()
+	if command == "" {
+		return nil
+	}
+	if _, err := exec.LookPath(command); err != nil {
+		return ErrExecCredentialBinaryNotFound(err, command)
+	}
+	return nil
+}
+
+// TokenFile returns the path of the file holding the bearer token of the context, empty when the token is inline
+func (kc K8sContext) TokenFile() string {
+	user, _ := kc.Auth["user"].(map[string]interface{})
+	tokenFile, _ := user["tokenFile"].(string)
+	return tokenFile
+}
+
+// CheckTokenFile verifies that the token file of the context, if any, is readable on the server.
+// Token files readable at upload are inlined when the kubeconfig is flattened, the ones left are likely local to the uploader.
+func (kc K8sContext) CheckTokenFile() error {
+	tokenFile := kc.TokenFile()
+	if tokenFile == "" {
+		return nil
+	}
+	if _, err := os.ReadFile(tokenFile); err != nil {
+		return ErrTokenFileNotReadable(err, tokenFile)
+	}
+	return nil
+}
+
+// ServerVersionWithTimeout fetches the version of the API server of the context, giving up after the timeout
+func (kc K8sContext) ServerVersionWithTimeout(timeout time.Duration) (*version.Info, error) {
+	h, err := kc.GenerateKubeHandler()
+	if err != nil {
+		return nil, err
+	}
+
+	info, _, err := kc.ServerVersionWithTimings(context.Background(), h, timeout)
+	return info, err
+}
+
+// ServerVersionWithTimings fetches the version of the API server of the context with the client, measuring the phases of the request.
+// A timeout of 0 waits for the API server as long as the context is not cancelled.
+func (kc K8sContext) ServerVersionWithTimings(ctx context.Context, h *kubernetes.Client, timeout time.Duration) (*version.Info, *K8sPhaseTimings, error) {
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+	trace := newK8sPhaseTrace()
+	body, err := h.KubeClient.DiscoveryClient.RESTClient().Get().AbsPath("/version").Timeout(timeout).Do(trace.withTrace(ctx)).Raw()
+	timings := trace.timings()
+	if err != nil {
+		return nil, timings, ErrUnreachableKubeAPI(err, kc.Server)
+	}
+
+	var info version.Info
+	if err := json.Unmarshal(body, &info); err != nil {
+		return nil, timings, ErrUnmarshal(err, "kubernetes server version")
+	}
+	return &info, timings, nil
+}
+
+// NamespaceLivenessWithTimings checks the liveness of the namespace of the cluster of the context by listing its pods,
+// measuring the phases of the request. Unlike fetching the version of the API server, it only needs access to the namespace,
+// for credentials scoped to a namespace. A timeout of 0 waits for the API server as long as the context is not cancelled.
+func (kc K8sContext) NamespaceLivenessWithTimings(ctx context.Context, h *kubernetes.Client, namespace string, timeout time.Duration) (*K8sPhaseTimings, error) {
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+	trace := newK8sPhaseTrace()
+	_, err := h.KubeClient.CoreV1().Pods(namespace).List(trace.withTrace(ctx), v1.ListOptions{Limit: 1})
+	return trace.timings(), err
+}
+
 // AssignServerID will attempt to assign kubernetes
 // server ID to the kubernetes context
 func (kc *K8sContext) AssignServerID(handler *kubernetes.Client) error {
@@ -574,6 +1029,9 @@ func RedactCredentialsForContext(ctx *K8sContext) (redactedContext K8sContext) {
 	redactedContext.ConnectionID = ""
 	redactedContext.KubernetesServerID = nil
 	redactedContext.MesheryInstanceID = nil
+	redactedContext.ProxyURL = ""
+	redactedContext.ProxyUsername = ""
+	redactedContext.ProxyPassword = ""
 	return
 }
 
@@ -598,3 +1056,23 @@ func GenerateK8sClientSet(context *K8sContext, eb *events.EventBuilder, eventMet
 
 	return handler, nil
 }
+
+// LoadK8sContextPages fetches every page of the saved contexts matching the search, along with their credentials if withCredentials
+func LoadK8sContextPages(provider Provider, token, search, order string, withCredentials bool) ([]*K8sContext, error) {
+	const pageSize = 25
+	contexts := []*K8sContext{}
+	for page := 0; ; page++ {
+		res, err := provider.GetK8sContexts(token, strconv.Itoa(page), strconv.Itoa(pageSize), search, order, "", withCredentials)
+		if err != nil {
+			return nil, ErrLoadK8sContexts(err)
+		}
+		var contextsPage MesheryK8sContextPage
+		if err := json.Unmarshal(res, &contextsPage); err != nil {
+			return nil, ErrUnmarshal(err, "k8s context")
+		}
+		contexts = append(contexts, contextsPage.Contexts...)
+		if len(contextsPage.Contexts) == 0 || (page+1)*pageSize >= contextsPage.TotalCount {
+			return contexts, nil
+		}
+	}
+}
@@ -0,0 +1,118 @@
+package models
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/base64"
+	"encoding/json"
+	"io"
+
+	"github.com/layer5io/meshery/server/internal/sql"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/viper"
+)
+
+// k8sCompressedKey marks a cluster or auth map whose content is stored gzip compressed (and base64 encoded) under this key.
+const k8sCompressedKey = "gzip"
+
+// CompressK8sContextForStorage returns the context with its cluster and auth compressed when "COMPRESS_STORED_KUBECONFIG" is enabled,
+// so that large flattened kubeconfigs with inlined certificates take less space in the connections store.
+// The context is returned unchanged if compression is disabled or fails.
+func CompressK8sContextForStorage(kc K8sContext) K8sContext {
+	if !viper.GetBool("COMPRESS_STORED_KUBECONFIG") {
+		return kc
+	}
+	compressed, err := kc.Compress()
+	if err != nil {
+		logrus.Warn(err)
+		return kc
+	}
+	return compressed
+}
+
+// Compress returns a copy of the context with the cluster and auth gzip compressed.
+// Only the name is kept in clear so the maps can still be told apart.
+func (kc K8sContext) Compress() (K8sContext, error) {
+	var err error
+	if kc.Cluster, err = compressK8sMap(kc.Cluster); err != nil {
+		return kc, ErrCompressK8sContext(err, kc.Name)
+	}
+	if kc.Auth, err = compressK8sMap(kc.Auth); err != nil {
+		return kc, ErrCompressK8sContext(err, kc.Name)
+	}
+	return kc, nil
+}
+
+// Decompress returns a copy of the context with the cluster and auth decompressed, a context which
+// is not compressed is returned as is.
+func (kc K8sContext) Decompress() (K8sContext, error) {
+	var err error
+	if kc.Cluster, err = decompressK8sMap(kc.Cluster); err != nil {
+		return kc, ErrCompressK8sContext(err, kc.Name)
+	}
+	if kc.Auth, err = decompressK8sMap(kc.Auth); err != nil {
+		return kc, ErrCompressK8sContext(err, kc.Name)
+	}
+	return kc, nil
+}
+
+// IsCompressed reports whether the cluster or auth of the context is stored compressed.
+func (kc K8sContext) IsCompressed() bool {
+	_, cluster := kc.Cluster[k8sCompressedKey]
+	_, auth := kc.Auth[k8sCompressedKey]
+	return cluster || auth
+}
+
+func compressK8sMap(m sql.Map) (sql.Map, error) {
+	if len(m) == 0 {
+		return m, nil
+	}
+	if _, ok := m[k8sCompressedKey]; ok {
+		return m, nil
+	}
+	data, err := json.Marshal(m)
+	if err != nil {
+		return m, err
+	}
+
+	var buf bytes.Buffer
+	gw := gzip.NewWriter(&buf)
+	if _, err := gw.Write(data); err != nil {
+		return m, err
+	}
+	if err := gw.Close(); err != nil {
+		return m, err
+	}
+
+	compressed := sql.Map{k8sCompressedKey: base64.StdEncoding.EncodeToString(buf.Bytes())}
+	if name, ok := m["name"]; ok {
+		compressed["name"] = name
+	}
+	return compressed, nil
+}
+
+func decompressK8sMap(m sql.Map) (sql.Map, error) {
+	encoded, ok := m[k8sCompressedKey].(string)
+	if !ok {
+		return m, nil
+	}
+	data, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return m, err
+	}
+	gr, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return m, err
+	}
+	defer gr.Close()
+	data, err = io.ReadAll(gr)
+	if err != nil {
+		return m, err
+	}
+
+	decompressed := sql.Map{}
+	if err := json.Unmarshal(data, &decompressed); err != nil {
+		return m, err
+	}
+	return decompressed, nil
+}
@@ -0,0 +1,58 @@
+package models
+
+import "github.com/layer5io/meshery/server/internal/sql"
+
+// k8sCredentialKeys are the keys of the user of a kubeconfig through which it authenticates with the cluster
+var k8sCredentialKeys = []string{"token", "tokenFile", "client-certificate", "client-certificate-data", "username", "exec", "auth-provider"}
+
+// MissingConfig returns what the stored configuration of the context is missing to build a client from it: "server",
+// "cluster" or "credentials", e.g. for a context saved from an incomplete kubeconfig or whose configuration was corrupted.
+// A context whose cluster and auth cannot be decompressed is missing both.
+func (kc K8sContext) MissingConfig() []string {
+	missing := []string{}
+	kc, err := kc.Decompress()
+	if err != nil {
+		return append(missing, "cluster", "credentials")
+	}
+
+	cluster, _ := k8sNestedMap(kc.Cluster, "cluster")
+	server, _ := cluster["server"].(string)
+	if kc.Server == "" || server == "" {
+		missing = append(missing, "server")
+	}
+	if _, ok := kc.Cluster["name"].(string); !ok || len(cluster) == 0 {
+		missing = append(missing, "cluster")
+	}
+
+	user := k8sAuthUser(kc.Auth)
+	hasCredentials := false
+	for _, key := range k8sCredentialKeys {
+		if v, ok := user[key]; ok && v != nil && v != "" {
+			hasCredentials = true
+			break
+		}
+	}
+	if _, ok := kc.Auth["name"].(string); !ok || !hasCredentials {
+		missing = append(missing, "credentials")
+	}
+	return missing
+}
+
+// ValidateConfig returns an error identifying what the stored configuration of the context is missing, see MissingConfig
+func (kc K8sContext) ValidateConfig() error {
+	if missing := kc.MissingConfig(); len(missing) > 0 {
+		return ErrUnusableK8sContext(kc.Name, missing)
+	}
+	return nil
+}
+
+// k8sNestedMap returns the map under the key, as read from a kubeconfig or from the storage
+func k8sNestedMap(m map[string]interface{}, key string) (map[string]interface{}, bool) {
+	switch nested := m[key].(type) {
+	case map[string]interface{}:
+		return nested, true
+	case sql.Map:
+		return nested, true
+	}
+	return nil, false
+}
@@ -0,0 +1,142 @@
+package models
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"sort"
+	"strings"
+)
+
+// Aspects by which the contexts of a same cluster may differ
+const (
+	K8sContextDiffersByServer        = "server"
+	K8sContextDiffersByCredentials   = "credentials"
+	K8sContextDiffersByNamespace     = "namespace"
+	K8sContextDiffersByImpersonation = "impersonation"
+	K8sContextDiffersByProxy         = "proxy"
+)
+
+// K8sContextConflict is a group of connections managing the same cluster, identified by the UID of its kube-system namespace,
+// operations through one of them may conflict with the others
+type K8sContextConflict struct {
+	KubernetesServerID string                    `json:"kubernetes_server_id"`
+	Contexts           []K8sContextConflictEntry `json:"contexts"`
+	// Differences are the aspects the contexts differ by, one of the K8sContextDiffersBy* values,
+	// none when the contexts are duplicates of each other
+	Differences []string `json:"differences"`
+}
+
+// K8sContextConflictEntry is a context of a conflict, its credentials are identified by their fingerprint only
+type K8sContextConflictEntry struct {
+	ID           string `json:"id"`
+	ConnectionID string `json:"connection_id,omitempty"`
+	Name         string `json:"name"`
+	Server       string `json:"server"`
+	Namespace    string `json:"namespace,omitempty"`
+	// CredentialsFingerprint is a short hash of the credentials of the context, equal for equal credentials
+	CredentialsFingerprint string `json:"credentials_fingerprint"`
+	Impersonate            string `json:"impersonate,omitempty"`
+	ProxyURL               string `json:"proxy_url,omitempty"`
+}
+
+// FindK8sContextConflicts groups the contexts by the cluster they point at and returns the clusters with more than one context,
+// ordered by server ID, the contexts of each ordered by name. Contexts whose cluster was never reached have no server ID and are left out.
+func FindK8sContextConflicts(contexts []*K8sContext) ([]K8sContextConflict, error) {
+	groups := make(map[string][]K8sContextConflictEntry)
+	for _, ctx := range contexts {
+		if ctx.KubernetesServerID == nil {
+			continue
+		}
+		entry, err := newK8sContextConflictEntry(ctx)
+		if err != nil {
+			return nil, err
+		}
+		serverID := ctx.KubernetesServerID.String()
+		groups[serverID] = append(groups[serverID], entry)
+	}
+
+	conflicts := []K8sContextConflict{}
+	for serverID, entries := range groups {
+		if len(entries) < 2 {
+			continue
+		}
+		sort.SliceStable(entries, func(i, j int) bool {
+			return entries[i].Name < entries[j].Name
+		})
+		conflicts = append(conflicts, K8sContextConflict{
+			KubernetesServerID: serverID,
+			Contexts:           entries,
+			Differences:        k8sContextDifferences(entries),
+		})
+	}
+	sort.Slice(conflicts, func(i, j int) bool {
+		return conflicts[i].KubernetesServerID < conflicts[j].KubernetesServerID
+	})
+	return conflicts, nil
+}
+
+func newK8sContextConflictEntry(ctx *K8sContext) (K8sContextConflictEntry, error) {
+	kc, err := ctx.Decompress()
+	if err != nil {
+		return K8sContextConflictEntry{}, err
+	}
+	impersonation, err := kc.Impersonation()
+	if err != nil {
+		return K8sContextConflictEntry{}, err
+	}
+	fingerprint, err := k8sCredentialsFingerprint(kc.Auth)
+	if err != nil {
+		return K8sContextConflictEntry{}, err
+	}
+	return K8sContextConflictEntry{
+		ID:                     kc.ID,
+		ConnectionID:           kc.ConnectionID,
+		Name:                   kc.Name,
+		Server:                 kc.Server,
+		Namespace:              kc.Namespace,
+		CredentialsFingerprint: fingerprint,
+		Impersonate:            impersonation.UserName,
+		ProxyURL:               kc.ProxyURL,
+	}, nil
+}
+
+// k8sCredentialsFingerprint hashes the credentials of the user of the auth, leaving out the impersonated identity
+func k8sCredentialsFingerprint(auth map[string]interface{}) (string, error) {
+	credentials := make(map[string]interface{})
+	for key, value := range k8sAuthUser(auth) {
+		if !strings.HasPrefix(key, "as") {
+			credentials[key] = value
+		}
+	}
+	byt, err := json.Marshal(credentials)
+	if err != nil {
+		return "", ErrMarshal(err, "kubernetes credentials")
+	}
+	sum := sha256.Sum256(byt)
+	return hex.EncodeToString(sum[:])[:12], nil
+}
+
+// k8sContextDifferences returns the aspects the contexts differ by
+func k8sContextDifferences(entries []K8sContextConflictEntry) []string {
+	aspects := []struct {
+		name  string
+		value func(K8sContextConflictEntry) string
+	}{
+		{K8sContextDiffersByServer, func(e K8sContextConflictEntry) string { return e.Server }},
+		{K8sContextDiffersByCredentials, func(e K8sContextConflictEntry) string { return e.CredentialsFingerprint }},
+		{K8sContextDiffersByNamespace, func(e K8sContextConflictEntry) string { return e.Namespace }},
+		{K8sContextDiffersByImpersonation, func(e K8sContextConflictEntry) string { return e.Impersonate }},
+		{K8sContextDiffersByProxy, func(e K8sContextConflictEntry) string { return e.ProxyURL }},
+	}
+	differences := []string{}
+	for _, aspect := range aspects {
+		for _, entry := range entries[1:] {
+			if aspect.value(entry) != aspect.value(entries[0]) {
+				differences = append(differences, aspect.name)
+				break
+			}
+		}
+	}
+	return differences
+}
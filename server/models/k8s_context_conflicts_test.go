@@ -0,0 +1,46 @@
+package models
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/gofrs/uuid"
+	"github.com/layer5io/meshery/server/internal/sql"
+)
+
+func TestFindK8sContextConflicts(t *testing.T) {
+	shared, other := uuid.Must(uuid.NewV4()), uuid.Must(uuid.NewV4())
+	auth := func(token string) sql.Map {
+		return sql.Map{"name": "admin", "user": map[string]interface{}{"token": token}}
+	}
+	contexts := []*K8sContext{
+		{ID: "1", Name: "prod-admin", Server: "https://prod", KubernetesServerID: &shared, Auth: auth("a")},
+		{ID: "2", Name: "prod-team-a", Server: "https://prod", Namespace: "team-a", KubernetesServerID: &shared, Auth: auth("b")},
+		{ID: "3", Name: "staging", Server: "https://staging", KubernetesServerID: &other, Auth: auth("a")},
+		{ID: "4", Name: "unreached", Server: "https://prod", Auth: auth("a")},
+	}
+
+	conflicts, err := FindK8sContextConflicts(contexts)
+	if err != nil {
+		t.Fatalf("FindK8sContextConflicts() failed with error: %s", err)
+	}
+	if len(conflicts) != 1 || conflicts[0].KubernetesServerID != shared.String() || len(conflicts[0].Contexts) != 2 {
+		t.Fatalf("FindK8sContextConflicts() = %+v, want the two contexts of the shared cluster", conflicts)
+	}
+	if got := strings.Join(conflicts[0].Differences, ","); got != "credentials,namespace" {
+		t.Errorf("differences = %s, want credentials,namespace", got)
+	}
+	if fingerprint := conflicts[0].Contexts[0].CredentialsFingerprint; len(fingerprint) != 12 {
+		t.Errorf("fingerprint = %q, want a short hash of the credentials", fingerprint)
+	}
+
+	// Contexts which are duplicates of each other differ by nothing
+	contexts[1].Namespace, contexts[1].Auth = "", auth("a")
+	conflicts, err = FindK8sContextConflicts(contexts)
+	if err != nil {
+		t.Fatalf("FindK8sContextConflicts() failed with error: %s", err)
+	}
+	if len(conflicts) != 1 || len(conflicts[0].Differences) != 0 {
+		t.Errorf("FindK8sContextConflicts() = %+v, want duplicates with no differences", conflicts)
+	}
+}
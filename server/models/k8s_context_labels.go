@@ -0,0 +1,67 @@
+package models
+
+import (
+	"fmt"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/validation"
+)
+
+// K8sContextLabelsPatch adds and removes labels of contexts, the labels both added and removed are added
+type K8sContextLabelsPatch struct {
+	Add    map[string]string `json:"add,omitempty"`
+	Remove []string          `json:"remove,omitempty"`
+}
+
+// K8sContextLabelsResult is the outcome of patching the labels of one context, the context is the patched one when Err is nil
+type K8sContextLabelsResult struct {
+	ID      string
+	Context K8sContext
+	Err     error
+}
+
+// Validate checks the patch adds labels following the syntax of the Kubernetes labels
+func (p K8sContextLabelsPatch) Validate() error {
+	if len(p.Add) == 0 && len(p.Remove) == 0 {
+		return ErrInvalidK8sContextLabels(fmt.Errorf("no labels to add or remove"))
+	}
+	for key, value := range p.Add {
+		if errs := validation.IsQualifiedName(key); len(errs) != 0 {
+			return ErrInvalidK8sContextLabels(fmt.Errorf("invalid label key %q: %s", key, strings.Join(errs, "; ")))
+		}
+		if errs := validation.IsValidLabelValue(value); len(errs) != 0 {
+			return ErrInvalidK8sContextLabels(fmt.Errorf("invalid value %q of label %q: %s", value, key, strings.Join(errs, "; ")))
+		}
+	}
+	return nil
+}
+
+// Apply returns the labels patched, labels is left unchanged
+func (p K8sContextLabelsPatch) Apply(labels map[string]string) map[string]string {
+	patched := make(map[string]string, len(labels)+len(p.Add))
+	for key, value := range labels {
+		patched[key] = value
+	}
+	for _, key := range p.Remove {
+		delete(patched, key)
+	}
+	for key, value := range p.Add {
+		patched[key] = value
+	}
+	return patched
+}
+
+// ParseK8sContextSelector parses a Kubernetes label selector (e.g. "env=staging,team!=infra") matched against the labels of the contexts
+func ParseK8sContextSelector(selector string) (labels.Selector, error) {
+	parsed, err := labels.Parse(selector)
+	if err != nil {
+		return nil, ErrInvalidK8sContextLabels(err)
+	}
+	return parsed, nil
+}
+
+// MatchesSelector reports whether the labels of the context match the selector
+func (kc K8sContext) MatchesSelector(selector labels.Selector) bool {
+	return selector.Matches(labels.Set(kc.Labels))
+}
@@ -0,0 +1,85 @@
+package models
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/layer5io/meshkit/database"
+)
+
+func TestK8sContextLabelsPatch(t *testing.T) {
+	patch := K8sContextLabelsPatch{Add: map[string]string{"env": "prod", "team": "infra"}, Remove: []string{"env", "tier"}}
+	if err := patch.Validate(); err != nil {
+		t.Fatalf("Validate() failed with error: %s", err)
+	}
+	labels := map[string]string{"env": "staging", "tier": "gold", "region": "eu"}
+	want := map[string]string{"env": "prod", "team": "infra", "region": "eu"}
+	if got := patch.Apply(labels); !reflect.DeepEqual(got, want) {
+		t.Errorf("Apply() = %v, want %v", got, want)
+	}
+	if labels["env"] != "staging" {
+		t.Errorf("Apply() changed the labels it patched")
+	}
+
+	for _, invalid := range []K8sContextLabelsPatch{
+		{},
+		{Add: map[string]string{"not a key": "value"}},
+		{Add: map[string]string{"env": "not a value"}},
+	} {
+		if err := invalid.Validate(); err == nil {
+			t.Errorf("Validate() of %+v succeeded, want an error", invalid)
+		}
+	}
+
+	selector, err := ParseK8sContextSelector("env=prod,team!=web")
+	if err != nil {
+		t.Fatalf("ParseK8sContextSelector() failed with error: %s", err)
+	}
+	if !(K8sContext{Labels: want}).MatchesSelector(selector) || (K8sContext{Labels: labels}).MatchesSelector(selector) {
+		t.Errorf("MatchesSelector() does not match the labels of the contexts against %s", selector)
+	}
+	if _, err := ParseK8sContextSelector("env in (prod"); err == nil {
+		t.Errorf("ParseK8sContextSelector() of an invalid selector succeeded, want an error")
+	}
+}
+
+func TestPatchMesheryK8sContextsLabels(t *testing.T) {
+	db, err := database.New(database.Options{Engine: database.SQLITE, Filename: filepath.Join(t.TempDir(), "contexts.db")})
+	if err != nil {
+		t.Fatalf("failed to open the database: %s", err)
+	}
+	if err := db.AutoMigrate(&K8sContext{}); err != nil {
+		t.Fatalf("failed to migrate the database: %s", err)
+	}
+	persister := &MesheryK8sContextPersister{DB: &db}
+	for _, kc := range []K8sContext{
+		{ID: "staging", Name: "staging", Labels: map[string]string{"env": "staging"}},
+		{ID: "prod", Name: "prod"},
+	} {
+		if _, err := persister.SaveMesheryK8sContext(kc); err != nil {
+			t.Fatalf("SaveMesheryK8sContext() failed with error: %s", err)
+		}
+	}
+	patch := K8sContextLabelsPatch{Add: map[string]string{"team": "infra"}}
+
+	results := persister.PatchMesheryK8sContextsLabels([]string{"staging", "prod"}, patch)
+	for _, result := range results {
+		if result.Err != nil || result.Context.Labels["team"] != "infra" {
+			t.Errorf("PatchMesheryK8sContextsLabels() = %+v, want the labels of %s patched", result, result.ID)
+		}
+	}
+	if kc, _ := persister.GetMesheryK8sContext("staging"); !reflect.DeepEqual(kc.Labels, map[string]string{"env": "staging", "team": "infra"}) {
+		t.Errorf("labels of the saved context = %v, want the patched labels", kc.Labels)
+	}
+
+	results = persister.PatchMesheryK8sContextsLabels([]string{"prod", "missing"}, K8sContextLabelsPatch{Remove: []string{"team"}})
+	for _, result := range results {
+		if result.Err == nil {
+			t.Errorf("PatchMesheryK8sContextsLabels() of %s succeeded, want every context to fail along with the missing one", result.ID)
+		}
+	}
+	if kc, _ := persister.GetMesheryK8sContext("prod"); kc.Labels["team"] != "infra" {
+		t.Errorf("labels of the saved context = %v, want them left unchanged as the patch was rolled back", kc.Labels)
+	}
+}
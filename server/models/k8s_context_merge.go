@@ -0,0 +1,105 @@
+package models
+
+import (
+	"encoding/json"
+	"sort"
+
+	"github.com/gofrs/uuid"
+	"gopkg.in/yaml.v2"
+	"k8s.io/client-go/tools/clientcmd"
+)
+
+// K8sContextMerge reports the contexts of a kubeconfig collapsed into a single connection
+// for pointing at the same cluster as the same user, differing only by their namespace.
+type K8sContextMerge struct {
+	// Context the others were merged into
+	Context string `json:"context"`
+	Server  string `json:"server"`
+	// MergedContexts are the contexts which were merged into Context, no connection is created for them
+	MergedContexts []string `json:"merged_contexts"`
+	Namespaces     []string `json:"namespaces"`
+}
+
+// MergeKubeconfigContextsByCluster collapses the contexts of the kubeconfig pointing at the same cluster as the same user
+// into the first of them by name, before any of them is dialed. The returned kubeconfig holds the contexts which are not merged
+// into another, the merges record the namespaces of the contexts of each of them, "default" when a context sets none.
+func MergeKubeconfigContextsByCluster(kubeconfig []byte) ([]byte, []K8sContextMerge, error) {
+	parsed, err := clientcmd.Load(kubeconfig)
+	if err != nil {
+		return nil, nil, ErrInvalidKubeconfigStructure(err)
+	}
+	kcfg := InternalKubeConfig{}
+	if err := yaml.Unmarshal(kubeconfig, &kcfg); err != nil {
+		return nil, nil, ErrInvalidKubeconfigStructure(err)
+	}
+
+	names := make([]string, 0, len(parsed.Contexts))
+	for name := range parsed.Contexts {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	instanceID := uuid.UUID{}
+	groups := make(map[string][]string)
+	keys := []string{}
+	for _, name := range names {
+		ctx, _ := kcfg.K8sContext(name, &instanceID)
+		key, err := k8sContextClusterUserKey(&ctx)
+		if err != nil {
+			return nil, nil, err
+		}
+		if _, ok := groups[key]; !ok {
+			keys = append(keys, key)
+		}
+		groups[key] = append(groups[key], name)
+	}
+
+	merges := []K8sContextMerge{}
+	for _, key := range keys {
+		group := groups[key]
+		if len(group) == 1 {
+			continue
+		}
+		primary := group[0]
+		merge := K8sContextMerge{Context: primary, Server: parsed.Clusters[parsed.Contexts[primary].Cluster].Server, MergedContexts: []string{}}
+		namespaces := make(map[string]bool, len(group))
+		for _, name := range group {
+			namespace := "default"
+			if ns := parsed.Contexts[name].Namespace; ns != "" {
+				namespace = ns
+			}
+			if !namespaces[namespace] {
+				namespaces[namespace] = true
+				merge.Namespaces = append(merge.Namespaces, namespace)
+			}
+			if name != primary {
+				merge.MergedContexts = append(merge.MergedContexts, name)
+				delete(parsed.Contexts, name)
+			}
+		}
+		sort.Strings(merge.Namespaces)
+		merges = append(merges, merge)
+	}
+	if len(merges) == 0 {
+		return kubeconfig, merges, nil
+	}
+
+	merged, err := clientcmd.Write(*parsed)
+	if err != nil {
+		return nil, nil, ErrInvalidKubeconfigStructure(err)
+	}
+	return merged, merges, nil
+}
+
+// k8sContextClusterUserKey identifies the cluster and the user of the context regardless of the names they are given in the kubeconfig
+func k8sContextClusterUserKey(ctx *K8sContext) (string, error) {
+	byt, err := json.Marshal(map[string]interface{}{
+		"server":  ctx.Server,
+		"cluster": ctx.Cluster["cluster"],
+		"user":    k8sAuthUser(ctx.Auth),
+	})
+	if err != nil {
+		return "", ErrMarshal(err, "kubernetes context")
+	}
+	return string(byt), nil
+}
@@ -0,0 +1,123 @@
+package models
+
+import (
+	"fmt"
+
+	"gopkg.in/yaml.v2"
+)
+
+// DisambiguateK8sContextNames renames the contexts of the kubeconfig whose name is taken by an earlier context,
+// as left behind by a mis-merge of kubeconfigs, which would otherwise fail to load altogether.
+// The duplicates get a numeric suffix (e.g. "dev-2") not used by any other context.
+//
+// The returned map holds the original name of each renamed context, keyed by its new name.
+// The kubeconfig is returned as is when its context names are unique.
+func DisambiguateK8sContextNames(kubeconfig []byte) ([]byte, map[string]string, error) {
+	var doc yaml.MapSlice
+	if err := yaml.Unmarshal(kubeconfig, &doc); err != nil {
+		return kubeconfig, nil, ErrInvalidKubeconfigStructure(err)
+	}
+
+	var contexts []interface{}
+	for _, item := range doc {
+		if item.Key == "contexts" {
+			contexts, _ = item.Value.([]interface{})
+		}
+	}
+
+	nameOf := func(ctx interface{}) (string, int) {
+		fields, _ := ctx.(yaml.MapSlice)
+		for i, field := range fields {
+			if field.Key == "name" {
+				name, _ := field.Value.(string)
+				return name, i
+			}
+		}
+		return "", -1
+	}
+
+	taken := make(map[string]bool, len(contexts))
+	for _, ctx := range contexts {
+		name, _ := nameOf(ctx)
+		taken[name] = true
+	}
+
+	renamed := map[string]string{}
+	seen := make(map[string]bool, len(contexts))
+	for _, ctx := range contexts {
+		name, idx := nameOf(ctx)
+		if idx < 0 {
+			continue
+		}
+		if !seen[name] {
+			seen[name] = true
+			continue
+		}
+		newName := name
+		for n := 2; taken[newName]; n++ {
+			newName = fmt.Sprintf("%s-%d", name, n)
+		}
+		taken[newName], seen[newName] = true, true
+		ctx.(yaml.MapSlice)[idx].Value = newName
+		renamed[newName] = name
+	}
+	if len(renamed) == 0 {
+		return kubeconfig, renamed, nil
+	}
+
+	disambiguated, err := yaml.Marshal(doc)
+	if err != nil {
+		return kubeconfig, nil, ErrMarshal(err, "kube config")
+	}
+	return disambiguated, renamed, nil
+}
+
+// kubeconfigFields are the top-level fields of a kubeconfig, one of which at least is expected in a kubeconfig
+var kubeconfigFields = []string{"clusters", "contexts", "users", "current-context"}
+
+// NormalizeKubeconfig injects the "apiVersion: v1" and "kind: Config" some generated kubeconfigs omit,
+// which client-go may or may not accept depending on its version.
+// A document which has none of the fields of a kubeconfig, or whose kind is not "Config", is rejected.
+// The kubeconfig is returned as is when it declares both.
+func NormalizeKubeconfig(kubeconfig []byte) ([]byte, error) {
+	var doc yaml.MapSlice
+	if err := yaml.Unmarshal(kubeconfig, &doc); err != nil {
+		return nil, ErrInvalidKubeconfigStructure(err)
+	}
+
+	var hasAPIVersion, hasKind, isKubeconfig bool
+	for _, item := range doc {
+		key, _ := item.Key.(string)
+		switch key {
+		case "apiVersion":
+			hasAPIVersion = true
+		case "kind":
+			hasKind = true
+			if kind, _ := item.Value.(string); kind != "Config" {
+				return nil, ErrInvalidKubeconfigStructure(fmt.Errorf("kind is %v instead of Config", item.Value))
+			}
+		}
+		for _, field := range kubeconfigFields {
+			isKubeconfig = isKubeconfig || key == field
+		}
+	}
+	if !isKubeconfig {
+		return nil, ErrInvalidKubeconfigStructure(fmt.Errorf("none of %v is set", kubeconfigFields))
+	}
+	if hasAPIVersion && hasKind {
+		return kubeconfig, nil
+	}
+
+	header := yaml.MapSlice{}
+	if !hasAPIVersion {
+		header = append(header, yaml.MapItem{Key: "apiVersion", Value: "v1"})
+	}
+	if !hasKind {
+		header = append(header, yaml.MapItem{Key: "kind", Value: "Config"})
+	}
+	normalized, err := yaml.Marshal(append(header, doc...))
+	if err != nil {
+		return nil, ErrMarshal(err, "kubeconfig")
+	}
+	return normalized, nil
+}
@@ -0,0 +1,112 @@
+package models
+
+import (
+	"net/url"
+	"regexp"
+	"strings"
+	"text/template"
+)
+
+// k8sContextNameFields are the fields available to the naming templates of the connections, see K8sContextNameData
+var k8sContextNameFields = []string{"Name", "Cluster", "Namespace", "Server", "Host", "Distribution", "Region", "Version", "ShortServerID"}
+
+var (
+	// e.g. ABCDEF0123456789.gr7.us-east-1.eks.amazonaws.com
+	eksServerPattern = regexp.MustCompile(`\.([a-z]{2}(?:-[a-z]+)+-\d+)\.eks\.amazonaws\.com(?:\.cn)?$`)
+	// e.g. arn:aws:eks:us-east-1:123456789012:cluster/my-cluster
+	eksContextPattern = regexp.MustCompile(`^arn:aws[a-z-]*:eks:([a-z0-9-]+):\d+:cluster/(.+)$`)
+	// e.g. gke_my-project_us-central1-a_my-cluster
+	gkeContextPattern = regexp.MustCompile(`^gke_[^_]+_([^_]+)_(.+)$`)
+	// e.g. my-cluster-dns-0a1b2c3d.hcp.eastus.azmk8s.io
+	aksServerPattern = regexp.MustCompile(`\.hcp\.([a-z0-9]+)\.azmk8s\.io$`)
+)
+
+// ParseK8sContextNameTemplate parses the template naming the connections of the uploaded contexts,
+// e.g. "{{.Distribution}}-{{.Region}}-{{.ShortServerID}}". Referring to a field which is not one of K8sContextNameData is an error.
+func ParseK8sContextNameTemplate(nameTemplate string) (*template.Template, error) {
+	tmpl, err := template.New("connection name").Option("missingkey=error").Parse(nameTemplate)
+	if err != nil {
+		return nil, ErrInvalidK8sContextNameTemplate(err, nameTemplate)
+	}
+	sample := make(map[string]string, len(k8sContextNameFields))
+	for _, field := range k8sContextNameFields {
+		sample[field] = field
+	}
+	if err := tmpl.Execute(&strings.Builder{}, sample); err != nil {
+		return nil, ErrInvalidK8sContextNameTemplate(err, nameTemplate)
+	}
+	return tmpl, nil
+}
+
+// K8sContextNameData returns the metadata of the context available to the naming templates, the fields which could not be
+// detected are left out:
+//   - Name of the context, Cluster of the kubeconfig and Namespace of the context
+//   - Server URL of the API server and its Host
+//   - Distribution (eks, gke, aks, kind, k3d, minikube or docker-desktop) and Region of the cluster, detected from the server and the name of the context
+//   - Version of Kubernetes and ShortServerID, the first 8 characters of the ID of the cluster
+func K8sContextNameData(ctx K8sContext) map[string]string {
+	data := map[string]string{
+		"Name":      ctx.Name,
+		"Namespace": ctx.Namespace,
+		"Server":    ctx.Server,
+		"Version":   ctx.Version,
+	}
+	data["Cluster"], _ = ctx.Cluster["name"].(string)
+	if ctx.KubernetesServerID != nil && !ctx.KubernetesServerID.IsNil() {
+		data["ShortServerID"] = ctx.KubernetesServerID.String()[:8]
+	}
+	host := ""
+	if u, err := url.Parse(ctx.Server); err == nil {
+		host = u.Hostname()
+	}
+	data["Host"] = host
+
+	switch {
+	case eksServerPattern.MatchString(host):
+		data["Distribution"], data["Region"] = "eks", eksServerPattern.FindStringSubmatch(host)[1]
+	case eksContextPattern.MatchString(ctx.Name):
+		m := eksContextPattern.FindStringSubmatch(ctx.Name)
+		data["Distribution"], data["Region"] = "eks", m[1]
+		if data["Cluster"] == ctx.Name {
+			data["Cluster"] = m[2]
+		}
+	case gkeContextPattern.MatchString(ctx.Name):
+		m := gkeContextPattern.FindStringSubmatch(ctx.Name)
+		data["Distribution"], data["Region"] = "gke", m[1]
+		if data["Cluster"] == ctx.Name {
+			data["Cluster"] = m[2]
+		}
+	case aksServerPattern.MatchString(host):
+		data["Distribution"], data["Region"] = "aks", aksServerPattern.FindStringSubmatch(host)[1]
+	case strings.HasPrefix(ctx.Name, "kind-"):
+		data["Distribution"] = "kind"
+	case strings.HasPrefix(ctx.Name, "k3d-"):
+		data["Distribution"] = "k3d"
+	case ctx.Name == "minikube" || ctx.Name == "docker-desktop":
+		data["Distribution"] = ctx.Name
+	}
+
+	for field, value := range data {
+		if value == "" {
+			delete(data, field)
+		}
+	}
+	return data
+}
+
+// ApplyNameTemplate names the context by the template, recording its former name in OriginalName.
+// The name is left as is when the template refers to metadata which is not available for the context,
+// or renders an empty name. Returns whether the context was renamed.
+func (kc *K8sContext) ApplyNameTemplate(tmpl *template.Template) bool {
+	var name strings.Builder
+	if err := tmpl.Execute(&name, K8sContextNameData(*kc)); err != nil {
+		return false
+	}
+	derived := strings.TrimSpace(name.String())
+	if derived == "" || derived == kc.Name {
+		return false
+	}
+	kc.OriginalName = kc.Name
+	kc.Name = derived
+	return true
+}
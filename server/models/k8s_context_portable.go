@@ -0,0 +1,119 @@
+package models
+
+import (
+	"fmt"
+	"time"
+
+	"k8s.io/client-go/tools/clientcmd"
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
+)
+
+// PortableK8sContextVersion is the version of the format of the portable contexts, bumped on incompatible changes
+const PortableK8sContextVersion = "v1"
+
+// PortableK8sContext is a connection in a self-contained format, to back it up or move it to another Meshery instance.
+// Only what the user configured on the connection travels with it, what Meshery discovers of the cluster is discovered again on import.
+type PortableK8sContext struct {
+	Version string `json:"version"`
+	Name    string `json:"name"`
+	Server  string `json:"server"`
+	// Kubeconfig of the context with the referenced files inlined, its credentials are left out unless WithCredentials
+	Kubeconfig      string    `json:"kubeconfig"`
+	WithCredentials bool      `json:"with_credentials"`
+	ExportedAt      time.Time `json:"exported_at"`
+
+	Notes                 string  `json:"notes,omitempty"`
+	SyncInterval          string  `json:"sync_interval,omitempty"`
+	Pinned                bool    `json:"pinned,omitempty"`
+	Managed               bool    `json:"managed,omitempty"`
+	ProxyURL              string  `json:"proxy_url,omitempty"`
+	ProxyUsername         string  `json:"proxy_username,omitempty"`
+	ProxyPassword         string  `json:"proxy_password,omitempty"`
+	DialTimeout           string  `json:"dial_timeout,omitempty"`
+	TLSHandshakeTimeout   string  `json:"tls_handshake_timeout,omitempty"`
+	ResponseHeaderTimeout string  `json:"response_header_timeout,omitempty"`
+	TLSServerName         string  `json:"tls_server_name,omitempty"`
+	QPS                   float32 `json:"qps,omitempty"`
+	Burst                 int     `json:"burst,omitempty"`
+
+	Labels map[string]string `json:"labels,omitempty"`
+}
+
+// NewPortableK8sContext exports the context in the portable format. The credentials of the cluster and of the proxy
+// are left out unless withCredentials, a portable context without them cannot be imported.
+func NewPortableK8sContext(kc K8sContext, withCredentials bool) (*PortableK8sContext, error) {
+	kubeconfig, err := kc.GenerateKubeConfig()
+	if err != nil {
+		return nil, err
+	}
+	cfg, err := clientcmd.Load(kubeconfig)
+	if err != nil {
+		return nil, ErrInvalidKubeconfigStructure(err)
+	}
+	if !withCredentials {
+		for name := range cfg.AuthInfos {
+			cfg.AuthInfos[name] = clientcmdapi.NewAuthInfo()
+		}
+		kc.ProxyUsername, kc.ProxyPassword = "", ""
+	}
+	kubeconfig, err = clientcmd.Write(*cfg)
+	if err != nil {
+		return nil, ErrMarshal(err, "kube config")
+	}
+
+	return &PortableK8sContext{
+		Version:               PortableK8sContextVersion,
+		Name:                  kc.Name,
+		Server:                kc.Server,
+		Kubeconfig:            string(kubeconfig),
+		WithCredentials:       withCredentials,
+		ExportedAt:            time.Now().UTC(),
+		Notes:                 kc.Notes,
+		SyncInterval:          kc.SyncInterval,
+		Pinned:                kc.Pinned,
+		Managed:               kc.Managed,
+		ProxyURL:              kc.ProxyURL,
+		ProxyUsername:         kc.ProxyUsername,
+		ProxyPassword:         kc.ProxyPassword,
+		DialTimeout:           kc.DialTimeout,
+		TLSHandshakeTimeout:   kc.TLSHandshakeTimeout,
+		ResponseHeaderTimeout: kc.ResponseHeaderTimeout,
+		TLSServerName:         kc.TLSServerName,
+		QPS:                   kc.QPS,
+		Burst:                 kc.Burst,
+		Labels:                kc.Labels,
+	}, nil
+}
+
+// Validate checks that the portable context can be imported, i.e. that it is of a known version
+// and holds the kubeconfig of a single context along with its credentials
+func (p *PortableK8sContext) Validate() error {
+	if p.Version != PortableK8sContextVersion {
+		return ErrInvalidPortableK8sContext(fmt.Errorf("unsupported version %q, expected %q", p.Version, PortableK8sContextVersion))
+	}
+	if !p.WithCredentials {
+		return ErrInvalidPortableK8sContext(fmt.Errorf("context %q was exported without its credentials", p.Name))
+	}
+	cfg, err := clientcmd.Load([]byte(p.Kubeconfig))
+	if err != nil {
+		return ErrInvalidPortableK8sContext(err)
+	}
+	if len(cfg.Contexts) != 1 {
+		return ErrInvalidPortableK8sContext(fmt.Errorf("the kubeconfig holds %d contexts, expected exactly one", len(cfg.Contexts)))
+	}
+	return nil
+}
+
+// Configure restores the configuration of the connection carried by the portable context onto the imported context
+func (p *PortableK8sContext) Configure(kc *K8sContext) {
+	kc.Source = K8sContextSourceImport
+	kc.Notes = p.Notes
+	kc.SyncInterval = p.SyncInterval
+	kc.Labels = p.Labels
+	kc.Pinned = p.Pinned
+	kc.Managed = p.Managed
+	kc.ProxyURL, kc.ProxyUsername, kc.ProxyPassword = p.ProxyURL, p.ProxyUsername, p.ProxyPassword
+	kc.DialTimeout, kc.TLSHandshakeTimeout, kc.ResponseHeaderTimeout = p.DialTimeout, p.TLSHandshakeTimeout, p.ResponseHeaderTimeout
+	kc.TLSServerName = p.TLSServerName
+	kc.QPS, kc.Burst = p.QPS, p.Burst
+}
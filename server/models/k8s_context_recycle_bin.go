@@ -0,0 +1,116 @@
+package models
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/layer5io/meshkit/logger"
+	"github.com/spf13/viper"
+)
+
+// DefaultK8sContextDeletionGracePeriod is how long the deleted contexts are kept for restore unless "CONNECTION_DELETION_GRACE_PERIOD" is set
+const DefaultK8sContextDeletionGracePeriod = 7 * 24 * time.Hour
+
+// K8sContextRecycleBin holds the deleted contexts for their grace period, during which they may be restored,
+// and permanently deletes them through their provider once it is over.
+// The contexts deleted before Meshery Server restarted are purged when the deleted contexts are listed, see Purgeable.
+type K8sContextRecycleBin struct {
+	GracePeriod time.Duration
+	Interval    time.Duration
+
+	mx      sync.Mutex
+	entries map[string]recycledK8sContext
+	log     logger.Handler
+	now     func() time.Time
+}
+
+type recycledK8sContext struct {
+	provider  Provider
+	token     string
+	deletedAt time.Time
+}
+
+// NewK8sContextRecycleBinFromConfig returns the recycle bin keeping the deleted contexts for "CONNECTION_DELETION_GRACE_PERIOD",
+// checked every "CONNECTION_EXPIRY_SWEEP_INTERVAL"
+func NewK8sContextRecycleBinFromConfig(log logger.Handler) *K8sContextRecycleBin {
+	gracePeriod := viper.GetDuration("CONNECTION_DELETION_GRACE_PERIOD")
+	if gracePeriod < 0 {
+		gracePeriod = DefaultK8sContextDeletionGracePeriod
+	}
+	interval := viper.GetDuration("CONNECTION_EXPIRY_SWEEP_INTERVAL")
+	if interval <= 0 {
+		interval = time.Minute
+	}
+	return &K8sContextRecycleBin{
+		GracePeriod: gracePeriod,
+		Interval:    interval,
+		entries:     make(map[string]recycledK8sContext),
+		log:         log,
+		now:         time.Now,
+	}
+}
+
+// Add keeps the context deleted at deletedAt, with the given id, until it is restored or purged by the provider
+func (b *K8sContextRecycleBin) Add(provider Provider, token, id string, deletedAt time.Time) {
+	b.mx.Lock()
+	defer b.mx.Unlock()
+	b.entries[id] = recycledK8sContext{provider: provider, token: token, deletedAt: deletedAt}
+}
+
+// Remove takes the context with the given id out of the recycle bin, once restored or purged
+func (b *K8sContextRecycleBin) Remove(id string) {
+	b.mx.Lock()
+	defer b.mx.Unlock()
+	delete(b.entries, id)
+}
+
+// PurgeAt returns when the deleted context is purged, nil when the context is not deleted
+func (b *K8sContextRecycleBin) PurgeAt(kc K8sContext) *time.Time {
+	if kc.DeletedAt == nil {
+		return nil
+	}
+	purgeAt := kc.DeletedAt.Add(b.GracePeriod)
+	return &purgeAt
+}
+
+// Purgeable reports whether the context is deleted and its grace period is over
+func (b *K8sContextRecycleBin) Purgeable(kc K8sContext) bool {
+	purgeAt := b.PurgeAt(kc)
+	return purgeAt != nil && !b.now().Before(*purgeAt)
+}
+
+// Run purges the contexts whose grace period is over every interval until ctx is done
+func (b *K8sContextRecycleBin) Run(ctx context.Context) {
+	ticker := time.NewTicker(b.Interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			b.purge()
+		}
+	}
+}
+
+func (b *K8sContextRecycleBin) purge() {
+	now := b.now()
+	purgeable := make(map[string]recycledK8sContext)
+	b.mx.Lock()
+	for id, entry := range b.entries {
+		if !now.Before(entry.deletedAt.Add(b.GracePeriod)) {
+			purgeable[id] = entry
+			delete(b.entries, id)
+		}
+	}
+	b.mx.Unlock()
+
+	for id, entry := range purgeable {
+		if _, err := entry.provider.DeleteK8sContext(entry.token, id); err != nil {
+			b.log.Info("unable to purge deleted kubernetes context ", id, ", it is purged once the deleted contexts are listed: ", err.Error())
+			continue
+		}
+		b.log.Info("purged deleted kubernetes context ", id)
+	}
+}
@@ -0,0 +1,120 @@
+package models
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/layer5io/meshery/server/internal/sql"
+	"github.com/layer5io/meshkit/database"
+	"github.com/layer5io/meshkit/logger"
+	"github.com/sirupsen/logrus"
+)
+
+// purgingProvider records the contexts deleted permanently
+type purgingProvider struct {
+	Provider
+	purged []string
+}
+
+func (p *purgingProvider) DeleteK8sContext(_, id string) (K8sContext, error) {
+	p.purged = append(p.purged, id)
+	return K8sContext{ID: id}, nil
+}
+
+func TestK8sContextRecycleBin(t *testing.T) {
+	log, err := logger.New("test", logger.Options{LogLevel: int(logrus.InfoLevel)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Date(2024, 1, 10, 0, 0, 0, 0, time.UTC)
+	bin := NewK8sContextRecycleBinFromConfig(log)
+	bin.GracePeriod = 24 * time.Hour
+	bin.now = func() time.Time { return now }
+
+	provider := &purgingProvider{}
+	bin.Add(provider, "token", "expired", now.Add(-25*time.Hour))
+	bin.Add(provider, "token", "recent", now.Add(-time.Hour))
+	bin.Add(provider, "token", "restored", now.Add(-48*time.Hour))
+	bin.Remove("restored")
+	bin.purge()
+
+	if len(provider.purged) != 1 || provider.purged[0] != "expired" {
+		t.Errorf("purged %v, want only the context past its grace period", provider.purged)
+	}
+	bin.purge()
+	if len(provider.purged) != 1 {
+		t.Errorf("purged %v, want the expired context purged once", provider.purged)
+	}
+
+	deletedAt := now.Add(-time.Hour)
+	kc := K8sContext{DeletedAt: &deletedAt}
+	if purgeAt := bin.PurgeAt(kc); purgeAt == nil || !purgeAt.Equal(deletedAt.Add(24*time.Hour)) {
+		t.Errorf("PurgeAt() = %v, want a day after the deletion", purgeAt)
+	}
+	if bin.Purgeable(kc) || bin.Purgeable(K8sContext{}) {
+		t.Error("Purgeable() = true for a context within its grace period or not deleted")
+	}
+	deletedAt = now.Add(-24 * time.Hour)
+	if !bin.Purgeable(kc) {
+		t.Error("Purgeable() = false for a context at the end of its grace period")
+	}
+}
+
+func TestSaveMesheryK8sContextRestoresDeleted(t *testing.T) {
+	db, err := database.New(database.Options{Engine: database.SQLITE, Filename: filepath.Join(t.TempDir(), "contexts.db")})
+	if err != nil {
+		t.Fatalf("failed to open the database: %s", err)
+	}
+	if err := db.AutoMigrate(&K8sContext{}); err != nil {
+		t.Fatalf("failed to migrate the database: %s", err)
+	}
+	persister := &MesheryK8sContextPersister{DB: &db}
+	if _, err := persister.SaveMesheryK8sContext(K8sContext{ID: "deleted", Name: "deleted"}); err != nil {
+		t.Fatalf("SaveMesheryK8sContext() failed with error: %s", err)
+	}
+	deletedAt := time.Now()
+	if _, err := persister.SetMesheryK8sContextDeletedAt("deleted", &deletedAt); err != nil {
+		t.Fatalf("SetMesheryK8sContextDeletedAt() failed with error: %s", err)
+	}
+
+	if _, err := persister.SaveMesheryK8sContext(K8sContext{ID: "deleted", Name: "deleted"}); err != ErrContextAlreadyPersisted {
+		t.Errorf("SaveMesheryK8sContext() of a deleted context = %v, want %v", err, ErrContextAlreadyPersisted)
+	}
+	if kc, err := persister.GetMesheryK8sContext("deleted"); err != nil || kc.DeletedAt != nil {
+		t.Errorf("GetMesheryK8sContext() = %+v, %v, want the context restored", kc, err)
+	}
+}
+
+func TestSaveMesheryK8sContextProxyCredentials(t *testing.T) {
+	db, err := database.New(database.Options{Engine: database.SQLITE, Filename: filepath.Join(t.TempDir(), "contexts.db")})
+	if err != nil {
+		t.Fatalf("failed to open the database: %s", err)
+	}
+	if err := db.AutoMigrate(&K8sContext{}); err != nil {
+		t.Fatalf("failed to migrate the database: %s", err)
+	}
+	persister := &MesheryK8sContextPersister{DB: &db}
+	auth := sql.Map{"name": "admin"}
+	kc := K8sContext{ID: "proxied", Name: "proxied", Auth: auth, ProxyURL: "http://proxy:3128", ProxyUsername: "user", ProxyPassword: "secret"}
+	if _, err := persister.SaveMesheryK8sContext(kc); err != nil {
+		t.Fatalf("SaveMesheryK8sContext() failed with error: %s", err)
+	}
+	if len(auth) != 1 {
+		t.Errorf("credentials of the saved context = %v, want them left untouched", auth)
+	}
+
+	if db.Migrator().HasColumn(&K8sContext{}, "proxy_password") {
+		t.Error("expected no column of its own for the proxy password")
+	}
+	got, err := persister.GetMesheryK8sContext("proxied")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.ProxyURL != kc.ProxyURL || got.ProxyUsername != "user" || got.ProxyPassword != "secret" {
+		t.Errorf("proxy = %s %s %s, want the proxy of the saved context", got.ProxyURL, got.ProxyUsername, got.ProxyPassword)
+	}
+	if _, ok := got.Auth[k8sContextProxyPasswordKey]; ok || got.Auth["name"] != "admin" {
+		t.Errorf("credentials = %v, want the credentials of the saved context", got.Auth)
+	}
+}
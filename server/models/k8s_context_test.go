@@ -0,0 +1,1441 @@
+package models
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gofrs/uuid"
+	"github.com/layer5io/meshery/server/internal/sql"
+	"github.com/layer5io/meshkit/utils/kubernetes"
+	"github.com/spf13/viper"
+	"gopkg.in/yaml.v2"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+	"k8s.io/client-go/tools/clientcmd"
+)
+
+// socks5Stub is a minimal SOCKS5 proxy supporting username/password authentication (RFC 1928, RFC 1929).
+// It records the credentials and the destination of the connections proxied through it.
+type socks5Stub struct {
+	listener net.Listener
+
+	mx           sync.Mutex
+	username     string
+	password     string
+	destinations []string
+}
+
+func newSocks5Stub(t *testing.T) *socks5Stub {
+	t.Helper()
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := &socks5Stub{listener: l}
+	go func() {
+		for {
+			conn, err := l.Accept()
+			if err != nil {
+				return
+			}
+			go s.handle(conn)
+		}
+	}()
+	t.Cleanup(func() {
+		_ = l.Close()
+	})
+	return s
+}
+
+func (s *socks5Stub) handle(conn net.Conn) {
+	defer conn.Close()
+
+	// greeting: VER NMETHODS METHODS...
+	header := make([]byte, 2)
+	if _, err := io.ReadFull(conn, header); err != nil {
+		return
+	}
+	methods := make([]byte, header[1])
+	if _, err := io.ReadFull(conn, methods); err != nil {
+		return
+	}
+	// select username/password authentication
+	if _, err := conn.Write([]byte{0x05, 0x02}); err != nil {
+		return
+	}
+
+	// auth: VER ULEN UNAME PLEN PASSWD
+	ver := make([]byte, 2)
+	if _, err := io.ReadFull(conn, ver); err != nil {
+		return
+	}
+	username := make([]byte, ver[1])
+	if _, err := io.ReadFull(conn, username); err != nil {
+		return
+	}
+	plen := make([]byte, 1)
+	if _, err := io.ReadFull(conn, plen); err != nil {
+		return
+	}
+	password := make([]byte, plen[0])
+	if _, err := io.ReadFull(conn, password); err != nil {
+		return
+	}
+	if _, err := conn.Write([]byte{0x01, 0x00}); err != nil {
+		return
+	}
+
+	// request: VER CMD RSV ATYP DST.ADDR DST.PORT
+	req := make([]byte, 4)
+	if _, err := io.ReadFull(conn, req); err != nil {
+		return
+	}
+	var host string
+	switch req[3] {
+	case 0x01:
+		addr := make([]byte, 4)
+		if _, err := io.ReadFull(conn, addr); err != nil {
+			return
+		}
+		host = net.IP(addr).String()
+	case 0x03:
+		l := make([]byte, 1)
+		if _, err := io.ReadFull(conn, l); err != nil {
+			return
+		}
+		addr := make([]byte, l[0])
+		if _, err := io.ReadFull(conn, addr); err != nil {
+			return
+		}
+		host = string(addr)
+	default:
+		return
+	}
+	portBytes := make([]byte, 2)
+	if _, err := io.ReadFull(conn, portBytes); err != nil {
+		return
+	}
+	destination := net.JoinHostPort(host, strconv.Itoa(int(binary.BigEndian.Uint16(portBytes))))
+
+	s.mx.Lock()
+	s.username, s.password = string(username), string(password)
+	s.destinations = append(s.destinations, destination)
+	s.mx.Unlock()
+
+	upstream, err := net.Dial("tcp", destination)
+	if err != nil {
+		_, _ = conn.Write([]byte{0x05, 0x01, 0x00, 0x01, 0, 0, 0, 0, 0, 0})
+		return
+	}
+	defer upstream.Close()
+	if _, err := conn.Write([]byte{0x05, 0x00, 0x00, 0x01, 0, 0, 0, 0, 0, 0}); err != nil {
+		return
+	}
+	go func() {
+		_, _ = io.Copy(upstream, conn)
+	}()
+	_, _ = io.Copy(conn, upstream)
+}
+
+func TestGenerateKubeHandlerSocks5Proxy(t *testing.T) {
+	apiServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(map[string]string{"major": "1", "minor": "28", "gitVersion": "v1.28.3"})
+	}))
+	defer apiServer.Close()
+
+	proxy := newSocks5Stub(t)
+
+	instanceID := uuid.Must(uuid.NewV4())
+	kc, _ := NewK8sContext(
+		"test",
+		map[string]interface{}{
+			"name":    "test",
+			"cluster": map[string]interface{}{"server": apiServer.URL},
+		},
+		map[string]interface{}{
+			"name": "test",
+			"user": map[string]interface{}{"token": "abc"},
+		},
+		apiServer.URL,
+		&instanceID,
+	)
+	kc.ProxyURL = "socks5://" + proxy.listener.Addr().String()
+	kc.ProxyUsername = "meshery"
+	kc.ProxyPassword = "secret"
+
+	handler, err := kc.GenerateKubeHandler()
+	if err != nil {
+		t.Fatalf("GenerateKubeHandler() failed with error: %s", err)
+	}
+	version, err := handler.KubeClient.DiscoveryClient.ServerVersion()
+	if err != nil {
+		t.Fatalf("ServerVersion() failed with error: %s", err)
+	}
+	if version.GitVersion != "v1.28.3" {
+		t.Errorf("ServerVersion() = %s, want v1.28.3", version.GitVersion)
+	}
+
+	proxy.mx.Lock()
+	defer proxy.mx.Unlock()
+	if len(proxy.destinations) == 0 || proxy.destinations[0] != apiServer.Listener.Addr().String() {
+		t.Errorf("expected the traffic to %s to be routed through the proxy, proxied destinations: %v", apiServer.Listener.Addr(), proxy.destinations)
+	}
+	if proxy.username != "meshery" || proxy.password != "secret" {
+		t.Errorf("proxy credentials = %s:%s, want meshery:secret", proxy.username, proxy.password)
+	}
+}
+
+func TestGenerateKubeHandlerInvalidProxy(t *testing.T) {
+	instanceID := uuid.Must(uuid.NewV4())
+	kc, _ := NewK8sContext(
+		"test",
+		map[string]interface{}{
+			"name":    "test",
+			"cluster": map[string]interface{}{"server": "https://127.0.0.1:6443"},
+		},
+		map[string]interface{}{
+			"name": "test",
+			"user": map[string]interface{}{"token": "abc"},
+		},
+		"https://127.0.0.1:6443",
+		&instanceID,
+	)
+	kc.ProxyURL = "ftp://127.0.0.1:21"
+	if _, err := kc.GenerateKubeHandler(); err == nil {
+		t.Error("GenerateKubeHandler() expected an error for an unsupported proxy scheme")
+	}
+}
+
+func TestGenerateKubeHandlerTransportTimeouts(t *testing.T) {
+	apiServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(200 * time.Millisecond)
+		_ = json.NewEncoder(w).Encode(map[string]string{"major": "1", "minor": "28", "gitVersion": "v1.28.3"})
+	}))
+	defer apiServer.Close()
+
+	instanceID := uuid.Must(uuid.NewV4())
+	kc, _ := NewK8sContext(
+		"test",
+		map[string]interface{}{
+			"name":    "test",
+			"cluster": map[string]interface{}{"server": apiServer.URL},
+		},
+		map[string]interface{}{
+			"name": "test",
+			"user": map[string]interface{}{"token": "abc"},
+		},
+		apiServer.URL,
+		&instanceID,
+	)
+
+	handler, err := kc.GenerateKubeHandler()
+	if err != nil {
+		t.Fatalf("GenerateKubeHandler() failed with error: %s", err)
+	}
+	_, timings, err := kc.ServerVersionWithTimings(context.Background(), handler, 5*time.Second)
+	if err != nil {
+		t.Fatalf("ServerVersionWithTimings() failed with error: %s", err)
+	}
+	if timings.ConnectMs <= 0 || timings.FirstByteMs < 200 || timings.TotalMs < timings.FirstByteMs {
+		t.Errorf("unexpected timings %+v", *timings)
+	}
+
+	kc.ResponseHeaderTimeout = "20ms"
+	handler, err = kc.GenerateKubeHandler()
+	if err != nil {
+		t.Fatalf("GenerateKubeHandler() failed with error: %s", err)
+	}
+	if _, _, err := kc.ServerVersionWithTimings(context.Background(), handler, 5*time.Second); err == nil {
+		t.Error("ServerVersionWithTimings() expected the response header timeout to expire")
+	}
+
+	kc.ResponseHeaderTimeout = "soon"
+	if _, err := kc.GenerateKubeHandler(); err == nil {
+		t.Error("GenerateKubeHandler() expected an error for an invalid response header timeout")
+	}
+}
+
+func TestOverrideKubeConfigServer(t *testing.T) {
+	kubeconfig := []byte(`apiVersion: v1
+kind: Config
+clusters:
+- name: prod
+  cluster:
+    server: https://prod.example.com:6443
+    certificate-authority-data: Y2E=
+- name: staging
+  cluster:
+    server: https://staging.example.com:6443
+contexts:
+- name: prod
+  context:
+    cluster: prod
+    user: admin
+- name: staging
+  context:
+    cluster: staging
+    user: admin
+users:
+- name: admin
+  user:
+    token: abc
+current-context: prod
+`)
+
+	overridden, originalServers, err := OverrideKubeConfigServer(kubeconfig, "", "https://10.0.0.10:6443")
+	if err != nil {
+		t.Fatalf("OverrideKubeConfigServer() failed with error: %s", err)
+	}
+	if len(originalServers) != 1 || originalServers["prod"] != "https://prod.example.com:6443" {
+		t.Errorf("original servers = %v, want the one of the current-context only", originalServers)
+	}
+
+	cfg, err := clientcmd.Load(overridden)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cluster := cfg.Clusters["prod"]
+	if cluster.Server != "https://10.0.0.10:6443" {
+		t.Errorf("server = %s, want https://10.0.0.10:6443", cluster.Server)
+	}
+	if cluster.TLSServerName != "prod.example.com" {
+		t.Errorf("tls-server-name = %s, want prod.example.com", cluster.TLSServerName)
+	}
+	if string(cluster.CertificateAuthorityData) != "ca" || cfg.AuthInfos["admin"].Token != "abc" {
+		t.Error("expected the CA and credentials of the kubeconfig to be kept")
+	}
+	if cfg.Clusters["staging"].Server != "https://staging.example.com:6443" {
+		t.Errorf("server of the other cluster = %s, want it left as is", cfg.Clusters["staging"].Server)
+	}
+
+	if _, originalServers, err := OverrideKubeConfigServer(kubeconfig, "staging", "https://10.0.0.20:6443"); err != nil || originalServers["staging"] != "https://staging.example.com:6443" {
+		t.Errorf("OverrideKubeConfigServer() of the staging context = %v, %v, want its cluster overridden", originalServers, err)
+	}
+	if _, _, err := OverrideKubeConfigServer(kubeconfig, "dev", "https://10.0.0.20:6443"); err == nil {
+		t.Error("OverrideKubeConfigServer() expected an error for a context not in the kubeconfig")
+	}
+
+	if _, _, err := OverrideKubeConfigServer(kubeconfig, "", "10.0.0.10:6443"); err == nil {
+		t.Error("OverrideKubeConfigServer() expected an error for a server URL without scheme")
+	}
+}
+
+func TestDisambiguateK8sContextNames(t *testing.T) {
+	kubeconfig := []byte(`apiVersion: v1
+kind: Config
+clusters:
+- name: dev-a
+  cluster:
+    server: https://dev-a.example.com:6443
+- name: dev-b
+  cluster:
+    server: https://dev-b.example.com:6443
+contexts:
+- name: dev
+  context:
+    cluster: dev-a
+    user: admin
+- name: dev-2
+  context:
+    cluster: dev-a
+    user: admin
+- name: dev
+  context:
+    cluster: dev-b
+    user: admin
+users:
+- name: admin
+  user:
+    token: abc
+current-context: dev
+`)
+	if _, err := clientcmd.Load(kubeconfig); err == nil {
+		t.Fatal("expected the kubeconfig with duplicate context names to fail to load as is")
+	}
+
+	disambiguated, renamed, err := DisambiguateK8sContextNames(kubeconfig)
+	if err != nil {
+		t.Fatalf("DisambiguateK8sContextNames() failed with error: %s", err)
+	}
+	if len(renamed) != 1 || renamed["dev-3"] != "dev" {
+		t.Errorf("renamed = %v, want map[dev-3:dev]", renamed)
+	}
+
+	cfg, err := clientcmd.Load(disambiguated)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(cfg.Contexts) != 3 || cfg.Contexts["dev"].Cluster != "dev-a" || cfg.Contexts["dev-3"].Cluster != "dev-b" {
+		t.Errorf("unexpected contexts after renaming: %v", cfg.Contexts)
+	}
+	if cfg.CurrentContext != "dev" {
+		t.Errorf("current-context = %s, want dev", cfg.CurrentContext)
+	}
+
+	unique, renamed, err := DisambiguateK8sContextNames(disambiguated)
+	if err != nil || len(renamed) != 0 || string(unique) != string(disambiguated) {
+		t.Error("expected a kubeconfig with unique context names to be returned as is")
+	}
+}
+
+func TestMergeKubeconfigContextsByCluster(t *testing.T) {
+	kubeconfig := []byte(`apiVersion: v1
+kind: Config
+clusters:
+- name: prod
+  cluster:
+    server: https://prod.example.com:6443
+- name: prod-alias
+  cluster:
+    server: https://prod.example.com:6443
+- name: dev
+  cluster:
+    server: https://dev.example.com:6443
+contexts:
+- name: prod-payments
+  context:
+    cluster: prod
+    user: admin
+    namespace: payments
+- name: prod-orders
+  context:
+    cluster: prod-alias
+    user: admin
+    namespace: orders
+- name: prod-default
+  context:
+    cluster: prod
+    user: admin
+- name: prod-viewer
+  context:
+    cluster: prod
+    user: viewer
+    namespace: payments
+- name: dev
+  context:
+    cluster: dev
+    user: admin
+users:
+- name: admin
+  user:
+    token: abc
+- name: viewer
+  user:
+    token: def
+`)
+	merged, merges, err := MergeKubeconfigContextsByCluster(kubeconfig)
+	if err != nil {
+		t.Fatalf("MergeKubeconfigContextsByCluster() failed with error: %s", err)
+	}
+	cfg, err := clientcmd.Load(merged)
+	if err != nil {
+		t.Fatal(err)
+	}
+	names := []string{}
+	for name := range cfg.Contexts {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	if want := []string{"dev", "prod-default", "prod-viewer"}; !reflect.DeepEqual(names, want) {
+		t.Errorf("contexts of the merged kubeconfig = %v, want %v", names, want)
+	}
+	if len(merges) != 1 {
+		t.Fatalf("merges = %+v, want the contexts of prod as admin merged", merges)
+	}
+	want := K8sContextMerge{Context: "prod-default", Server: "https://prod.example.com:6443", MergedContexts: []string{"prod-orders", "prod-payments"}, Namespaces: []string{"default", "orders", "payments"}}
+	if !reflect.DeepEqual(merges[0], want) {
+		t.Errorf("merge = %+v, want %+v", merges[0], want)
+	}
+
+	unique := []byte(`apiVersion: v1
+kind: Config
+clusters:
+- name: dev
+  cluster:
+    server: https://dev.example.com:6443
+contexts:
+- name: dev
+  context:
+    cluster: dev
+    user: admin
+users:
+- name: admin
+  user:
+    token: abc
+`)
+	if same, merges, err := MergeKubeconfigContextsByCluster(unique); err != nil || len(merges) != 0 || string(same) != string(unique) {
+		t.Error("expected a kubeconfig without contexts to merge to be returned as is")
+	}
+}
+
+func TestCheckExecCredentialBinary(t *testing.T) {
+	dir := t.TempDir()
+	plugin := filepath.Join(dir, "fake-auth-plugin")
+	if err := os.WriteFile(plugin, []byte("#!/bin/sh\n"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PATH", dir)
+
+	execContext := func(command string) K8sContext {
+		return K8sContext{Name: "eks", Auth: sql.Map{
+			"name": "eks-user",
+			"user": map[string]interface{}{
+				"exec": map[string]interface{}{"apiVersion": "client.authentication.k8s.io/v1beta1", "command": command},
+			},
+		}}
+	}
+
+	if err := (K8sContext{Name: "static", Auth: sql.Map{"user": map[string]interface{}{"token": "abc"}}}).CheckExecCredentialBinary(); err != nil {
+		t.Errorf("expected no error for a context without exec plugin, got %s", err)
+	}
+	if err := execContext("fake-auth-plugin").CheckExecCredentialBinary(); err != nil {
+		t.Errorf("expected no error for a plugin on the PATH, got %s", err)
+	}
+	if err := execContext(plugin).CheckExecCredentialBinary(); err != nil {
+		t.Errorf("expected no error for a plugin referenced by path, got %s", err)
+	}
+
+	err := execContext("aws-iam-authenticator").CheckExecCredentialBinary()
+	if err == nil {
+		t.Fatal("expected an error for a plugin missing from the PATH")
+	}
+	if !strings.Contains(err.Error(), "exec credential binary 'aws-iam-authenticator' not found on server") {
+		t.Errorf("unexpected error: %s", err)
+	}
+}
+
+func TestRedactKubeConfig(t *testing.T) {
+	kubeconfig := []byte(`apiVersion: v1
+kind: Config
+clusters:
+- name: prod
+  cluster:
+    server: https://prod.example.com:6443
+contexts:
+- name: prod
+  context:
+    cluster: prod
+    user: admin
+- name: eks
+  context:
+    cluster: prod
+    user: eks
+users:
+- name: admin
+  user:
+    token: secret-token
+    client-key-data: c2VjcmV0LWtleQ==
+- name: eks
+  user:
+    exec:
+      apiVersion: client.authentication.k8s.io/v1beta1
+      command: aws
+      env:
+      - name: AWS_SECRET_ACCESS_KEY
+        value: secret-access-key
+current-context: prod
+`)
+
+	redacted, err := RedactKubeConfig(kubeconfig)
+	if err != nil {
+		t.Fatalf("RedactKubeConfig() failed with error: %s", err)
+	}
+	for _, secret := range []string{"secret-token", "c2VjcmV0LWtleQ==", "secret-access-key"} {
+		if strings.Contains(string(redacted), secret) {
+			t.Errorf("expected %s to be redacted", secret)
+		}
+	}
+	cfg, err := clientcmd.Load(redacted)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Clusters["prod"].Server != "https://prod.example.com:6443" || cfg.AuthInfos["eks"].Exec.Command != "aws" {
+		t.Error("expected the kubeconfig to be kept apart from the credentials")
+	}
+}
+
+func TestK8sContextCompression(t *testing.T) {
+	kc := K8sContext{
+		Name:    "prod",
+		Cluster: map[string]interface{}{"name": "prod", "cluster": map[string]interface{}{"server": "https://prod.example.com:6443", "certificate-authority-data": "Y2E="}},
+		Auth:    map[string]interface{}{"name": "admin", "user": map[string]interface{}{"token": "abc"}},
+		Server:  "https://prod.example.com:6443",
+	}
+
+	compressed, err := kc.Compress()
+	if err != nil {
+		t.Fatalf("Compress() failed with error: %s", err)
+	}
+	if !compressed.IsCompressed() || kc.IsCompressed() {
+		t.Fatal("expected only the returned context to be compressed")
+	}
+	if _, ok := compressed.Cluster["cluster"]; ok {
+		t.Error("expected the cluster to be stored compressed")
+	}
+	if compressed.Cluster["name"] != "prod" || compressed.Auth["name"] != "admin" {
+		t.Error("expected the names to be kept in clear")
+	}
+
+	want, err := kc.GenerateKubeConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := compressed.GenerateKubeConfig()
+	if err != nil {
+		t.Fatalf("GenerateKubeConfig() of the compressed context failed with error: %s", err)
+	}
+	if string(got) != string(want) {
+		t.Errorf("kubeconfig of the compressed context = %s, want %s", got, want)
+	}
+
+	compressed.Auth["gzip"] = "not gzip"
+	if _, err := compressed.Decompress(); err == nil {
+		t.Error("Decompress() expected an error for corrupted data")
+	}
+}
+
+func TestResolvePrimaryK8sContext(t *testing.T) {
+	inCluster := &K8sContext{ID: "1", Name: "in-cluster", DeploymentType: K8sContextDeploymentInCluster}
+	alpha := &K8sContext{ID: "2", Name: "alpha", DeploymentType: "out_of_cluster"}
+	beta := &K8sContext{ID: "3", Name: "beta", DeploymentType: "out_of_cluster"}
+	contexts := []*K8sContext{beta, inCluster, alpha}
+
+	unreachable := map[string]bool{}
+	reachable := func(ctx *K8sContext) error {
+		if unreachable[ctx.Name] {
+			return errors.New("unreachable")
+		}
+		return nil
+	}
+
+	tests := []struct {
+		name        string
+		override    string
+		fallback    string
+		unreachable []string
+		want        string
+		reason      string
+	}{
+		{name: "in cluster preferred", fallback: "beta", want: "in-cluster", reason: PrimaryK8sContextInCluster},
+		{name: "override wins", override: "3", want: "beta", reason: PrimaryK8sContextOverride},
+		{name: "fallback when in cluster unreachable", fallback: "beta", unreachable: []string{"in-cluster"}, want: "beta", reason: PrimaryK8sContextFallback},
+		{name: "first reachable", fallback: "beta", unreachable: []string{"in-cluster", "beta"}, want: "alpha", reason: PrimaryK8sContextFirstReachable},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			unreachable = map[string]bool{}
+			for _, name := range tt.unreachable {
+				unreachable[name] = true
+			}
+			primary, err := ResolvePrimaryK8sContext(contexts, tt.override, tt.fallback, reachable)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if primary.Context.Name != tt.want || primary.Reason != tt.reason {
+				t.Errorf("got %s (%s), want %s (%s)", primary.Context.Name, primary.Reason, tt.want, tt.reason)
+			}
+		})
+	}
+
+	unreachable = map[string]bool{"in-cluster": true, "alpha": true, "beta": true}
+	if _, err := ResolvePrimaryK8sContext(contexts, "", "", reachable); err == nil {
+		t.Error("expected an error when no context is reachable")
+	}
+}
+
+func TestCheckTokenFile(t *testing.T) {
+	tokenFile := filepath.Join(t.TempDir(), "token")
+	if err := os.WriteFile(tokenFile, []byte("abc"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	tokenFileContext := func(path string) K8sContext {
+		return K8sContext{Name: "sa", Auth: sql.Map{"user": map[string]interface{}{"tokenFile": path}}}
+	}
+
+	if err := (K8sContext{Name: "static", Auth: sql.Map{"user": map[string]interface{}{"token": "abc"}}}).CheckTokenFile(); err != nil {
+		t.Errorf("expected no error for a context with an inline token, got %s", err)
+	}
+	if err := tokenFileContext(tokenFile).CheckTokenFile(); err != nil {
+		t.Errorf("expected no error for a readable token file, got %s", err)
+	}
+	err := tokenFileContext("/home/dev/.kube/token").CheckTokenFile()
+	if err == nil || !strings.Contains(err.Error(), "token file '/home/dev/.kube/token' not readable on server") {
+		t.Errorf("unexpected error for a missing token file: %v", err)
+	}
+}
+
+func TestK8sComponentsHostFor(t *testing.T) {
+	if host := K8sComponentsHostFor("", "", "ctx"); host != K8sComponentsHost("ctx") {
+		t.Errorf("K8sComponentsHostFor() = %+v, want the default host %+v", host, K8sComponentsHost("ctx"))
+	}
+	if host := K8sComponentsHostFor("registry.example.com", "", "ctx"); host.Hostname != "registry.example.com" || host.Metadata != "ctx" {
+		t.Errorf("K8sComponentsHostFor() = %+v, want registry.example.com with the context as metadata", host)
+	}
+
+	for _, hostname := range []string{"kubernetes", "registry.example.com"} {
+		if err := ValidateRegistryHostname(hostname); err != nil {
+			t.Errorf("ValidateRegistryHostname(%q) failed with error: %s", hostname, err)
+		}
+	}
+	for _, hostname := range []string{"Kubernetes", "registry/example", "-registry"} {
+		if err := ValidateRegistryHostname(hostname); err == nil {
+			t.Errorf("ValidateRegistryHostname(%q) expected an error", hostname)
+		}
+	}
+}
+
+func TestK8sContextExpiry(t *testing.T) {
+	ttl, err := ParseK8sContextTTL("2h")
+	if err != nil || ttl != 2*time.Hour {
+		t.Fatalf("ParseK8sContextTTL(\"2h\") = %s, %v, want 2h", ttl, err)
+	}
+	for _, ttl := range []string{"2", "0s", "-1h"} {
+		if _, err := ParseK8sContextTTL(ttl); err == nil {
+			t.Errorf("ParseK8sContextTTL(%q) expected an error", ttl)
+		}
+	}
+
+	now := time.Now()
+	expiresAt := now.Add(ttl)
+	kc := K8sContext{ExpiresAt: &expiresAt}
+	if kc.Expired(now) || !kc.Expired(expiresAt) {
+		t.Errorf("Expired() expected the context to expire at %s only", expiresAt)
+	}
+	if (&K8sContext{}).Expired(now) {
+		t.Error("Expired() expected a context without an expiry to never expire")
+	}
+}
+
+func TestGenerateKubeHandlerClientRateLimits(t *testing.T) {
+	instanceID := uuid.Must(uuid.NewV4())
+	kc, _ := NewK8sContext(
+		"test",
+		map[string]interface{}{
+			"name":    "test",
+			"cluster": map[string]interface{}{"server": "https://127.0.0.1:6443"},
+		},
+		map[string]interface{}{
+			"name": "test",
+			"user": map[string]interface{}{"token": "abc"},
+		},
+		"https://127.0.0.1:6443",
+		&instanceID,
+	)
+
+	handler, err := kc.GenerateKubeHandler()
+	if err != nil {
+		t.Fatalf("GenerateKubeHandler() failed with error: %s", err)
+	}
+	if handler.RestConfig.QPS != DefaultK8sClientQPS || handler.RestConfig.Burst != DefaultK8sClientBurst {
+		t.Errorf("GenerateKubeHandler() rate limits = %v/%d, want the defaults", handler.RestConfig.QPS, handler.RestConfig.Burst)
+	}
+
+	viper.Set("KUBERNETES_CLIENT_QPS", 20)
+	defer viper.Set("KUBERNETES_CLIENT_QPS", nil)
+	kc.Burst = 30
+	handler, err = kc.GenerateKubeHandler()
+	if err != nil {
+		t.Fatalf("GenerateKubeHandler() failed with error: %s", err)
+	}
+	if handler.RestConfig.QPS != 20 || handler.RestConfig.Burst != 30 {
+		t.Errorf("GenerateKubeHandler() rate limits = %v/%d, want 20/30", handler.RestConfig.QPS, handler.RestConfig.Burst)
+	}
+
+	kc.QPS = -1
+	if _, err := kc.GenerateKubeHandler(); err == nil {
+		t.Error("GenerateKubeHandler() expected an error for a negative qps")
+	}
+	if _, _, err := ParseK8sClientRateLimits("fast", ""); err == nil {
+		t.Error("ParseK8sClientRateLimits() expected an error for an invalid qps")
+	}
+}
+
+func TestGenerateKubeHandlerImpersonation(t *testing.T) {
+	var mx sync.Mutex
+	var gotUser string
+	var gotGroups []string
+	apiServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mx.Lock()
+		gotUser, gotGroups = r.Header.Get("Impersonate-User"), r.Header.Values("Impersonate-Group")
+		mx.Unlock()
+		_ = json.NewEncoder(w).Encode(map[string]string{"major": "1", "minor": "28", "gitVersion": "v1.28.3"})
+	}))
+	defer apiServer.Close()
+
+	instanceID := uuid.Must(uuid.NewV4())
+	kc, _ := NewK8sContext(
+		"test",
+		map[string]interface{}{
+			"name":    "test",
+			"cluster": map[string]interface{}{"server": apiServer.URL},
+		},
+		map[string]interface{}{
+			"name": "test",
+			"user": map[string]interface{}{"token": "abc", "as": "system:serviceaccount:meshery:reader", "as-groups": []interface{}{"readers"}},
+		},
+		apiServer.URL,
+		&instanceID,
+	)
+	serverVersionAs := func(wantUser string, wantGroups []string) {
+		t.Helper()
+		handler, err := kc.GenerateKubeHandler()
+		if err != nil {
+			t.Fatalf("GenerateKubeHandler() failed with error: %s", err)
+		}
+		if _, err := handler.KubeClient.DiscoveryClient.ServerVersion(); err != nil {
+			t.Fatalf("ServerVersion() failed with error: %s", err)
+		}
+		mx.Lock()
+		defer mx.Unlock()
+		if gotUser != wantUser || !reflect.DeepEqual(gotGroups, wantGroups) {
+			t.Errorf("impersonated %q %v, want %q %v", gotUser, gotGroups, wantUser, wantGroups)
+		}
+	}
+
+	serverVersionAs("system:serviceaccount:meshery:reader", []string{"readers"})
+	kc.SetImpersonation("system:serviceaccount:meshery:operator", []string{"operators", "readers"})
+	serverVersionAs("system:serviceaccount:meshery:operator", []string{"operators", "readers"})
+	kc.SetImpersonation("", nil)
+	serverVersionAs("", nil)
+}
+
+func TestDetectK8sCapabilities(t *testing.T) {
+	responses := map[string]string{
+		"/apis":                                     `{"kind":"APIGroupList","groups":[{"name":"networking.istio.io","versions":[{"groupVersion":"networking.istio.io/v1beta1","version":"v1beta1"}]}]}`,
+		"/apis/networking.k8s.io/v1":                `{"kind":"APIResourceList","groupVersion":"networking.k8s.io/v1","resources":[{"name":"networkpolicies","kind":"NetworkPolicy","namespaced":true,"verbs":["list"]},{"name":"ingressclasses","kind":"IngressClass","namespaced":false,"verbs":["list"]}]}`,
+		"/apis/storage.k8s.io/v1/storageclasses":    `{"items":[{"metadata":{"name":"standard","annotations":{"storageclass.kubernetes.io/is-default-class":"true"}}}]}`,
+		"/apis/networking.k8s.io/v1/ingressclasses": `{"items":[]}`,
+	}
+	apiServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, ok := responses[r.URL.Path]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(body))
+	}))
+	defer apiServer.Close()
+
+	instanceID := uuid.Must(uuid.NewV4())
+	kc, _ := NewK8sContext(
+		"test",
+		map[string]interface{}{
+			"name":    "test",
+			"cluster": map[string]interface{}{"server": apiServer.URL},
+		},
+		map[string]interface{}{
+			"name": "test",
+			"user": map[string]interface{}{"token": "abc"},
+		},
+		apiServer.URL,
+		&instanceID,
+	)
+	handler, err := kc.GenerateKubeHandler()
+	if err != nil {
+		t.Fatalf("GenerateKubeHandler() failed with error: %s", err)
+	}
+
+	capabilities := DetectK8sCapabilities(context.Background(), handler)
+	want := map[string]bool{
+		K8sCapabilityNetworkPolicies:     true,
+		K8sCapabilityDefaultStorageClass: true,
+		K8sCapabilityIngressController:   false,
+		K8sCapabilityServiceMesh:         true,
+	}
+	if len(capabilities) != len(want) {
+		t.Fatalf("DetectK8sCapabilities() = %v, want %v", capabilities, want)
+	}
+	for capability, v := range want {
+		if capabilities[capability] != v {
+			t.Errorf("DetectK8sCapabilities()[%s] = %v, want %v", capability, capabilities[capability], v)
+		}
+	}
+}
+
+func TestValidateK8sCredentials(t *testing.T) {
+	newKubeClient := func(t *testing.T, server string) *kubernetes.Client {
+		t.Helper()
+		instanceID := uuid.Must(uuid.NewV4())
+		kc, _ := NewK8sContext(
+			"test",
+			map[string]interface{}{
+				"name":    "test",
+				"cluster": map[string]interface{}{"server": server},
+			},
+			map[string]interface{}{
+				"name": "test",
+				"user": map[string]interface{}{"token": "abc"},
+			},
+			server,
+			&instanceID,
+		)
+		handler, err := kc.GenerateKubeHandler()
+		if err != nil {
+			t.Fatalf("GenerateKubeHandler() failed with error: %s", err)
+		}
+		return handler
+	}
+	status := func(w http.ResponseWriter, code int, reason string) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(code)
+		_, _ = fmt.Fprintf(w, `{"kind":"Status","apiVersion":"v1","status":"Failure","reason":%q,"code":%d}`, reason, code)
+	}
+
+	tests := []struct {
+		name       string
+		handler    http.HandlerFunc
+		wantStatus string
+		wantUser   string
+	}{
+		{
+			name: "valid",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "application/json")
+				_, _ = w.Write([]byte(`{"kind":"SelfSubjectReview","apiVersion":"authentication.k8s.io/v1","status":{"userInfo":{"username":"alice","groups":["system:authenticated"]}}}`))
+			},
+			wantStatus: K8sCredentialsValid,
+			wantUser:   "alice",
+		},
+		{
+			name: "invalid",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				status(w, http.StatusUnauthorized, "Unauthorized")
+			},
+			wantStatus: K8sCredentialsInvalid,
+		},
+		{
+			name: "authenticated on a cluster without SelfSubjectReviews",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				if strings.HasPrefix(r.URL.Path, "/apis/authentication.k8s.io/") {
+					status(w, http.StatusNotFound, "NotFound")
+					return
+				}
+				status(w, http.StatusForbidden, "Forbidden")
+			},
+			wantStatus: K8sCredentialsValid,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			apiServer := httptest.NewServer(tt.handler)
+			defer apiServer.Close()
+			got := ValidateK8sCredentials(context.Background(), newKubeClient(t, apiServer.URL))
+			if got.Status != tt.wantStatus || got.Valid != (tt.wantStatus == K8sCredentialsValid) || got.Username != tt.wantUser {
+				t.Errorf("ValidateK8sCredentials() = %+v, want status %s and user %q", got, tt.wantStatus, tt.wantUser)
+			}
+		})
+	}
+
+	apiServer := httptest.NewServer(http.NotFoundHandler())
+	kubeclient := newKubeClient(t, apiServer.URL)
+	apiServer.Close()
+	if got := ValidateK8sCredentials(context.Background(), kubeclient); got.Status != K8sCredentialsUnreachable || got.Valid {
+		t.Errorf("ValidateK8sCredentials() = %+v, want status %s", got, K8sCredentialsUnreachable)
+	}
+}
+
+func TestK8sContextApplyNameTemplate(t *testing.T) {
+	if _, err := ParseK8sContextNameTemplate("{{.Regoin}}"); err == nil {
+		t.Error("ParseK8sContextNameTemplate() expected an error for an unknown field")
+	}
+	tmpl, err := ParseK8sContextNameTemplate("{{.Distribution}}-{{.Region}}-{{.ShortServerID}}")
+	if err != nil {
+		t.Fatalf("ParseK8sContextNameTemplate() failed with error: %s", err)
+	}
+
+	serverID := uuid.Must(uuid.FromString("0a1b2c3d-4e5f-6789-abcd-ef0123456789"))
+	tests := []struct {
+		name string
+		ctx  K8sContext
+		want string
+	}{
+		{
+			name: "eks",
+			ctx:  K8sContext{Name: "arn:aws:eks:us-east-1:123456789012:cluster/payments", Server: "https://ABCDEF0123456789.gr7.us-east-1.eks.amazonaws.com", KubernetesServerID: &serverID},
+			want: "eks-us-east-1-0a1b2c3d",
+		},
+		{
+			name: "gke",
+			ctx:  K8sContext{Name: "gke_my-project_us-central1-a_payments", Server: "https://34.1.2.3", KubernetesServerID: &serverID},
+			want: "gke-us-central1-a-0a1b2c3d",
+		},
+		{
+			name: "aks",
+			ctx:  K8sContext{Name: "payments-admin", Server: "https://payments-dns-0a1b2c3d.hcp.eastus.azmk8s.io:443", KubernetesServerID: &serverID},
+			want: "aks-eastus-0a1b2c3d",
+		},
+		{
+			// kind clusters have no region, the context keeps its name
+			name: "missing metadata",
+			ctx:  K8sContext{Name: "kind-dev", Server: "https://127.0.0.1:6443", KubernetesServerID: &serverID},
+			want: "kind-dev",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			original := tt.ctx.Name
+			renamed := tt.ctx.ApplyNameTemplate(tmpl)
+			if tt.ctx.Name != tt.want {
+				t.Errorf("Name = %s, want %s", tt.ctx.Name, tt.want)
+			}
+			if renamed != (tt.want != original) {
+				t.Errorf("ApplyNameTemplate() = %t, want %t", renamed, tt.want != original)
+			}
+			if renamed && tt.ctx.OriginalName != original {
+				t.Errorf("OriginalName = %s, want %s", tt.ctx.OriginalName, original)
+			}
+		})
+	}
+}
+
+func TestGenerateKubeHandlerTLSServerName(t *testing.T) {
+	// The certificate of the API server is issued for a name other than the address it is dialed at, as behind a proxy
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "api.cluster.internal"},
+		DNSNames:              []string{"api.cluster.internal"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	apiServer := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(map[string]string{"major": "1", "minor": "28", "gitVersion": "v1.28.3"})
+	}))
+	apiServer.TLS = &tls.Config{Certificates: []tls.Certificate{{Certificate: [][]byte{der}, PrivateKey: key}}}
+	apiServer.StartTLS()
+	defer apiServer.Close()
+
+	caData := base64.StdEncoding.EncodeToString(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}))
+	instanceID := uuid.Must(uuid.NewV4())
+	kc, _ := NewK8sContext(
+		"test",
+		map[string]interface{}{
+			"name":    "test",
+			"cluster": map[string]interface{}{"server": apiServer.URL, "certificate-authority-data": caData},
+		},
+		map[string]interface{}{
+			"name": "test",
+			"user": map[string]interface{}{"token": "abc"},
+		},
+		apiServer.URL,
+		&instanceID,
+	)
+	serverVersion := func() error {
+		t.Helper()
+		handler, err := kc.GenerateKubeHandler()
+		if err != nil {
+			t.Fatalf("GenerateKubeHandler() failed with error: %s", err)
+		}
+		_, err = handler.KubeClient.DiscoveryClient.ServerVersion()
+		return err
+	}
+
+	if err := serverVersion(); err == nil {
+		t.Error("ServerVersion() expected the certificate not to be valid for the address of the server")
+	}
+	kc.TLSServerName = "api.cluster.internal"
+	if err := serverVersion(); err != nil {
+		t.Errorf("ServerVersion() failed with error: %s", err)
+	}
+}
+
+func TestReadKubeconfigSecret(t *testing.T) {
+	for _, ref := range []string{"meshery", "meshery//config", "meshery/clusters/config/extra"} {
+		if _, err := ParseKubeconfigSecretRef(ref); err == nil {
+			t.Errorf("ParseKubeconfigSecretRef(%q) expected an error", ref)
+		}
+	}
+	ref, err := ParseKubeconfigSecretRef("meshery/clusters")
+	if err != nil {
+		t.Fatalf("ParseKubeconfigSecretRef() failed with error: %s", err)
+	}
+	if ref.Key != DefaultKubeconfigSecretKey {
+		t.Errorf("Key = %s, want the default key %s", ref.Key, DefaultKubeconfigSecretKey)
+	}
+
+	kubeconfig := []byte(`apiVersion: v1
+kind: Config
+clusters:
+- name: prod
+  cluster:
+    server: https://prod.example.com:6443
+users:
+- name: admin
+  user:
+    token: abc
+contexts:
+- name: prod
+  context:
+    cluster: prod
+    user: admin
+current-context: prod
+`)
+	client := fake.NewSimpleClientset(&corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "meshery", Name: "clusters"},
+		Data:       map[string][]byte{"config": kubeconfig},
+	})
+	data, err := ReadKubeconfigSecret(context.Background(), client, ref)
+	if err != nil {
+		t.Fatalf("ReadKubeconfigSecret() failed with error: %s", err)
+	}
+	// The kubeconfig goes through the discovery of the contexts as any other
+	instanceID := uuid.Must(uuid.NewV4())
+	kcfg := InternalKubeConfig{}
+	if err := yaml.Unmarshal(data, &kcfg); err != nil {
+		t.Fatal(err)
+	}
+	if kc, _ := kcfg.K8sContext("prod", &instanceID); kc.Server != "https://prod.example.com:6443" {
+		t.Errorf("server of the context of the secret = %s, want https://prod.example.com:6443", kc.Server)
+	}
+
+	if _, err := ReadKubeconfigSecret(context.Background(), client, KubeconfigSecretRef{Namespace: "meshery", Name: "clusters", Key: "kubeconfig"}); err == nil {
+		t.Error("ReadKubeconfigSecret() expected an error for a missing key")
+	}
+	if _, err := ReadKubeconfigSecret(context.Background(), client, KubeconfigSecretRef{Namespace: "default", Name: "clusters", Key: "config"}); err == nil {
+		t.Error("ReadKubeconfigSecret() expected an error for a missing secret")
+	}
+}
+
+func TestPortableK8sContext(t *testing.T) {
+	kc := K8sContext{
+		Name:     "staging",
+		Server:   "https://staging.example.com",
+		Cluster:  sql.Map{"name": "staging", "cluster": map[string]interface{}{"server": "https://staging.example.com"}},
+		Auth:     sql.Map{"name": "admin", "user": map[string]interface{}{"token": "s3cr3t"}},
+		Notes:    "shared staging, do not delete",
+		Pinned:   true,
+		ProxyURL: "http://proxy.example.com:3128", ProxyUsername: "proxy", ProxyPassword: "hunter2",
+	}
+
+	portable, err := NewPortableK8sContext(kc, false)
+	if err != nil {
+		t.Fatalf("NewPortableK8sContext() failed with error: %s", err)
+	}
+	if strings.Contains(portable.Kubeconfig, "s3cr3t") || portable.ProxyPassword != "" || portable.WithCredentials {
+		t.Errorf("NewPortableK8sContext() = %+v, want the credentials left out", portable)
+	}
+	if err := portable.Validate(); err == nil {
+		t.Error("Validate() expected an error for a context exported without its credentials")
+	}
+
+	portable, err = NewPortableK8sContext(kc, true)
+	if err != nil {
+		t.Fatalf("NewPortableK8sContext() failed with error: %s", err)
+	}
+	if !strings.Contains(portable.Kubeconfig, "s3cr3t") || portable.ProxyPassword != "hunter2" {
+		t.Errorf("NewPortableK8sContext() = %+v, want the credentials included", portable)
+	}
+	byt, err := json.Marshal(portable)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var imported PortableK8sContext
+	if err := json.Unmarshal(byt, &imported); err != nil {
+		t.Fatal(err)
+	}
+	if err := imported.Validate(); err != nil {
+		t.Fatalf("Validate() failed with error: %s", err)
+	}
+	var restored K8sContext
+	imported.Configure(&restored)
+	if restored.Notes != kc.Notes || !restored.Pinned || restored.ProxyURL != kc.ProxyURL || restored.Source != K8sContextSourceImport {
+		t.Errorf("Configure() = %+v, want the configuration of the exported connection", restored)
+	}
+
+	imported.Version = "v0"
+	if err := imported.Validate(); err == nil {
+		t.Error("Validate() expected an error for an unsupported version")
+	}
+}
+
+func TestGenerateKubeHandlerDevModeLocalTLS(t *testing.T) {
+	// The API server of a local cluster presents a self-signed certificate which is not trusted
+	apiServer := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(map[string]string{"major": "1", "minor": "29", "gitVersion": "v1.29.2"})
+	}))
+	defer apiServer.Close()
+	t.Cleanup(func() {
+		viper.Set("KUBERNETES_DEV_MODE", false)
+		viper.Set("PLAYGROUND", false)
+	})
+
+	instanceID := uuid.Must(uuid.NewV4())
+	newContext := func(server string) K8sContext {
+		kc, _ := NewK8sContext(
+			"kind-dev",
+			map[string]interface{}{"name": "kind-dev", "cluster": map[string]interface{}{"server": server}},
+			map[string]interface{}{"name": "kind-dev", "user": map[string]interface{}{"token": "abc"}},
+			server,
+			&instanceID,
+		)
+		return kc
+	}
+	kc := newContext(apiServer.URL)
+	serverVersion := func() error {
+		t.Helper()
+		handler, err := kc.GenerateKubeHandler()
+		if err != nil {
+			t.Fatalf("GenerateKubeHandler() failed with error: %s", err)
+		}
+		_, err = handler.KubeClient.DiscoveryClient.ServerVersion()
+		return err
+	}
+
+	tests := []struct {
+		name       string
+		devMode    bool
+		playground bool
+		relaxed    bool
+	}{
+		{"not in dev mode", false, false, false},
+		{"in dev mode", true, false, true},
+		{"in dev mode of a hosted Meshery", true, true, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			viper.Set("KUBERNETES_DEV_MODE", tt.devMode)
+			viper.Set("PLAYGROUND", tt.playground)
+			if kc.RelaxesTLSVerification() != tt.relaxed {
+				t.Errorf("RelaxesTLSVerification() = %t, want %t", !tt.relaxed, tt.relaxed)
+			}
+			if err := serverVersion(); (err == nil) != tt.relaxed {
+				t.Errorf("ServerVersion() error = %v, want the certificate verified unless relaxed", err)
+			}
+		})
+	}
+
+	viper.Set("KUBERNETES_DEV_MODE", true)
+	viper.Set("PLAYGROUND", false)
+	if newContext("https://prod.example.com:6443").RelaxesTLSVerification() {
+		t.Error("RelaxesTLSVerification() = true for a remote cluster, want its certificate verified in dev mode too")
+	}
+	for server, local := range map[string]bool{
+		"https://127.0.0.1:6443":       true,
+		"https://localhost:8443":       true,
+		"https://192.168.49.2:8443":    true,
+		"https://10.0.0.5":             true,
+		"https://172.20.0.1":           true,
+		"https://[::1]:6443":           true,
+		"https://172.32.0.1":           false,
+		"https://34.118.224.1":         false,
+		"https://kind-control-plane":   false,
+		"https://api.example.com:6443": false,
+	} {
+		if IsLocalK8sServer(server) != local {
+			t.Errorf("IsLocalK8sServer(%s) = %t, want %t", server, !local, local)
+		}
+	}
+}
+
+func TestParseK8sSyncInterval(t *testing.T) {
+	tests := []struct {
+		interval string
+		want     time.Duration
+		wantErr  bool
+	}{
+		{"", 0, false},
+		{"5m", 5 * time.Minute, false},
+		{MinK8sSyncInterval.String(), MinK8sSyncInterval, false},
+		{"10s", 0, true},
+		{"-5m", 0, true},
+		{"often", 0, true},
+	}
+	for _, tt := range tests {
+		got, err := ParseK8sSyncInterval(tt.interval)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("ParseK8sSyncInterval(%q) = %v, %v, want %v (error %v)", tt.interval, got, err, tt.want, tt.wantErr)
+		}
+	}
+
+	if got := (K8sContext{SyncInterval: "1s"}).MeshSyncInterval(); got != 0 {
+		t.Errorf("MeshSyncInterval() = %v for an interval below the minimum, want 0", got)
+	}
+}
+
+func TestMergeK8sContextConnectionMetadata(t *testing.T) {
+	existing := map[string]interface{}{
+		"name":       "staging",
+		"notes":      "stale",
+		"deleted_at": "2026-01-01T00:00:00Z",
+		"owner_team": "platform",
+	}
+	metadata := mergeK8sContextConnectionMetadata(existing, K8sContext{ID: "1", Name: "staging", Notes: "shared staging"})
+
+	if metadata["owner_team"] != "platform" {
+		t.Errorf("owner_team = %v, want the key not managed by the context kept", metadata["owner_team"])
+	}
+	if metadata["notes"] != "shared staging" {
+		t.Errorf("notes = %v, want the notes of the context", metadata["notes"])
+	}
+	if _, ok := metadata["deleted_at"]; ok {
+		t.Error("expected deleted_at removed once the context is restored")
+	}
+	if existing["notes"] != "stale" {
+		t.Error("expected the existing metadata left untouched")
+	}
+}
+
+func TestK8sContextConnectionMetadata(t *testing.T) {
+	metadata := k8sContextConnectionMetadata(K8sContext{
+		ID:             "1",
+		Name:           "staging",
+		Namespace:      "team-a",
+		Source:         K8sContextSourceUpload,
+		OriginalServer: "https://prod.example.com:6443",
+		DialTimeout:    "5s",
+		TLSServerName:  "kubernetes.default.svc",
+		QPS:            12.5,
+		Namespaces:     []string{"orders", "payments"},
+		OriginalName:   "arn:aws:eks:eu-west-1:123456789012:cluster/staging",
+		ProxyURL:       "http://proxy:3128",
+		ProxyPassword:  "secret",
+	})
+	for key, want := range map[string]interface{}{
+		"namespace":       "team-a",
+		"source":          K8sContextSourceUpload,
+		"original_server": "https://prod.example.com:6443",
+		"dial_timeout":    "5s",
+		"tls_server_name": "kubernetes.default.svc",
+		"qps":             "12.5",
+		"namespaces":      []string{"orders", "payments"},
+		"original_name":   "arn:aws:eks:eu-west-1:123456789012:cluster/staging",
+		"proxy_url":       "http://proxy:3128",
+	} {
+		if !reflect.DeepEqual(metadata[key], want) {
+			t.Errorf("metadata[%q] = %v, want %v", key, metadata[key], want)
+		}
+	}
+
+	if _, ok := metadata["proxy_password"]; ok {
+		t.Error("expected the proxy password kept out of the metadata")
+	}
+	if _, ok := metadata["burst"]; ok {
+		t.Error("expected the default burst kept out of the metadata")
+	}
+
+	// the contexts are read back with their optional metadata, the keys they no longer set are cleared
+	merged := mergeK8sContextConnectionMetadata(metadata, K8sContext{ID: "1", Name: "staging", Source: K8sContextSourceUpload})
+	for _, key := range []string{"namespace", "namespaces", "original_server", "original_name", "proxy_url"} {
+		if _, ok := merged[key]; ok {
+			t.Errorf("expected %s removed once cleared on the context", key)
+		}
+	}
+	if merged["source"] != K8sContextSourceUpload {
+		t.Errorf("source = %v, want the source of the context", merged["source"])
+	}
+}
+
+func TestSplitByNamespace(t *testing.T) {
+	client := fake.NewSimpleClientset(
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "team-a"}},
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "team-b"}},
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "kube-system"}},
+	)
+	// the user of the context is only bound to the namespaces of its teams
+	client.PrependReactor("create", "selfsubjectaccessreviews", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		review := action.(k8stesting.CreateAction).GetObject().(*authorizationv1.SelfSubjectAccessReview)
+		review.Status.Allowed = strings.HasPrefix(review.Spec.ResourceAttributes.Namespace, "team-")
+		return true, review, nil
+	})
+
+	instanceID := uuid.Must(uuid.NewV4())
+	kc := K8sContext{Name: "shared", Server: "https://shared:6443", MesheryInstanceID: &instanceID}
+	ctxs, err := kc.splitByNamespace(context.Background(), client)
+	if err != nil {
+		t.Fatalf("splitByNamespace() failed with error: %s", err)
+	}
+	names := []string{}
+	for _, ctx := range ctxs {
+		names = append(names, ctx.Name)
+		if ctx.Name != kc.Name+"-"+ctx.Namespace || ctx.ID == "" {
+			t.Errorf("context %s of namespace %s with ID %q, want it named after the namespace with an ID", ctx.Name, ctx.Namespace, ctx.ID)
+		}
+	}
+	sort.Strings(names)
+	if !reflect.DeepEqual(names, []string{"shared-team-a", "shared-team-b"}) {
+		t.Errorf("split contexts = %v, want the contexts of the accessible namespaces only", names)
+	}
+}
+
+func TestRefreshKubeConfigAuth(t *testing.T) {
+	kubeconfig := []byte(`apiVersion: v1
+kind: Config
+clusters:
+- name: gke
+  cluster:
+    server: https://gke.example.com
+contexts:
+- name: gke
+  context:
+    cluster: gke
+    user: gke
+current-context: gke
+users:
+- name: gke
+  user:
+    auth-provider:
+      name: gcp
+      config:
+        access-token: expired
+        expiry: "2020-01-01T00:00:00Z"
+        cmd-path: gcloud
+`)
+	refreshed, ok, err := RefreshKubeConfigAuth(kubeconfig)
+	if err != nil || !ok {
+		t.Fatalf("RefreshKubeConfigAuth() = %t, %v, want the auth-provider refreshed", ok, err)
+	}
+	cfg, err := clientcmd.Load(refreshed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if config := cfg.AuthInfos["gke"].AuthProvider.Config; !reflect.DeepEqual(config, map[string]string{"cmd-path": "gcloud"}) {
+		t.Errorf("auth-provider config = %v, want the cached credentials dropped", config)
+	}
+
+	// Static credentials cannot be refreshed
+	static := []byte(strings.Replace(string(kubeconfig), "    auth-provider:\n      name: gcp\n      config:\n        access-token: expired\n        expiry: \"2020-01-01T00:00:00Z\"\n        cmd-path: gcloud\n", "    token: static\n", 1))
+	if _, ok, err := RefreshKubeConfigAuth(static); err != nil || ok {
+		t.Errorf("RefreshKubeConfigAuth() of a static token = %t, %v, want it not refreshed", ok, err)
+	}
+}
@@ -0,0 +1,71 @@
+package models
+
+import (
+	"context"
+
+	"github.com/layer5io/meshkit/utils/kubernetes"
+	authenticationv1 "k8s.io/api/authentication/v1"
+	authenticationv1beta1 "k8s.io/api/authentication/v1beta1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// Outcomes of the validation of the credentials of a context
+const (
+	K8sCredentialsValid       = "valid"
+	K8sCredentialsInvalid     = "invalid"
+	K8sCredentialsUnreachable = "unreachable"
+)
+
+// K8sCredentialsValidation reports whether the stored credentials of a context still authenticate with its cluster
+type K8sCredentialsValidation struct {
+	// Status is "valid", "invalid" when the cluster rejected the credentials, or "unreachable" when the cluster
+	// could not be asked, in which case the credentials may or may not be valid.
+	Status string `json:"status"`
+	Valid  bool   `json:"valid"`
+	// User the credentials authenticate as, when the cluster serves SelfSubjectReviews
+	Username string   `json:"username,omitempty"`
+	Groups   []string `json:"groups,omitempty"`
+	Error    string   `json:"error,omitempty"`
+}
+
+// ValidateK8sCredentials makes a lightweight authenticated call to the cluster to find out whether the credentials
+// of the client still authenticate, e.g. after tokens were rotated or certificates expired.
+// The call is a SelfSubjectReview, falling back to listing a namespace on clusters older than 1.27 which do not serve them.
+// Being authenticated but not authorized (403) counts as valid credentials.
+func ValidateK8sCredentials(ctx context.Context, kubeclient *kubernetes.Client) K8sCredentialsValidation {
+	user, err := selfSubjectReview(ctx, kubeclient)
+	if kerrors.IsNotFound(err) {
+		user = nil
+		_, err = kubeclient.KubeClient.CoreV1().Namespaces().List(ctx, metav1.ListOptions{Limit: 1})
+	}
+
+	switch {
+	case err == nil || kerrors.IsForbidden(err):
+		validation := K8sCredentialsValidation{Status: K8sCredentialsValid, Valid: true}
+		if user != nil {
+			validation.Username, validation.Groups = user.Username, user.Groups
+		}
+		return validation
+	case kerrors.IsUnauthorized(err):
+		return K8sCredentialsValidation{Status: K8sCredentialsInvalid, Error: err.Error()}
+	default:
+		return K8sCredentialsValidation{Status: K8sCredentialsUnreachable, Error: err.Error()}
+	}
+}
+
+// selfSubjectReview returns the user the client authenticates as, through the GA or else the beta API
+func selfSubjectReview(ctx context.Context, kubeclient *kubernetes.Client) (*authenticationv1.UserInfo, error) {
+	review, err := kubeclient.KubeClient.AuthenticationV1().SelfSubjectReviews().Create(ctx, &authenticationv1.SelfSubjectReview{}, metav1.CreateOptions{})
+	if err == nil {
+		return &review.Status.UserInfo, nil
+	}
+	if !kerrors.IsNotFound(err) {
+		return nil, err
+	}
+	betaReview, err := kubeclient.KubeClient.AuthenticationV1beta1().SelfSubjectReviews().Create(ctx, &authenticationv1beta1.SelfSubjectReview{}, metav1.CreateOptions{})
+	if err != nil {
+		return nil, err
+	}
+	return &betaReview.Status.UserInfo, nil
+}
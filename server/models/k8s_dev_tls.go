@@ -0,0 +1,47 @@
+package models
+
+import (
+	"net"
+	"net/url"
+	"strings"
+
+	"github.com/spf13/viper"
+	"k8s.io/client-go/rest"
+)
+
+// k8sDevModeEnabled reports whether "KUBERNETES_DEV_MODE" is on, it is always off for a hosted (playground) Meshery
+func k8sDevModeEnabled() bool {
+	return viper.GetBool("KUBERNETES_DEV_MODE") && !viper.GetBool("PLAYGROUND")
+}
+
+// RelaxesTLSVerification reports whether the certificate of the API server of the context is not verified,
+// for local clusters (e.g. kind or minikube) with self-signed certificates while in dev mode, see IsLocalK8sServer.
+func (kc K8sContext) RelaxesTLSVerification() bool {
+	return k8sDevModeEnabled() && IsLocalK8sServer(kc.Server)
+}
+
+// IsLocalK8sServer reports whether the API server URL is localhost, a loopback address or a private address
+// (RFC 1918 or an IPv6 unique local address). Hostnames other than localhost are not resolved.
+func IsLocalK8sServer(server string) bool {
+	u, err := url.Parse(server)
+	if err != nil {
+		return false
+	}
+	host := u.Hostname()
+	if host == "localhost" || strings.HasSuffix(host, ".localhost") {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && (ip.IsLoopback() || ip.IsPrivate())
+}
+
+// configureLocalTLS skips the verification of the certificate of the API server when the context relaxes it
+func (kc *K8sContext) configureLocalTLS(restConfig *rest.Config) {
+	if !kc.RelaxesTLSVerification() {
+		return
+	}
+	// client-go refuses a CA along with the insecure flag
+	restConfig.TLSClientConfig.Insecure = true
+	restConfig.TLSClientConfig.CAData = nil
+	restConfig.TLSClientConfig.CAFile = ""
+}
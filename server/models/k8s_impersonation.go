@@ -0,0 +1,87 @@
+package models
+
+import (
+	"github.com/layer5io/meshery/server/internal/sql"
+	"k8s.io/client-go/rest"
+)
+
+// Impersonation returns the identity impersonated by the user of the context, as set by "as", "as-uid", "as-groups"
+// and "as-user-extra" in the kubeconfig, e.g. a restricted service account distinct from the base credentials.
+func (kc K8sContext) Impersonation() (rest.ImpersonationConfig, error) {
+	kc, err := kc.Decompress()
+	if err != nil {
+		return rest.ImpersonationConfig{}, err
+	}
+
+	impersonation := rest.ImpersonationConfig{}
+	user := k8sAuthUser(kc.Auth)
+	impersonation.UserName, _ = user["as"].(string)
+	impersonation.UID, _ = user["as-uid"].(string)
+	impersonation.Groups = stringSlice(user["as-groups"])
+	if extra, ok := user["as-user-extra"].(map[string]interface{}); ok && len(extra) > 0 {
+		impersonation.Extra = make(map[string][]string, len(extra))
+		for key, values := range extra {
+			impersonation.Extra[key] = stringSlice(values)
+		}
+	}
+	return impersonation, nil
+}
+
+// SetImpersonation overrides the identity impersonated by the user of the context, the impersonation of the kubeconfig
+// is dropped altogether when username is empty. The context must not be compressed.
+func (kc *K8sContext) SetImpersonation(username string, groups []string) {
+	user := k8sAuthUser(kc.Auth)
+	if user == nil {
+		user = map[string]interface{}{}
+		if kc.Auth == nil {
+			kc.Auth = sql.Map{}
+		}
+	}
+	for _, key := range []string{"as", "as-uid", "as-groups", "as-user-extra"} {
+		delete(user, key)
+	}
+	if username != "" {
+		user["as"] = username
+		if len(groups) > 0 {
+			user["as-groups"] = groups
+		}
+	}
+	kc.Auth["user"] = user
+}
+
+// configureImpersonation makes the requests to the API server as the identity impersonated by the context, if any
+func (kc *K8sContext) configureImpersonation(restConfig *rest.Config) error {
+	impersonation, err := kc.Impersonation()
+	if err != nil {
+		return err
+	}
+	restConfig.Impersonate = impersonation
+	return nil
+}
+
+// k8sAuthUser returns the user of the auth of the context, as read from a kubeconfig or from the storage
+func k8sAuthUser(auth map[string]interface{}) map[string]interface{} {
+	switch user := auth["user"].(type) {
+	case map[string]interface{}:
+		return user
+	case sql.Map:
+		return user
+	}
+	return nil
+}
+
+func stringSlice(v interface{}) []string {
+	switch values := v.(type) {
+	case []string:
+		return values
+	case []interface{}:
+		s := make([]string, 0, len(values))
+		for _, value := range values {
+			if str, ok := value.(string); ok {
+				s = append(s, str)
+			}
+		}
+		return s
+	}
+	return nil
+}
@@ -0,0 +1,65 @@
+package models
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	k8s "k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+)
+
+// DefaultKubeconfigSecretKey is the key of the secret holding the kubeconfig when the reference names none
+const DefaultKubeconfigSecretKey = "config"
+
+// KubeconfigSecretRef references the key of a secret holding a kubeconfig
+type KubeconfigSecretRef struct {
+	Namespace string
+	Name      string
+	Key       string
+}
+
+func (ref KubeconfigSecretRef) String() string {
+	return ref.Namespace + "/" + ref.Name + "/" + ref.Key
+}
+
+// ParseKubeconfigSecretRef parses a reference to a secret holding a kubeconfig of the form namespace/name[/key],
+// the key defaults to "config"
+func ParseKubeconfigSecretRef(ref string) (KubeconfigSecretRef, error) {
+	parts := strings.Split(strings.TrimSpace(ref), "/")
+	if len(parts) == 2 {
+		parts = append(parts, DefaultKubeconfigSecretKey)
+	}
+	if len(parts) != 3 || parts[0] == "" || parts[1] == "" || parts[2] == "" {
+		return KubeconfigSecretRef{}, ErrInvalidKubeconfigSecretRef(ref)
+	}
+	return KubeconfigSecretRef{Namespace: parts[0], Name: parts[1], Key: parts[2]}, nil
+}
+
+// ReadKubeconfigSecret reads the kubeconfig held by the key of the secret
+func ReadKubeconfigSecret(ctx context.Context, client k8s.Interface, ref KubeconfigSecretRef) ([]byte, error) {
+	secret, err := client.CoreV1().Secrets(ref.Namespace).Get(ctx, ref.Name, metav1.GetOptions{})
+	if err != nil {
+		return nil, ErrReadKubeconfigSecret(err, ref.String())
+	}
+	data, ok := secret.Data[ref.Key]
+	if !ok || len(data) == 0 {
+		return nil, ErrReadKubeconfigSecret(fmt.Errorf("secret %s/%s has no key %q", ref.Namespace, ref.Name, ref.Key), ref.String())
+	}
+	return data, nil
+}
+
+// ReadKubeconfigSecretInCluster reads the kubeconfig held by the key of the secret through the in-cluster client,
+// for Meshery Server running in a cluster where the kubeconfigs of the target clusters are managed as secrets.
+func ReadKubeconfigSecretInCluster(ctx context.Context, ref KubeconfigSecretRef) ([]byte, error) {
+	restConfig, err := rest.InClusterConfig()
+	if err != nil {
+		return nil, ErrReadKubeconfigSecret(err, ref.String())
+	}
+	client, err := k8s.NewForConfig(restConfig)
+	if err != nil {
+		return nil, ErrReadKubeconfigSecret(err, ref.String())
+	}
+	return ReadKubeconfigSecret(ctx, client, ref)
+}
@@ -0,0 +1,120 @@
+package models
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// defaultK8sMeshModelMetadata is the known-good metadata of the Kubernetes model, used when the
+// model template is missing or malformed so that the generated components are never left without metadata.
+var defaultK8sMeshModelMetadata = map[string]interface{}{
+	"primaryColor":   "#326CE5",
+	"secondaryColor": "#7AA1F0",
+	"shape":          "round-rectangle",
+}
+
+// k8sMeshModelMetadataStringFields are the fields of the model template which must be strings when present.
+var k8sMeshModelMetadataStringFields = []string{"primaryColor", "secondaryColor", "shape", "svgColor", "svgWhite", "svgComplete"}
+
+// k8sMeshModelMetadataCache holds the metadata of the Kubernetes model in memory, it is replaced as a whole on reload
+// and never mutated, hence the map handed out by GetK8sMeshModelMetadata is safe to read concurrently.
+var k8sMeshModelMetadataCache struct {
+	mu       sync.RWMutex
+	metadata map[string]interface{}
+	loadedAt time.Time
+}
+
+// GetK8sMeshModelMetadata returns the cached metadata of the Kubernetes model, which must not be modified
+func GetK8sMeshModelMetadata() map[string]interface{} {
+	k8sMeshModelMetadataCache.mu.RLock()
+	defer k8sMeshModelMetadataCache.mu.RUnlock()
+	return k8sMeshModelMetadataCache.metadata
+}
+
+// ReloadK8sMeshModelMetadata flushes the cached metadata of the Kubernetes model and reads it again from the model template
+func ReloadK8sMeshModelMetadata() {
+	metadata := loadK8sMeshModelMetadata(k8sMeshModelPath)
+	k8sMeshModelMetadataCache.mu.Lock()
+	defer k8sMeshModelMetadataCache.mu.Unlock()
+	k8sMeshModelMetadataCache.metadata = metadata
+	k8sMeshModelMetadataCache.loadedAt = time.Now()
+}
+
+// CacheStats describes an in-memory cache of Meshery Server
+type CacheStats struct {
+	Name       string    `json:"name"`
+	Entries    int       `json:"entries"`
+	Keys       []string  `json:"keys"`
+	LoadedAt   time.Time `json:"loaded_at"`
+	AgeSeconds int64     `json:"age_seconds"`
+}
+
+// K8sMeshModelMetadataCacheStats describes the cache of the metadata of the Kubernetes model
+func K8sMeshModelMetadataCacheStats() CacheStats {
+	k8sMeshModelMetadataCache.mu.RLock()
+	defer k8sMeshModelMetadataCache.mu.RUnlock()
+	keys := make([]string, 0, len(k8sMeshModelMetadataCache.metadata))
+	for k := range k8sMeshModelMetadataCache.metadata {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return CacheStats{
+		Name:       "k8s_meshmodel_metadata",
+		Entries:    len(keys),
+		Keys:       keys,
+		LoadedAt:   k8sMeshModelMetadataCache.loadedAt,
+		AgeSeconds: int64(time.Since(k8sMeshModelMetadataCache.loadedAt).Seconds()),
+	}
+}
+
+// loadK8sMeshModelMetadata reads the metadata of the Kubernetes model from the template at path,
+// falling back to the embedded default if the template is missing or malformed.
+func loadK8sMeshModelMetadata(path string) map[string]interface{} {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		logrus.Debugf("unable to read the kubernetes model template %s, using the default metadata: %v", path, err)
+		return copyK8sMeshModelMetadata(defaultK8sMeshModelMetadata)
+	}
+
+	metadata, err := parseK8sMeshModelMetadata(data)
+	if err != nil {
+		logrus.Error(ErrInvalidK8sMeshModelTemplate(err, path))
+		return copyK8sMeshModelMetadata(defaultK8sMeshModelMetadata)
+	}
+	return metadata
+}
+
+// parseK8sMeshModelMetadata parses the model template and validates the type of its known fields
+func parseK8sMeshModelMetadata(data []byte) (map[string]interface{}, error) {
+	metadata := make(map[string]interface{})
+	if err := json.Unmarshal(data, &metadata); err != nil {
+		return nil, err
+	}
+	if len(metadata) == 0 {
+		return nil, fmt.Errorf("the template is empty")
+	}
+	for _, field := range k8sMeshModelMetadataStringFields {
+		v, ok := metadata[field]
+		if !ok {
+			continue
+		}
+		if _, ok := v.(string); !ok {
+			return nil, fmt.Errorf("field %q must be a string, got %T", field, v)
+		}
+	}
+	return metadata, nil
+}
+
+func copyK8sMeshModelMetadata(metadata map[string]interface{}) map[string]interface{} {
+	c := make(map[string]interface{}, len(metadata))
+	for k, v := range metadata {
+		c[k] = v
+	}
+	return c
+}
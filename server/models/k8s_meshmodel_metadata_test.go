@@ -0,0 +1,155 @@
+package models
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/layer5io/meshkit/database"
+	"github.com/layer5io/meshkit/models/meshmodel/core/v1alpha1"
+	meshmodel "github.com/layer5io/meshkit/models/meshmodel/registry"
+)
+
+func TestLoadK8sMeshModelMetadata(t *testing.T) {
+	dir := t.TempDir()
+	tests := []struct {
+		name     string
+		template string
+		want     string
+	}{
+		{name: "valid", template: `{"primaryColor": "#000000", "shape": "circle"}`, want: "#000000"},
+		{name: "invalid json", template: `{"primaryColor": "#000000",`, want: defaultK8sMeshModelMetadata["primaryColor"].(string)},
+		{name: "invalid field type", template: `{"primaryColor": 42}`, want: defaultK8sMeshModelMetadata["primaryColor"].(string)},
+		{name: "empty", template: `{}`, want: defaultK8sMeshModelMetadata["primaryColor"].(string)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(dir, tt.name+".json")
+			if err := os.WriteFile(path, []byte(tt.template), 0o600); err != nil {
+				t.Fatal(err)
+			}
+			metadata := loadK8sMeshModelMetadata(path)
+			if metadata["primaryColor"] != tt.want {
+				t.Errorf("primaryColor = %v, want %s", metadata["primaryColor"], tt.want)
+			}
+		})
+	}
+
+	if metadata := loadK8sMeshModelMetadata(filepath.Join(dir, "missing.json")); len(metadata) == 0 {
+		t.Error("expected the default metadata for a missing template")
+	}
+}
+
+func TestParseK8sMeshModelMetadataReportsField(t *testing.T) {
+	_, err := parseK8sMeshModelMetadata([]byte(`{"svgColor": ["<svg/>"]}`))
+	if err == nil || !strings.Contains(err.Error(), "svgColor") {
+		t.Errorf("parseK8sMeshModelMetadata() error = %v, want an error naming svgColor", err)
+	}
+}
+
+func TestK8sMeshModelMetadataCacheStats(t *testing.T) {
+	ReloadK8sMeshModelMetadata()
+	stats := K8sMeshModelMetadataCacheStats()
+	if stats.Entries != len(GetK8sMeshModelMetadata()) || len(stats.Keys) != stats.Entries {
+		t.Errorf("K8sMeshModelMetadataCacheStats() = %+v, want %d entries", stats, len(GetK8sMeshModelMetadata()))
+	}
+	if stats.LoadedAt.IsZero() {
+		t.Error("K8sMeshModelMetadataCacheStats() expected the time the cache was loaded at")
+	}
+}
+
+func TestUpdateK8sContextComponentsMetadata(t *testing.T) {
+	db, err := database.New(database.Options{Engine: database.SQLITE, Filename: filepath.Join(t.TempDir(), "registry.db")})
+	if err != nil {
+		t.Fatalf("failed to open the database: %s", err)
+	}
+	if err := db.AutoMigrate(&K8sComponentsRegistryHost{}, &K8sComponentsRegistrationCheckpoint{}); err != nil {
+		t.Fatal(err)
+	}
+	reg, err := meshmodel.NewRegistryManager(&db)
+	if err != nil {
+		t.Fatalf("failed to create the registry: %s", err)
+	}
+	// the same kinds registered by two registrants, the hosts of two contexts
+	for _, ctxID := range []string{"staging", "prod"} {
+		for _, kind := range []string{"Deployment", "Service"} {
+			comp := v1alpha1.ComponentDefinition{
+				TypeMeta: v1alpha1.TypeMeta{Kind: kind, APIVersion: "apps/v1"},
+				Model:    v1alpha1.Model{Name: "kubernetes", Version: "v1.27.3", Category: v1alpha1.Category{Name: "Orchestration & Management"}},
+				Metadata: map[string]interface{}{"context": ctxID},
+				Schema:   "{}",
+			}
+			if err := reg.RegisterEntity(K8sComponentsHost(ctxID), comp); err != nil {
+				t.Fatalf("failed to register %s for %s: %s", kind, ctxID, err)
+			}
+		}
+	}
+
+	updated, err := UpdateK8sContextComponentsMetadata(&db, "staging", func(comp *v1alpha1.ComponentDefinition) bool {
+		comp.Metadata["refreshed"] = true
+		return true
+	})
+	if err != nil || updated != 2 {
+		t.Fatalf("UpdateK8sContextComponentsMetadata() = %d, %v, want both components of the context updated", updated, err)
+	}
+	for ctxID, want := range map[string]bool{"staging": true, "prod": false} {
+		comps, err := GetK8sContextComponents(&db, ctxID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, comp := range comps {
+			if refreshed, _ := comp.Metadata["refreshed"].(bool); refreshed != want {
+				t.Errorf("%s of %s refreshed = %t, want %t", comp.Kind, ctxID, refreshed, want)
+			}
+		}
+	}
+}
+
+func TestK8sContextComponentsCustomRegistryHost(t *testing.T) {
+	db, err := database.New(database.Options{Engine: database.SQLITE, Filename: filepath.Join(t.TempDir(), "registry.db")})
+	if err != nil {
+		t.Fatalf("failed to open the database: %s", err)
+	}
+	if err := db.AutoMigrate(&K8sComponentsRegistryHost{}, &K8sComponentsRegistrationCheckpoint{}); err != nil {
+		t.Fatal(err)
+	}
+	reg, err := meshmodel.NewRegistryManager(&db)
+	if err != nil {
+		t.Fatalf("failed to create the registry: %s", err)
+	}
+	host := K8sComponentsHostFor("registry.example.com", "tenant-a", "staging")
+	if err := SaveK8sComponentsRegistryHost(&db, "staging", host); err != nil {
+		t.Fatal(err)
+	}
+	comp := v1alpha1.ComponentDefinition{
+		TypeMeta: v1alpha1.TypeMeta{Kind: "Deployment", APIVersion: "apps/v1"},
+		Model:    v1alpha1.Model{Name: "kubernetes", Version: "v1.27.3", Category: v1alpha1.Category{Name: "Orchestration & Management"}},
+		Metadata: map[string]interface{}{},
+		Schema:   "{}",
+	}
+	if err := reg.RegisterEntity(host, comp); err != nil {
+		t.Fatal(err)
+	}
+
+	if count, err := CountK8sContextComponents(&db, "staging"); err != nil || count != 1 {
+		t.Errorf("CountK8sContextComponents() = %d, %v, want the component registered against the custom host", count, err)
+	}
+	if removed, err := UnregisterK8sContextComponents(&db, "staging", "Deployment", []string{"apps/v1"}); err != nil || removed != 1 {
+		t.Errorf("UnregisterK8sContextComponents() = %d, %v, want the component registered against the custom host removed", removed, err)
+	}
+}
+
+func TestValidateRegistryHostMetadata(t *testing.T) {
+	for metadata, valid := range map[string]bool{
+		"tenant-a":               true,
+		"region: eu-west-1":      true,
+		" tenant-a":              false,
+		"tenant\na":              false,
+		strings.Repeat("a", 254): false,
+	} {
+		if err := ValidateRegistryHostMetadata(metadata); (err == nil) != valid {
+			t.Errorf("ValidateRegistryHostMetadata(%q) = %v, want valid %t", metadata, err, valid)
+		}
+	}
+}
@@ -0,0 +1,51 @@
+package models
+
+import (
+	"context"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	k8s "k8s.io/client-go/kubernetes"
+)
+
+// K8sNodesSummary is the size of a cluster at a glance, the count of its nodes by readiness and the resources they can allocate
+type K8sNodesSummary struct {
+	Ready    int `json:"ready"`
+	NotReady int `json:"not_ready"`
+	// Total CPU and memory allocatable to the pods by the nodes, ready or not, as Kubernetes quantities (e.g. "8", "31Gi")
+	AllocatableCPU    string `json:"allocatable_cpu"`
+	AllocatableMemory string `json:"allocatable_memory"`
+}
+
+// SummarizeK8sNodes lists the nodes of the cluster, which requires the permission to list nodes, and summarizes them
+func SummarizeK8sNodes(ctx context.Context, client k8s.Interface) (*K8sNodesSummary, error) {
+	nodes, err := client.CoreV1().Nodes().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	summary := &K8sNodesSummary{}
+	cpu, memory := resource.Quantity{}, resource.Quantity{}
+	for _, node := range nodes.Items {
+		if k8sNodeReady(node) {
+			summary.Ready++
+		} else {
+			summary.NotReady++
+		}
+		cpu.Add(*node.Status.Allocatable.Cpu())
+		memory.Add(*node.Status.Allocatable.Memory())
+	}
+	summary.AllocatableCPU, summary.AllocatableMemory = cpu.String(), memory.String()
+	return summary, nil
+}
+
+// k8sNodeReady reports whether the Ready condition of the node is true, nodes whose kubelet stopped reporting are not ready
+func k8sNodeReady(node corev1.Node) bool {
+	for _, condition := range node.Status.Conditions {
+		if condition.Type == corev1.NodeReady {
+			return condition.Status == corev1.ConditionTrue
+		}
+	}
+	return false
+}
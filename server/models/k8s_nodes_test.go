@@ -0,0 +1,42 @@
+package models
+
+import (
+	"context"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestSummarizeK8sNodes(t *testing.T) {
+	node := func(name string, ready corev1.ConditionStatus, cpu, memory string) *corev1.Node {
+		n := &corev1.Node{
+			ObjectMeta: metav1.ObjectMeta{Name: name},
+			Status: corev1.NodeStatus{Allocatable: corev1.ResourceList{
+				corev1.ResourceCPU:    resource.MustParse(cpu),
+				corev1.ResourceMemory: resource.MustParse(memory),
+			}},
+		}
+		if ready != "" {
+			n.Status.Conditions = []corev1.NodeCondition{{Type: corev1.NodeReady, Status: ready}}
+		}
+		return n
+	}
+	client := fake.NewSimpleClientset(
+		node("a", corev1.ConditionTrue, "4", "16Gi"),
+		node("b", corev1.ConditionTrue, "3500m", "8Gi"),
+		node("c", corev1.ConditionUnknown, "2", "8Gi"),
+		node("d", "", "500m", "0"),
+	)
+
+	summary, err := SummarizeK8sNodes(context.Background(), client)
+	if err != nil {
+		t.Fatalf("SummarizeK8sNodes() failed with error: %s", err)
+	}
+	want := K8sNodesSummary{Ready: 2, NotReady: 2, AllocatableCPU: "10", AllocatableMemory: "32Gi"}
+	if *summary != want {
+		t.Errorf("SummarizeK8sNodes() = %+v, want %+v", *summary, want)
+	}
+}
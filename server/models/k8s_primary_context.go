@@ -0,0 +1,96 @@
+package models
+
+import (
+	"fmt"
+	"sort"
+)
+
+// Reasons for which a context is resolved as the primary one
+const (
+	PrimaryK8sContextOverride       = "override"
+	PrimaryK8sContextInCluster      = "in_cluster"
+	PrimaryK8sContextFallback       = "fallback"
+	PrimaryK8sContextFirstReachable = "first_reachable"
+)
+
+// PrimaryK8sContext is the context Meshery uses to operate on its own cluster
+type PrimaryK8sContext struct {
+	Context *K8sContext `json:"context"`
+	// Reason is one of the PrimaryK8sContext* values
+	Reason      string `json:"reason"`
+	Description string `json:"description"`
+	Reachable   bool   `json:"reachable"`
+}
+
+// ResolvePrimaryK8sContext picks the primary context out of the given ones. In order of preference:
+//   - the override, regardless of whether it is reachable
+//   - the reachable in-cluster context
+//   - the designated out-of-cluster fallback, if reachable
+//   - the first reachable context by name
+//
+// The override and the fallback match a context by ID, connection ID or name and are ignored when empty.
+// reachable returns an error when the API server of the context cannot be reached.
+func ResolvePrimaryK8sContext(contexts []*K8sContext, override, fallback string, reachable func(*K8sContext) error) (*PrimaryK8sContext, error) {
+	find := func(ref string) *K8sContext {
+		if ref == "" {
+			return nil
+		}
+		for _, ctx := range contexts {
+			if ctx.ID == ref || ctx.ConnectionID == ref || ctx.Name == ref {
+				return ctx
+			}
+		}
+		return nil
+	}
+
+	if ctx := find(override); ctx != nil {
+		return &PrimaryK8sContext{
+			Context:     ctx,
+			Reason:      PrimaryK8sContextOverride,
+			Description: fmt.Sprintf("Context %s is configured as the primary one", ctx.Name),
+			Reachable:   reachable(ctx) == nil,
+		}, nil
+	}
+
+	for _, ctx := range contexts {
+		if ctx.DeploymentType != K8sContextDeploymentInCluster {
+			continue
+		}
+		if err := reachable(ctx); err != nil {
+			continue
+		}
+		return &PrimaryK8sContext{
+			Context:     ctx,
+			Reason:      PrimaryK8sContextInCluster,
+			Description: fmt.Sprintf("Meshery runs in the cluster of context %s", ctx.Name),
+			Reachable:   true,
+		}, nil
+	}
+
+	if ctx := find(fallback); ctx != nil && reachable(ctx) == nil {
+		return &PrimaryK8sContext{
+			Context:     ctx,
+			Reason:      PrimaryK8sContextFallback,
+			Description: fmt.Sprintf("No reachable in-cluster context, context %s is the designated fallback", ctx.Name),
+			Reachable:   true,
+		}, nil
+	}
+
+	sorted := make([]*K8sContext, len(contexts))
+	copy(sorted, contexts)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].Name < sorted[j].Name
+	})
+	for _, ctx := range sorted {
+		if ctx.DeploymentType == K8sContextDeploymentInCluster || reachable(ctx) != nil {
+			continue
+		}
+		return &PrimaryK8sContext{
+			Context:     ctx,
+			Reason:      PrimaryK8sContextFirstReachable,
+			Description: fmt.Sprintf("No reachable in-cluster or designated fallback context, context %s is the first reachable one", ctx.Name),
+			Reachable:   true,
+		}, nil
+	}
+	return nil, ErrNoPrimaryK8sContext
+}
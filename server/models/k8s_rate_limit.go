@@ -0,0 +1,88 @@
+package models
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/spf13/viper"
+	"k8s.io/client-go/rest"
+)
+
+// Default rate limits of the requests to the API servers, the ones Meshery has always used.
+const (
+	DefaultK8sClientQPS   = 50
+	DefaultK8sClientBurst = 100
+)
+
+// K8sClientRateLimits are the limits of the client-side rate limiter of the requests to the API server of a context.
+type K8sClientRateLimits struct {
+	QPS   float32 `json:"qps"`
+	Burst int     `json:"burst"`
+}
+
+// ClientRateLimits resolves the rate limits of the requests to the API server of the context,
+// the limits set on the context take precedence over the "KUBERNETES_CLIENT_QPS" and "KUBERNETES_CLIENT_BURST" defaults.
+func (kc *K8sContext) ClientRateLimits() (K8sClientRateLimits, error) {
+	limits := K8sClientRateLimits{
+		QPS:   float32(viper.GetFloat64("KUBERNETES_CLIENT_QPS")),
+		Burst: viper.GetInt("KUBERNETES_CLIENT_BURST"),
+	}
+	if limits.QPS <= 0 {
+		limits.QPS = DefaultK8sClientQPS
+	}
+	if limits.Burst <= 0 {
+		limits.Burst = DefaultK8sClientBurst
+	}
+
+	if kc.QPS < 0 {
+		return limits, ErrInvalidK8sClientRateLimit(fmt.Errorf("qps must not be negative"), "qps", strconv.FormatFloat(float64(kc.QPS), 'f', -1, 32))
+	}
+	if kc.Burst < 0 {
+		return limits, ErrInvalidK8sClientRateLimit(fmt.Errorf("burst must not be negative"), "burst", strconv.Itoa(kc.Burst))
+	}
+	if kc.QPS > 0 {
+		limits.QPS = kc.QPS
+	}
+	if kc.Burst > 0 {
+		limits.Burst = kc.Burst
+	}
+	return limits, nil
+}
+
+// ParseK8sClientRateLimits parses the qps and burst overrides of a context, either may be empty to keep the default.
+func ParseK8sClientRateLimits(qps, burst string) (qpsLimit float32, burstLimit int, err error) {
+	if qps != "" {
+		v, err := strconv.ParseFloat(qps, 32)
+		if err == nil && v < 0 {
+			err = fmt.Errorf("qps must not be negative")
+		}
+		if err != nil {
+			return 0, 0, ErrInvalidK8sClientRateLimit(err, "qps", qps)
+		}
+		qpsLimit = float32(v)
+	}
+	if burst != "" {
+		v, err := strconv.Atoi(burst)
+		if err == nil && v < 0 {
+			err = fmt.Errorf("burst must not be negative")
+		}
+		if err != nil {
+			return 0, 0, ErrInvalidK8sClientRateLimit(err, "burst", burst)
+		}
+		burstLimit = v
+	}
+	return qpsLimit, burstLimit, nil
+}
+
+// configureRateLimit applies the rate limits of the context to the rest config, so that the requests to the
+// API server are throttled on the client rather than rejected with 429 by the priority and fairness of the cluster.
+func (kc *K8sContext) configureRateLimit(restConfig *rest.Config) error {
+	limits, err := kc.ClientRateLimits()
+	if err != nil {
+		return err
+	}
+	restConfig.QPS = limits.QPS
+	restConfig.Burst = limits.Burst
+	restConfig.RateLimiter = nil
+	return nil
+}
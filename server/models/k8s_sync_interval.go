@@ -0,0 +1,112 @@
+package models
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// MinK8sSyncInterval is the shortest interval at which MeshSync may be asked to resync the resources of a cluster,
+// shorter intervals would flood the broker and the database with the whole informer store.
+const MinK8sSyncInterval = 30 * time.Second
+
+// ParseK8sSyncInterval parses the sync interval of a connection given as a Go duration (e.g. "5m"),
+// an empty interval is valid and means MeshSync is not asked to resync periodically.
+func ParseK8sSyncInterval(interval string) (time.Duration, error) {
+	if interval == "" {
+		return 0, nil
+	}
+	v, err := time.ParseDuration(interval)
+	if err != nil {
+		return 0, ErrInvalidK8sSyncInterval(err, interval)
+	}
+	if v < MinK8sSyncInterval {
+		return 0, ErrInvalidK8sSyncInterval(fmt.Errorf("sync interval must be at least %s", MinK8sSyncInterval), interval)
+	}
+	return v, nil
+}
+
+// MeshSyncInterval returns the interval at which the resources of the cluster of the context are resynced,
+// 0 when the context has none (or an invalid one stored before it was validated).
+func (kc K8sContext) MeshSyncInterval() time.Duration {
+	v, err := ParseK8sSyncInterval(kc.SyncInterval)
+	if err != nil {
+		return 0
+	}
+	return v
+}
+
+// meshsyncResync holds the sync interval of a MeshSync data handler, shared by the copies of the handler.
+// ctx is cancelled when the handler is stopped.
+type meshsyncResync struct {
+	mu       sync.Mutex
+	interval time.Duration
+	changed  chan struct{}
+	ctx      context.Context
+	cancel   context.CancelFunc
+}
+
+func newMeshsyncResync(interval time.Duration) *meshsyncResync {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &meshsyncResync{interval: interval, changed: make(chan struct{}, 1), ctx: ctx, cancel: cancel}
+}
+
+func (r *meshsyncResync) get() time.Duration {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.interval
+}
+
+func (r *meshsyncResync) set(interval time.Duration) {
+	r.mu.Lock()
+	r.interval = interval
+	r.mu.Unlock()
+	select {
+	case r.changed <- struct{}{}:
+	default:
+	}
+}
+
+// SetSyncInterval changes the interval at which MeshSync is asked for its store, 0 stops the periodic resync
+func (mh *MeshsyncDataHandler) SetSyncInterval(interval time.Duration) {
+	mh.resync.set(interval)
+}
+
+// Stop stops the periodic resync of the handler and closes its connection to the broker
+func (mh *MeshsyncDataHandler) Stop() {
+	if mh.resync != nil {
+		mh.resync.cancel()
+	}
+	if mh.broker != nil {
+		mh.broker.CloseConnection()
+	}
+}
+
+// resyncPeriodically asks MeshSync for its store every sync interval, picking up the changes of the interval,
+// until the handler is stopped
+func (mh *MeshsyncDataHandler) resyncPeriodically() {
+	for {
+		interval := mh.resync.get()
+		if interval <= 0 {
+			select {
+			case <-mh.resync.changed:
+				continue
+			case <-mh.resync.ctx.Done():
+				return
+			}
+		}
+		timer := time.NewTimer(interval)
+		select {
+		case <-timer.C:
+			if err := mh.requestMeshsyncStore(); err != nil {
+				mh.log.Error(err)
+			}
+		case <-mh.resync.changed:
+			timer.Stop()
+		case <-mh.resync.ctx.Done():
+			timer.Stop()
+			return
+		}
+	}
+}
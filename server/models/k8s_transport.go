@@ -0,0 +1,192 @@
+package models
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptrace"
+	"sync"
+	"time"
+
+	"github.com/spf13/viper"
+	"k8s.io/client-go/rest"
+)
+
+// Default timeouts of the transport to the API servers, the same as the ones of client-go.
+// A response header timeout of 0 waits for the response headers as long as the request is not cancelled.
+const (
+	DefaultK8sDialTimeout           = 30 * time.Second
+	DefaultK8sTLSHandshakeTimeout   = 10 * time.Second
+	DefaultK8sResponseHeaderTimeout = 0
+)
+
+// K8sTransportTimeouts are the timeouts of the transport to the API server of a context.
+type K8sTransportTimeouts struct {
+	Dial           time.Duration
+	TLSHandshake   time.Duration
+	ResponseHeader time.Duration
+}
+
+// TransportTimeouts resolves the timeouts of the transport to the API server of the context,
+// the timeouts set on the context take precedence over the "KUBERNETES_*_TIMEOUT" defaults.
+func (kc *K8sContext) TransportTimeouts() (K8sTransportTimeouts, error) {
+	timeouts := K8sTransportTimeouts{
+		Dial:           viper.GetDuration("KUBERNETES_DIAL_TIMEOUT"),
+		TLSHandshake:   viper.GetDuration("KUBERNETES_TLS_HANDSHAKE_TIMEOUT"),
+		ResponseHeader: viper.GetDuration("KUBERNETES_RESPONSE_HEADER_TIMEOUT"),
+	}
+	overrides := []struct {
+		name  string
+		value string
+		into  *time.Duration
+	}{
+		{"dial_timeout", kc.DialTimeout, &timeouts.Dial},
+		{"tls_handshake_timeout", kc.TLSHandshakeTimeout, &timeouts.TLSHandshake},
+		{"response_header_timeout", kc.ResponseHeaderTimeout, &timeouts.ResponseHeader},
+	}
+	for _, override := range overrides {
+		if override.value == "" {
+			continue
+		}
+		v, err := ParseK8sTransportTimeout(override.name, override.value)
+		if err != nil {
+			return timeouts, err
+		}
+		*override.into = v
+	}
+	return timeouts, nil
+}
+
+// ParseK8sTransportTimeout parses the named transport timeout given as a Go duration (e.g. "5s").
+func ParseK8sTransportTimeout(name, value string) (time.Duration, error) {
+	v, err := time.ParseDuration(value)
+	if err != nil {
+		return 0, ErrInvalidTransportTimeout(err, name, value)
+	}
+	if v < 0 {
+		return 0, ErrInvalidTransportTimeout(fmt.Errorf("timeout must not be negative"), name, value)
+	}
+	return v, nil
+}
+
+// configureTransport applies the transport timeouts of the context to the rest config.
+// Setting the proxy keeps client-go from sharing the transport with the other clients of the same TLS config,
+// the transport is hence owned by the client generated from the rest config.
+func (kc *K8sContext) configureTransport(restConfig *rest.Config) error {
+	timeouts, err := kc.TransportTimeouts()
+	if err != nil {
+		return err
+	}
+
+	if restConfig.Proxy == nil {
+		restConfig.Proxy = http.ProxyFromEnvironment
+	}
+	restConfig.Dial = (&net.Dialer{
+		Timeout:   timeouts.Dial,
+		KeepAlive: 30 * time.Second,
+	}).DialContext
+	restConfig.Wrap(func(rt http.RoundTripper) http.RoundTripper {
+		transport, ok := rt.(*http.Transport)
+		if !ok {
+			return rt
+		}
+		transport = transport.Clone()
+		transport.TLSHandshakeTimeout = timeouts.TLSHandshake
+		transport.ResponseHeaderTimeout = timeouts.ResponseHeader
+		return transport
+	})
+	return nil
+}
+
+// configureTLSServerName verifies the certificate of the API server against the TLS server name of the context, if any,
+// instead of the host of the server URL (or the tls-server-name of the kubeconfig).
+func (kc *K8sContext) configureTLSServerName(restConfig *rest.Config) {
+	if kc.TLSServerName != "" {
+		restConfig.TLSClientConfig.ServerName = kc.TLSServerName
+	}
+}
+
+// K8sPhaseTimings are the durations, in milliseconds, of the phases of a request to an API server.
+// The DNS, connect and TLS handshake phases are absent when an idle connection is reused.
+type K8sPhaseTimings struct {
+	DNSMs          float64 `json:"dns_ms,omitempty"`
+	ConnectMs      float64 `json:"connect_ms,omitempty"`
+	TLSHandshakeMs float64 `json:"tls_handshake_ms,omitempty"`
+	// FirstByteMs is the wait for the first byte of the response once the request was written.
+	FirstByteMs float64 `json:"first_byte_ms,omitempty"`
+	TotalMs     float64 `json:"total_ms"`
+	ReusedConn  bool    `json:"reused_conn,omitempty"`
+}
+
+// k8sPhaseTrace measures the phases of the requests made with the context returned by withTrace.
+// The callbacks of the trace are invoked from the goroutines of the transport.
+type k8sPhaseTrace struct {
+	mu                                    sync.Mutex
+	start                                 time.Time
+	dnsStart, connectStart, tlsStart      time.Time
+	wroteRequest                          time.Time
+	dns, connect, tlsHandshake, firstByte time.Duration
+	reused                                bool
+}
+
+func newK8sPhaseTrace() *k8sPhaseTrace {
+	return &k8sPhaseTrace{start: time.Now()}
+}
+
+func (t *k8sPhaseTrace) withTrace(ctx context.Context) context.Context {
+	since := func(from *time.Time, into *time.Duration) {
+		t.mu.Lock()
+		defer t.mu.Unlock()
+		if !from.IsZero() && *into == 0 {
+			*into = time.Since(*from)
+		}
+	}
+	now := func(into *time.Time) {
+		t.mu.Lock()
+		defer t.mu.Unlock()
+		if into.IsZero() {
+			*into = time.Now()
+		}
+	}
+	return httptrace.WithClientTrace(ctx, &httptrace.ClientTrace{
+		DNSStart:     func(httptrace.DNSStartInfo) { now(&t.dnsStart) },
+		DNSDone:      func(httptrace.DNSDoneInfo) { since(&t.dnsStart, &t.dns) },
+		ConnectStart: func(_, _ string) { now(&t.connectStart) },
+		ConnectDone: func(_, _ string, err error) {
+			if err == nil {
+				since(&t.connectStart, &t.connect)
+			}
+		},
+		TLSHandshakeStart: func() { now(&t.tlsStart) },
+		TLSHandshakeDone: func(_ tls.ConnectionState, err error) {
+			if err == nil {
+				since(&t.tlsStart, &t.tlsHandshake)
+			}
+		},
+		GotConn: func(info httptrace.GotConnInfo) {
+			t.mu.Lock()
+			defer t.mu.Unlock()
+			t.reused = info.Reused
+		},
+		WroteRequest:         func(httptrace.WroteRequestInfo) { now(&t.wroteRequest) },
+		GotFirstResponseByte: func() { since(&t.wroteRequest, &t.firstByte) },
+	})
+}
+
+func (t *k8sPhaseTrace) timings() *K8sPhaseTimings {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	ms := func(d time.Duration) float64 {
+		return float64(d) / float64(time.Millisecond)
+	}
+	return &K8sPhaseTimings{
+		DNSMs:          ms(t.dns),
+		ConnectMs:      ms(t.connect),
+		TLSHandshakeMs: ms(t.tlsHandshake),
+		FirstByteMs:    ms(t.firstByte),
+		TotalMs:        ms(time.Since(t.start)),
+		ReusedConn:     t.reused,
+	}
+}
@@ -43,6 +43,8 @@ type MesheryControllersHelper struct {
 	ctxOperatorStatusMap map[string]controllers.MesheryControllerStatus
 	// maps each context with a meshsync data handler
 	ctxMeshsyncDataHandlerMap map[string]MeshsyncDataHandler
+	// maps each context with the latest interval its meshsync data handler resyncs at
+	ctxMeshsyncIntervalMap map[string]time.Duration
 
 	mu sync.Mutex
 
@@ -70,6 +72,7 @@ func NewMesheryControllersHelper(log logger.Handler, operatorDepConfig controlle
 		oprDepConfig:              operatorDepConfig,
 		ctxOperatorStatusMap:      make(map[string]controllers.MesheryControllerStatus),
 		ctxMeshsyncDataHandlerMap: make(map[string]MeshsyncDataHandler),
+		ctxMeshsyncIntervalMap:    make(map[string]time.Duration),
 		dbHandler:                 dbHandler,
 	}
 }
@@ -77,14 +80,25 @@ func NewMesheryControllersHelper(log logger.Handler, operatorDepConfig controlle
 // initializes Meshsync data handler for the contexts for whom it has not been
 // initialized yet. Apart from updating the map, it also runs the handler after
 // updating the map. The presence of a handler for a context in a map indicate that
-// the meshsync data for that context is properly being handled
-func (mch *MesheryControllersHelper) UpdateMeshsynDataHandlers(ctx context.Context, connectionID, userID, mesheryInstanceID uuid.UUID, provider Provider) *MesheryControllersHelper {
+// the meshsync data for that context is properly being handled.
+// MeshSync of the context k8sContextID is asked for its store every syncInterval, never again when 0, unless
+// the interval was changed since through SetMeshsyncInterval. The interval is applied to its handler if already running.
+func (mch *MesheryControllersHelper) UpdateMeshsynDataHandlers(ctx context.Context, k8sContextID string, connectionID, userID, mesheryInstanceID uuid.UUID, provider Provider, syncInterval time.Duration) *MesheryControllersHelper {
 	// only checking those contexts whose MesheryConrollers are active
 	go func(mch *MesheryControllersHelper) {
 		mch.mu.Lock()
 		defer mch.mu.Unlock()
+		if interval, ok := mch.ctxMeshsyncIntervalMap[k8sContextID]; ok {
+			syncInterval = interval
+		} else {
+			mch.ctxMeshsyncIntervalMap[k8sContextID] = syncInterval
+		}
 		for ctxID, controllerHandlers := range mch.ctxControllerHandlersMap {
-			if _, ok := mch.ctxMeshsyncDataHandlerMap[ctxID]; !ok {
+			if msDataHandler, ok := mch.ctxMeshsyncDataHandlerMap[ctxID]; ok {
+				if ctxID == k8sContextID {
+					msDataHandler.SetSyncInterval(syncInterval)
+				}
+			} else {
 				// brokerStatus := controllerHandlers[MesheryBroker].GetStatus()
 				// do something if broker is being deployed , maybe try again after sometime
 				brokerEndpoint, err := controllerHandlers[MesheryBroker].GetPublicEndpoint()
@@ -113,6 +127,7 @@ func (mch *MesheryControllersHelper) UpdateMeshsynDataHandlers(ctx context.Conte
 				mch.log.Info(fmt.Sprintf("Connected to Meshery Broker (%v) for Kubernetes context (%v)", brokerEndpoint, ctxID))
 				token, _ := ctx.Value(TokenCtxKey).(string)
 				msDataHandler := NewMeshsyncDataHandler(brokerHandler, *mch.dbHandler, mch.log, provider, userID, connectionID, mesheryInstanceID, token)
+				msDataHandler.SetSyncInterval(mch.ctxMeshsyncIntervalMap[ctxID])
 				err = msDataHandler.Run()
 				if err != nil {
 					mch.log.Warn(err)
@@ -128,6 +143,35 @@ func (mch *MesheryControllersHelper) UpdateMeshsynDataHandlers(ctx context.Conte
 	return mch
 }
 
+// SetMeshsyncInterval changes the interval at which MeshSync is asked for its store for the context,
+// applied to the MeshSync data handler of the context right away if running, else once it is attached.
+func (mch *MesheryControllersHelper) SetMeshsyncInterval(ctxID string, syncInterval time.Duration) {
+	mch.mu.Lock()
+	defer mch.mu.Unlock()
+	mch.ctxMeshsyncIntervalMap[ctxID] = syncInterval
+	if msDataHandler, ok := mch.ctxMeshsyncDataHandlerMap[ctxID]; ok {
+		msDataHandler.SetSyncInterval(syncInterval)
+	}
+}
+
+// MeshsyncInterval returns the latest interval at which MeshSync of the context is asked for its store
+func (mch *MesheryControllersHelper) MeshsyncInterval(ctxID string) time.Duration {
+	mch.mu.Lock()
+	defer mch.mu.Unlock()
+	return mch.ctxMeshsyncIntervalMap[ctxID]
+}
+
+// RemoveMeshsyncDataHandler stops the meshsync data handler of the context, if any, along with its periodic resync
+// and its connection to the broker. A handler is attached anew when the context is connected again.
+func (mch *MesheryControllersHelper) RemoveMeshsyncDataHandler(ctxID string) {
+	mch.mu.Lock()
+	defer mch.mu.Unlock()
+	if msDataHandler, ok := mch.ctxMeshsyncDataHandlerMap[ctxID]; ok {
+		msDataHandler.Stop()
+		delete(mch.ctxMeshsyncDataHandlerMap, ctxID)
+	}
+}
+
 // attach a MesheryController for each context if
 // 1. the config is valid
 // 2. if it is not already attached
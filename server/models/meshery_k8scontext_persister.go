@@ -3,7 +3,9 @@ package models
 import (
 	"encoding/json"
 	"strings"
+	"time"
 
+	"github.com/layer5io/meshery/server/internal/sql"
 	"github.com/layer5io/meshery/server/models/connections"
 	"github.com/layer5io/meshkit/database"
 	"gorm.io/gorm"
@@ -23,6 +25,36 @@ type MesheryK8sContextPage struct {
 	Contexts   []*K8sContext `json:"contexts"`
 }
 
+// Keys of the credentials of the proxy within the credentials of the persisted contexts
+const (
+	k8sContextProxyUsernameKey = "proxy_username"
+	k8sContextProxyPasswordKey = "proxy_password"
+)
+
+// BeforeSave keeps the credentials of the proxy with the credentials of the context rather than in columns of their own
+func (kc *K8sContext) BeforeSave(_ *gorm.DB) error {
+	if kc.ProxyUsername == "" && kc.ProxyPassword == "" {
+		return nil
+	}
+	// the map of the credentials is shared with the context being saved
+	auth := make(sql.Map, len(kc.Auth)+2)
+	for k, v := range kc.Auth {
+		auth[k] = v
+	}
+	auth[k8sContextProxyUsernameKey], auth[k8sContextProxyPasswordKey] = kc.ProxyUsername, kc.ProxyPassword
+	kc.Auth = auth
+	return nil
+}
+
+// AfterFind restores the credentials of the proxy kept with the credentials of the context, see BeforeSave
+func (kc *K8sContext) AfterFind(_ *gorm.DB) error {
+	kc.ProxyUsername, _ = kc.Auth[k8sContextProxyUsernameKey].(string)
+	kc.ProxyPassword, _ = kc.Auth[k8sContextProxyPasswordKey].(string)
+	delete(kc.Auth, k8sContextProxyUsernameKey)
+	delete(kc.Auth, k8sContextProxyPasswordKey)
+	return nil
+}
+
 // GetMesheryK8sContexts returns all of the contexts
 func (mkcp *MesheryK8sContextPersister) GetMesheryK8sContexts(search, order string, page, pageSize uint64) ([]byte, error) {
 	order = SanitizeOrderInput(order, []string{"created_at", "updated_at", "name"})
@@ -34,7 +66,8 @@ func (mkcp *MesheryK8sContextPersister) GetMesheryK8sContexts(search, order stri
 	count := int64(0)
 	contexts := []*K8sContext{}
 
-	query := mkcp.DB.Order(order)
+	// the deleted contexts are in the recycle bin, see GetDeletedMesheryK8sContexts
+	query := mkcp.DB.Where("deleted_at IS NULL").Order(order)
 
 	if search != "" {
 		like := "%" + strings.ToLower(search) + "%"
@@ -76,16 +109,25 @@ func (mkcp *MesheryK8sContextPersister) SaveMesheryK8sContext(mkc K8sContext) (c
 	}
 
 	// Perform the operation in a transaction
+	persisted := false
 	err := mkcp.DB.Transaction(func(tx *gorm.DB) error {
 		var mesheryK8sContext K8sContext
 
 		// Check if there is already an entry for this context
 		if err := tx.First(&mesheryK8sContext, "id = ?", mkc.ID).Error; err == nil {
-			return ErrContextAlreadyPersisted
+			persisted = true
+			// saving a deleted context again restores it, committed before reporting it as already persisted
+			if mesheryK8sContext.DeletedAt != nil {
+				return tx.Model(&mesheryK8sContext).Update("deleted_at", nil).Error
+			}
+			return nil
 		}
 
 		return tx.Save(&mkc).Error
 	})
+	if err == nil && persisted {
+		err = ErrContextAlreadyPersisted
+	}
 
 	return conn, err
 }
@@ -97,6 +139,96 @@ func (mkcp *MesheryK8sContextPersister) GetMesheryK8sContext(id string) (K8sCont
 	return mesheryK8sContext, err
 }
 
+// SetMesheryK8sContextPinned pins or unpins the context
+func (mkcp *MesheryK8sContextPersister) SetMesheryK8sContextPinned(id string, pinned bool) (K8sContext, error) {
+	var mesheryK8sContext K8sContext
+	if err := mkcp.DB.First(&mesheryK8sContext, "id = ?", id).Error; err != nil {
+		return mesheryK8sContext, err
+	}
+
+	mesheryK8sContext.Pinned = pinned
+	err := mkcp.DB.Model(&mesheryK8sContext).Update("pinned", pinned).Error
+	return mesheryK8sContext, err
+}
+
+// SetMesheryK8sContextNotes replaces the notes of the context
+func (mkcp *MesheryK8sContextPersister) SetMesheryK8sContextNotes(id, notes string) (K8sContext, error) {
+	var mesheryK8sContext K8sContext
+	if err := mkcp.DB.First(&mesheryK8sContext, "id = ?", id).Error; err != nil {
+		return mesheryK8sContext, err
+	}
+
+	mesheryK8sContext.Notes = notes
+	err := mkcp.DB.Model(&mesheryK8sContext).Update("notes", notes).Error
+	return mesheryK8sContext, err
+}
+
+// SetMesheryK8sContextSyncInterval replaces the sync interval of the context
+func (mkcp *MesheryK8sContextPersister) SetMesheryK8sContextSyncInterval(id, syncInterval string) (K8sContext, error) {
+	var mesheryK8sContext K8sContext
+	if err := mkcp.DB.First(&mesheryK8sContext, "id = ?", id).Error; err != nil {
+		return mesheryK8sContext, err
+	}
+
+	mesheryK8sContext.SyncInterval = syncInterval
+	err := mkcp.DB.Model(&mesheryK8sContext).Update("sync_interval", syncInterval).Error
+	return mesheryK8sContext, err
+}
+
+// PatchMesheryK8sContextsLabels patches the labels of the contexts in a single transaction,
+// the labels of none of the contexts are updated when those of one of them could not be
+func (mkcp *MesheryK8sContextPersister) PatchMesheryK8sContextsLabels(ids []string, patch K8sContextLabelsPatch) []K8sContextLabelsResult {
+	results := make([]K8sContextLabelsResult, len(ids))
+	failedID := ""
+	_ = mkcp.DB.Transaction(func(tx *gorm.DB) error {
+		for i, id := range ids {
+			results[i].ID = id
+			var mesheryK8sContext K8sContext
+			err := tx.First(&mesheryK8sContext, "id = ? AND deleted_at IS NULL", id).Error
+			if err == nil {
+				mesheryK8sContext.Labels = patch.Apply(mesheryK8sContext.Labels)
+				err = tx.Model(&mesheryK8sContext).Select("labels").Updates(&mesheryK8sContext).Error
+			}
+			results[i].Context, results[i].Err = mesheryK8sContext, err
+			if err != nil && failedID == "" {
+				failedID = id
+			}
+		}
+		if failedID != "" {
+			return ErrK8sContextLabelsNotApplied(failedID)
+		}
+		return nil
+	})
+
+	if failedID != "" {
+		for i := range results {
+			if results[i].Err == nil {
+				results[i].Err = ErrK8sContextLabelsNotApplied(failedID)
+			}
+		}
+	}
+	return results
+}
+
+// SetMesheryK8sContextDeletedAt marks the context as deleted at deletedAt, or restores it when nil
+func (mkcp *MesheryK8sContextPersister) SetMesheryK8sContextDeletedAt(id string, deletedAt *time.Time) (K8sContext, error) {
+	var mesheryK8sContext K8sContext
+	if err := mkcp.DB.First(&mesheryK8sContext, "id = ?", id).Error; err != nil {
+		return mesheryK8sContext, err
+	}
+
+	mesheryK8sContext.DeletedAt = deletedAt
+	err := mkcp.DB.Model(&mesheryK8sContext).Update("deleted_at", deletedAt).Error
+	return mesheryK8sContext, err
+}
+
+// GetDeletedMesheryK8sContexts returns the contexts in the recycle bin, most recently deleted first
+func (mkcp *MesheryK8sContextPersister) GetDeletedMesheryK8sContexts() ([]*K8sContext, error) {
+	contexts := []*K8sContext{}
+	err := mkcp.DB.Where("deleted_at IS NOT NULL").Order("deleted_at desc").Find(&contexts).Error
+	return contexts, err
+}
+
 // func (mkcp *MesheryK8sContextPersister) SetMesheryK8sCurrentContext(id string) error {
 // 	// Perform the operation in a transaction
 // 	return mkcp.DB.Transaction(func(tx *gorm.DB) error {
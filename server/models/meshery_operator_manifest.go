@@ -0,0 +1,143 @@
+package models
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/spf13/viper"
+	"helm.sh/helm/v3/pkg/action"
+	"helm.sh/helm/v3/pkg/chart"
+	"helm.sh/helm/v3/pkg/chart/loader"
+	"helm.sh/helm/v3/pkg/chartutil"
+	"helm.sh/helm/v3/pkg/cli"
+	"helm.sh/helm/v3/pkg/getter"
+	"helm.sh/helm/v3/pkg/repo"
+)
+
+// Release of the Meshery Operator chart, which deploys the operator along with Meshery Broker and MeshSync
+const (
+	OperatorChart       = "meshery-operator"
+	OperatorReleaseName = "meshery-operator"
+	OperatorNamespace   = "meshery"
+)
+
+// OperatorManifest is the manifest Meshery applies to deploy its operator to a cluster, for the admins to apply it themselves
+type OperatorManifest struct {
+	KubernetesVersion string `json:"kubernetes_version"`
+	ChartVersion      string `json:"chart_version"`
+	Namespace         string `json:"namespace"`
+	// Manifest is the multi-document YAML of the namespace and of the resources of the operator, Meshery Broker and MeshSync
+	Manifest string `json:"manifest"`
+}
+
+// OperatorManifest renders the Meshery Operator chart of the release of Meshery, with the overrides it is deployed with,
+// for a cluster of the given version. The chart is fetched from its repository once per chart version, the latest chart
+// of the builds without a release version is cached for "OPERATOR_CHART_CACHE_TTL".
+func (mch *MesheryControllersHelper) OperatorManifest(kubeVersion string) (*OperatorManifest, error) {
+	chrt, err := operatorCharts.load(mch.oprDepConfig.HelmChartRepo, mch.oprDepConfig.MesheryReleaseVersion)
+	if err != nil {
+		return nil, ErrRenderOperatorManifest(err, kubeVersion)
+	}
+	overrides := map[string]interface{}{}
+	if mch.oprDepConfig.GetHelmOverrides != nil {
+		overrides = mch.oprDepConfig.GetHelmOverrides(false)
+	}
+	manifest, err := RenderOperatorManifest(chrt, kubeVersion, overrides)
+	if err != nil {
+		return nil, ErrRenderOperatorManifest(err, kubeVersion)
+	}
+	return manifest, nil
+}
+
+// RenderOperatorManifest renders the chart as installed in OperatorNamespace on a cluster of the given version,
+// the version of the Kubernetes libraries of Meshery is assumed when empty
+func RenderOperatorManifest(chrt *chart.Chart, kubeVersion string, values map[string]interface{}) (*OperatorManifest, error) {
+	install := action.NewInstall(&action.Configuration{})
+	install.ReleaseName = OperatorReleaseName
+	install.Namespace = OperatorNamespace
+	install.DryRun, install.ClientOnly, install.IncludeCRDs = true, true, true
+	if kubeVersion != "" {
+		kv, err := chartutil.ParseKubeVersion(kubeVersion)
+		if err != nil {
+			return nil, err
+		}
+		install.KubeVersion = kv
+	}
+	rel, err := install.Run(chrt, values)
+	if err != nil {
+		return nil, err
+	}
+
+	// The namespace is created along with the release by Meshery, it is not part of the rendered chart.
+	namespace := fmt.Sprintf("apiVersion: v1\nkind: Namespace\nmetadata:\n  name: %s\n", OperatorNamespace)
+	return &OperatorManifest{
+		KubernetesVersion: kubeVersion,
+		ChartVersion:      chrt.Metadata.Version,
+		Namespace:         OperatorNamespace,
+		Manifest:          namespace + "---\n" + strings.TrimSpace(rel.Manifest) + "\n",
+	}, nil
+}
+
+// operatorCharts caches the Meshery Operator charts by repository and version, the charts of a version never change
+var operatorCharts = &operatorChartCache{charts: make(map[string]operatorChart)}
+
+type operatorChartCache struct {
+	mx     sync.Mutex
+	charts map[string]operatorChart
+}
+
+type operatorChart struct {
+	chart *chart.Chart
+	// expires is when the latest chart is fetched again, zero for the charts of a version
+	expires time.Time
+}
+
+// load returns the chart of the version, the latest one when empty, fetching it from the repository unless cached.
+// The latest chart changes with the releases, it is cached for "OPERATOR_CHART_CACHE_TTL" only.
+func (oc *operatorChartCache) load(repoURL, version string) (*chart.Chart, error) {
+	key := repoURL + "/" + version
+	oc.mx.Lock()
+	cached, ok := oc.charts[key]
+	oc.mx.Unlock()
+	if ok && (cached.expires.IsZero() || time.Now().Before(cached.expires)) {
+		return cached.chart, nil
+	}
+
+	getters := getter.All(cli.New())
+	chartURL, err := repo.FindChartInRepoURL(repoURL, OperatorChart, version, "", "", "", getters)
+	if err != nil {
+		return nil, err
+	}
+	u, err := url.Parse(chartURL)
+	if err != nil {
+		return nil, err
+	}
+	chartGetter, err := getters.ByScheme(u.Scheme)
+	if err != nil {
+		return nil, err
+	}
+	archive, err := chartGetter.Get(chartURL)
+	if err != nil {
+		return nil, err
+	}
+	chrt, err := loader.LoadArchive(archive)
+	if err != nil {
+		return nil, err
+	}
+
+	cached = operatorChart{chart: chrt}
+	if version == "" {
+		ttl := viper.GetDuration("OPERATOR_CHART_CACHE_TTL")
+		if ttl <= 0 {
+			return chrt, nil
+		}
+		cached.expires = time.Now().Add(ttl)
+	}
+	oc.mx.Lock()
+	oc.charts[key] = cached
+	oc.mx.Unlock()
+	return chrt, nil
+}
@@ -0,0 +1,111 @@
+package models
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/spf13/viper"
+	"helm.sh/helm/v3/pkg/chart"
+	"helm.sh/helm/v3/pkg/chartutil"
+	"helm.sh/helm/v3/pkg/repo"
+)
+
+func TestRenderOperatorManifest(t *testing.T) {
+	chrt := &chart.Chart{
+		Metadata: &chart.Metadata{APIVersion: chart.APIVersionV2, Name: OperatorChart, Version: "v0.7.0"},
+		Values:   map[string]interface{}{"replicas": 1},
+		Templates: []*chart.File{{
+			Name: "templates/deployment.yaml",
+			Data: []byte(`apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: {{ .Release.Name }}
+  namespace: {{ .Release.Namespace }}
+  annotations:
+    kube-version: {{ .Capabilities.KubeVersion.Version }}
+spec:
+  replicas: {{ .Values.replicas }}
+`),
+		}},
+	}
+
+	manifest, err := RenderOperatorManifest(chrt, "v1.27.3", map[string]interface{}{"replicas": 2})
+	if err != nil {
+		t.Fatalf("RenderOperatorManifest() failed with error: %s", err)
+	}
+	if manifest.ChartVersion != "v0.7.0" || manifest.Namespace != OperatorNamespace || manifest.KubernetesVersion != "v1.27.3" {
+		t.Errorf("RenderOperatorManifest() = %+v, want the chart v0.7.0 rendered in %s for v1.27.3", manifest, OperatorNamespace)
+	}
+	for _, want := range []string{"kind: Namespace", "name: meshery-operator", "namespace: meshery", "kube-version: v1.27.3", "replicas: 2"} {
+		if !strings.Contains(manifest.Manifest, want) {
+			t.Errorf("manifest lacks %q:\n%s", want, manifest.Manifest)
+		}
+	}
+
+	if _, err := RenderOperatorManifest(chrt, "not a version", nil); err == nil {
+		t.Error("RenderOperatorManifest() expected an error for an invalid Kubernetes version")
+	}
+}
+
+func TestOperatorChartCacheLoad(t *testing.T) {
+	dir := t.TempDir()
+	archive, err := chartutil.Save(&chart.Chart{
+		Metadata: &chart.Metadata{APIVersion: chart.APIVersionV2, Name: OperatorChart, Version: "v0.7.0"},
+	}, dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var archiveRequests int32
+	mux := http.NewServeMux()
+	// the repository is served over plain http, the getter is picked from the scheme of the chart URL
+	ts := httptest.NewServer(mux)
+	defer ts.Close()
+	index := repo.NewIndexFile()
+	if err := index.MustAdd(&chart.Metadata{APIVersion: chart.APIVersionV2, Name: OperatorChart, Version: "v0.7.0"}, filepath.Base(archive), ts.URL, ""); err != nil {
+		t.Fatal(err)
+	}
+	if err := index.WriteFile(filepath.Join(dir, "index.yaml"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	mux.HandleFunc("/index.yaml", func(w http.ResponseWriter, r *http.Request) {
+		http.ServeFile(w, r, filepath.Join(dir, "index.yaml"))
+	})
+	mux.HandleFunc("/"+filepath.Base(archive), func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&archiveRequests, 1)
+		data, _ := os.ReadFile(archive)
+		_, _ = w.Write(data)
+	})
+
+	viper.Set("OPERATOR_CHART_CACHE_TTL", time.Hour)
+	defer viper.Set("OPERATOR_CHART_CACHE_TTL", nil)
+	cache := &operatorChartCache{charts: make(map[string]operatorChart)}
+	for i := 0; i < 2; i++ {
+		chrt, err := cache.load(ts.URL, "")
+		if err != nil {
+			t.Fatalf("load() failed with error: %s", err)
+		}
+		if chrt.Metadata.Version != "v0.7.0" {
+			t.Errorf("load() = chart %s, want v0.7.0", chrt.Metadata.Version)
+		}
+	}
+	if n := atomic.LoadInt32(&archiveRequests); n != 1 {
+		t.Errorf("chart fetched %d times, want the latest chart cached", n)
+	}
+
+	// the latest chart is fetched again once its ttl elapsed
+	cached := cache.charts[ts.URL+"/"]
+	cached.expires = time.Now().Add(-time.Second)
+	cache.charts[ts.URL+"/"] = cached
+	if _, err := cache.load(ts.URL, ""); err != nil {
+		t.Fatalf("load() failed with error: %s", err)
+	}
+	if n := atomic.LoadInt32(&archiveRequests); n != 2 {
+		t.Errorf("chart fetched %d times, want the expired chart fetched again", n)
+	}
+}
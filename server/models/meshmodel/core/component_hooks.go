@@ -0,0 +1,70 @@
+package core
+
+import (
+	"sort"
+	"sync"
+
+	"github.com/layer5io/meshkit/models/meshmodel/core/v1alpha1"
+)
+
+// K8sComponentHook is invoked on each component generated for a cluster right before it is registered, once its metadata is written.
+// It may modify the component, e.g. to enforce the naming and metadata policies of an organization, or reject it by returning an error,
+// in which case the component is not registered and the error is reported as the reason it was rejected.
+// Hooks are called concurrently by the registrations of different contexts.
+type K8sComponentHook interface {
+	BeforeRegister(ctxID string, comp *v1alpha1.ComponentDefinition) error
+}
+
+// K8sComponentHookFunc adapts a function to a K8sComponentHook
+type K8sComponentHookFunc func(ctxID string, comp *v1alpha1.ComponentDefinition) error
+
+// BeforeRegister calls f(ctxID, comp)
+func (f K8sComponentHookFunc) BeforeRegister(ctxID string, comp *v1alpha1.ComponentDefinition) error {
+	return f(ctxID, comp)
+}
+
+// NoopK8sComponentHook registers the components as they are generated, it is the hook used unless one is configured
+type NoopK8sComponentHook struct{}
+
+// BeforeRegister leaves the component as is
+func (NoopK8sComponentHook) BeforeRegister(string, *v1alpha1.ComponentDefinition) error {
+	return nil
+}
+
+var (
+	k8sComponentHookMu sync.RWMutex
+	k8sComponentHook   K8sComponentHook = NoopK8sComponentHook{}
+)
+
+// SetK8sComponentHook configures the hook invoked by every registration of the components of a cluster which is not given one
+// through K8sComponentsRegistrationOptions, a nil hook restores the no-op one.
+func SetK8sComponentHook(hook K8sComponentHook) {
+	if hook == nil {
+		hook = NoopK8sComponentHook{}
+	}
+	k8sComponentHookMu.Lock()
+	defer k8sComponentHookMu.Unlock()
+	k8sComponentHook = hook
+}
+
+// configuredK8sComponentHook returns the hook configured through SetK8sComponentHook
+func configuredK8sComponentHook() K8sComponentHook {
+	k8sComponentHookMu.RLock()
+	defer k8sComponentHookMu.RUnlock()
+	return k8sComponentHook
+}
+
+// rejectedComponents returns the components which were rejected by the hook, ordered by apiVersion and kind
+func (r *k8sComponentsRegistration) rejectedComponents() []ComponentRegistrationFailure {
+	rejected := make([]ComponentRegistrationFailure, 0, len(r.rejected))
+	for _, f := range r.rejected {
+		rejected = append(rejected, f)
+	}
+	sort.Slice(rejected, func(i, j int) bool {
+		if rejected[i].APIVersion != rejected[j].APIVersion {
+			return rejected[i].APIVersion < rejected[j].APIVersion
+		}
+		return rejected[i].Kind < rejected[j].Kind
+	})
+	return rejected
+}
@@ -0,0 +1,76 @@
+package core
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// ParseK8sComponentKinds parses the comma separated group/kind pairs of the kinds to register as components,
+// e.g. "apps/Deployment,networking.k8s.io/Ingress,Pod". A kind without a group, or with the "core" group, is of the core API group.
+// Returns the kinds as group/kind, or as the kind alone for the core API group.
+func ParseK8sComponentKinds(kinds string) ([]string, error) {
+	parsed := []string{}
+	seen := make(map[string]bool)
+	for _, kind := range strings.Split(kinds, ",") {
+		kind = strings.TrimSpace(kind)
+		if kind == "" {
+			continue
+		}
+		group, name := "", kind
+		if i := strings.LastIndex(kind, "/"); i >= 0 {
+			group, name = kind[:i], kind[i+1:]
+		}
+		if name == "" || strings.ContainsAny(name, " .") {
+			return nil, ErrInvalidK8sComponentKinds(fmt.Errorf("%q is not a group/kind pair", kind))
+		}
+		key := k8sGroupKind(group, name)
+		if !seen[key] {
+			seen[key] = true
+			parsed = append(parsed, key)
+		}
+	}
+	return parsed, nil
+}
+
+// k8sGroupKind returns the group/kind of a kind, the kind alone for the core API group
+func k8sGroupKind(group, kind string) string {
+	if group == "" || group == "core" {
+		return kind
+	}
+	return group + "/" + kind
+}
+
+// k8sComponentGroupKind returns the group/kind of the component, from its apiVersion and kind
+func k8sComponentGroupKind(apiVersion, kind string) string {
+	group := ""
+	if i := strings.LastIndex(apiVersion, "/"); i >= 0 {
+		group = apiVersion[:i]
+	}
+	return k8sGroupKind(group, kind)
+}
+
+// allowKinds restricts the registration to the given group/kinds, all the kinds are registered when none is given
+func (r *k8sComponentsRegistration) allowKinds(kinds []string) {
+	if len(kinds) == 0 {
+		return
+	}
+	r.kinds = make(map[string]bool, len(kinds))
+	r.foundKinds = make(map[string]bool, len(kinds))
+	for _, kind := range kinds {
+		r.kinds[kind] = true
+	}
+}
+
+// kindsNotFound returns the kinds allowed for the registration which none of the components generated for the cluster were of,
+// e.g. typos or custom resources which are not installed
+func (r *k8sComponentsRegistration) kindsNotFound() []string {
+	notFound := []string{}
+	for kind := range r.kinds {
+		if !r.foundKinds[kind] {
+			notFound = append(notFound, kind)
+		}
+	}
+	sort.Strings(notFound)
+	return notFound
+}
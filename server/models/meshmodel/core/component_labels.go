@@ -0,0 +1,40 @@
+package core
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/layer5io/meshkit/models/meshmodel/core/v1alpha1"
+)
+
+// ParseK8sComponentLabels parses the JSON object of the labels to attach to the registered components,
+// e.g. {"team": "payments", "cost-center": "cc-42"}, so that they can be filtered on in the registry.
+func ParseK8sComponentLabels(labels string) (map[string]string, error) {
+	parsed := make(map[string]string)
+	if err := json.Unmarshal([]byte(labels), &parsed); err != nil {
+		return nil, ErrInvalidK8sComponentLabels(err)
+	}
+	for key := range parsed {
+		if strings.TrimSpace(key) == "" {
+			return nil, ErrInvalidK8sComponentLabels(fmt.Errorf("label with value %q has an empty key", parsed[key]))
+		}
+	}
+	return parsed, nil
+}
+
+// applyK8sComponentLabels merges the labels into the metadata of the component once the model derived metadata is written,
+// a label never overwrites the metadata of the same key taken from the model.
+func applyK8sComponentLabels(comp *v1alpha1.ComponentDefinition, labels map[string]string) {
+	if len(labels) == 0 {
+		return
+	}
+	if comp.Metadata == nil {
+		comp.Metadata = make(map[string]interface{}, len(labels))
+	}
+	for key, value := range labels {
+		if _, ok := comp.Metadata[key]; !ok {
+			comp.Metadata[key] = value
+		}
+	}
+}
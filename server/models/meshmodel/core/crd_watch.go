@@ -0,0 +1,280 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/gofrs/uuid"
+	"github.com/layer5io/meshery/server/models"
+	"github.com/layer5io/meshkit/database"
+	"github.com/layer5io/meshkit/logger"
+	"github.com/layer5io/meshkit/models/events"
+	"github.com/layer5io/meshkit/models/meshmodel/core/v1alpha1"
+	meshmodel "github.com/layer5io/meshkit/models/meshmodel/registry"
+	"github.com/layer5io/meshkit/utils/kubernetes"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/dynamic/dynamicinformer"
+	"k8s.io/client-go/tools/cache"
+)
+
+var crdGVR = schema.GroupVersionResource{Group: "apiextensions.k8s.io", Version: "v1", Resource: "customresourcedefinitions"}
+
+const (
+	// crdWatchSyncTimeout bounds the initial listing of the custom resource definitions when a watch starts
+	crdWatchSyncTimeout = 30 * time.Second
+	// The schema of a new custom resource definition is published asynchronously by the API server,
+	// its components are generated again until they show up in the OpenAPI of the cluster.
+	crdWatchRegistrationAttempts = 5
+	crdWatchRegistrationBackoff  = 2 * time.Second
+)
+
+// K8sComponentsWatchOptions describe the connection whose components are watched and where they are registered
+type K8sComponentsWatchOptions struct {
+	Provider          models.Provider
+	Config            []byte
+	ContextID         string
+	ContextName       string
+	ConnectionID      string
+	UserID            uuid.UUID
+	MesheryInstanceID uuid.UUID
+	Registry          *meshmodel.RegistryManager
+	DB                *database.Handler
+	EventBroadcast    *models.Broadcast
+	Log               logger.Handler
+	// Registration customizes the registration of the components, as for the registration of the connection.
+	// The components are registered against the registry host of the connection when no host is given.
+	Registration K8sComponentsRegistrationOptions
+}
+
+// K8sComponentsWatch registers and unregisters the components of a connection as custom resource definitions
+// are created and deleted in its cluster, instead of registering all of them again.
+type K8sComponentsWatch struct {
+	ConnectionID string    `json:"connection_id"`
+	ContextID    string    `json:"context_id"`
+	StartedAt    time.Time `json:"started_at"`
+
+	opts   K8sComponentsWatchOptions
+	cancel context.CancelFunc
+	// generate generates the components of the cluster of the given group versions
+	generate func(ctx context.Context, kubeconfig []byte, groupVersions map[string]bool, fn func(v1alpha1.ComponentDefinition)) ([]APIGroupDiscoveryFailure, error)
+}
+
+var (
+	k8sComponentsWatches   = make(map[string]*K8sComponentsWatch)
+	k8sComponentsWatchesMx sync.Mutex
+)
+
+// StartK8sComponentsWatch starts watching the custom resource definitions of the cluster of the connection, once the existing
+// ones are listed. Those are left to the registration of the connection, only the ones created or deleted afterwards are handled.
+// Reports false along with the running watch when the connection is watched already, or is being watched as the existing
+// definitions are listed.
+func StartK8sComponentsWatch(opts K8sComponentsWatchOptions) (*K8sComponentsWatch, bool, error) {
+	cli, err := kubernetes.New(opts.Config)
+	if err != nil {
+		return nil, false, ErrWatchK8sCRDs(err, opts.ContextID)
+	}
+	return startK8sComponentsWatch(newK8sComponentsWatch(opts), cli.DynamicKubeClient)
+}
+
+func newK8sComponentsWatch(opts K8sComponentsWatchOptions) *K8sComponentsWatch {
+	return &K8sComponentsWatch{
+		ConnectionID: opts.ConnectionID,
+		ContextID:    opts.ContextID,
+		opts:         opts,
+		generate:     forEachK8sMeshModelComponent,
+	}
+}
+
+func startK8sComponentsWatch(w *K8sComponentsWatch, client dynamic.Interface) (*K8sComponentsWatch, bool, error) {
+	ctx, cancel := context.WithCancel(context.Background())
+	w.StartedAt = time.Now()
+	w.cancel = cancel
+
+	// The watch is tracked before the definitions are listed, so that the other connections
+	// are not held up by a slow cluster while it is started.
+	k8sComponentsWatchesMx.Lock()
+	if running, ok := k8sComponentsWatches[w.ConnectionID]; ok {
+		k8sComponentsWatchesMx.Unlock()
+		cancel()
+		return running, false, nil
+	}
+	k8sComponentsWatches[w.ConnectionID] = w
+	k8sComponentsWatchesMx.Unlock()
+
+	informer := dynamicinformer.NewDynamicSharedInformerFactory(client, 0).ForResource(crdGVR).Informer()
+	_, err := informer.AddEventHandler(cache.ResourceEventHandlerDetailedFuncs{
+		AddFunc: func(obj interface{}, isInInitialList bool) {
+			if !isInInitialList {
+				w.crdCreated(ctx, obj)
+			}
+		},
+		DeleteFunc: w.crdDeleted,
+	})
+	if err != nil {
+		w.stop()
+		return nil, false, ErrWatchK8sCRDs(err, w.ContextID)
+	}
+	go informer.Run(ctx.Done())
+
+	syncCtx, cancelSync := context.WithTimeout(ctx, crdWatchSyncTimeout)
+	defer cancelSync()
+	if !cache.WaitForCacheSync(syncCtx.Done(), informer.HasSynced) {
+		w.stop()
+		return nil, false, ErrWatchK8sCRDs(fmt.Errorf("custom resource definitions were not listed within %s", crdWatchSyncTimeout), w.ContextID)
+	}
+	return w, true, nil
+}
+
+// StopK8sComponentsWatch stops watching the cluster of the connection, and reports whether it was watched
+func StopK8sComponentsWatch(connectionID string) bool {
+	k8sComponentsWatchesMx.Lock()
+	w, ok := k8sComponentsWatches[connectionID]
+	k8sComponentsWatchesMx.Unlock()
+	if !ok {
+		return false
+	}
+	w.stop()
+	return true
+}
+
+// stop stops the watch and untracks it, unless the connection is watched by another one since
+func (w *K8sComponentsWatch) stop() {
+	k8sComponentsWatchesMx.Lock()
+	if k8sComponentsWatches[w.ConnectionID] == w {
+		delete(k8sComponentsWatches, w.ConnectionID)
+	}
+	k8sComponentsWatchesMx.Unlock()
+	w.cancel()
+}
+
+// crdCreated registers the components of the custom resource definition
+func (w *K8sComponentsWatch) crdCreated(ctx context.Context, obj interface{}) {
+	crd, ok := obj.(*unstructured.Unstructured)
+	if !ok {
+		return
+	}
+	kind, apiVersions := crdKindAndAPIVersions(crd)
+	groupVersions := make(map[string]bool, len(apiVersions))
+	for _, apiVersion := range apiVersions {
+		groupVersions[apiVersion] = true
+	}
+
+	unlock := lockK8sComponentsRegistration(w.ContextID)
+	defer unlock()
+
+	registration := newK8sComponentsRegistrationWithOptions(w.opts.Registry, w.ContextID, w.opts.Registration)
+	if w.opts.Registration.Hostname == "" && w.opts.Registration.HostMetadata == "" && w.opts.DB != nil {
+		if host, err := models.GetK8sComponentsRegistryHost(w.opts.DB, w.ContextID); err == nil {
+			registration.host = host
+		}
+	}
+	registration.log = w.opts.Log
+	count := 0
+	var err error
+	for attempt := 0; attempt < crdWatchRegistrationAttempts; attempt++ {
+		if attempt > 0 {
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(crdWatchRegistrationBackoff):
+			}
+		}
+		generated := false
+		_, err = w.generate(ctx, w.opts.Config, groupVersions, func(c v1alpha1.ComponentDefinition) {
+			if c.Kind != kind {
+				return
+			}
+			generated = true
+			if registration.register(c) {
+				count++
+			}
+		})
+		if ctx.Err() != nil {
+			return
+		}
+		if err != nil || generated {
+			break
+		}
+	}
+
+	metadata := map[string]interface{}{
+		"crd":          crd.GetName(),
+		"kind":         kind,
+		"api_versions": apiVersions,
+	}
+	severity := events.Informational
+	description := fmt.Sprintf("%d Kubernetes components registered for %s as the custom resource definition %s was created", count, w.opts.ContextName, crd.GetName())
+	if err != nil {
+		severity = events.Error
+		metadata["error"] = ErrCreatingKubernetesComponents(err, w.ContextID)
+		description = fmt.Sprintf("Failed to register the Kubernetes components of the custom resource definition %s for %s", crd.GetName(), w.opts.ContextName)
+	} else if failures := registration.failures(); len(failures) > 0 {
+		severity = events.Warning
+		metadata["failed_components"] = failures
+		description = fmt.Sprintf("%s, %d components failed to register", description, len(failures))
+	}
+	w.publish("registration", severity, description, metadata)
+}
+
+// crdDeleted unregisters the components of the custom resource definition
+func (w *K8sComponentsWatch) crdDeleted(obj interface{}) {
+	if tombstone, ok := obj.(cache.DeletedFinalStateUnknown); ok {
+		obj = tombstone.Obj
+	}
+	crd, ok := obj.(*unstructured.Unstructured)
+	if !ok {
+		return
+	}
+	kind, apiVersions := crdKindAndAPIVersions(crd)
+
+	unlock := lockK8sComponentsRegistration(w.ContextID)
+	defer unlock()
+
+	metadata := map[string]interface{}{
+		"crd":          crd.GetName(),
+		"kind":         kind,
+		"api_versions": apiVersions,
+	}
+	count, err := models.UnregisterK8sContextComponents(w.opts.DB, w.ContextID, kind, apiVersions)
+	if err != nil {
+		metadata["error"] = err
+		w.publish("unregistration", events.Error, fmt.Sprintf("Failed to unregister the Kubernetes components of the custom resource definition %s for %s", crd.GetName(), w.opts.ContextName), metadata)
+		return
+	}
+	w.publish("unregistration", events.Informational, fmt.Sprintf("%d Kubernetes components unregistered for %s as the custom resource definition %s was deleted", count, w.opts.ContextName, crd.GetName()), metadata)
+}
+
+func (w *K8sComponentsWatch) publish(action string, severity events.EventSeverity, description string, metadata map[string]interface{}) {
+	event := events.NewEvent().ActedUpon(uuid.FromStringOrNil(w.ConnectionID)).WithCategory("kubernetes_components").WithAction(action).
+		FromSystem(w.opts.MesheryInstanceID).FromUser(w.opts.UserID).WithSeverity(severity).WithDescription(description).WithMetadata(metadata).Build()
+	models.PersistEvent(w.opts.Provider, w.opts.Log, event)
+	// The informer is not held up by the subscribers of the events
+	if w.opts.EventBroadcast != nil {
+		go w.opts.EventBroadcast.Publish(w.opts.UserID, event)
+	}
+}
+
+// crdKindAndAPIVersions returns the kind of the custom resource definition and the apiVersions it is served at
+func crdKindAndAPIVersions(crd *unstructured.Unstructured) (string, []string) {
+	kind, _, _ := unstructured.NestedString(crd.Object, "spec", "names", "kind")
+	group, _, _ := unstructured.NestedString(crd.Object, "spec", "group")
+	versions, _, _ := unstructured.NestedSlice(crd.Object, "spec", "versions")
+	apiVersions := make([]string, 0, len(versions))
+	for _, v := range versions {
+		version, ok := v.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		if served, _ := version["served"].(bool); !served {
+			continue
+		}
+		if name, _ := version["name"].(string); name != "" {
+			apiVersions = append(apiVersions, group+"/"+name)
+		}
+	}
+	return kind, apiVersions
+}
@@ -0,0 +1,154 @@
+package core
+
+import (
+	"context"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/gofrs/uuid"
+	mutil "github.com/layer5io/meshery/server/helpers/utils"
+	"github.com/layer5io/meshery/server/models"
+	"github.com/layer5io/meshkit/database"
+	"github.com/layer5io/meshkit/models/events"
+	"github.com/layer5io/meshkit/models/meshmodel/core/v1alpha1"
+	meshmodel "github.com/layer5io/meshkit/models/meshmodel/registry"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+)
+
+func TestCRDKindAndAPIVersions(t *testing.T) {
+	crd := &unstructured.Unstructured{Object: map[string]interface{}{
+		"spec": map[string]interface{}{
+			"group": "example.com",
+			"names": map[string]interface{}{"kind": "Widget"},
+			"versions": []interface{}{
+				map[string]interface{}{"name": "v1", "served": true},
+				map[string]interface{}{"name": "v1beta1", "served": false},
+				map[string]interface{}{"name": "v2", "served": true},
+			},
+		},
+	}}
+	kind, apiVersions := crdKindAndAPIVersions(crd)
+	if want := []string{"example.com/v1", "example.com/v2"}; kind != "Widget" || !reflect.DeepEqual(apiVersions, want) {
+		t.Errorf("crdKindAndAPIVersions() = %s %v, want Widget %v", kind, apiVersions, want)
+	}
+}
+
+func TestK8sComponentsWatch(t *testing.T) {
+	defer mutil.SetSVGStore(mutil.GetSVGStore())
+	mutil.SetSVGStore(&svgRecorder{})
+
+	db, err := database.New(database.Options{Engine: database.SQLITE, Filename: filepath.Join(t.TempDir(), "watch.db")})
+	if err != nil {
+		t.Fatalf("failed to open the database: %s", err)
+	}
+	if err := db.AutoMigrate(&models.K8sComponentsRegistryHost{}, &models.K8sComponentsRegistrationCheckpoint{}); err != nil {
+		t.Fatalf("failed to migrate the database: %s", err)
+	}
+	reg, err := meshmodel.NewRegistryManager(&db)
+	if err != nil {
+		t.Fatalf("failed to create the registry: %s", err)
+	}
+
+	userID := uuid.Must(uuid.NewV4())
+	broadcast := models.NewBroadcaster()
+	published, unsubscribe := broadcast.Subscribe(userID)
+	defer unsubscribe()
+
+	w := newK8sComponentsWatch(K8sComponentsWatchOptions{
+		ContextID:      "ctx-watch",
+		ContextName:    "watched",
+		ConnectionID:   "conn-watch",
+		UserID:         userID,
+		Registry:       reg,
+		DB:             &db,
+		EventBroadcast: broadcast,
+		Registration: K8sComponentsRegistrationOptions{
+			Kinds:  []string{"example.com/Widget"},
+			Labels: map[string]string{"team": "platform"},
+		},
+	})
+	// The cluster serves the Widget and Gadget kinds of the custom resource definition
+	w.generate = func(_ context.Context, _ []byte, groupVersions map[string]bool, fn func(v1alpha1.ComponentDefinition)) ([]APIGroupDiscoveryFailure, error) {
+		if !groupVersions["example.com/v1"] {
+			return nil, nil
+		}
+		for _, kind := range []string{"Widget", "Gadget"} {
+			fn(v1alpha1.ComponentDefinition{
+				TypeMeta: v1alpha1.TypeMeta{Kind: kind, APIVersion: "example.com/v1"},
+				Model:    v1alpha1.Model{Name: "kubernetes", Version: "v1.27.3", Category: v1alpha1.Category{Name: "Orchestration & Management"}},
+				Metadata: map[string]interface{}{},
+				Schema:   "{}",
+			})
+		}
+		return nil, nil
+	}
+
+	client := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), map[schema.GroupVersionResource]string{crdGVR: "CustomResourceDefinitionList"})
+	if _, started, err := startK8sComponentsWatch(w, client); err != nil || !started {
+		t.Fatalf("startK8sComponentsWatch() = %t, %v, want the watch started", started, err)
+	}
+	defer StopK8sComponentsWatch("conn-watch")
+	if _, started, _ := startK8sComponentsWatch(newK8sComponentsWatch(w.opts), client); started {
+		t.Error("startK8sComponentsWatch() started a second watch of the connection")
+	}
+
+	waitEvent := func(action string) {
+		t.Helper()
+		select {
+		case data := <-published:
+			if event, ok := data.(*events.Event); !ok || event.Action != action {
+				t.Fatalf("published %+v, want a %s event", data, action)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatalf("no %s event published", action)
+		}
+	}
+
+	crd := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "apiextensions.k8s.io/v1",
+		"kind":       "CustomResourceDefinition",
+		"metadata":   map[string]interface{}{"name": "widgets.example.com"},
+		"spec": map[string]interface{}{
+			"group":    "example.com",
+			"names":    map[string]interface{}{"kind": "Widget"},
+			"versions": []interface{}{map[string]interface{}{"name": "v1", "served": true}},
+		},
+	}}
+	if _, err := client.Resource(crdGVR).Create(context.Background(), crd, metav1.CreateOptions{}); err != nil {
+		t.Fatalf("failed to create the custom resource definition: %s", err)
+	}
+	waitEvent("registration")
+
+	comps, err := models.GetK8sContextComponents(&db, "ctx-watch")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(comps) != 1 || comps[0].Kind != "Widget" {
+		t.Fatalf("GetK8sContextComponents() = %v, want the Widget component only", comps)
+	}
+	if team := comps[0].Metadata["team"]; team != "platform" {
+		t.Errorf("component label team = %v, want platform", team)
+	}
+
+	// The checkpoint of an incomplete registration of the connection must not skip the widgets once created again
+	if err := models.SaveK8sComponentsRegistrationCheckpoint(&db, &models.K8sComponentsRegistrationCheckpoint{ContextID: "ctx-watch", Registered: []string{"example.com/v1/Widget"}}); err != nil {
+		t.Fatal(err)
+	}
+	if err := client.Resource(crdGVR).Delete(context.Background(), "widgets.example.com", metav1.DeleteOptions{}); err != nil {
+		t.Fatalf("failed to delete the custom resource definition: %s", err)
+	}
+	waitEvent("unregistration")
+
+	if comps, err := models.GetK8sContextComponents(&db, "ctx-watch"); err != nil || len(comps) != 0 {
+		t.Errorf("GetK8sContextComponents() = %v, %v, want the components unregistered", comps, err)
+	}
+	if checkpoint, err := models.GetK8sComponentsRegistrationCheckpoint(&db, "ctx-watch"); err != nil || checkpoint != nil {
+		t.Errorf("GetK8sComponentsRegistrationCheckpoint() = %v, %v, want the checkpoint cleared", checkpoint, err)
+	}
+}
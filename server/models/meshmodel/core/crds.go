@@ -0,0 +1,70 @@
+package core
+
+import (
+	"context"
+	"encoding/json"
+	"sort"
+	"strings"
+
+	"github.com/layer5io/meshkit/utils/kubernetes"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// K8sCRD is a custom resource installed in a cluster, at the version preferred by its API server
+type K8sCRD struct {
+	Group   string `json:"group"`
+	Version string `json:"version"`
+	Kind    string `json:"kind"`
+	// Resource is the plural name the custom resource is served at
+	Resource string `json:"resource"`
+	// Scope is either "Namespaced" or "Cluster"
+	Scope string `json:"scope"`
+}
+
+// ListK8sCRDs returns the custom resources served by the cluster, as reported by the discovery client, without generating any component.
+// The API groups whose discovery failed are skipped and returned along with the custom resources of the others.
+func ListK8sCRDs(ctx context.Context, cli *kubernetes.Client) ([]K8sCRD, []APIGroupDiscoveryFailure, error) {
+	crdresult, err := cli.KubeClient.RESTClient().Get().RequestURI("/apis/apiextensions.k8s.io/v1/customresourcedefinitions").Do(ctx).Raw()
+	if err != nil {
+		return nil, nil, err
+	}
+	var xcrd crd
+	if err := json.Unmarshal(crdresult, &xcrd); err != nil {
+		return nil, nil, err
+	}
+	customResources := make(map[schema.GroupKind]bool, len(xcrd.Items))
+	for _, item := range xcrd.Items {
+		customResources[schema.GroupKind{Group: item.Spec.Group, Kind: item.Spec.Names.Kind}] = true
+	}
+
+	lists, err := cli.KubeClient.DiscoveryClient.ServerPreferredResources()
+	failures, err := apiGroupDiscoveryFailures(err)
+	if err != nil {
+		return nil, nil, err
+	}
+	crds := make([]K8sCRD, 0, len(customResources))
+	for _, list := range lists {
+		gv, err := schema.ParseGroupVersion(list.GroupVersion)
+		if err != nil {
+			continue
+		}
+		for _, res := range list.APIResources {
+			// Subresources (e.g. "widgets/status") share the kind of their resource
+			if strings.Contains(res.Name, "/") || !customResources[schema.GroupKind{Group: gv.Group, Kind: res.Kind}] {
+				continue
+			}
+			scope := "Cluster"
+			if res.Namespaced {
+				scope = "Namespaced"
+			}
+			crds = append(crds, K8sCRD{Group: gv.Group, Version: gv.Version, Kind: res.Kind, Resource: res.Name, Scope: scope})
+		}
+	}
+	sort.Slice(crds, func(i, j int) bool {
+		if crds[i].Group != crds[j].Group {
+			return crds[i].Group < crds[j].Group
+		}
+		return crds[i].Kind < crds[j].Kind
+	})
+	return crds, failures, nil
+}
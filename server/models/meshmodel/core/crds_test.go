@@ -0,0 +1,54 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/layer5io/meshkit/utils/kubernetes"
+)
+
+func TestListK8sCRDs(t *testing.T) {
+	responses := map[string]string{
+		"/api":                 `{"kind":"APIVersions","versions":["v1"]}`,
+		"/api/v1":              `{"kind":"APIResourceList","groupVersion":"v1","resources":[{"name":"pods","kind":"Pod","namespaced":true,"verbs":["list"]}]}`,
+		"/apis":                `{"kind":"APIGroupList","groups":[{"name":"example.com","versions":[{"groupVersion":"example.com/v1","version":"v1"}],"preferredVersion":{"groupVersion":"example.com/v1","version":"v1"}}]}`,
+		"/apis/example.com/v1": `{"kind":"APIResourceList","groupVersion":"example.com/v1","resources":[{"name":"widgets","kind":"Widget","namespaced":true,"verbs":["list"]},{"name":"widgets/status","kind":"Widget","namespaced":true,"verbs":["get"]},{"name":"gadgets","kind":"Gadget","namespaced":false,"verbs":["list"]}]}`,
+		"/apis/apiextensions.k8s.io/v1/customresourcedefinitions": `{"items":[{"spec":{"group":"example.com","names":{"kind":"Widget"}}},{"spec":{"group":"example.com","names":{"kind":"Gadget"}}}]}`,
+	}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, ok := responses[r.URL.Path]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(body))
+	}))
+	defer srv.Close()
+
+	cli, err := kubernetes.New([]byte(fmt.Sprintf(`apiVersion: v1
+kind: Config
+clusters: [{name: test, cluster: {server: %q}}]
+contexts: [{name: test, context: {cluster: test, user: test}}]
+users: [{name: test, user: {token: test}}]
+current-context: test
+`, srv.URL)))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	crds, failures, err := ListK8sCRDs(context.Background(), cli)
+	if err != nil {
+		t.Fatalf("ListK8sCRDs() failed with error: %s", err)
+	}
+	want := []K8sCRD{
+		{Group: "example.com", Version: "v1", Kind: "Gadget", Resource: "gadgets", Scope: "Cluster"},
+		{Group: "example.com", Version: "v1", Kind: "Widget", Resource: "widgets", Scope: "Namespaced"},
+	}
+	if fmt.Sprint(crds) != fmt.Sprint(want) || len(failures) != 0 {
+		t.Errorf("ListK8sCRDs() = %v, %v, want %v", crds, failures, want)
+	}
+}
@@ -4,8 +4,33 @@ import "github.com/layer5io/meshkit/errors"
 
 const (
 	ErrCreatingKubernetesComponentsCode = "1545"
+	ErrInvalidK8sComponentManifestCode  = "1576"
+	ErrWatchK8sCRDsCode                 = "1592"
+	ErrInvalidK8sComponentKindsCode     = "1594"
+	ErrInvalidK8sComponentLabelsCode    = "1597"
+	ErrListExcludedK8sComponentsCode    = "1623"
 )
 
 func ErrCreatingKubernetesComponents(err error, ctxID string) error {
 	return errors.New(ErrCreatingKubernetesComponentsCode, errors.Alert, []string{"failed to register/create kubernetes components for contextID " + ctxID}, []string{err.Error()}, []string{"component generation was canceled due to deletion or reload of K8s context", "Invalid kubeconfig", "Filters passed incorrectly in config", "Could not fetch API resources from Kubernetes server"}, []string{"If there is the log \"Starting to register ...\" for the same contextID after this error means that for some reason the context was reloaded which caused this run to abort. In that case, this error can be ignored.", "Make sure that the configuration filters passed are in accordance with output from /openapi/v2"})
 }
+
+func ErrInvalidK8sComponentManifest(err error) error {
+	return errors.New(ErrInvalidK8sComponentManifestCode, errors.Alert, []string{"invalid manifest of kubernetes components"}, []string{err.Error()}, []string{"The manifest was not generated by Meshery or has been modified.", "Some of the components are missing their kind or apiVersion."}, []string{"Regenerate the manifest by exporting the components of a connected cluster."})
+}
+
+func ErrWatchK8sCRDs(err error, ctxID string) error {
+	return errors.New(ErrWatchK8sCRDsCode, errors.Alert, []string{"failed to watch the custom resource definitions of contextID " + ctxID}, []string{err.Error()}, []string{"The cluster is unreachable.", "Meshery is not allowed to list and watch customresourcedefinitions.apiextensions.k8s.io."}, []string{"Ensure the cluster is reachable and that the credentials of the connection are allowed to list and watch custom resource definitions."})
+}
+
+func ErrInvalidK8sComponentKinds(err error) error {
+	return errors.New(ErrInvalidK8sComponentKindsCode, errors.Alert, []string{"invalid kinds of kubernetes components to register"}, []string{err.Error()}, []string{"The kinds are not comma separated group/kind pairs."}, []string{"Pass the kinds as comma separated group/kind pairs, e.g. \"apps/Deployment,Pod\", the group being omitted for the kinds of the core API group."})
+}
+
+func ErrInvalidK8sComponentLabels(err error) error {
+	return errors.New(ErrInvalidK8sComponentLabelsCode, errors.Alert, []string{"invalid labels of kubernetes components to register"}, []string{err.Error()}, []string{"The labels are not a JSON object of strings.", "Some of the labels have an empty key."}, []string{"Pass the labels as a JSON object mapping each label to its value, e.g. {\"team\": \"payments\", \"cost-center\": \"cc-42\"}."})
+}
+
+func ErrListExcludedK8sComponents(err error, ctxID string) error {
+	return errors.New(ErrListExcludedK8sComponentsCode, errors.Alert, []string{"failed to list the custom resources of the excluded namespaces for contextID " + ctxID}, []string{err.Error()}, []string{"Meshery is not allowed to list the custom resource definitions or their instances.", "The cluster is unreachable."}, []string{"Ensure the credentials of the connection are allowed to list custom resource definitions and their instances, or exclude no namespace."})
+}
@@ -0,0 +1,110 @@
+package core
+
+import (
+	"context"
+	"sort"
+	"strings"
+
+	"github.com/layer5io/meshkit/utils/kubernetes"
+	"github.com/spf13/viper"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/metadata"
+)
+
+// DefaultExcludedNamespaces are the system namespaces excluded from the component discovery by default
+var DefaultExcludedNamespaces = []string{"kube-system", "kube-public", "kube-node-lease"}
+
+// excludedNamespacesSampleSize bounds the instances of a custom resource listed to find the namespaces it lives in
+const excludedNamespacesSampleSize = 500
+
+// ExcludedK8sComponent is a component which was not registered as its instances live in excluded namespaces only
+type ExcludedK8sComponent struct {
+	Kind       string   `json:"kind"`
+	APIVersion string   `json:"apiVersion"`
+	Namespaces []string `json:"namespaces"`
+}
+
+// ExcludedNamespacesFromConfig returns the namespaces excluded from the component discovery,
+// configured through "KUBERNETES_COMPONENT_EXCLUDED_NAMESPACES" as a comma separated list when set in the environment.
+func ExcludedNamespacesFromConfig() []string {
+	// viper splits the strings on whitespace rather than on commas
+	if namespaces, ok := viper.Get("KUBERNETES_COMPONENT_EXCLUDED_NAMESPACES").(string); ok {
+		return trimNamespaces(strings.Split(namespaces, ","))
+	}
+	return trimNamespaces(viper.GetStringSlice("KUBERNETES_COMPONENT_EXCLUDED_NAMESPACES"))
+}
+
+// trimNamespaces trims the namespaces and drops the empty ones
+func trimNamespaces(namespaces []string) []string {
+	trimmed := make([]string, 0, len(namespaces))
+	for _, ns := range namespaces {
+		if ns = strings.TrimSpace(ns); ns != "" {
+			trimmed = append(trimmed, ns)
+		}
+	}
+	return trimmed
+}
+
+// k8sComponentsConfinedToNamespaces returns the namespaced custom resources whose instances all live in the excluded namespaces,
+// keyed by apiVersion and kind. Components are generated per kind rather than per namespace, hence a kind is excluded only
+// when the excluded namespaces are the only ones it is used in, e.g. the custom resources of the operators of kube-system.
+// The custom resources without instances, with more instances than sampled or which cannot be listed are kept.
+func k8sComponentsConfinedToNamespaces(ctx context.Context, cli *kubernetes.Client, excludedNamespaces []string) (map[string]ExcludedK8sComponent, error) {
+	crds, _, err := ListK8sCRDs(ctx, cli)
+	if err != nil {
+		return nil, err
+	}
+	metadataClient, err := metadata.NewForConfig(&cli.RestConfig)
+	if err != nil {
+		return nil, err
+	}
+	return confinedK8sComponents(ctx, crds, metadataClient, excludedNamespaces)
+}
+
+// confinedK8sComponents lists the instances of the namespaced custom resources to find the ones confined to the excluded namespaces
+func confinedK8sComponents(ctx context.Context, crds []K8sCRD, metadataClient metadata.Interface, excludedNamespaces []string) (map[string]ExcludedK8sComponent, error) {
+	excluded := make(map[string]bool, len(excludedNamespaces))
+	for _, ns := range excludedNamespaces {
+		excluded[ns] = true
+	}
+
+	confined := make(map[string]ExcludedK8sComponent)
+	for _, crd := range crds {
+		if crd.Scope != "Namespaced" {
+			continue
+		}
+		gvr := schema.GroupVersionResource{Group: crd.Group, Version: crd.Version, Resource: crd.Resource}
+		list, err := metadataClient.Resource(gvr).List(ctx, metav1.ListOptions{Limit: excludedNamespacesSampleSize})
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil, ctx.Err()
+			}
+			continue
+		}
+		if len(list.Items) == 0 || list.GetContinue() != "" {
+			continue
+		}
+		namespaces := make(map[string]bool)
+		for _, item := range list.Items {
+			namespaces[item.GetNamespace()] = true
+		}
+		if component, ok := confinedK8sComponent(gvr.GroupVersion().String(), crd.Kind, namespaces, excluded); ok {
+			confined[component.APIVersion+"/"+component.Kind] = component
+		}
+	}
+	return confined, nil
+}
+
+// confinedK8sComponent reports whether the namespaces the component is used in are all excluded
+func confinedK8sComponent(apiVersion, kind string, namespaces, excluded map[string]bool) (ExcludedK8sComponent, bool) {
+	component := ExcludedK8sComponent{Kind: kind, APIVersion: apiVersion, Namespaces: make([]string, 0, len(namespaces))}
+	for ns := range namespaces {
+		if !excluded[ns] {
+			return component, false
+		}
+		component.Namespaces = append(component.Namespaces, ns)
+	}
+	sort.Strings(component.Namespaces)
+	return component, len(component.Namespaces) > 0
+}
@@ -0,0 +1,72 @@
+package core
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+
+	"github.com/layer5io/meshkit/utils/kubernetes"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// K8sCoreAPIGroup names the core API group (of e.g. pods and services), whose name is empty, when filtering by group
+const K8sCoreAPIGroup = "core"
+
+// K8sOpenAPI is the OpenAPI v3 schema of a cluster, the input the components of the cluster are generated from
+type K8sOpenAPI struct {
+	// Paths maps the OpenAPI v3 paths of the group versions (e.g. "apis/apps/v1") to their documents, as served by the API server
+	Paths map[string]json.RawMessage `json:"paths"`
+	// API groups whose schema could not be fetched (e.g. unavailable aggregated API services), missing from the paths
+	DiscoveryFailures []APIGroupDiscoveryFailure `json:"discovery_failures,omitempty"`
+}
+
+// FetchK8sOpenAPI fetches the OpenAPI v3 documents of every group version served by the cluster, of the given API group only
+// unless group is empty. The core API group is given as K8sCoreAPIGroup.
+func FetchK8sOpenAPI(ctx context.Context, cli *kubernetes.Client, group string) (*K8sOpenAPI, error) {
+	content, err := cli.KubeClient.RESTClient().Get().RequestURI("/openapi/v3").Do(ctx).Raw()
+	if err != nil {
+		return nil, err
+	}
+	var index OpenAPIV3Response
+	if err := json.Unmarshal(content, &index); err != nil {
+		return nil, err
+	}
+
+	openAPI := &K8sOpenAPI{Paths: make(map[string]json.RawMessage)}
+	for path, entry := range index.Paths {
+		if !strings.HasPrefix(path, "api") {
+			continue
+		}
+		groupVersion := openAPIPathGroupVersion(path)
+		if group != "" && !inK8sAPIGroup(groupVersion, group) {
+			continue
+		}
+		document, err := cli.KubeClient.RESTClient().Get().RequestURI(entry.URL).Do(ctx).Raw()
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil, ctx.Err()
+			}
+			// The schema of an aggregated API group is served by its API service, which may be unavailable.
+			if kerrors.IsServiceUnavailable(err) {
+				openAPI.DiscoveryFailures = append(openAPI.DiscoveryFailures, APIGroupDiscoveryFailure{GroupVersion: groupVersion, Reason: err.Error()})
+				continue
+			}
+			return nil, err
+		}
+		openAPI.Paths[path] = document
+	}
+	return openAPI, nil
+}
+
+// inK8sAPIGroup reports whether the group version belongs to the API group, K8sCoreAPIGroup for the core one
+func inK8sAPIGroup(groupVersion, group string) bool {
+	gv, err := schema.ParseGroupVersion(groupVersion)
+	if err != nil {
+		return false
+	}
+	if gv.Group == "" {
+		return group == K8sCoreAPIGroup
+	}
+	return gv.Group == group
+}
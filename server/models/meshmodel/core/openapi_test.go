@@ -0,0 +1,66 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/layer5io/meshkit/utils/kubernetes"
+)
+
+func TestFetchK8sOpenAPI(t *testing.T) {
+	responses := map[string]string{
+		"/openapi/v3":              `{"paths":{"api/v1":{"serverRelativeURL":"/openapi/v3/api/v1?hash=a"},"apis/apps/v1":{"serverRelativeURL":"/openapi/v3/apis/apps/v1?hash=b"},"apis/metrics.k8s.io/v1beta1":{"serverRelativeURL":"/openapi/v3/apis/metrics.k8s.io/v1beta1?hash=c"},"version":{"serverRelativeURL":"/openapi/v3/version?hash=d"}}}`,
+		"/openapi/v3/api/v1":       `{"openapi":"3.0.0","info":{"title":"Kubernetes","version":"v1"}}`,
+		"/openapi/v3/apis/apps/v1": `{"openapi":"3.0.0","info":{"title":"Kubernetes","version":"apps/v1"}}`,
+		"/openapi/v3/version":      `{"openapi":"3.0.0"}`,
+	}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/openapi/v3/apis/metrics.k8s.io/v1beta1" {
+			http.Error(w, "service unavailable", http.StatusServiceUnavailable)
+			return
+		}
+		body, ok := responses[r.URL.Path]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(body))
+	}))
+	defer srv.Close()
+
+	cli, err := kubernetes.New([]byte(fmt.Sprintf(`apiVersion: v1
+kind: Config
+clusters: [{name: test, cluster: {server: %q}}]
+contexts: [{name: test, context: {cluster: test, user: test}}]
+users: [{name: test, user: {token: test}}]
+current-context: test
+`, srv.URL)))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	openAPI, err := FetchK8sOpenAPI(context.Background(), cli, "")
+	if err != nil {
+		t.Fatalf("FetchK8sOpenAPI() failed with error: %s", err)
+	}
+	if len(openAPI.Paths) != 2 || openAPI.Paths["api/v1"] == nil || openAPI.Paths["apis/apps/v1"] == nil {
+		t.Errorf("FetchK8sOpenAPI() paths = %v, want the documents of api/v1 and apis/apps/v1", openAPI.Paths)
+	}
+	if len(openAPI.DiscoveryFailures) != 1 || openAPI.DiscoveryFailures[0].GroupVersion != "metrics.k8s.io/v1beta1" {
+		t.Errorf("FetchK8sOpenAPI() discovery failures = %v, want the unavailable metrics.k8s.io/v1beta1", openAPI.DiscoveryFailures)
+	}
+
+	for group, want := range map[string]string{"apps": "apis/apps/v1", K8sCoreAPIGroup: "api/v1"} {
+		openAPI, err := FetchK8sOpenAPI(context.Background(), cli, group)
+		if err != nil {
+			t.Fatalf("FetchK8sOpenAPI(%q) failed with error: %s", group, err)
+		}
+		if len(openAPI.Paths) != 1 || openAPI.Paths[want] == nil {
+			t.Errorf("FetchK8sOpenAPI(%q) paths = %v, want only %s", group, openAPI.Paths, want)
+		}
+	}
+}
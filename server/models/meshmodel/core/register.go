@@ -5,7 +5,9 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"sort"
 	"strings"
+	"sync"
 
 	"cuelang.org/go/cue"
 	"cuelang.org/go/cue/cuecontext"
@@ -19,7 +21,10 @@ import (
 	"github.com/layer5io/meshkit/utils"
 
 	"github.com/layer5io/meshery/server/models/pattern/core"
+	"github.com/layer5io/meshkit/database"
+	"github.com/layer5io/meshkit/logger"
 	"github.com/layer5io/meshkit/models/events"
+	"github.com/layer5io/meshkit/models/meshmodel/core/types"
 	meshmodel "github.com/layer5io/meshkit/models/meshmodel/registry"
 	oamcore "github.com/layer5io/meshkit/models/oam/core/v1alpha1"
 
@@ -27,7 +32,9 @@ import (
 	"github.com/layer5io/meshkit/utils/kubernetes"
 	"github.com/layer5io/meshkit/utils/manifests"
 
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
 	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/discovery"
 )
 
 type crd struct {
@@ -37,54 +44,513 @@ type crdhelper struct {
 	Spec spec `json:"spec"`
 }
 type spec struct {
-	Names names `json:"names"`
+	Group string `json:"group"`
+	Names names  `json:"names"`
 }
 type names struct {
 	Kind string `json:"kind"`
 }
 
-func RegisterK8sMeshModelComponents(provider *models.Provider, _ context.Context, config []byte, ctxID string, connectionID string, userID string, mesheryInstanceID uuid.UUID, reg *meshmodel.RegistryManager, ec *models.Broadcast, ctxName string) (err error) {
+func RegisterK8sMeshModelComponents(provider *models.Provider, ctx context.Context, config []byte, ctxID string, connectionID string, userID string, mesheryInstanceID uuid.UUID, reg *meshmodel.RegistryManager, ec *models.Broadcast, ctxName string) (int, error) {
+	return registerK8sMeshModelComponentsWithOptions(provider, ctx, config, ctxID, connectionID, userID, mesheryInstanceID, reg, ec, ctxName, K8sComponentsRegistrationOptions{})
+}
+
+// RegisterK8sMeshModelComponentsForModelVersion returns a registration function which associates the components
+// with the given version of the Kubernetes model in the registry, instead of the version of the cluster.
+// This allows registering the components of clusters at different versions side by side.
+func RegisterK8sMeshModelComponentsForModelVersion(modelVersion string) models.K8sRegistrationFunction {
+	return RegisterK8sMeshModelComponentsWithOptions(K8sComponentsRegistrationOptions{ModelVersion: modelVersion})
+}
+
+// K8sComponentsRegistrationOptions customize the registration of the components of a cluster
+type K8sComponentsRegistrationOptions struct {
+	// ModelVersion pins the version of the Kubernetes model, the version of the cluster is used when empty
+	ModelVersion string
+	// Hostname of the registry host the components are registered against, "kubernetes" when empty
+	Hostname string
+	// Metadata of the registry host, the ID of the context when empty
+	HostMetadata string
+	// ExcludeNamespaces are the namespaces whose custom resources are not registered as components,
+	// "KUBERNETES_COMPONENT_EXCLUDED_NAMESPACES" when nil and none when empty
+	ExcludeNamespaces []string
+	// Kinds, if not empty, are the only kinds registered as components, as parsed by ParseK8sComponentKinds
+	Kinds []string
+	// Labels are merged into the metadata of each component, as parsed by ParseK8sComponentLabels
+	Labels map[string]string
+	// Hook is invoked on each component before it is registered, the one set through SetK8sComponentHook when nil
+	Hook K8sComponentHook
+}
+
+// RegisterK8sMeshModelComponentsWithOptions returns a registration function which registers the components
+// as customized by the options, e.g. against the registry host of a federated registry
+func RegisterK8sMeshModelComponentsWithOptions(opts K8sComponentsRegistrationOptions) models.K8sRegistrationFunction {
+	return func(provider *models.Provider, ctx context.Context, config []byte, ctxID string, connectionID string, userID string, mesheryInstanceID uuid.UUID, reg *meshmodel.RegistryManager, ec *models.Broadcast, ctxName string) (int, error) {
+		return registerK8sMeshModelComponentsWithOptions(provider, ctx, config, ctxID, connectionID, userID, mesheryInstanceID, reg, ec, ctxName, opts)
+	}
+}
+
+func registerK8sMeshModelComponentsWithOptions(provider *models.Provider, ctx context.Context, config []byte, ctxID string, connectionID string, userID string, mesheryInstanceID uuid.UUID, reg *meshmodel.RegistryManager, ec *models.Broadcast, ctxName string, opts K8sComponentsRegistrationOptions) (count int, err error) {
 	connectionUUID := uuid.FromStringOrNil(connectionID)
 	userUUID := uuid.FromStringOrNil(userID)
 
-	man, err := GetK8sMeshModelComponents(config)
-	if err != nil {
-		return ErrCreatingKubernetesComponents(err, ctxID)
+	// registration tracks the components already registered in this run so that
+	// a resumed registration (after a credential refresh) does not register them twice.
+	// The same cluster may be registered by several users at once, their registrations are serialized.
+	unlock := lockK8sComponentsRegistration(ctxID)
+	defer unlock()
+
+	registration := newK8sComponentsRegistrationWithOptions(reg, ctxID, opts)
+	registration.log = models.LoggerFromContext(ctx, nil)
+	registration.excludeNamespaces = opts.ExcludeNamespaces
+	if registration.excludeNamespaces == nil {
+		registration.excludeNamespaces = ExcludedNamespacesFromConfig()
 	}
-	if man == nil {
-		return ErrCreatingKubernetesComponents(errors.New("generated components are nil"), ctxID)
+	registration.progress = models.K8sComponentsRegistrationProgressFromContext(ctx)
+	// A previous registration which did not complete is resumed, only the remaining components are registered
+	resumed := 0
+	if provider != nil {
+		registration.db = (*provider).GetGenericPersister()
+		resumed = registration.resume()
+		registration.saveHost()
 	}
-	count := 0
-	for _, c := range man {
-		writeK8sMetadata(&c, reg)
-		err = reg.RegisterEntity(meshmodel.Host{
-			Hostname: "kubernetes",
-			Metadata: ctxID,
-		}, c)
-		count++
-	}
-	event := events.NewEvent().ActedUpon(connectionUUID).WithCategory("kubernetes_components").WithAction("registration").FromSystem(mesheryInstanceID).FromUser(userUUID).WithSeverity(events.Informational).WithDescription(fmt.Sprintf("%d Kubernetes components registered for %s", count, ctxName)).WithMetadata(map[string]interface{}{
+	count, err = registerK8sMeshModelComponents(ctx, config, registration)
+
+	// Cloud-auth (gcp/azure/oidc/exec) tokens can expire between the ping and the registration,
+	// refresh the credentials through the auth plugin and resume with the remaining components.
+	countBeforeRefresh := -1
+	if err != nil && kerrors.IsUnauthorized(err) {
+		refreshedConfig, ok, rerr := models.RefreshKubeConfigAuth(config)
+		if ok && rerr == nil {
+			countBeforeRefresh = count
+			var countAfterRefresh int
+			countAfterRefresh, err = registerK8sMeshModelComponents(ctx, refreshedConfig, registration)
+			count += countAfterRefresh
+		}
+	}
+	if err != nil {
+		registration.saveCheckpoint(err)
+		return count, ErrCreatingKubernetesComponents(err, ctxID)
+	}
+	registration.completeCheckpoint()
+
+	metadata := map[string]interface{}{
 		"doc": "https://docs.meshery.io/tasks/lifecycle-management",
-	}).Build()
+	}
+	if registration.host.Hostname != models.DefaultK8sComponentsHostname {
+		metadata["registry_host"] = registration.host.Hostname
+	}
+	severity := events.Informational
+	description := fmt.Sprintf("%d Kubernetes components registered for %s", count, ctxName)
+	if countBeforeRefresh >= 0 {
+		metadata["registered_before_refresh"] = countBeforeRefresh
+		metadata["registered_after_refresh"] = count - countBeforeRefresh
+		description = fmt.Sprintf("%d Kubernetes components registered for %s (credentials were refreshed during registration)", count, ctxName)
+	}
+	if resumed > 0 {
+		metadata["resumed_from_checkpoint"] = resumed
+		description = fmt.Sprintf("%s, resuming the previous registration which registered %d components", description, resumed)
+	}
+	if failures := registration.failures(); len(failures) > 0 {
+		severity = events.Warning
+		metadata["failed_components"] = failures
+		description = fmt.Sprintf("%s, %d components failed to register", description, len(failures))
+	}
+	if rejected := registration.rejectedComponents(); len(rejected) > 0 {
+		severity = events.Warning
+		metadata["rejected_components"] = rejected
+		description = fmt.Sprintf("%s, %d components were rejected by the pre-registration hook", description, len(rejected))
+	}
+	if registration.kinds != nil {
+		metadata["kinds"] = opts.Kinds
+		if notFound := registration.kindsNotFound(); len(notFound) > 0 {
+			severity = events.Warning
+			metadata["kinds_not_found"] = notFound
+			description = fmt.Sprintf("%s, %d of the requested kinds were not found in the cluster: %s", description, len(notFound), strings.Join(notFound, ", "))
+		}
+	}
+	if excluded := registration.excludedComponents(); len(excluded) > 0 {
+		metadata["excluded_namespaces"] = registration.excludeNamespaces
+		metadata["excluded_components"] = excluded
+		description = fmt.Sprintf("%s, %d components were excluded as they are only used in the excluded namespaces", description, len(excluded))
+	}
+	if registration.exclusionFailure != nil {
+		severity = events.Warning
+		metadata["excluded_namespaces"] = registration.excludeNamespaces
+		metadata["exclusion_error"] = registration.exclusionFailure
+		description = fmt.Sprintf("%s, the components used in the excluded namespaces only could not be found and were registered", description)
+	}
+	// Flaky aggregated API services make the discovery partially fail, the components of those API groups are missing.
+	if failures := registration.discoveryFailures; len(failures) > 0 {
+		severity = events.Warning
+		metadata["failed_api_groups"] = failures
+		description = fmt.Sprintf("%s, the component set is incomplete as the discovery of %d API groups failed", description, len(failures))
+	}
+	event := events.NewEvent().ActedUpon(connectionUUID).WithCategory("kubernetes_components").WithAction("registration").FromSystem(mesheryInstanceID).FromUser(userUUID).WithSeverity(severity).WithDescription(description).WithMetadata(metadata).Build()
 
-	_ = (*provider).PersistEvent(event)
+	if provider != nil {
+		models.PersistEvent(*provider, registration.log, event)
+	}
 	ec.Publish(userUUID, event)
 	return
 }
 
-func writeK8sMetadata(comp *v1alpha1.ComponentDefinition, reg *meshmodel.RegistryManager) {
-	ent, _, _ := reg.GetEntities(&v1alpha1.ComponentFilter{
+// registerK8sMeshModelComponents generates the components for the cluster and registers the ones not registered yet.
+// Components are registered as soon as they are generated, so when an error is returned the components registered until then are retained and counted.
+// Returns the number of components successfully registered.
+func registerK8sMeshModelComponents(ctx context.Context, config []byte, registration *k8sComponentsRegistration) (int, error) {
+	if len(registration.excludeNamespaces) > 0 && registration.confined == nil {
+		registration.confined = registration.confinedComponents(ctx, config)
+		if err := ctx.Err(); err != nil {
+			registration.confined = nil
+			return 0, err
+		}
+	}
+	count := 0
+	discoveryFailures, err := forEachK8sMeshModelComponent(ctx, config, nil, func(c v1alpha1.ComponentDefinition) {
+		if registration.register(c) {
+			count++
+		}
+	})
+	registration.discoveryFailures = discoveryFailures
+	return count, err
+}
+
+// RegisterK8sMeshModelComponentsFromManifest registers pre-generated components (as produced by GetK8sMeshModelComponents)
+// for the context without accessing the cluster, for air-gapped clusters which Meshery cannot introspect.
+// The manifest is validated as a whole before any of the components is registered.
+// Returns the number of components registered along with the components which failed to register.
+func RegisterK8sMeshModelComponentsFromManifest(comps []v1alpha1.ComponentDefinition, ctxID string, reg *meshmodel.RegistryManager, modelVersion string) (int, []ComponentRegistrationFailure, error) {
+	return registerK8sMeshModelComponentsFromManifest(comps, newK8sComponentsRegistration(reg, ctxID, modelVersion))
+}
+
+func registerK8sMeshModelComponentsFromManifest(comps []v1alpha1.ComponentDefinition, registration *k8sComponentsRegistration) (int, []ComponentRegistrationFailure, error) {
+	for i, c := range comps {
+		if c.Kind == "" || c.APIVersion == "" {
+			return 0, nil, ErrInvalidK8sComponentManifest(fmt.Errorf("component at index %d is missing its kind or apiVersion", i))
+		}
+	}
+	unlock := lockK8sComponentsRegistration(registration.ctxID)
+	defer unlock()
+
+	count := 0
+	for _, c := range comps {
+		if c.Model.Name == "" {
+			c.Model.Name = "kubernetes"
+			c.Model.DisplayName = "Kubernetes"
+			c.Model.Category = v1alpha1.Category{Name: "Orchestration & Management"}
+		}
+		if registration.register(c) {
+			count++
+		}
+	}
+	return count, registration.failures(), nil
+}
+
+// k8sComponentsRegistrationLocks serializes the registrations of the components of each context
+var k8sComponentsRegistrationLocks sync.Map
+
+// lockK8sComponentsRegistration waits for the other registrations of the context to complete, and returns the function releasing the context.
+func lockK8sComponentsRegistration(ctxID string) func() {
+	mu, _ := k8sComponentsRegistrationLocks.LoadOrStore(ctxID, &sync.Mutex{})
+	mu.(*sync.Mutex).Lock()
+	return mu.(*sync.Mutex).Unlock
+}
+
+// componentRegistry is the subset of the registry used to register the components
+type componentRegistry interface {
+	RegisterEntity(h meshmodel.Host, en meshmodel.Entity) error
+	GetEntities(f types.Filter) ([]meshmodel.Entity, *int64, *int)
+}
+
+// ComponentRegistrationFailure describes a component which failed to register
+type ComponentRegistrationFailure struct {
+	Kind       string `json:"kind"`
+	APIVersion string `json:"apiVersion"`
+	Reason     string `json:"reason"`
+}
+
+// k8sComponentsRegistration tracks the outcome of the registration of the components of a context
+type k8sComponentsRegistration struct {
+	reg          componentRegistry
+	ctxID        string
+	modelVersion string
+	// host the components are registered against
+	host       meshmodel.Host
+	registered map[string]bool
+	failed     map[string]ComponentRegistrationFailure
+	// API groups whose discovery failed in the last run, their components are missing
+	discoveryFailures []APIGroupDiscoveryFailure
+	// log, if set, receives the per-component detail of the registration at debug level
+	log logger.Handler
+	// excludeNamespaces are the namespaces whose custom resources are not registered, the confined components
+	// are the ones used in those namespaces only, keyed by apiVersion and kind
+	excludeNamespaces []string
+	confined          map[string]ExcludedK8sComponent
+	excluded          map[string]ExcludedK8sComponent
+	// exclusionFailure is why the components confined to the excluded namespaces could not be found
+	exclusionFailure error
+	// db, if set, persists the checkpoints of the registration so that it can be resumed
+	db *database.Handler
+	// kinds, if set, are the only group/kinds registered, the found ones are those some component was generated for
+	kinds      map[string]bool
+	foundKinds map[string]bool
+	// progress, if set, receives the outcome of each component
+	progress models.K8sComponentsRegistrationProgress
+	// labels, if set, are merged into the metadata of each component
+	labels map[string]string
+	// hook may modify or reject each component before it is registered, rejected holds the components it rejected
+	hook     K8sComponentHook
+	rejected map[string]ComponentRegistrationFailure
+}
+
+// k8sComponentsCheckpointInterval is the number of components registered between two checkpoints of a registration
+const k8sComponentsCheckpointInterval = 100
+
+// newK8sComponentsRegistration returns a registration of components for the context.
+// When modelVersion is empty, the components are associated with the model of the version of the cluster.
+func newK8sComponentsRegistration(reg componentRegistry, ctxID, modelVersion string) *k8sComponentsRegistration {
+	return &k8sComponentsRegistration{
+		reg:          reg,
+		ctxID:        ctxID,
+		modelVersion: modelVersion,
+		host:         models.K8sComponentsHost(ctxID),
+		registered:   make(map[string]bool),
+		failed:       make(map[string]ComponentRegistrationFailure),
+		excluded:     make(map[string]ExcludedK8sComponent),
+		hook:         configuredK8sComponentHook(),
+		rejected:     make(map[string]ComponentRegistrationFailure),
+	}
+}
+
+// newK8sComponentsRegistrationWithOptions returns a registration of components for the context against the registry host,
+// restricted to the kinds and with the labels and the hook of the options
+func newK8sComponentsRegistrationWithOptions(reg componentRegistry, ctxID string, opts K8sComponentsRegistrationOptions) *k8sComponentsRegistration {
+	registration := newK8sComponentsRegistration(reg, ctxID, opts.ModelVersion)
+	registration.host = models.K8sComponentsHostFor(opts.Hostname, opts.HostMetadata, ctxID)
+	registration.allowKinds(opts.Kinds)
+	registration.labels = opts.Labels
+	if opts.Hook != nil {
+		registration.hook = opts.Hook
+	}
+	return registration
+}
+
+// register registers the component unless it was registered already, and reports whether it was registered.
+// Failures are recorded, a component which failed is attempted again when seen again (e.g. on a resumed registration).
+func (r *k8sComponentsRegistration) register(c v1alpha1.ComponentDefinition) bool {
+	key := c.APIVersion + "/" + c.Kind
+	if r.kinds != nil {
+		groupKind := k8sComponentGroupKind(c.APIVersion, c.Kind)
+		if !r.kinds[groupKind] {
+			return false
+		}
+		r.foundKinds[groupKind] = true
+	}
+	if r.registered[key] {
+		return false
+	}
+	if excluded, ok := r.confined[key]; ok {
+		r.excluded[key] = excluded
+		r.debug("excluded component ", key, " for context ", r.ctxID, " used in the namespaces ", excluded.Namespaces, " only")
+		r.report(c, models.K8sComponentExcluded, nil)
+		return false
+	}
+
+	if r.modelVersion != "" {
+		c.Model.Version = r.modelVersion
+	}
+	writeK8sMetadata(&c, r.reg, r.modelVersion, r.ctxID, r.labels)
+	// A rejected component is not a failure, it is not attempted again by a resumed registration
+	if err := r.hook.BeforeRegister(r.ctxID, &c); err != nil {
+		r.rejected[key] = ComponentRegistrationFailure{
+			Kind:       c.Kind,
+			APIVersion: c.APIVersion,
+			Reason:     err.Error(),
+		}
+		r.debug("component ", key, " for context ", r.ctxID, " rejected by the pre-registration hook: ", err)
+		r.report(c, models.K8sComponentRejected, err)
+		return false
+	}
+	delete(r.rejected, key)
+	if err := r.reg.RegisterEntity(r.host, c); err != nil {
+		r.failed[key] = ComponentRegistrationFailure{
+			Kind:       c.Kind,
+			APIVersion: c.APIVersion,
+			Reason:     err.Error(),
+		}
+		r.debug("failed to register component ", key, " for context ", r.ctxID, ": ", err)
+		r.report(c, models.K8sComponentFailed, err)
+		return false
+	}
+	delete(r.failed, key)
+	r.registered[key] = true
+	r.debug("registered component ", key, " for context ", r.ctxID)
+	r.report(c, models.K8sComponentRegistered, nil)
+	// The registration may not get to fail gracefully, e.g. when Meshery is restarted
+	if len(r.registered)%k8sComponentsCheckpointInterval == 0 {
+		r.saveCheckpoint(nil)
+	}
+	return true
+}
+
+// resume seeds the registration with the components registered by the last registration of the context, if it did not complete
+// with the same registry host and model version. Returns the number of components which are not registered again.
+func (r *k8sComponentsRegistration) resume() int {
+	if r.db == nil {
+		return 0
+	}
+	checkpoint, err := models.GetK8sComponentsRegistrationCheckpoint(r.db, r.ctxID)
+	if err != nil {
+		r.debug("failed to read the registration checkpoint of context ", r.ctxID, ": ", err)
+		return 0
+	}
+	if checkpoint == nil || checkpoint.Hostname != r.host.Hostname || checkpoint.ModelVersion != r.modelVersion {
+		return 0
+	}
+	for _, key := range checkpoint.Registered {
+		r.registered[key] = true
+	}
+	return len(checkpoint.Registered)
+}
+
+// checkpoint returns the components registered so far, ordered by apiVersion and kind
+func (r *k8sComponentsRegistration) checkpoint(err error) *models.K8sComponentsRegistrationCheckpoint {
+	checkpoint := &models.K8sComponentsRegistrationCheckpoint{
+		ContextID:    r.ctxID,
+		Hostname:     r.host.Hostname,
+		ModelVersion: r.modelVersion,
+		Registered:   make([]string, 0, len(r.registered)),
+	}
+	for key := range r.registered {
+		checkpoint.Registered = append(checkpoint.Registered, key)
+	}
+	sort.Strings(checkpoint.Registered)
+	if err != nil {
+		checkpoint.Error = err.Error()
+	}
+	return checkpoint
+}
+
+// saveCheckpoint persists the components registered so far, along with the error the registration failed with if any
+func (r *k8sComponentsRegistration) saveCheckpoint(err error) {
+	if r.db == nil {
+		return
+	}
+	if serr := models.SaveK8sComponentsRegistrationCheckpoint(r.db, r.checkpoint(err)); serr != nil {
+		r.debug("failed to save the registration checkpoint of context ", r.ctxID, ": ", serr)
+	}
+}
+
+// saveHost records the registry host the components are registered against, so that they are found again
+// when the components of the context are counted, listed, updated or unregistered
+func (r *k8sComponentsRegistration) saveHost() {
+	if r.db == nil {
+		return
+	}
+	if err := models.SaveK8sComponentsRegistryHost(r.db, r.ctxID, r.host); err != nil {
+		r.debug("failed to save the registry host of context ", r.ctxID, ": ", err)
+	}
+}
+
+// completeCheckpoint removes the checkpoint once all the components are registered,
+// it is kept while some failed to register so that the next registration registers those only
+func (r *k8sComponentsRegistration) completeCheckpoint() {
+	if r.db == nil {
+		return
+	}
+	if len(r.failed) > 0 {
+		r.saveCheckpoint(nil)
+		return
+	}
+	if err := models.DeleteK8sComponentsRegistrationCheckpoint(r.db, r.ctxID); err != nil {
+		r.debug("failed to remove the registration checkpoint of context ", r.ctxID, ": ", err)
+	}
+}
+
+// report passes the outcome of the component on to the progress of the registration, if any
+func (r *k8sComponentsRegistration) report(c v1alpha1.ComponentDefinition, status string, err error) {
+	if r.progress == nil {
+		return
+	}
+	outcome := models.K8sComponentRegistrationOutcome{ContextID: r.ctxID, Kind: c.Kind, APIVersion: c.APIVersion, Status: status}
+	if err != nil {
+		outcome.Error = err.Error()
+	}
+	r.progress(outcome)
+}
+
+func (r *k8sComponentsRegistration) debug(description ...interface{}) {
+	if r.log != nil {
+		r.log.Debug(description...)
+	}
+}
+
+// excludedComponents returns the components which were excluded, ordered by apiVersion and kind
+func (r *k8sComponentsRegistration) excludedComponents() []ExcludedK8sComponent {
+	excluded := make([]ExcludedK8sComponent, 0, len(r.excluded))
+	for _, e := range r.excluded {
+		excluded = append(excluded, e)
+	}
+	sort.Slice(excluded, func(i, j int) bool {
+		if excluded[i].APIVersion != excluded[j].APIVersion {
+			return excluded[i].APIVersion < excluded[j].APIVersion
+		}
+		return excluded[i].Kind < excluded[j].Kind
+	})
+	return excluded
+}
+
+// failures returns the components which failed to register, ordered by apiVersion and kind
+func (r *k8sComponentsRegistration) failures() []ComponentRegistrationFailure {
+	failures := make([]ComponentRegistrationFailure, 0, len(r.failed))
+	for _, f := range r.failed {
+		failures = append(failures, f)
+	}
+	sort.Slice(failures, func(i, j int) bool {
+		if failures[i].APIVersion != failures[j].APIVersion {
+			return failures[i].APIVersion < failures[j].APIVersion
+		}
+		return failures[i].Kind < failures[j].Kind
+	})
+	return failures
+}
+
+// confinedComponents returns the components confined to the excluded namespaces. The components are registered
+// without the exclusions when the custom resources cannot be listed, the failure is reported in the event.
+func (r *k8sComponentsRegistration) confinedComponents(ctx context.Context, config []byte) map[string]ExcludedK8sComponent {
+	cli, err := kubernetes.New(config)
+	if err == nil {
+		var confined map[string]ExcludedK8sComponent
+		if confined, err = k8sComponentsConfinedToNamespaces(ctx, cli, r.excludeNamespaces); err == nil {
+			return confined
+		}
+	}
+	r.exclusionFailure = ErrListExcludedK8sComponents(err, r.ctxID)
+	r.debug("failed to list the custom resources of the excluded namespaces for context ", r.ctxID, ": ", err)
+	return map[string]ExcludedK8sComponent{}
+}
+
+// writeK8sMetadata writes the metadata of the component from the registry, or from the model when the registry has none,
+// then merges in the labels given to the registration
+func writeK8sMetadata(comp *v1alpha1.ComponentDefinition, reg componentRegistry, modelVersion, ctxID string, labels map[string]string) {
+	defer applyK8sComponentLabels(comp, labels)
+	filter := &v1alpha1.ComponentFilter{
 		Name:       comp.Kind,
 		APIVersion: comp.APIVersion,
-	})
+	}
+	// Pick the metadata from the pinned version of the model only
+	if modelVersion != "" {
+		filter.ModelName = comp.Model.Name
+		filter.Version = modelVersion
+	}
+	ent, _, _ := reg.GetEntities(filter)
 	//If component was not available in the registry, then use the generic model level metadata
 	if len(ent) == 0 {
-		comp.Metadata = utils.MergeMaps(comp.Metadata, models.K8sMeshModelMetadata)
-		mutil.WriteSVGsOnFileSystem(comp)
+		applyK8sMeshModelMetadata(comp, ctxID)
 	} else {
 		existingComp, ok := ent[0].(v1alpha1.ComponentDefinition)
 		if !ok {
-			comp.Metadata = utils.MergeMaps(comp.Metadata, models.K8sMeshModelMetadata)
+			comp.Metadata = utils.MergeMaps(comp.Metadata, models.GetK8sMeshModelMetadata())
 			return
 		}
 		comp.Metadata = utils.MergeMaps(comp.Metadata, existingComp.Metadata)
@@ -92,6 +558,34 @@ func writeK8sMetadata(comp *v1alpha1.ComponentDefinition, reg *meshmodel.Registr
 	}
 }
 
+// applyK8sMeshModelMetadata merges the model level metadata of the Kubernetes model into the component and writes its SVGs
+func applyK8sMeshModelMetadata(comp *v1alpha1.ComponentDefinition, ctxID string) {
+	comp.Metadata = utils.MergeMaps(comp.Metadata, models.GetK8sMeshModelMetadata())
+	mutil.WriteSVGsForContext(comp, ctxID)
+}
+
+// K8sComponentsMetadataRefresh is the outcome of refreshing the metadata of the components registered for a kubernetes context
+type K8sComponentsMetadataRefresh struct {
+	Refreshed int `json:"refreshed"`
+}
+
+// RefreshK8sMeshModelComponentsMetadata merges the current model level metadata of the Kubernetes model into the
+// components registered by the host of the kubernetes context and persists them, regenerating their SVGs.
+// Useful after the model template was updated, without discovering the components of the cluster again.
+func RefreshK8sMeshModelComponentsMetadata(db *database.Handler, ctxID string) (K8sComponentsMetadataRefresh, error) {
+	unlock := lockK8sComponentsRegistration(ctxID)
+	defer unlock()
+
+	models.ReloadK8sMeshModelMetadata()
+	var result K8sComponentsMetadataRefresh
+	refreshed, err := models.UpdateK8sContextComponentsMetadata(db, ctxID, func(comp *v1alpha1.ComponentDefinition) bool {
+		applyK8sMeshModelMetadata(comp, ctxID)
+		return true
+	})
+	result.Refreshed = refreshed
+	return result, err
+}
+
 func RegisterMeshmodelComponentsForCRDS(reg meshmodel.RegistryManager, k8sYaml []byte, contextID string, version string) {
 	//TODO: Replace GenerateComponents in meshkit to natively produce MeshModel components to avoid any interconversion
 	comp, err := manifests.GenerateComponents(context.Background(), string(k8sYaml), manifests.K8s, manifests.Config{
@@ -146,46 +640,45 @@ type Entry struct {
 	URL string `json:"serverRelativeURL"`
 }
 
-func mergeAllAPIResults(content []byte, cli *kubernetes.Client) [][]byte {
-	var res OpenAPIV3Response
-	_ = json.Unmarshal(content, &res)
-	m := make([][]byte, 0)
-	for k, path := range res.Paths {
-		if !strings.HasPrefix(k, "api") {
-			continue
-		}
-		req := cli.KubeClient.RESTClient().Get().RequestURI(path.URL)
-		res := req.Do(context.Background())
-		content, err := res.Raw()
-		if err != nil {
-			return nil
-		}
-		m = append(m, content)
+// move to meshmodel
+func GetK8sMeshModelComponents(kubeconfig []byte) ([]v1alpha1.ComponentDefinition, error) {
+	components := make([]v1alpha1.ComponentDefinition, 0)
+	_, err := forEachK8sMeshModelComponent(context.Background(), kubeconfig, nil, func(c v1alpha1.ComponentDefinition) {
+		components = append(components, c)
+	})
+	if err != nil {
+		return nil, core.ErrGetK8sComponents(err)
 	}
-	return m
+	return components, nil
 }
 
-// move to meshmodel
-func GetK8sMeshModelComponents(kubeconfig []byte) ([]v1alpha1.ComponentDefinition, error) {
+// forEachK8sMeshModelComponent generates the components for the cluster one OpenAPI path at a time and invokes fn for each of them.
+// The API groups whose discovery failed (e.g. unavailable aggregated API services) are skipped and returned, the
+// components of the other API groups are still generated.
+// The OpenAPI paths which cannot be fetched are skipped, unless the credentials are rejected.
+// The generation stops when ctx is cancelled, with the error of the context.
+// The returned error is not wrapped so that callers can inspect the API status (eg: Unauthorized).
+// When groupVersions is not nil, only the components of those group versions are generated.
+func forEachK8sMeshModelComponent(ctx context.Context, kubeconfig []byte, groupVersions map[string]bool, fn func(v1alpha1.ComponentDefinition)) ([]APIGroupDiscoveryFailure, error) {
 	cli, err := kubernetes.New(kubeconfig)
 	if err != nil {
-		return nil, core.ErrGetK8sComponents(err)
+		return nil, err
 	}
 	req := cli.KubeClient.RESTClient().Get().RequestURI("/openapi/v3")
 	k8version, err := cli.KubeClient.ServerVersion()
 	if err != nil {
-		return nil, core.ErrGetK8sComponents(err)
+		return nil, err
 	}
 	var customResources = make(map[string]bool)
 	crdresult, err := cli.KubeClient.RESTClient().Get().RequestURI("/apis/apiextensions.k8s.io/v1/customresourcedefinitions").Do(context.Background()).Raw()
 	if err != nil {
-		return nil, core.ErrGetK8sComponents(err)
+		return nil, err
 	}
 
 	var xcrd crd
 	err = json.Unmarshal(crdresult, &xcrd)
 	if err != nil {
-		return nil, core.ErrGetK8sComponents(err)
+		return nil, err
 	}
 	for _, item := range xcrd.Items {
 		customResources[item.Spec.Names.Kind] = true
@@ -193,12 +686,15 @@ func GetK8sMeshModelComponents(kubeconfig []byte) ([]v1alpha1.ComponentDefinitio
 	res := req.Do(context.Background())
 	content, err := res.Raw()
 	if err != nil {
-		return nil, core.ErrGetK8sComponents(err)
+		return nil, err
 	}
-	contents := mergeAllAPIResults(content, cli)
-	apiResources, err := getAPIRes(cli)
+	apiResources, discoveryFailures, err := getAPIRes(cli)
 	if err != nil {
-		return nil, core.ErrGetK8sComponents(err)
+		return nil, err
+	}
+	failedGroupVersions := make(map[string]bool, len(discoveryFailures))
+	for _, f := range discoveryFailures {
+		failedGroupVersions[f.GroupVersion] = true
 	}
 
 	var arrAPIResources []string
@@ -207,37 +703,73 @@ func GetK8sMeshModelComponents(kubeconfig []byte) ([]v1alpha1.ComponentDefinitio
 		kindToNamespace[api.Kind] = api.Namespaced
 		arrAPIResources = append(arrAPIResources, res)
 	}
-	var crds []crdResponse
-	for _, content := range contents {
-		crds = append(crds, getCRDsFromManifest(string(content), arrAPIResources)...)
-	}
-	components := make([]v1alpha1.ComponentDefinition, 0)
-	for _, crd := range crds {
-		m := make(map[string]interface{})
-		m[customResourceKey] = customResources[crd.kind]
-		m[namespacedKey] = kindToNamespace[crd.kind]
-		apiVersion := crd.apiVersion
-		c := v1alpha1.ComponentDefinition{
-			Format: v1alpha1.JSON,
-			Schema: crd.schema,
-			TypeMeta: v1alpha1.TypeMeta{
-				Kind:       crd.kind,
-				APIVersion: apiVersion,
-			},
-			Metadata:    m,
-			DisplayName: manifests.FormatToReadableString(crd.kind),
-			Model: v1alpha1.Model{
-				Version:     k8version.String(),
-				Name:        "kubernetes",
-				DisplayName: "Kubernetes",
-				Category: v1alpha1.Category{
-					Name: "Orchestration & Management",
+
+	var openAPIRes OpenAPIV3Response
+	_ = json.Unmarshal(content, &openAPIRes)
+	for k, path := range openAPIRes.Paths {
+		if !strings.HasPrefix(k, "api") {
+			continue
+		}
+		groupVersion := openAPIPathGroupVersion(k)
+		if failedGroupVersions[groupVersion] || (groupVersions != nil && !groupVersions[groupVersion]) {
+			continue
+		}
+		if err := ctx.Err(); err != nil {
+			return discoveryFailures, err
+		}
+		content, err := cli.KubeClient.RESTClient().Get().RequestURI(path.URL).Do(ctx).Raw()
+		if err != nil {
+			if ctx.Err() != nil {
+				return discoveryFailures, ctx.Err()
+			}
+			// Expired credentials fail the remaining paths as well, the registration resumes once they are refreshed
+			if kerrors.IsUnauthorized(err) {
+				return discoveryFailures, err
+			}
+			// The schema of an aggregated API group is served by its API service, which may be unavailable.
+			if kerrors.IsServiceUnavailable(err) {
+				discoveryFailures = append(discoveryFailures, APIGroupDiscoveryFailure{GroupVersion: groupVersion, Reason: err.Error()})
+			}
+			continue
+		}
+		for _, crd := range getCRDsFromManifest(string(content), arrAPIResources) {
+			// Stop before registering (and writing the SVGs of) any further component
+			if err := ctx.Err(); err != nil {
+				return discoveryFailures, err
+			}
+			m := make(map[string]interface{})
+			m[customResourceKey] = customResources[crd.kind]
+			m[namespacedKey] = kindToNamespace[crd.kind]
+			apiVersion := crd.apiVersion
+			fn(v1alpha1.ComponentDefinition{
+				Format: v1alpha1.JSON,
+				Schema: crd.schema,
+				TypeMeta: v1alpha1.TypeMeta{
+					Kind:       crd.kind,
+					APIVersion: apiVersion,
 				},
-			},
+				Metadata:    m,
+				DisplayName: manifests.FormatToReadableString(crd.kind),
+				Model: v1alpha1.Model{
+					Version:     k8version.String(),
+					Name:        "kubernetes",
+					DisplayName: "Kubernetes",
+					Category: v1alpha1.Category{
+						Name: "Orchestration & Management",
+					},
+				},
+			})
 		}
-		components = append(components, c)
 	}
-	return components, nil
+	return discoveryFailures, nil
+}
+
+// openAPIPathGroupVersion returns the group version of an OpenAPI v3 path, e.g. "apps/v1" for "apis/apps/v1" and "v1" for "api/v1"
+func openAPIPathGroupVersion(path string) string {
+	if gv, ok := strings.CutPrefix(path, "apis/"); ok {
+		return gv
+	}
+	return strings.TrimPrefix(path, "api/")
 }
 
 const customResourceKey = "isCustomResource"
@@ -344,21 +876,48 @@ func getCRDsFromManifest(manifest string, arrAPIResources []string) []crdRespons
 	return res
 }
 
+// APIGroupDiscoveryFailure is an API group version whose discovery failed, the components of which could not be generated
+type APIGroupDiscoveryFailure struct {
+	GroupVersion string `json:"group_version"`
+	Reason       string `json:"reason"`
+}
+
 // TODO: To be moved in meshkit
 // getAPIRes gets all the available api resources from kube-api server. It is equivalent to the output of `kubectl api-resources`
-// Returns a map of api resources with key as api-resource kind and value as api-resource object
-func getAPIRes(cli *kubernetes.Client) (map[string]v1.APIResource, error) {
+// Returns a map of api resources with key as api-resource kind and value as api-resource object.
+// The discovery of some API groups failing does not fail it, the failed API groups are returned along with the resources of the others.
+func getAPIRes(cli *kubernetes.Client) (map[string]v1.APIResource, []APIGroupDiscoveryFailure, error) {
 	var apiRes = make(map[string]v1.APIResource)
 	lists, err := cli.KubeClient.DiscoveryClient.ServerPreferredResources()
+	failures, err := apiGroupDiscoveryFailures(err)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 	for _, list := range lists {
 		for _, name := range list.APIResources {
 			apiRes[name.Kind] = name
 		}
 	}
-	return apiRes, nil
+	return apiRes, failures, nil
+}
+
+// apiGroupDiscoveryFailures splits a partial discovery failure into the failed API groups, any other error is returned as is
+func apiGroupDiscoveryFailures(err error) ([]APIGroupDiscoveryFailure, error) {
+	if err == nil {
+		return nil, nil
+	}
+	var groupErr *discovery.ErrGroupDiscoveryFailed
+	if !errors.As(err, &groupErr) {
+		return nil, err
+	}
+	failures := make([]APIGroupDiscoveryFailure, 0, len(groupErr.Groups))
+	for gv, gerr := range groupErr.Groups {
+		failures = append(failures, APIGroupDiscoveryFailure{GroupVersion: gv.String(), Reason: gerr.Error()})
+	}
+	sort.Slice(failures, func(i, j int) bool {
+		return failures[i].GroupVersion < failures[j].GroupVersion
+	})
+	return failures, nil
 }
 
 // TODO: To be moved in meshkit
@@ -0,0 +1,523 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	mutil "github.com/layer5io/meshery/server/helpers/utils"
+	"github.com/layer5io/meshery/server/models"
+	"github.com/layer5io/meshkit/database"
+	"github.com/layer5io/meshkit/models/meshmodel/core/types"
+	"github.com/layer5io/meshkit/models/meshmodel/core/v1alpha1"
+	meshmodel "github.com/layer5io/meshkit/models/meshmodel/registry"
+	"github.com/spf13/viper"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/discovery"
+	metadatafake "k8s.io/client-go/metadata/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+// failingRegistry is a registry stub which fails to register the components of the given kinds
+type failingRegistry struct {
+	failKinds  map[string]bool
+	registered []string
+	// metadata of the existing components, components holds the registered ones
+	metadata   map[string]interface{}
+	components []v1alpha1.ComponentDefinition
+}
+
+func (fr *failingRegistry) RegisterEntity(_ meshmodel.Host, en meshmodel.Entity) error {
+	comp := en.(v1alpha1.ComponentDefinition)
+	if fr.failKinds[comp.Kind] {
+		return fmt.Errorf("unable to register %s", comp.Kind)
+	}
+	fr.registered = append(fr.registered, comp.Kind)
+	fr.components = append(fr.components, comp)
+	return nil
+}
+
+func (fr *failingRegistry) GetEntities(f types.Filter) ([]meshmodel.Entity, *int64, *int) {
+	// Return an existing component so that the metadata is taken from the registry
+	metadata := make(map[string]interface{}, len(fr.metadata))
+	for key, value := range fr.metadata {
+		metadata[key] = value
+	}
+	return []meshmodel.Entity{v1alpha1.ComponentDefinition{Model: v1alpha1.Model{Name: "kubernetes"}, Metadata: metadata}}, nil, nil
+}
+
+func TestRegisterK8sMeshModelComponentsPerComponentErrors(t *testing.T) {
+	reg := &failingRegistry{failKinds: map[string]bool{"Deployment": true}}
+	comps := []v1alpha1.ComponentDefinition{
+		{TypeMeta: v1alpha1.TypeMeta{Kind: "Pod", APIVersion: "v1"}},
+		{TypeMeta: v1alpha1.TypeMeta{Kind: "Deployment", APIVersion: "apps/v1"}},
+		{TypeMeta: v1alpha1.TypeMeta{Kind: "Service", APIVersion: "v1"}},
+	}
+
+	count, failures, err := registerK8sMeshModelComponentsFromManifest(comps, newK8sComponentsRegistration(reg, "ctx", ""))
+	if err != nil {
+		t.Fatalf("registration failed with error: %s", err)
+	}
+	if count != 2 {
+		t.Errorf("registered count = %d, want 2 (the failed component must not be counted)", count)
+	}
+	if len(reg.registered) != 2 || reg.registered[0] != "Pod" || reg.registered[1] != "Service" {
+		t.Errorf("registered components = %v, want the registration to continue past the failure", reg.registered)
+	}
+	if len(failures) != 1 || failures[0].Kind != "Deployment" || failures[0].Reason == "" {
+		t.Errorf("failures = %+v, want Deployment with its reason", failures)
+	}
+}
+
+func TestAPIGroupDiscoveryFailures(t *testing.T) {
+	err := &discovery.ErrGroupDiscoveryFailed{Groups: map[schema.GroupVersion]error{
+		{Group: "metrics.k8s.io", Version: "v1beta1"}:        fmt.Errorf("the server is currently unable to handle the request"),
+		{Group: "custom.metrics.k8s.io", Version: "v1beta1"}: fmt.Errorf("the server is currently unable to handle the request"),
+	}}
+
+	failures, rerr := apiGroupDiscoveryFailures(fmt.Errorf("unable to retrieve the complete list of server APIs: %w", err))
+	if rerr != nil {
+		t.Fatalf("apiGroupDiscoveryFailures() returned error %v, want the failed groups", rerr)
+	}
+	if len(failures) != 2 || failures[0].GroupVersion != "custom.metrics.k8s.io/v1beta1" || failures[1].GroupVersion != "metrics.k8s.io/v1beta1" {
+		t.Errorf("failures = %+v, want the sorted failed group versions", failures)
+	}
+
+	if _, rerr := apiGroupDiscoveryFailures(fmt.Errorf("connection refused")); rerr == nil {
+		t.Error("apiGroupDiscoveryFailures() expected other errors to be returned")
+	}
+}
+
+func TestOpenAPIPathGroupVersion(t *testing.T) {
+	for path, want := range map[string]string{
+		"api/v1":                      "v1",
+		"apis/apps/v1":                "apps/v1",
+		"apis/metrics.k8s.io/v1beta1": "metrics.k8s.io/v1beta1",
+	} {
+		if got := openAPIPathGroupVersion(path); got != want {
+			t.Errorf("openAPIPathGroupVersion(%q) = %q, want %q", path, got, want)
+		}
+	}
+}
+
+// overlapRegistry is a registry stub which records whether the registrations against the same host overlapped
+type overlapRegistry struct {
+	mu         sync.Mutex
+	inflight   map[string]int
+	overlapped bool
+}
+
+func (or *overlapRegistry) RegisterEntity(h meshmodel.Host, _ meshmodel.Entity) error {
+	or.mu.Lock()
+	or.inflight[h.Metadata]++
+	or.overlapped = or.overlapped || or.inflight[h.Metadata] > 1
+	or.mu.Unlock()
+	time.Sleep(time.Millisecond)
+	or.mu.Lock()
+	or.inflight[h.Metadata]--
+	or.mu.Unlock()
+	return nil
+}
+
+func (or *overlapRegistry) GetEntities(_ types.Filter) ([]meshmodel.Entity, *int64, *int) {
+	return nil, nil, nil
+}
+
+// svgRecorder is an SVG store recording the keys of the SVGs written to it
+type svgRecorder struct {
+	mu   sync.Mutex
+	keys []string
+}
+
+func (sr *svgRecorder) Put(_ context.Context, key string, _ []byte) (string, error) {
+	sr.mu.Lock()
+	defer sr.mu.Unlock()
+	sr.keys = append(sr.keys, key)
+	return key, nil
+}
+
+func TestRegisterK8sMeshModelComponentsConcurrentUsers(t *testing.T) {
+	store := &svgRecorder{}
+	defer mutil.SetSVGStore(mutil.GetSVGStore())
+	mutil.SetSVGStore(store)
+
+	reg := &overlapRegistry{inflight: make(map[string]int)}
+	manifest := func() []v1alpha1.ComponentDefinition {
+		comps := make([]v1alpha1.ComponentDefinition, 0, 10)
+		for i := 0; i < 10; i++ {
+			comps = append(comps, v1alpha1.ComponentDefinition{
+				TypeMeta: v1alpha1.TypeMeta{Kind: fmt.Sprintf("Kind%d", i), APIVersion: "v1"},
+				Metadata: map[string]interface{}{"svgColor": fmt.Sprintf("<svg id=%q/>", i)},
+			})
+		}
+		return comps
+	}
+
+	// Several users registering the same clusters at once
+	var wg sync.WaitGroup
+	for _, ctxID := range []string{"ctx-a", "ctx-a", "ctx-b", "ctx-b"} {
+		wg.Add(1)
+		go func(ctxID string) {
+			defer wg.Done()
+			if _, _, err := registerK8sMeshModelComponentsFromManifest(manifest(), newK8sComponentsRegistration(reg, ctxID, "")); err != nil {
+				t.Errorf("registration of %s failed with error: %s", ctxID, err)
+			}
+		}(ctxID)
+	}
+	wg.Wait()
+
+	if reg.overlapped {
+		t.Error("the registrations of the same context overlapped, want them serialized")
+	}
+	if len(store.keys) == 0 {
+		t.Fatal("no svg was written")
+	}
+	for _, key := range store.keys {
+		if !strings.HasPrefix(key, "kubernetes/ctx-a/") && !strings.HasPrefix(key, "kubernetes/ctx-b/") {
+			t.Errorf("svg written to %s, want the svgs written under the directory of their context", key)
+		}
+	}
+}
+
+func TestRegisterK8sMeshModelComponentsExcludedNamespaces(t *testing.T) {
+	excluded := map[string]bool{"kube-system": true}
+	if _, ok := confinedK8sComponent("example.com/v1", "Widget", map[string]bool{"kube-system": true, "default": true}, excluded); ok {
+		t.Error("confinedK8sComponent() expected a component also used outside of the excluded namespaces to be kept")
+	}
+	confined, ok := confinedK8sComponent("example.com/v1", "Gadget", map[string]bool{"kube-system": true}, excluded)
+	if !ok {
+		t.Fatal("confinedK8sComponent() expected a component used in the excluded namespaces only to be excluded")
+	}
+
+	reg := &failingRegistry{}
+	registration := newK8sComponentsRegistration(reg, "ctx", "")
+	registration.confined = map[string]ExcludedK8sComponent{"example.com/v1/Gadget": confined}
+	comps := []v1alpha1.ComponentDefinition{
+		{TypeMeta: v1alpha1.TypeMeta{Kind: "Widget", APIVersion: "example.com/v1"}},
+		{TypeMeta: v1alpha1.TypeMeta{Kind: "Gadget", APIVersion: "example.com/v1"}},
+	}
+	count, _, err := registerK8sMeshModelComponentsFromManifest(comps, registration)
+	if err != nil {
+		t.Fatalf("registration failed with error: %s", err)
+	}
+	if excluded := registration.excludedComponents(); count != 1 || len(excluded) != 1 || excluded[0].Kind != "Gadget" || excluded[0].Namespaces[0] != "kube-system" {
+		t.Errorf("registered %d components, excluded %+v, want Gadget excluded for kube-system", count, excluded)
+	}
+}
+
+func TestConfinedK8sComponents(t *testing.T) {
+	instance := func(apiVersion, kind, namespace, name string) *metav1.PartialObjectMetadata {
+		return &metav1.PartialObjectMetadata{
+			TypeMeta:   metav1.TypeMeta{APIVersion: apiVersion, Kind: kind},
+			ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: name},
+		}
+	}
+	scheme := metadatafake.NewTestScheme()
+	if err := metav1.AddMetaToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	client := metadatafake.NewSimpleMetadataClient(scheme,
+		instance("example.com/v1", "Widget", "kube-system", "a"),
+		instance("example.com/v1", "Widget", "default", "b"),
+		instance("example.com/v1", "Gadget", "kube-system", "c"),
+		instance("example.com/v1", "Gizmo", "kube-system", "d"),
+	)
+	// The instances of gizmos cannot be listed
+	client.PrependReactor("list", "gizmos", func(k8stesting.Action) (bool, runtime.Object, error) {
+		return true, nil, fmt.Errorf("gizmos are forbidden")
+	})
+	crds := []K8sCRD{
+		{Group: "example.com", Version: "v1", Kind: "Widget", Resource: "widgets", Scope: "Namespaced"},
+		{Group: "example.com", Version: "v1", Kind: "Gadget", Resource: "gadgets", Scope: "Namespaced"},
+		{Group: "example.com", Version: "v1", Kind: "Gizmo", Resource: "gizmos", Scope: "Namespaced"},
+		{Group: "example.com", Version: "v1", Kind: "Doohickey", Resource: "doohickeys", Scope: "Namespaced"},
+		{Group: "example.com", Version: "v1", Kind: "Cluster", Resource: "clusters", Scope: "Cluster"},
+	}
+
+	confined, err := confinedK8sComponents(context.Background(), crds, client, []string{"kube-system"})
+	if err != nil {
+		t.Fatalf("confinedK8sComponents() error = %v", err)
+	}
+	want := map[string]ExcludedK8sComponent{
+		"example.com/v1/Gadget": {Kind: "Gadget", APIVersion: "example.com/v1", Namespaces: []string{"kube-system"}},
+	}
+	if !reflect.DeepEqual(confined, want) {
+		t.Errorf("confinedK8sComponents() = %+v, want %+v", confined, want)
+	}
+}
+
+func TestRegisterK8sMeshModelComponentsExclusionFailure(t *testing.T) {
+	registration := newK8sComponentsRegistration(&failingRegistry{}, "ctx", "")
+	registration.excludeNamespaces = []string{"kube-system"}
+	// The custom resources of an unreachable cluster cannot be listed, the components are registered without the exclusions
+	confined := registration.confinedComponents(context.Background(), []byte("not a kubeconfig"))
+	if confined == nil || len(confined) != 0 {
+		t.Errorf("confinedComponents() = %v, want no component confined", confined)
+	}
+	if registration.exclusionFailure == nil {
+		t.Error("confinedComponents() expected the failure to be recorded")
+	}
+}
+
+func TestExcludedNamespacesFromConfig(t *testing.T) {
+	defer viper.Set("KUBERNETES_COMPONENT_EXCLUDED_NAMESPACES", nil)
+	for _, tc := range []struct {
+		value interface{}
+		want  []string
+	}{
+		{value: DefaultExcludedNamespaces, want: DefaultExcludedNamespaces},
+		{value: "kube-system, istio-system,,", want: []string{"kube-system", "istio-system"}},
+		{value: " ", want: []string{}},
+	} {
+		viper.Set("KUBERNETES_COMPONENT_EXCLUDED_NAMESPACES", tc.value)
+		if got := ExcludedNamespacesFromConfig(); !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("ExcludedNamespacesFromConfig() of %q = %q, want %q", tc.value, got, tc.want)
+		}
+	}
+}
+
+func TestRegisterK8sMeshModelComponentsResumesFromCheckpoint(t *testing.T) {
+	db, err := database.New(database.Options{Engine: database.SQLITE, Filename: filepath.Join(t.TempDir(), "checkpoints.db")})
+	if err != nil {
+		t.Fatalf("failed to open the database: %s", err)
+	}
+	if err := db.AutoMigrate(&models.K8sComponentsRegistrationCheckpoint{}); err != nil {
+		t.Fatalf("failed to migrate the database: %s", err)
+	}
+	comps := []v1alpha1.ComponentDefinition{
+		{TypeMeta: v1alpha1.TypeMeta{Kind: "Pod", APIVersion: "v1"}},
+		{TypeMeta: v1alpha1.TypeMeta{Kind: "Deployment", APIVersion: "apps/v1"}},
+		{TypeMeta: v1alpha1.TypeMeta{Kind: "Service", APIVersion: "v1"}},
+	}
+
+	// The registry is briefly down while Deployment is registered
+	registration := newK8sComponentsRegistration(&failingRegistry{failKinds: map[string]bool{"Deployment": true}}, "ctx", "")
+	registration.db = &db
+	if _, _, err := registerK8sMeshModelComponentsFromManifest(comps, registration); err != nil {
+		t.Fatalf("registration failed with error: %s", err)
+	}
+	registration.completeCheckpoint()
+	checkpoint, err := models.GetK8sComponentsRegistrationCheckpoint(&db, "ctx")
+	if err != nil || checkpoint == nil {
+		t.Fatalf("GetK8sComponentsRegistrationCheckpoint() = %v, %v, want the checkpoint of the incomplete registration", checkpoint, err)
+	}
+	if want := []string{"v1/Pod", "v1/Service"}; strings.Join(checkpoint.Registered, ",") != strings.Join(want, ",") {
+		t.Errorf("checkpoint registered %v, want %v", checkpoint.Registered, want)
+	}
+
+	reg := &failingRegistry{}
+	registration = newK8sComponentsRegistration(reg, "ctx", "")
+	registration.db = &db
+	if resumed := registration.resume(); resumed != 2 {
+		t.Errorf("resume() = %d, want the 2 components of the checkpoint", resumed)
+	}
+	count, _, err := registerK8sMeshModelComponentsFromManifest(comps, registration)
+	if err != nil {
+		t.Fatalf("registration failed with error: %s", err)
+	}
+	if count != 1 || len(reg.registered) != 1 || reg.registered[0] != "Deployment" {
+		t.Errorf("registered %v, want the remaining Deployment only", reg.registered)
+	}
+	registration.completeCheckpoint()
+	if checkpoint, err := models.GetK8sComponentsRegistrationCheckpoint(&db, "ctx"); err != nil || checkpoint != nil {
+		t.Errorf("GetK8sComponentsRegistrationCheckpoint() = %v, %v, want the checkpoint removed once the registration completed", checkpoint, err)
+	}
+}
+
+func TestRegisterK8sMeshModelComponentsKinds(t *testing.T) {
+	if _, err := ParseK8sComponentKinds("apps/"); err == nil {
+		t.Error("ParseK8sComponentKinds() expected an error for a group without kind")
+	}
+	kinds, err := ParseK8sComponentKinds(" apps/Deployment, core/Pod,Pod,example.com/Widgte")
+	if err != nil {
+		t.Fatalf("ParseK8sComponentKinds() failed with error: %s", err)
+	}
+	if want := "apps/Deployment,Pod,example.com/Widgte"; strings.Join(kinds, ",") != want {
+		t.Errorf("ParseK8sComponentKinds() = %v, want %s", kinds, want)
+	}
+
+	reg := &failingRegistry{}
+	registration := newK8sComponentsRegistration(reg, "ctx", "")
+	registration.allowKinds(kinds)
+	comps := []v1alpha1.ComponentDefinition{
+		{TypeMeta: v1alpha1.TypeMeta{Kind: "Pod", APIVersion: "v1"}},
+		{TypeMeta: v1alpha1.TypeMeta{Kind: "Deployment", APIVersion: "apps/v1"}},
+		{TypeMeta: v1alpha1.TypeMeta{Kind: "Deployment", APIVersion: "example.com/v1"}},
+		{TypeMeta: v1alpha1.TypeMeta{Kind: "Service", APIVersion: "v1"}},
+		{TypeMeta: v1alpha1.TypeMeta{Kind: "Widget", APIVersion: "example.com/v1"}},
+	}
+	count, _, err := registerK8sMeshModelComponentsFromManifest(comps, registration)
+	if err != nil {
+		t.Fatalf("registration failed with error: %s", err)
+	}
+	if count != 2 || strings.Join(reg.registered, ",") != "Pod,Deployment" {
+		t.Errorf("registered %v, want the requested kinds only", reg.registered)
+	}
+	if notFound := registration.kindsNotFound(); len(notFound) != 1 || notFound[0] != "example.com/Widgte" {
+		t.Errorf("kindsNotFound() = %v, want the misspelled kind", notFound)
+	}
+}
+
+func TestRegisterK8sMeshModelComponentsLabels(t *testing.T) {
+	if _, err := ParseK8sComponentLabels(`["team"]`); err == nil {
+		t.Error("ParseK8sComponentLabels() expected an error for labels which are not an object")
+	}
+	if _, err := ParseK8sComponentLabels(`{" ": "payments"}`); err == nil {
+		t.Error("ParseK8sComponentLabels() expected an error for an empty key")
+	}
+	labels, err := ParseK8sComponentLabels(`{"team": "payments", "cost-center": "cc-42", "genealogy": "overridden"}`)
+	if err != nil {
+		t.Fatalf("ParseK8sComponentLabels() failed with error: %s", err)
+	}
+
+	reg := &failingRegistry{metadata: map[string]interface{}{"genealogy": "parent"}}
+	registration := newK8sComponentsRegistration(reg, "ctx", "")
+	registration.labels = labels
+	comps := []v1alpha1.ComponentDefinition{
+		{TypeMeta: v1alpha1.TypeMeta{Kind: "Pod", APIVersion: "v1"}},
+		{TypeMeta: v1alpha1.TypeMeta{Kind: "Deployment", APIVersion: "apps/v1"}},
+	}
+	if _, _, err := registerK8sMeshModelComponentsFromManifest(comps, registration); err != nil {
+		t.Fatalf("registration failed with error: %s", err)
+	}
+	if len(reg.components) != 2 {
+		t.Fatalf("registered %v, want both components", reg.registered)
+	}
+	for _, comp := range reg.components {
+		if comp.Metadata["team"] != "payments" || comp.Metadata["cost-center"] != "cc-42" {
+			t.Errorf("metadata of %s = %v, want the labels", comp.Kind, comp.Metadata)
+		}
+		if comp.Metadata["genealogy"] != "parent" {
+			t.Errorf("metadata of %s = %v, want the label not to overwrite the model metadata", comp.Kind, comp.Metadata)
+		}
+	}
+}
+
+func TestRegisterK8sMeshModelComponentsHook(t *testing.T) {
+	reg := &failingRegistry{}
+	registration := newK8sComponentsRegistration(reg, "ctx", "")
+	if _, ok := registration.hook.(NoopK8sComponentHook); !ok {
+		t.Fatalf("hook = %T, want the no-op hook when none is configured", registration.hook)
+	}
+	registration.hook = K8sComponentHookFunc(func(ctxID string, comp *v1alpha1.ComponentDefinition) error {
+		if comp.Kind == "Secret" {
+			return fmt.Errorf("secrets are not modeled in %s", ctxID)
+		}
+		comp.DisplayName = "acme-" + strings.ToLower(comp.Kind)
+		return nil
+	})
+	comps := []v1alpha1.ComponentDefinition{
+		{TypeMeta: v1alpha1.TypeMeta{Kind: "Pod", APIVersion: "v1"}},
+		{TypeMeta: v1alpha1.TypeMeta{Kind: "Secret", APIVersion: "v1"}},
+	}
+
+	count, failures, err := registerK8sMeshModelComponentsFromManifest(comps, registration)
+	if err != nil {
+		t.Fatalf("registration failed with error: %s", err)
+	}
+	if count != 1 || len(reg.components) != 1 || reg.components[0].DisplayName != "acme-pod" {
+		t.Errorf("registered %+v, want the Pod as modified by the hook only", reg.components)
+	}
+	if len(failures) != 0 {
+		t.Errorf("failures = %+v, want the rejected component not to count as a failure", failures)
+	}
+	if rejected := registration.rejectedComponents(); len(rejected) != 1 || rejected[0].Kind != "Secret" || rejected[0].Reason != "secrets are not modeled in ctx" {
+		t.Errorf("rejected = %+v, want the Secret with the reason given by the hook", rejected)
+	}
+}
+
+// fakeK8sAPIServer serves the discovery and the OpenAPI of a cluster with the pods of the core group and the deployments of apps/v1,
+// the OpenAPI schema of apps/v1 responds with the given status
+func fakeK8sAPIServer(t *testing.T, appsStatus int) []byte {
+	t.Helper()
+	schema := func(group, version, kind string) string {
+		return fmt.Sprintf(`{"components":{"schemas":{"%s":{"type":"object","properties":{"spec":{"type":"object"}},"x-kubernetes-group-version-kind":[{"group":%q,"kind":%q,"version":%q}]}}}}`, kind, group, kind, version)
+	}
+	responses := map[string]string{
+		"/version": `{"major":"1","minor":"27","gitVersion":"v1.27.3"}`,
+		"/apis/apiextensions.k8s.io/v1/customresourcedefinitions": `{"items":[]}`,
+		"/api":                     `{"kind":"APIVersions","versions":["v1"]}`,
+		"/apis":                    `{"kind":"APIGroupList","groups":[{"name":"apps","versions":[{"groupVersion":"apps/v1","version":"v1"}],"preferredVersion":{"groupVersion":"apps/v1","version":"v1"}}]}`,
+		"/api/v1":                  `{"kind":"APIResourceList","groupVersion":"v1","resources":[{"name":"pods","kind":"Pod","namespaced":true,"verbs":["get","list"]}]}`,
+		"/apis/apps/v1":            `{"kind":"APIResourceList","groupVersion":"apps/v1","resources":[{"name":"deployments","kind":"Deployment","namespaced":true,"verbs":["get","list"]}]}`,
+		"/openapi/v3":              `{"paths":{"api/v1":{"serverRelativeURL":"/openapi/v3/api/v1"},"apis/apps/v1":{"serverRelativeURL":"/openapi/v3/apis/apps/v1"}}}`,
+		"/openapi/v3/api/v1":       schema("", "v1", "Pod"),
+		"/openapi/v3/apis/apps/v1": schema("apps", "v1", "Deployment"),
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, ok := responses[r.URL.Path]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		if r.URL.Path == "/openapi/v3/apis/apps/v1" && appsStatus != http.StatusOK {
+			w.WriteHeader(appsStatus)
+			_, _ = w.Write([]byte(`{"kind":"Status","apiVersion":"v1","status":"Failure"}`))
+			return
+		}
+		_, _ = w.Write([]byte(body))
+	}))
+	t.Cleanup(server.Close)
+	return []byte(fmt.Sprintf(`apiVersion: v1
+kind: Config
+clusters:
+- name: fake
+  cluster:
+    server: %s
+contexts:
+- name: fake
+  context:
+    cluster: fake
+    user: fake
+current-context: fake
+users:
+- name: fake
+  user:
+    token: fake
+`, server.URL))
+}
+
+func TestForEachK8sMeshModelComponent(t *testing.T) {
+	generated := func(kubeconfig []byte) ([]string, []APIGroupDiscoveryFailure, error) {
+		kinds := []string{}
+		failures, err := forEachK8sMeshModelComponent(context.Background(), kubeconfig, nil, func(c v1alpha1.ComponentDefinition) {
+			kinds = append(kinds, c.APIVersion+"/"+c.Kind)
+		})
+		sort.Strings(kinds)
+		return kinds, failures, err
+	}
+
+	kinds, failures, err := generated(fakeK8sAPIServer(t, http.StatusOK))
+	if err != nil || len(failures) != 0 || !reflect.DeepEqual(kinds, []string{"apps/v1/Deployment", "v1/Pod"}) {
+		t.Errorf("forEachK8sMeshModelComponent() = %v, %v, %v, want the pods and the deployments", kinds, failures, err)
+	}
+
+	// A path which cannot be fetched is skipped, the components of the other paths are still generated
+	kinds, failures, err = generated(fakeK8sAPIServer(t, http.StatusInternalServerError))
+	if err != nil || len(failures) != 0 || !reflect.DeepEqual(kinds, []string{"v1/Pod"}) {
+		t.Errorf("forEachK8sMeshModelComponent() = %v, %v, %v, want the pods only", kinds, failures, err)
+	}
+
+	// The schema of an unavailable aggregated API service is reported as a discovery failure
+	kinds, failures, err = generated(fakeK8sAPIServer(t, http.StatusServiceUnavailable))
+	if err != nil || len(failures) != 1 || failures[0].GroupVersion != "apps/v1" || !reflect.DeepEqual(kinds, []string{"v1/Pod"}) {
+		t.Errorf("forEachK8sMeshModelComponent() = %v, %v, %v, want the pods along with the failure of apps/v1", kinds, failures, err)
+	}
+
+	// Rejected credentials fail the generation so that they are refreshed
+	if _, _, err = generated(fakeK8sAPIServer(t, http.StatusUnauthorized)); !kerrors.IsUnauthorized(err) {
+		t.Errorf("forEachK8sMeshModelComponent() error = %v, want unauthorized", err)
+	}
+}
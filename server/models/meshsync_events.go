@@ -26,6 +26,8 @@ type MeshsyncDataHandler struct {
 	ConnectionID uuid.UUID
 	InstanceID   uuid.UUID
 	Token        string
+	// resync is the interval at which MeshSync is asked for its store again, see SetSyncInterval
+	resync *meshsyncResync
 }
 
 func NewMeshsyncDataHandler(broker broker.Handler, dbHandler database.Handler, log logger.Handler, provider Provider, userID, connID, instanceID uuid.UUID, token string) *MeshsyncDataHandler {
@@ -38,6 +40,7 @@ func NewMeshsyncDataHandler(broker broker.Handler, dbHandler database.Handler, l
 		ConnectionID: connID,
 		InstanceID:   instanceID,
 		Token:        token,
+		resync:       newMeshsyncResync(0),
 	}
 }
 
@@ -57,6 +60,7 @@ func (mh *MeshsyncDataHandler) Run() error {
 		if err != nil {
 			return err
 		}
+		go mh.resyncPeriodically()
 	}
 
 	return nil
@@ -115,6 +119,7 @@ func (mh *MeshsyncDataHandler) subsribeToStoreUpdates(statusChan chan bool) {
 
 		objectsSlice := storeUpdate.Object.([]interface{})
 
+		persisted := 0
 		for _, object := range objectsSlice {
 			obj, err := mh.Unmarshal(object)
 			if err != nil {
@@ -126,7 +131,9 @@ func (mh *MeshsyncDataHandler) subsribeToStoreUpdates(statusChan chan bool) {
 				mh.log.Error(err)
 				continue
 			}
+			persisted++
 		}
+		notifyMeshsyncStorePersisted(mh.ConnectionID, persisted)
 	}
 }
 
@@ -173,6 +180,7 @@ func (mh *MeshsyncDataHandler) meshsyncEventsAccumulator(event *broker.Message)
 		if result.Error != nil {
 			return ErrDBPut(result.Error)
 		}
+		recordMeshSyncActivity(mh.ConnectionID)
 		return nil
 	case broker.Delete:
 		result := mh.dbHandler.Delete(&obj)
@@ -181,6 +189,7 @@ func (mh *MeshsyncDataHandler) meshsyncEventsAccumulator(event *broker.Message)
 		}
 	}
 
+	recordMeshSyncActivity(mh.ConnectionID)
 	mh.log.Info("Updated database in response to ", event.EventType, " event of object: ", obj.KubernetesResourceMeta.Name, " in namespace: ", obj.KubernetesResourceMeta.Namespace, " of kind: ", obj.Kind)
 
 	return nil
@@ -201,9 +210,11 @@ func (mh *MeshsyncDataHandler) persistStoreUpdate(object *meshsyncmodel.Kubernet
 		if result.Error != nil {
 			return ErrDBPut(result.Error)
 		}
+		recordMeshSyncActivity(mh.ConnectionID)
 		mh.log.Info("Updated object: ", object.KubernetesResourceMeta.Name, "/", object.KubernetesResourceMeta.Namespace, " of kind: ", object.Kind, " in the database")
 		return nil
 	}
+	recordMeshSyncActivity(mh.ConnectionID)
 	mh.log.Info("Added object: ", object.KubernetesResourceMeta.Name, "/", object.KubernetesResourceMeta.Namespace, " of kind: ", object.Kind, " to the database")
 
 	return nil
@@ -261,10 +272,10 @@ func (mh *MeshsyncDataHandler) getComponentMetadata(apiVersion string, kind stri
 	if result.Error != nil {
 		if result.Error == gorm.ErrRecordNotFound {
 			mh.log.Error(ErrResultNotFound(result.Error))
-			metadata = K8sMeshModelMetadata
+			metadata = GetK8sMeshModelMetadata()
 		} else {
 			mh.log.Error(ErrDBRead(result.Error))
-			metadata = K8sMeshModelMetadata
+			metadata = GetK8sMeshModelMetadata()
 		}
 	}
 
@@ -0,0 +1,96 @@
+package models
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/gofrs/uuid"
+	"github.com/layer5io/meshkit/models/controllers"
+	"github.com/layer5io/meshkit/utils/kubernetes"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// Namespace and labels of the MeshSync pods deployed by Meshery Operator
+const (
+	meshSyncNamespace     = "meshery"
+	meshSyncLabelSelector = "component=meshsync"
+)
+
+// MeshSyncPodStatus is the status of a MeshSync pod
+type MeshSyncPodStatus struct {
+	Name     string `json:"name"`
+	Phase    string `json:"phase"`
+	Ready    bool   `json:"ready"`
+	Restarts int32  `json:"restarts"`
+}
+
+// MeshSyncHealth reports the health of MeshSync, which discovers the resources of the cluster of a context
+type MeshSyncHealth struct {
+	Deployed bool `json:"deployed"`
+	// Status of the MeshSync controller, e.g. "Running" or "Connected" once connected to the broker
+	Status string              `json:"status"`
+	Pods   []MeshSyncPodStatus `json:"pods"`
+	// LastSyncAt is when the last resource discovered by MeshSync was persisted, nil when none was since Meshery started
+	LastSyncAt *time.Time `json:"last_sync_at,omitempty"`
+	// OperatorUndeployed reports whether Meshery Operator, and MeshSync along, was undeployed on purpose
+	OperatorUndeployed bool `json:"operator_undeployed"`
+	Healthy            bool `json:"healthy"`
+}
+
+// meshSyncLastSync holds the time of the last resource discovered by MeshSync persisted for each connection
+var meshSyncLastSync sync.Map
+
+func recordMeshSyncActivity(connectionID uuid.UUID) {
+	meshSyncLastSync.Store(connectionID.String(), time.Now())
+}
+
+// LastMeshSyncAt returns when the last resource discovered by MeshSync was persisted for the connection
+func LastMeshSyncAt(connectionID string) *time.Time {
+	v, ok := meshSyncLastSync.Load(connectionID)
+	if !ok {
+		return nil
+	}
+	t := v.(time.Time)
+	return &t
+}
+
+// GetMeshSyncHealth reports the health of MeshSync in the cluster of the context.
+// MeshSync is healthy when deployed with all of its pods ready.
+func GetMeshSyncHealth(ctx context.Context, kubeclient *kubernetes.Client, k8sContext K8sContext, connectionID string, ot *OperatorTracker) (MeshSyncHealth, error) {
+	status := controllers.NewMeshsyncHandler(kubeclient).GetStatus()
+	health := MeshSyncHealth{
+		Status:     status.String(),
+		Pods:       []MeshSyncPodStatus{},
+		LastSyncAt: LastMeshSyncAt(connectionID),
+	}
+	if ot != nil {
+		health.OperatorUndeployed = ot.IsUndeployed(k8sContext.ID)
+	}
+	switch status {
+	case controllers.NotDeployed, controllers.Undeployed, controllers.Unknown:
+		return health, nil
+	}
+	health.Deployed = true
+
+	pods, err := kubeclient.KubeClient.CoreV1().Pods(meshSyncNamespace).List(ctx, metav1.ListOptions{LabelSelector: meshSyncLabelSelector})
+	if err != nil {
+		return health, err
+	}
+	health.Healthy = len(pods.Items) > 0
+	for _, pod := range pods.Items {
+		podStatus := MeshSyncPodStatus{Name: pod.Name, Phase: string(pod.Status.Phase)}
+		for _, cond := range pod.Status.Conditions {
+			if cond.Type == corev1.PodReady {
+				podStatus.Ready = cond.Status == corev1.ConditionTrue
+			}
+		}
+		for _, cs := range pod.Status.ContainerStatuses {
+			podStatus.Restarts += cs.RestartCount
+		}
+		health.Healthy = health.Healthy && podStatus.Ready
+		health.Pods = append(health.Pods, podStatus)
+	}
+	return health, nil
+}
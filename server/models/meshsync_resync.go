@@ -0,0 +1,88 @@
+package models
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/gofrs/uuid"
+)
+
+// meshsyncStoreWaiters holds, for each connection, the resyncs waiting for the next store of MeshSync to be persisted
+var meshsyncStoreWaiters = struct {
+	mu      sync.Mutex
+	waiters map[uuid.UUID][]chan int
+}{waiters: make(map[uuid.UUID][]chan int)}
+
+func waitMeshsyncStore(connectionID uuid.UUID) chan int {
+	ch := make(chan int, 1)
+	meshsyncStoreWaiters.mu.Lock()
+	defer meshsyncStoreWaiters.mu.Unlock()
+	meshsyncStoreWaiters.waiters[connectionID] = append(meshsyncStoreWaiters.waiters[connectionID], ch)
+	return ch
+}
+
+func stopWaitingMeshsyncStore(connectionID uuid.UUID, ch chan int) {
+	meshsyncStoreWaiters.mu.Lock()
+	defer meshsyncStoreWaiters.mu.Unlock()
+	waiters := meshsyncStoreWaiters.waiters[connectionID]
+	for i, waiter := range waiters {
+		if waiter == ch {
+			meshsyncStoreWaiters.waiters[connectionID] = append(waiters[:i], waiters[i+1:]...)
+			break
+		}
+	}
+	if len(meshsyncStoreWaiters.waiters[connectionID]) == 0 {
+		delete(meshsyncStoreWaiters.waiters, connectionID)
+	}
+}
+
+// notifyMeshsyncStorePersisted releases the resyncs of the connection waiting for the store, with the count of resources persisted
+func notifyMeshsyncStorePersisted(connectionID uuid.UUID, persisted int) {
+	meshsyncStoreWaiters.mu.Lock()
+	waiters := meshsyncStoreWaiters.waiters[connectionID]
+	delete(meshsyncStoreWaiters.waiters, connectionID)
+	meshsyncStoreWaiters.mu.Unlock()
+	for _, ch := range waiters {
+		ch <- persisted
+	}
+}
+
+// Resync asks MeshSync for its whole store of the resources of the cluster and waits for it to be persisted,
+// refreshing the inventory of the resources drifted from the cluster. It returns the count of resources persisted.
+func (mh *MeshsyncDataHandler) Resync(ctx context.Context) (int, error) {
+	ch := waitMeshsyncStore(mh.ConnectionID)
+	if err := mh.requestMeshsyncStore(); err != nil {
+		stopWaitingMeshsyncStore(mh.ConnectionID, ch)
+		return 0, err
+	}
+	select {
+	case persisted := <-ch:
+		return persisted, nil
+	case <-ctx.Done():
+		stopWaitingMeshsyncStore(mh.ConnectionID, ch)
+		return 0, ctx.Err()
+	}
+}
+
+// ResyncMeshsyncData performs a full resync of the resources of the cluster of the context through its MeshSync data handler,
+// within the timeout. It fails with ErrMeshSyncNotConnected when MeshSync is not connected for the context.
+func (mch *MesheryControllersHelper) ResyncMeshsyncData(ctx context.Context, k8sContext K8sContext, timeout time.Duration) (int, error) {
+	mch.mu.Lock()
+	msDataHandler, ok := mch.ctxMeshsyncDataHandlerMap[k8sContext.ID]
+	mch.mu.Unlock()
+	if !ok {
+		return 0, ErrMeshSyncNotConnected(k8sContext.Name)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	persisted, err := msDataHandler.Resync(ctx)
+	switch err {
+	case context.DeadlineExceeded:
+		return 0, ErrMeshSyncResyncTimeout(k8sContext.Name, timeout)
+	case context.Canceled:
+		return 0, ErrMeshSyncResyncCancelled(err, k8sContext.Name)
+	}
+	return persisted, err
+}
@@ -0,0 +1,101 @@
+package models
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/gofrs/uuid"
+	"github.com/layer5io/meshkit/broker"
+	"github.com/layer5io/meshkit/logger"
+	"github.com/layer5io/meshkit/models/controllers"
+	"github.com/sirupsen/logrus"
+)
+
+// storeReplyingBroker replies to the requests of the store of MeshSync as if the given count of resources was persisted
+type storeReplyingBroker struct {
+	broker.Handler
+	connectionID uuid.UUID
+	persisted    int
+	subjects     chan string
+}
+
+func (b *storeReplyingBroker) Publish(subject string, _ *broker.Message) error {
+	b.subjects <- subject
+	go notifyMeshsyncStorePersisted(b.connectionID, b.persisted)
+	return nil
+}
+
+func TestMesheryControllersHelperResyncMeshsyncData(t *testing.T) {
+	log, err := logger.New("test", logger.Options{LogLevel: int(logrus.InfoLevel)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	mch := NewMesheryControllersHelper(log, controllers.OperatorDeploymentConfig{}, nil)
+	k8sContext := K8sContext{ID: "ctx", Name: "staging"}
+
+	if _, err := mch.ResyncMeshsyncData(context.Background(), k8sContext, time.Second); err == nil {
+		t.Fatal("ResyncMeshsyncData() expected an error when MeshSync is not connected")
+	}
+
+	connectionID := uuid.Must(uuid.NewV4())
+	b := &storeReplyingBroker{connectionID: connectionID, persisted: 3, subjects: make(chan string, 1)}
+	mch.ctxMeshsyncDataHandlerMap[k8sContext.ID] = MeshsyncDataHandler{broker: b, log: log, ConnectionID: connectionID}
+
+	persisted, err := mch.ResyncMeshsyncData(context.Background(), k8sContext, 5*time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if persisted != 3 {
+		t.Errorf("ResyncMeshsyncData() = %d, want the 3 resources persisted", persisted)
+	}
+	if subject := <-b.subjects; subject != MeshsyncRequestSubject {
+		t.Errorf("store requested on %q, want %q", subject, MeshsyncRequestSubject)
+	}
+
+	// a store which never comes times out
+	b.connectionID = uuid.Must(uuid.NewV4())
+	if _, err := mch.ResyncMeshsyncData(context.Background(), k8sContext, 50*time.Millisecond); err == nil {
+		t.Error("ResyncMeshsyncData() expected a timeout when MeshSync does not reply")
+	}
+}
+
+func TestMesheryControllersHelperMeshsyncInterval(t *testing.T) {
+	log, err := logger.New("test", logger.Options{LogLevel: int(logrus.InfoLevel)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	mch := NewMesheryControllersHelper(log, controllers.OperatorDeploymentConfig{}, nil)
+	// the interval changed while disconnected is applied on reconnect, to the context reconnected only
+	mch.SetMeshsyncInterval("staging", time.Minute)
+	for _, ctxID := range []string{"staging", "prod"} {
+		mch.ctxControllerHandlersMap[ctxID] = nil
+		mch.ctxMeshsyncDataHandlerMap[ctxID] = MeshsyncDataHandler{log: log, resync: newMeshsyncResync(0)}
+	}
+	staging, prod := mch.ctxMeshsyncDataHandlerMap["staging"].resync, mch.ctxMeshsyncDataHandlerMap["prod"].resync
+
+	mch.UpdateMeshsynDataHandlers(context.Background(), "staging", uuid.Nil, uuid.Nil, uuid.Nil, nil, 5*time.Minute)
+	deadline := time.Now().Add(5 * time.Second)
+	for staging.get() != time.Minute && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	if staging.get() != time.Minute || prod.get() != 0 {
+		t.Errorf("sync intervals = %s and %s, want 1m for the reconnected context and none for the other", staging.get(), prod.get())
+	}
+
+	msDataHandler := mch.ctxMeshsyncDataHandlerMap["prod"]
+	stopped := make(chan struct{})
+	go func() {
+		msDataHandler.resyncPeriodically()
+		close(stopped)
+	}()
+	mch.RemoveMeshsyncDataHandler("prod")
+	select {
+	case <-stopped:
+	case <-time.After(5 * time.Second):
+		t.Error("the periodic resync did not stop along with its handler")
+	}
+	if _, ok := mch.GetMeshSyncDataHandlersForEachContext()["prod"]; ok {
+		t.Error("RemoveMeshsyncDataHandler() left the handler of the context")
+	}
+}
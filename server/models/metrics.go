@@ -0,0 +1,33 @@
+package models
+
+import (
+	"context"
+
+	"github.com/layer5io/meshkit/models/events"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+)
+
+// The meter is obtained from the global provider, it delegates to the OTLP provider registered
+// by the server when "OTEL_ENABLED" is set and is a no-op otherwise.
+var meter = otel.Meter("github.com/layer5io/meshery/server/models")
+
+// EventPersistFailures counts the events which could not be persisted by the provider, by category and action of the event.
+// Such events are lost from the audit trail even though the operation that emitted them succeeded.
+// It is exported over OTLP, as meshery_event_persist_failures_total once translated to Prometheus.
+var EventPersistFailures, _ = meter.Int64Counter(
+	"meshery.event.persist_failures",
+	metric.WithDescription("Total number of events that failed to be persisted"),
+)
+
+// Attributes of the event metrics
+const (
+	attrEventCategory = attribute.Key("meshery.event.category")
+	attrEventAction   = attribute.Key("meshery.event.action")
+)
+
+// countEventPersistFailure counts the event as one which could not be persisted
+func countEventPersistFailure(event *events.Event) {
+	EventPersistFailures.Add(context.Background(), 1, metric.WithAttributes(attrEventCategory.String(event.Category), attrEventAction.String(event.Action)))
+}
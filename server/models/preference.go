@@ -5,6 +5,7 @@ import (
 	"time"
 
 	"github.com/grafana-tools/sdk"
+	"github.com/layer5io/meshery/server/models/connections"
 )
 
 // K8SNode - represents a kubernetes node
@@ -52,6 +53,14 @@ type LoadTestPreferences struct {
 	LoadGenerator      string `json:"gen,omitempty"`
 }
 
+// K8sConnectionPreferences represents the preferences for the connections of the uploaded kubeconfigs
+type K8sConnectionPreferences struct {
+	// DefaultState is the state new connections are taken to, "connected" unless set to "ignored" to connect them manually
+	DefaultState connections.ConnectionStatus `json:"defaultState,omitempty"`
+	// NameTemplate names the new connections from the detected metadata of their clusters, see ParseK8sContextNameTemplate
+	NameTemplate string `json:"nameTemplate,omitempty"`
+}
+
 // Parameters to updates Anonymous stats
 type PreferenceParams struct {
 	AnonymousUsageStats  bool `json:"anonymousUsageStats"`
@@ -60,15 +69,24 @@ type PreferenceParams struct {
 
 // Preference represents the data stored in session / local DB
 type Preference struct {
-	MeshAdapters              []*Adapter             `json:"meshAdapters,omitempty"`
-	Grafana                   *Grafana               `json:"grafana,omitempty"`
-	Prometheus                *Prometheus            `json:"prometheus,omitempty"`
-	LoadTestPreferences       *LoadTestPreferences   `json:"loadTestPrefs,omitempty"`
-	AnonymousUsageStats       bool                   `json:"anonymousUsageStats"`
-	AnonymousPerfResults      bool                   `json:"anonymousPerfResults"`
-	UpdatedAt                 time.Time              `json:"updated_at,omitempty"`
-	UsersExtensionPreferences map[string]interface{} `json:"usersExtensionPreferences,omitempty"`
-	RemoteProviderPreferences map[string]interface{} `json:"remoteProviderPreferences,omitempty"`
+	MeshAdapters              []*Adapter                `json:"meshAdapters,omitempty"`
+	Grafana                   *Grafana                  `json:"grafana,omitempty"`
+	Prometheus                *Prometheus               `json:"prometheus,omitempty"`
+	LoadTestPreferences       *LoadTestPreferences      `json:"loadTestPrefs,omitempty"`
+	K8sConnectionPreferences  *K8sConnectionPreferences `json:"k8sConnectionPrefs,omitempty"`
+	AnonymousUsageStats       bool                      `json:"anonymousUsageStats"`
+	AnonymousPerfResults      bool                      `json:"anonymousPerfResults"`
+	UpdatedAt                 time.Time                 `json:"updated_at,omitempty"`
+	UsersExtensionPreferences map[string]interface{}    `json:"usersExtensionPreferences,omitempty"`
+	RemoteProviderPreferences map[string]interface{}    `json:"remoteProviderPreferences,omitempty"`
+}
+
+// K8sConnectionDefaultState returns the state the new connections of the user are taken to, "connected" by default
+func (p *Preference) K8sConnectionDefaultState() connections.ConnectionStatus {
+	if p == nil || p.K8sConnectionPreferences == nil || p.K8sConnectionPreferences.DefaultState == "" {
+		return connections.CONNECTED
+	}
+	return p.K8sConnectionPreferences.DefaultState
 }
 
 func init() {
@@ -0,0 +1,27 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/layer5io/meshery/server/models/connections"
+)
+
+func TestK8sConnectionDefaultState(t *testing.T) {
+	tests := []struct {
+		name string
+		pref *Preference
+		want connections.ConnectionStatus
+	}{
+		{"no preferences", nil, connections.CONNECTED},
+		{"no connection preferences", &Preference{}, connections.CONNECTED},
+		{"unset default state", &Preference{K8sConnectionPreferences: &K8sConnectionPreferences{}}, connections.CONNECTED},
+		{"ignored", &Preference{K8sConnectionPreferences: &K8sConnectionPreferences{DefaultState: connections.IGNORED}}, connections.IGNORED},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.pref.K8sConnectionDefaultState(); got != tt.want {
+				t.Errorf("K8sConnectionDefaultState() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,47 @@
+package models
+
+import (
+	stderrors "errors"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/layer5io/meshkit/errors"
+)
+
+// IsTransientProviderError reports whether a request to the provider failed for a reason which may not persist,
+// hence is worth retrying: the provider was unreachable, or answered with a 5xx or 429 status.
+// A context already persisted, a 4xx status or any other error is not transient.
+func IsTransientProviderError(err error) bool {
+	if err == nil || err == ErrContextAlreadyPersisted {
+		return false
+	}
+	var netErr net.Error
+	if stderrors.As(err, &netErr) {
+		return true
+	}
+	mErr, ok := errors.Is(err)
+	if !ok {
+		return false
+	}
+	switch mErr.Code {
+	case ErrUnreachableRemoteProviderCode:
+		return true
+	case ErrFetchCode, ErrPostCode:
+		status := providerErrorStatusCode(mErr)
+		return status >= http.StatusInternalServerError || status == http.StatusTooManyRequests
+	}
+	return false
+}
+
+// providerErrorStatusCode returns the status code recorded in the long description of ErrFetch and ErrPost, 0 if none
+func providerErrorStatusCode(err *errors.Error) int {
+	for _, description := range err.LongDescription {
+		if code, ok := strings.CutPrefix(description, "Status Code: "); ok {
+			status, _ := strconv.Atoi(code)
+			return status
+		}
+	}
+	return 0
+}
@@ -2,6 +2,7 @@ package models
 
 import (
 	"net/http"
+	"time"
 
 	"github.com/gofrs/uuid"
 	"github.com/layer5io/meshery/server/models/connections"
@@ -314,6 +315,12 @@ const (
 
 	RegistryManagerKey ContextKey = "registrymanagerkey"
 
+	// LoggerCtxKey is the context key for persisting a request scoped logger to context
+	LoggerCtxKey ContextKey = "logger"
+
+	// K8sComponentsRegistrationProgressCtxKey is the context key for persisting the callback receiving the outcome of each component registered
+	K8sComponentsRegistrationProgressCtxKey ContextKey = "k8s_components_registration_progress"
+
 	HandlerKey               ContextKey = "handlerkey"
 	SystemIDKey              ContextKey = "systemidKey"
 	MesheryServerURL         ContextKey = "mesheryserverurl"
@@ -393,6 +400,14 @@ type Provider interface {
 	DeleteK8sContext(token, id string) (K8sContext, error)
 	GetK8sContext(token, connectionID string) (K8sContext, error)
 	LoadAllK8sContext(token string) ([]*K8sContext, error)
+	SetK8sContextPinned(token, id string, pinned bool) (K8sContext, error)
+	SetK8sContextNotes(token, id, notes string) (K8sContext, error)
+	SetK8sContextSyncInterval(token, id, syncInterval string) (K8sContext, error)
+	// PatchK8sContextsLabels patches the labels of the contexts, in a single transaction when the provider supports it
+	PatchK8sContextsLabels(token string, ids []string, patch K8sContextLabelsPatch) []K8sContextLabelsResult
+	// SetK8sContextDeletedAt moves the context to the recycle bin, or restores it when deletedAt is nil
+	SetK8sContextDeletedAt(token, id string, deletedAt *time.Time) (K8sContext, error)
+	GetDeletedK8sContexts(token string) ([]*K8sContext, error)
 	// SetCurrentContext(token, id string) (K8sContext, error)
 	// GetCurrentContext(token string) (K8sContext, error)
 
@@ -462,7 +477,7 @@ type Provider interface {
 	GetConnectionsStatus(req *http.Request, userID string) (*connections.ConnectionsStatusPage, error)
 	UpdateConnection(req *http.Request, conn *connections.Connection) (*connections.Connection, error)
 	UpdateConnectionById(req *http.Request, conn *ConnectionPayload, connId string) (*connections.Connection, error)
-	UpdateConnectionStatusByID(token string, connectionID uuid.UUID, connectionStatus connections.ConnectionStatus) (*connections.Connection, int, error)
+	UpdateConnectionStatusByID(token string, connectionID uuid.UUID, connectionStatus connections.ConnectionStatus, statusReason string) (*connections.Connection, int, error)
 	DeleteConnection(req *http.Request, connID uuid.UUID) (*connections.Connection, error)
 	DeleteMesheryConnection() error
 
@@ -495,3 +510,16 @@ type Provider interface {
 	AddDesignToWorkspace(req *http.Request, workspaceID string, designID string) ([]byte, error)
 	RemoveDesignFromWorkspace(req *http.Request, workspaceID string, designID string) ([]byte, error)
 }
+
+// K8sContextPersister is implemented by the providers which may not be able to persist Kubernetes contexts,
+// e.g. the remote providers lacking the "persist-connection" capability.
+type K8sContextPersister interface {
+	SupportsK8sContextPersistence() bool
+}
+
+// SupportsK8sContextPersistence returns false if the provider is known not to persist Kubernetes contexts,
+// the providers not implementing K8sContextPersister are assumed to support it.
+func SupportsK8sContextPersistence(provider Provider) bool {
+	persister, ok := provider.(K8sContextPersister)
+	return !ok || persister.SupportsK8sContextPersistence()
+}
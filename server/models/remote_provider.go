@@ -633,28 +633,122 @@ func (l *RemoteProvider) HandleUnAuthenticated(w http.ResponseWriter, req *http.
 	http.Redirect(w, req, "/provider", http.StatusFound)
 }
 
-func (l *RemoteProvider) SaveK8sContext(token string, k8sContext K8sContext) (connections.Connection, error) {
-
-	k8sServerID := *k8sContext.KubernetesServerID
+// k8sContextConnectionMetadata is the metadata of the connection of the context
+func k8sContextConnectionMetadata(k8sContext K8sContext) map[string]interface{} {
+	var k8sServerID, mesheryInstanceID uuid.UUID
+	if k8sContext.KubernetesServerID != nil {
+		k8sServerID = *k8sContext.KubernetesServerID
+	}
+	if k8sContext.MesheryInstanceID != nil {
+		mesheryInstanceID = *k8sContext.MesheryInstanceID
+	}
 
 	_metadata := map[string]string{
 		"id":                   k8sContext.ID,
 		"server":               k8sContext.Server,
-		"meshery_instance_id":  k8sContext.MesheryInstanceID.String(),
+		"meshery_instance_id":  mesheryInstanceID.String(),
 		"deployment_type":      k8sContext.DeploymentType,
 		"version":              k8sContext.Version,
 		"name":                 k8sContext.Name,
 		"kubernetes_server_id": k8sServerID.String(),
+		"managed":              strconv.FormatBool(k8sContext.Managed),
+		"pinned":               strconv.FormatBool(k8sContext.Pinned),
+		"notes":                k8sContext.Notes,
+		"sync_interval":        k8sContext.SyncInterval,
+	}
+	if k8sContext.Namespace != "" {
+		_metadata["namespace"] = k8sContext.Namespace
+	}
+	if k8sContext.Source != "" {
+		_metadata["source"] = k8sContext.Source
+	}
+	if k8sContext.OriginalServer != "" {
+		_metadata["original_server"] = k8sContext.OriginalServer
+	}
+	if k8sContext.OriginalName != "" {
+		_metadata["original_name"] = k8sContext.OriginalName
+	}
+	if k8sContext.ProxyURL != "" {
+		_metadata["proxy_url"] = k8sContext.ProxyURL
+	}
+	// the timeouts of the transport to the API server, the defaults apply when unset
+	for key, timeout := range map[string]string{
+		"dial_timeout":            k8sContext.DialTimeout,
+		"tls_handshake_timeout":   k8sContext.TLSHandshakeTimeout,
+		"response_header_timeout": k8sContext.ResponseHeaderTimeout,
+	} {
+		if timeout != "" {
+			_metadata[key] = timeout
+		}
+	}
+	if k8sContext.TLSServerName != "" {
+		_metadata["tls_server_name"] = k8sContext.TLSServerName
+	}
+	if k8sContext.ExpiresAt != nil {
+		_metadata["expires_at"] = k8sContext.ExpiresAt.UTC().Format(time.RFC3339)
+	}
+	if k8sContext.DeletedAt != nil {
+		_metadata["deleted_at"] = k8sContext.DeletedAt.UTC().Format(time.RFC3339)
+	}
+	// the rate limits of the context only, the defaults apply when unset
+	if k8sContext.QPS > 0 {
+		_metadata["qps"] = strconv.FormatFloat(float64(k8sContext.QPS), 'f', -1, 32)
+	}
+	if k8sContext.Burst > 0 {
+		_metadata["burst"] = strconv.Itoa(k8sContext.Burst)
 	}
 	metadata := make(map[string]interface{}, len(_metadata))
 	for k, v := range _metadata {
 		metadata[k] = v
 	}
+	if len(k8sContext.Labels) != 0 {
+		metadata["labels"] = k8sContext.Labels
+	}
+	if len(k8sContext.Namespaces) != 0 {
+		metadata["namespaces"] = k8sContext.Namespaces
+	}
+	return metadata
+}
+
+// k8sContextOptionalMetadataKeys are the keys of the metadata of the connection which are left out when unset on the context
+var k8sContextOptionalMetadataKeys = []string{
+	"namespace", "namespaces", "source", "original_server", "original_name", "proxy_url",
+	"dial_timeout", "tls_handshake_timeout", "response_header_timeout", "tls_server_name", "expires_at", "deleted_at", "qps", "burst", "labels",
+}
+
+// mergeK8sContextConnectionMetadata merges the metadata of the context into the existing metadata of its connection,
+// the keys the context does not manage are kept and its optional keys which are unset are removed.
+func mergeK8sContextConnectionMetadata(existing map[string]interface{}, k8sContext K8sContext) map[string]interface{} {
+	metadata := make(map[string]interface{}, len(existing))
+	for k, v := range existing {
+		metadata[k] = v
+	}
+	for _, k := range k8sContextOptionalMetadataKeys {
+		delete(metadata, k)
+	}
+	for k, v := range k8sContextConnectionMetadata(k8sContext) {
+		metadata[k] = v
+	}
+	return metadata
+}
+
+// SupportsK8sContextPersistence returns true if the provider has the "persist-connection" capability
+func (l *RemoteProvider) SupportsK8sContextPersistence() bool {
+	return l.Capabilities.IsSupported(PersistConnection)
+}
+
+func (l *RemoteProvider) SaveK8sContext(token string, k8sContext K8sContext) (connections.Connection, error) {
+	metadata := k8sContextConnectionMetadata(k8sContext)
 
 	cred := map[string]interface{}{
 		"auth":    k8sContext.Auth,
 		"cluster": k8sContext.Cluster,
 	}
+	// the credentials of the proxy are secrets as much as the ones of the cluster
+	if k8sContext.ProxyUsername != "" || k8sContext.ProxyPassword != "" {
+		cred["proxy_username"] = k8sContext.ProxyUsername
+		cred["proxy_password"] = k8sContext.ProxyPassword
+	}
 
 	conn := &ConnectionPayload{
 		Kind:    "kubernetes",
@@ -763,6 +857,106 @@ func (l *RemoteProvider) LoadAllK8sContext(token string) ([]*K8sContext, error)
 	return results, nil
 }
 
+// SetK8sContextPinned pins or unpins the context of the connection with the given ID
+func (l *RemoteProvider) SetK8sContextPinned(token, connectionID string, pinned bool) (K8sContext, error) {
+	return l.updateK8sContext(token, connectionID, func(k8sContext *K8sContext) { k8sContext.Pinned = pinned })
+}
+
+// SetK8sContextNotes replaces the notes of the context of the connection with the given ID
+func (l *RemoteProvider) SetK8sContextNotes(token, connectionID, notes string) (K8sContext, error) {
+	return l.updateK8sContext(token, connectionID, func(k8sContext *K8sContext) { k8sContext.Notes = notes })
+}
+
+// SetK8sContextSyncInterval replaces the sync interval of the context of the connection with the given ID
+func (l *RemoteProvider) SetK8sContextSyncInterval(token, connectionID, syncInterval string) (K8sContext, error) {
+	return l.updateK8sContext(token, connectionID, func(k8sContext *K8sContext) { k8sContext.SyncInterval = syncInterval })
+}
+
+// updateK8sContext applies update to the context of the connection with the given ID and saves it
+func (l *RemoteProvider) updateK8sContext(token, connectionID string, update func(*K8sContext)) (K8sContext, error) {
+	k8sContext, err := l.GetK8sContext(token, connectionID)
+	if err != nil {
+		return K8sContext{}, err
+	}
+	update(&k8sContext)
+	if err := l.updateK8sContextConnection(token, connectionID, k8sContext); err != nil {
+		return K8sContext{}, err
+	}
+	return k8sContext, nil
+}
+
+// PatchK8sContextsLabels patches the labels of the contexts of the connections with the given IDs, the connections are
+// updated one by one as the remote provider has no transactions, the labels of the others are still updated when one fails
+func (l *RemoteProvider) PatchK8sContextsLabels(token string, connectionIDs []string, patch K8sContextLabelsPatch) []K8sContextLabelsResult {
+	results := make([]K8sContextLabelsResult, 0, len(connectionIDs))
+	for _, connectionID := range connectionIDs {
+		result := K8sContextLabelsResult{ID: connectionID}
+		k8sContext, err := l.GetK8sContext(token, connectionID)
+		if err == nil {
+			k8sContext.Labels = patch.Apply(k8sContext.Labels)
+			err = l.updateK8sContextConnection(token, connectionID, k8sContext)
+		}
+		result.Context, result.Err = k8sContext, err
+		results = append(results, result)
+	}
+	return results
+}
+
+// SetK8sContextDeletedAt moves the context of the connection with the given ID to the recycle bin, or restores it when deletedAt is nil
+func (l *RemoteProvider) SetK8sContextDeletedAt(token, connectionID string, deletedAt *time.Time) (K8sContext, error) {
+	return l.updateK8sContext(token, connectionID, func(k8sContext *K8sContext) { k8sContext.DeletedAt = deletedAt })
+}
+
+// GetDeletedK8sContexts returns the contexts of the connections in the recycle bin, whatever the status of their connection
+func (l *RemoteProvider) GetDeletedK8sContexts(token string) ([]*K8sContext, error) {
+	page := 0
+	pageSize := 25
+	results := []*K8sContext{}
+
+	for {
+		res, err := l.GetK8sContexts(token, strconv.Itoa(page), strconv.Itoa(pageSize), "", "", "", false)
+		if err != nil {
+			return results, err
+		}
+		var k8scontext MesheryK8sContextPage
+		err = json.Unmarshal(res, &k8scontext)
+		if err != nil {
+			return results, ErrMarshal(err, "kubernetes context")
+		}
+		for _, ctx := range k8scontext.Contexts {
+			if ctx.DeletedAt != nil {
+				results = append(results, ctx)
+			}
+		}
+
+		if (page+1)*pageSize >= k8scontext.TotalCount {
+			break
+		}
+
+		page++
+	}
+
+	return results, nil
+}
+
+// updateK8sContextConnection updates the metadata of the connection with the given ID with the one of the context,
+// the metadata is replaced as a whole by the remote provider so it is merged into the existing one first
+func (l *RemoteProvider) updateK8sContextConnection(token, connectionID string, k8sContext K8sContext) error {
+	existing, _, err := l.GetConnectionByID(token, uuid.FromStringOrNil(connectionID), "kubernetes")
+	if err != nil {
+		return err
+	}
+	conn := &ConnectionPayload{
+		ID:       uuid.FromStringOrNil(connectionID),
+		Kind:     "kubernetes",
+		Type:     "platform",
+		SubType:  "orchestrator",
+		MetaData: mergeK8sContextConnectionMetadata(existing.Metadata, k8sContext),
+	}
+	_, err = l.updateConnectionByID(token, conn, connectionID)
+	return err
+}
+
 func (l *RemoteProvider) DeleteK8sContext(token, id string) (K8sContext, error) {
 	logrus.Infof("attempting to delete kubernetes context from cloud for id: %s", id)
 	if !l.Capabilities.IsSupported(PersistConnection) {
@@ -3835,7 +4029,8 @@ func (l *RemoteProvider) GetConnectionsStatus(req *http.Request, userID string)
 	return &cp, nil
 }
 
-func (l *RemoteProvider) UpdateConnectionStatusByID(token string, connectionID uuid.UUID, connectionStatus connections.ConnectionStatus) (*connections.Connection, int, error) {
+// UpdateConnectionStatusByID updates the status of the connection, the reason of the status is passed along as the "reason" query parameter
+func (l *RemoteProvider) UpdateConnectionStatusByID(token string, connectionID uuid.UUID, connectionStatus connections.ConnectionStatus, statusReason string) (*connections.Connection, int, error) {
 	if !l.Capabilities.IsSupported(PersistConnection) {
 		logrus.Error("operation not available")
 		return nil, http.StatusForbidden, ErrInvalidCapability("PersistConnection", l.ProviderName)
@@ -3843,6 +4038,11 @@ func (l *RemoteProvider) UpdateConnectionStatusByID(token string, connectionID u
 	ep, _ := l.Capabilities.GetEndpointForFeature(PersistConnection)
 	bf := bytes.NewBuffer([]byte(connectionStatus))
 	remoteProviderURL, _ := url.Parse(fmt.Sprintf("%s%s/status/%s", l.RemoteProviderURL, ep, connectionID))
+	if statusReason != "" {
+		q := remoteProviderURL.Query()
+		q.Set("reason", statusReason)
+		remoteProviderURL.RawQuery = q.Encode()
+	}
 
 	cReq, _ := http.NewRequest(http.MethodPut, remoteProviderURL.String(), bf)
 
@@ -3860,6 +4060,9 @@ func (l *RemoteProvider) UpdateConnectionStatusByID(token string, connectionID u
 		if err = json.Unmarshal(bdr, &conn); err != nil {
 			return nil, http.StatusInternalServerError, ErrUnmarshal(err, "connection")
 		}
+		if conn.StatusReason == "" {
+			conn.StatusReason = statusReason
+		}
 		return &conn, resp.StatusCode, nil
 	}
 
@@ -3911,6 +4114,16 @@ func (l *RemoteProvider) UpdateConnection(req *http.Request, connection *connect
 
 // UpdateConnectionById - to update an existing connection using the connection id
 func (l *RemoteProvider) UpdateConnectionById(req *http.Request, connection *ConnectionPayload, connId string) (*connections.Connection, error) {
+	tokenString, err := l.GetToken(req)
+	if err != nil {
+		logrus.Error("error getting token: ", err)
+		return nil, err
+	}
+	return l.updateConnectionByID(tokenString, connection, connId)
+}
+
+// updateConnectionByID updates the connection with the given ID on behalf of the owner of the token
+func (l *RemoteProvider) updateConnectionByID(token string, connection *ConnectionPayload, connId string) (*connections.Connection, error) {
 	if !l.Capabilities.IsSupported(PersistConnection) {
 		logrus.Error("operation not available")
 		return nil, ErrInvalidCapability("PersistConnection", l.ProviderName)
@@ -3924,13 +4137,8 @@ func (l *RemoteProvider) UpdateConnectionById(req *http.Request, connection *Con
 	remoteProviderURL, _ := url.Parse(fmt.Sprintf("%s%s/%s", l.RemoteProviderURL, ep, connId))
 	logrus.Debugf("Making request to : %s", remoteProviderURL.String())
 	cReq, _ := http.NewRequest(http.MethodPut, remoteProviderURL.String(), bf)
-	tokenString, err := l.GetToken(req)
-	if err != nil {
-		logrus.Error("error getting token: ", err)
-		return nil, err
-	}
 
-	resp, err := l.DoRequest(cReq, tokenString)
+	resp, err := l.DoRequest(cReq, token)
 	if err != nil {
 		if resp == nil {
 			return nil, ErrUnreachableRemoteProvider(err)
@@ -72,19 +72,97 @@ func NewRouter(_ context.Context, h models.HandlerInterface, port int, g http.Ha
 
 	gMux.Handle("/api/system/kubernetes", h.ProviderMiddleware(h.AuthMiddleware(h.SessionInjectorMiddleware(h.K8SConfigHandler), models.ProviderAuth))).
 		Methods("POST")
+	gMux.Handle("/api/system/kubernetes/schema", h.ProviderMiddleware(h.AuthMiddleware(http.HandlerFunc(h.K8SConfigSchemaHandler), models.NoAuth))).
+		Methods("GET")
+	gMux.Handle("/api/system/kubernetes/auth-plugins", h.ProviderMiddleware(h.AuthMiddleware(http.HandlerFunc(h.K8sAuthPluginsHandler), models.NoAuth))).
+		Methods("GET")
 	gMux.Handle("/api/system/kubernetes/ping", h.ProviderMiddleware(h.AuthMiddleware(h.SessionInjectorMiddleware(h.KubernetesPingHandler), models.ProviderAuth))).
 		Methods("GET")
 
 	gMux.Handle("/api/system/kubernetes/contexts", h.ProviderMiddleware(h.AuthMiddleware(h.SessionInjectorMiddleware(h.GetContextsFromK8SConfig), models.ProviderAuth))).
 		Methods("POST")
+	gMux.Handle("/api/system/kubernetes/kubeconfig/test", h.ProviderMiddleware(h.AuthMiddleware(h.SessionInjectorMiddleware(h.K8sConfigTestHandler), models.ProviderAuth))).
+		Methods("POST")
+	gMux.Handle("/api/system/kubernetes/jobs/{job_id}", h.ProviderMiddleware(h.AuthMiddleware(h.SessionInjectorMiddleware(h.K8sRegistrationJobCancelHandler), models.ProviderAuth))).
+		Methods("DELETE")
+	gMux.Handle("/api/system/kubernetes/jobs/{job_id}", h.ProviderMiddleware(h.AuthMiddleware(h.SessionInjectorMiddleware(h.K8sRegistrationJobStatusHandler), models.ProviderAuth))).
+		Methods("GET")
+	gMux.Handle("/api/system/kubernetes/primary", h.ProviderMiddleware(h.AuthMiddleware(h.SessionInjectorMiddleware(h.PrimaryK8sContextHandler), models.ProviderAuth))).
+		Methods("GET")
+	gMux.Handle("/api/system/kubernetes/cache", h.ProviderMiddleware(h.AuthMiddleware(h.SessionInjectorMiddleware(h.GetK8sCacheHandler), models.ProviderAuth))).
+		Methods("GET")
+	gMux.Handle("/api/system/kubernetes/cache", h.ProviderMiddleware(h.AuthMiddleware(h.SessionInjectorMiddleware(h.DeleteK8sCacheHandler), models.ProviderAuth))).
+		Methods("DELETE")
+	gMux.Handle("/api/system/kubernetes/stats", h.ProviderMiddleware(h.AuthMiddleware(h.SessionInjectorMiddleware(h.K8sStatsHandler), models.ProviderAuth))).
+		Methods("GET")
 	gMux.Handle("/api/system/kubernetes/register", h.ProviderMiddleware(h.AuthMiddleware(h.SessionInjectorMiddleware(h.K8sRegistrationHandler), models.ProviderAuth))).
 		Methods("POST")
+	gMux.Handle("/api/system/kubernetes/components/register-manifest", h.ProviderMiddleware(h.AuthMiddleware(h.SessionInjectorMiddleware(h.K8sComponentsRegisterManifestHandler), models.ProviderAuth))).
+		Methods("POST")
 	gMux.Handle("/api/system/kubernetes/contexts", h.ProviderMiddleware(h.AuthMiddleware(h.SessionInjectorMiddleware(h.GetAllContexts), models.ProviderAuth))).
 		Methods("GET")
+	gMux.Handle("/api/system/kubernetes/contexts/reconcile", h.ProviderMiddleware(h.AuthMiddleware(h.SessionInjectorMiddleware(h.K8sContextsReconcileHandler), models.ProviderAuth))).
+		Methods("POST")
+	gMux.Handle("/api/system/kubernetes/contexts/apply", h.ProviderMiddleware(h.AuthMiddleware(h.SessionInjectorMiddleware(h.K8sContextsApplyHandler), models.ProviderAuth))).
+		Methods("POST")
+	gMux.Handle("/api/system/kubernetes/contexts/compare", h.ProviderMiddleware(h.AuthMiddleware(h.SessionInjectorMiddleware(h.K8sComponentsCompareHandler), models.ProviderAuth))).
+		Methods("GET")
+	gMux.Handle("/api/system/kubernetes/contexts/conflicts", h.ProviderMiddleware(h.AuthMiddleware(h.SessionInjectorMiddleware(h.K8sContextConflictsHandler), models.ProviderAuth))).
+		Methods("GET")
+	gMux.Handle("/api/system/kubernetes/contexts/deleted", h.ProviderMiddleware(h.AuthMiddleware(h.SessionInjectorMiddleware(h.DeletedK8sContextsHandler), models.ProviderAuth))).
+		Methods("GET")
+	gMux.Handle("/api/system/kubernetes/contexts/import", h.ProviderMiddleware(h.AuthMiddleware(h.SessionInjectorMiddleware(h.PortableK8sContextImportHandler), models.ProviderAuth))).
+		Methods("POST")
+	gMux.Handle("/api/system/kubernetes/contexts/tags", h.ProviderMiddleware(h.AuthMiddleware(h.SessionInjectorMiddleware(h.K8sContextsLabelsHandler), models.ProviderAuth))).
+		Methods("POST")
+	gMux.Handle("/api/system/kubernetes/contexts/by-server-id/{uid}", h.ProviderMiddleware(h.AuthMiddleware(h.SessionInjectorMiddleware(h.GetContextByServerID), models.ProviderAuth))).
+		Methods("GET")
 	gMux.Handle("/api/system/kubernetes/contexts/{id}", h.ProviderMiddleware(h.AuthMiddleware(h.SessionInjectorMiddleware(h.GetContext), models.ProviderAuth))).
 		Methods("GET")
 	gMux.Handle("/api/system/kubernetes/contexts/{id}", h.ProviderMiddleware(h.AuthMiddleware(h.SessionInjectorMiddleware(h.DeleteContext), models.ProviderAuth))).
 		Methods("DELETE")
+	gMux.Handle("/api/system/kubernetes/contexts/{id}/pin", h.ProviderMiddleware(h.AuthMiddleware(h.SessionInjectorMiddleware(h.K8sContextPinHandler), models.ProviderAuth))).
+		Methods("PATCH")
+	gMux.Handle("/api/system/kubernetes/contexts/{id}/notes", h.ProviderMiddleware(h.AuthMiddleware(h.SessionInjectorMiddleware(h.K8sContextNotesHandler), models.ProviderAuth))).
+		Methods("PATCH")
+	gMux.Handle("/api/system/kubernetes/contexts/{id}/restore", h.ProviderMiddleware(h.AuthMiddleware(h.SessionInjectorMiddleware(h.RestoreK8sContextHandler), models.ProviderAuth))).
+		Methods("POST")
+	gMux.Handle("/api/system/kubernetes/contexts/{id}/sync-interval", h.ProviderMiddleware(h.AuthMiddleware(h.SessionInjectorMiddleware(h.K8sContextSyncIntervalHandler), models.ProviderAuth))).
+		Methods("PATCH")
+	gMux.Handle("/api/system/kubernetes/contexts/{connection_id}/diagnostics", h.ProviderMiddleware(h.AuthMiddleware(h.SessionInjectorMiddleware(h.K8sContextDiagnosticsHandler), models.ProviderAuth))).
+		Methods("GET")
+	gMux.Handle("/api/system/kubernetes/contexts/{connection_id}/meshery-rbac", h.ProviderMiddleware(h.AuthMiddleware(h.SessionInjectorMiddleware(h.MesheryRBACCheckHandler), models.ProviderAuth))).
+		Methods("POST")
+	gMux.Handle("/api/system/kubernetes/contexts/{connection_id}/reconnect", h.ProviderMiddleware(h.AuthMiddleware(h.SessionInjectorMiddleware(h.K8sContextReconnectHandler), models.ProviderAuth))).
+		Methods("POST")
+	gMux.Handle("/api/system/kubernetes/contexts/{connection_id}/portable", h.ProviderMiddleware(h.AuthMiddleware(h.SessionInjectorMiddleware(h.PortableK8sContextExportHandler), models.ProviderAuth))).
+		Methods("GET")
+	gMux.Handle("/api/system/kubernetes/contexts/{connection_id}/components/export", h.ProviderMiddleware(h.AuthMiddleware(h.SessionInjectorMiddleware(h.K8sComponentsExportHandler), models.ProviderAuth))).
+		Methods("GET")
+	gMux.Handle("/api/system/kubernetes/contexts/{connection_id}/components/refresh-metadata", h.ProviderMiddleware(h.AuthMiddleware(h.SessionInjectorMiddleware(h.K8sComponentsRefreshMetadataHandler), models.ProviderAuth))).
+		Methods("POST")
+	gMux.Handle("/api/system/kubernetes/contexts/{connection_id}/components/status", h.ProviderMiddleware(h.AuthMiddleware(h.SessionInjectorMiddleware(h.K8sComponentsStatusHandler), models.ProviderAuth))).
+		Methods("GET")
+	gMux.Handle("/api/system/kubernetes/contexts/{connection_id}/components/watch", h.ProviderMiddleware(h.AuthMiddleware(h.SessionInjectorMiddleware(h.K8sComponentsWatchStartHandler), models.ProviderAuth))).
+		Methods("POST")
+	gMux.Handle("/api/system/kubernetes/contexts/{connection_id}/components/watch", h.ProviderMiddleware(h.AuthMiddleware(h.SessionInjectorMiddleware(h.K8sComponentsWatchStopHandler), models.ProviderAuth))).
+		Methods("DELETE")
+	gMux.Handle("/api/system/kubernetes/contexts/{connection_id}/events", h.ProviderMiddleware(h.AuthMiddleware(h.SessionInjectorMiddleware(h.K8sContextEventsHandler), models.ProviderAuth))).
+		Methods("GET")
+	gMux.Handle("/api/system/kubernetes/contexts/{connection_id}/validate-credentials", h.ProviderMiddleware(h.AuthMiddleware(h.SessionInjectorMiddleware(h.K8sContextValidateCredentialsHandler), models.ProviderAuth))).
+		Methods("POST")
+	gMux.Handle("/api/system/kubernetes/contexts/{connection_id}/crds", h.ProviderMiddleware(h.AuthMiddleware(h.SessionInjectorMiddleware(h.K8sContextCRDsHandler), models.ProviderAuth))).
+		Methods("POST")
+	gMux.Handle("/api/system/kubernetes/contexts/{connection_id}/meshsync", h.ProviderMiddleware(h.AuthMiddleware(h.SessionInjectorMiddleware(h.GetMeshSyncHealth), models.ProviderAuth))).
+		Methods("GET")
+	gMux.Handle("/api/system/kubernetes/contexts/{connection_id}/resync", h.ProviderMiddleware(h.AuthMiddleware(h.SessionInjectorMiddleware(h.MeshSyncResyncHandler), models.ProviderAuth))).
+		Methods("POST")
+	gMux.Handle("/api/system/kubernetes/contexts/{connection_id}/openapi", h.ProviderMiddleware(h.AuthMiddleware(h.SessionInjectorMiddleware(h.K8sContextOpenAPIHandler), models.ProviderAuth))).
+		Methods("GET")
+	gMux.Handle("/api/system/kubernetes/contexts/{connection_id}/capabilities", h.ProviderMiddleware(h.AuthMiddleware(h.SessionInjectorMiddleware(h.K8sContextCapabilitiesHandler), models.ProviderAuth))).
+		Methods("GET")
+	gMux.Handle("/api/system/kubernetes/contexts/{connection_id}/operator-manifest", h.ProviderMiddleware(h.AuthMiddleware(h.SessionInjectorMiddleware(h.OperatorManifestHandler), models.ProviderAuth))).
+		Methods("GET")
 
 	gMux.Handle("/api/perf/profile", h.ProviderMiddleware(h.AuthMiddleware(h.SessionInjectorMiddleware(h.LoadTestHandler), models.ProviderAuth))).
 		Methods("GET", "POST")